@@ -0,0 +1,152 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"math"
+)
+
+// An Accumulator computes streaming statistics (count, mean, variance, min, max)
+// for each element of a float64 vector using Welford's algorithm.
+//
+// An Accumulator is not safe for concurrent use. To parallelize, use one
+// accumulator per goroutine and combine the results with Merge.
+type Accumulator struct {
+	n    int
+	mean []float64
+	m2   []float64
+	min  []float64
+	max  []float64
+}
+
+// Creates an accumulator for vectors of dimension dim.
+func NewAccumulator(dim int) *Accumulator {
+
+	acc := &Accumulator{
+		mean: make([]float64, dim),
+		m2:   make([]float64, dim),
+		min:  make([]float64, dim),
+		max:  make([]float64, dim),
+	}
+	for i := 0; i < dim; i++ {
+		acc.min[i] = math.Inf(1)
+		acc.max[i] = math.Inf(-1)
+	}
+	return acc
+}
+
+// Adds a vector to the accumulator.
+func (acc *Accumulator) Add(x []float64) error {
+
+	if len(x) != len(acc.mean) {
+		return fmt.Errorf("Vector dimension is %d, expected %d.", len(x), len(acc.mean))
+	}
+	acc.n++
+	n := float64(acc.n)
+	for i, v := range x {
+		delta := v - acc.mean[i]
+		acc.mean[i] += delta / n
+		acc.m2[i] += delta * (v - acc.mean[i])
+		if v < acc.min[i] {
+			acc.min[i] = v
+		}
+		if v > acc.max[i] {
+			acc.max[i] = v
+		}
+	}
+	return nil
+}
+
+// Adds all the vectors received from a channel until it is closed.
+func (acc *Accumulator) AddChannel(ch <-chan []float64) error {
+
+	for x := range ch {
+		if e := acc.Add(x); e != nil {
+			// Drain the channel so the producer doesn't block.
+			for _ = range ch {
+			}
+			return e
+		}
+	}
+	return nil
+}
+
+// Merges the statistics of other into acc.
+func (acc *Accumulator) Merge(other *Accumulator) error {
+
+	if len(other.mean) != len(acc.mean) {
+		return fmt.Errorf("Accumulator dimension is %d, expected %d.", len(other.mean), len(acc.mean))
+	}
+	if other.n == 0 {
+		return nil
+	}
+	na, nb := float64(acc.n), float64(other.n)
+	n := na + nb
+	for i := range acc.mean {
+		delta := other.mean[i] - acc.mean[i]
+		acc.mean[i] += delta * nb / n
+		acc.m2[i] += other.m2[i] + delta*delta*na*nb/n
+		acc.min[i] = math.Min(acc.min[i], other.min[i])
+		acc.max[i] = math.Max(acc.max[i], other.max[i])
+	}
+	acc.n += other.n
+	return nil
+}
+
+// Returns the dimension of the accumulator.
+func (acc *Accumulator) Dim() int {
+
+	return len(acc.mean)
+}
+
+// Returns the number of vectors added.
+func (acc *Accumulator) Count() int {
+
+	return acc.n
+}
+
+// Returns the mean of each element.
+func (acc *Accumulator) Mean() []float64 {
+
+	return append([]float64(nil), acc.mean...)
+}
+
+// Returns the sample variance of each element. The variance is zero when
+// fewer than two vectors were added.
+func (acc *Accumulator) Variance() []float64 {
+
+	v := make([]float64, len(acc.m2))
+	if acc.n < 2 {
+		return v
+	}
+	for i, m2 := range acc.m2 {
+		v[i] = m2 / float64(acc.n-1)
+	}
+	return v
+}
+
+// Returns the sample standard deviation of each element.
+func (acc *Accumulator) StdDev() []float64 {
+
+	v := acc.Variance()
+	for i := range v {
+		v[i] = math.Sqrt(v[i])
+	}
+	return v
+}
+
+// Returns the minimum of each element.
+func (acc *Accumulator) Min() []float64 {
+
+	return append([]float64(nil), acc.min...)
+}
+
+// Returns the maximum of each element.
+func (acc *Accumulator) Max() []float64 {
+
+	return append([]float64(nil), acc.max...)
+}
@@ -0,0 +1,85 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/gonum/floats"
+)
+
+func TestAccumulator(t *testing.T) {
+
+	df := synthFrame(1000, 4, 7)
+
+	// Sequential.
+	acc := NewAccumulator(5)
+	CheckError(t, acc.AddChannel(df.Float64SliceChannel("wifi", "acceleration")))
+	if acc.Count() != 1000 {
+		t.Fatalf("Count must be 1000, not %d.", acc.Count())
+	}
+
+	// Two passes over the data as a reference.
+	mean := make([]float64, 5)
+	variance := make([]float64, 5)
+	for i := 0; i < df.N(); i++ {
+		sl, e := df.Float64Slice(i, "wifi", "acceleration")
+		CheckError(t, e)
+		floats.Add(mean, sl)
+	}
+	floats.Scale(1/1000.0, mean)
+	for i := 0; i < df.N(); i++ {
+		sl, _ := df.Float64Slice(i, "wifi", "acceleration")
+		floats.Sub(sl, mean)
+		floats.Mul(sl, sl)
+		floats.Add(variance, sl)
+	}
+	floats.Scale(1/999.0, variance)
+
+	if !floats.EqualApprox(acc.Mean(), mean, 1e-9) {
+		t.Fatalf("mean %v doesn't match %v.", acc.Mean(), mean)
+	}
+	if !floats.EqualApprox(acc.Variance(), variance, 1e-9) {
+		t.Fatalf("variance %v doesn't match %v.", acc.Variance(), variance)
+	}
+
+	// Parallel accumulation must produce the same results.
+	parts := make([]*Accumulator, 4)
+	var wg sync.WaitGroup
+	for p := range parts {
+		parts[p] = NewAccumulator(5)
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := p; i < df.N(); i += len(parts) {
+				sl, _ := df.Float64Slice(i, "wifi", "acceleration")
+				parts[p].Add(sl)
+			}
+		}(p)
+	}
+	wg.Wait()
+	merged := NewAccumulator(5)
+	for _, part := range parts {
+		CheckError(t, merged.Merge(part))
+	}
+	if merged.Count() != 1000 {
+		t.Fatalf("Count must be 1000, not %d.", merged.Count())
+	}
+	if !floats.EqualApprox(merged.Mean(), mean, 1e-9) {
+		t.Fatalf("merged mean %v doesn't match %v.", merged.Mean(), mean)
+	}
+	if !floats.EqualApprox(merged.Variance(), variance, 1e-9) {
+		t.Fatalf("merged variance %v doesn't match %v.", merged.Variance(), variance)
+	}
+	if !floats.Equal(merged.Min(), acc.Min()) || !floats.Equal(merged.Max(), acc.Max()) {
+		t.Fatalf("merged min/max don't match.")
+	}
+
+	if e := acc.Add([]float64{1}); e == nil {
+		t.Fatalf("expected dimension error.")
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// An AggregateFunc reduces a list of values to a single value. Nil values
+// are removed before the function is called.
+type AggregateFunc func(values []float64) float64
+
+var aggregates = struct {
+	sync.RWMutex
+	m map[string]AggregateFunc
+}{m: make(map[string]AggregateFunc)}
+
+func init() {
+
+	RegisterAggregate("count", func(v []float64) float64 { return float64(len(v)) })
+	RegisterAggregate("sum", aggSum)
+	RegisterAggregate("mean", aggMean)
+	RegisterAggregate("min", func(v []float64) float64 {
+		m := math.NaN()
+		for i, x := range v {
+			if i == 0 || x < m {
+				m = x
+			}
+		}
+		return m
+	})
+	RegisterAggregate("max", func(v []float64) float64 {
+		m := math.NaN()
+		for i, x := range v {
+			if i == 0 || x > m {
+				m = x
+			}
+		}
+		return m
+	})
+	RegisterAggregate("std", func(v []float64) float64 {
+		if len(v) < 2 {
+			return math.NaN()
+		}
+		mean := aggMean(v)
+		var ss float64
+		for _, x := range v {
+			ss += (x - mean) * (x - mean)
+		}
+		return math.Sqrt(ss / float64(len(v)-1))
+	})
+	RegisterAggregate("median", func(v []float64) float64 {
+		if len(v) == 0 {
+			return math.NaN()
+		}
+		s := append([]float64(nil), v...)
+		sort.Float64s(s)
+		if len(s)%2 == 1 {
+			return s[len(s)/2]
+		}
+		return (s[len(s)/2-1] + s[len(s)/2]) / 2
+	})
+}
+
+func aggSum(v []float64) float64 {
+
+	var sum float64
+	for _, x := range v {
+		sum += x
+	}
+	return sum
+}
+
+func aggMean(v []float64) float64 {
+
+	if len(v) == 0 {
+		return math.NaN()
+	}
+	return aggSum(v) / float64(len(v))
+}
+
+// Registers an aggregate function by name so it can be used in grouping and
+// window operations. Built-in aggregates are count, sum, mean, min, max, std,
+// and median. Registering an existing name returns an error.
+func RegisterAggregate(name string, fn AggregateFunc) error {
+
+	aggregates.Lock()
+	defer aggregates.Unlock()
+	if _, ok := aggregates.m[name]; ok {
+		return fmt.Errorf("Aggregate [%s] is already registered.", name)
+	}
+	aggregates.m[name] = fn
+	return nil
+}
+
+// Returns the aggregate function registered with name.
+func LookupAggregate(name string) (AggregateFunc, error) {
+
+	aggregates.RLock()
+	defer aggregates.RUnlock()
+	fn, ok := aggregates.m[name]
+	if !ok {
+		return nil, fmt.Errorf("There is no aggregate named [%s].", name)
+	}
+	return fn, nil
+}
+
+// Returns the names of the registered aggregates in sorted order.
+func Aggregates() []string {
+
+	aggregates.RLock()
+	defer aggregates.RUnlock()
+	names := make([]string, 0, len(aggregates.m))
+	for name := range aggregates.m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Applies a registered aggregate over a trailing window of size rows of a
+// float64 variable and stores the result in a new variable named
+// name + "_" + agg. The first rows use the available values.
+func (df *DataFrame) Rolling(name string, size int, agg string) error {
+
+	if size <= 0 {
+		return fmt.Errorf("Window size must be positive, got %d.", size)
+	}
+	fn, err := LookupAggregate(agg)
+	if err != nil {
+		return err
+	}
+	indices, err := df.indices(name)
+	if err != nil {
+		return err
+	}
+	idx := indices[0]
+	values := make([]interface{}, df.N())
+	window := make([]float64, 0, size)
+	for i := range df.Data {
+		window = window[:0]
+		start := i - size + 1
+		if start < 0 {
+			start = 0
+		}
+		for j := start; j <= i; j++ {
+			switch v := df.Data[j][idx].(type) {
+			case nil:
+			case float64:
+				window = append(window, v)
+			default:
+				return fmt.Errorf("In frame %d, variable [%s] is of type [%s]. Must be of type float64.",
+					j, name, reflect.TypeOf(v).String())
+			}
+		}
+		values[i] = fn(window)
+	}
+	return df.addVar(name+"_"+agg, values)
+}
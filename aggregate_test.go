@@ -0,0 +1,52 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonum/floats"
+)
+
+func TestRegisterAggregate(t *testing.T) {
+
+	rms := func(v []float64) float64 {
+		var ss float64
+		for _, x := range v {
+			ss += x * x
+		}
+		return math.Sqrt(ss / float64(len(v)))
+	}
+	CheckError(t, RegisterAggregate("test_rms", rms))
+	if e := RegisterAggregate("test_rms", rms); e == nil {
+		t.Fatalf("expected error for duplicate aggregate.")
+	}
+	if _, e := LookupAggregate("nope"); e == nil {
+		t.Fatalf("expected error for unknown aggregate.")
+	}
+
+	tmpDir := getTempDir()
+	f1, _ := createDataFiles(t, tmpDir)
+	df, dfe := ReadDataFrameFile(f1)
+	CheckError(t, dfe)
+
+	CheckError(t, df.Rolling("acceleration", 2, "test_rms"))
+	CheckError(t, df.Rolling("acceleration", 3, "mean"))
+	var rolled, means []float64
+	for i := 0; i < df.N(); i++ {
+		sl, e := df.Float64Slice(i, "acceleration_test_rms", "acceleration_mean")
+		CheckError(t, e)
+		rolled = append(rolled, sl[0])
+		means = append(means, sl[1])
+	}
+	if !floats.EqualApprox(rolled[:2], []float64{1.3, math.Sqrt((1.3*1.3 + 1.4*1.4) / 2)}, 1e-9) {
+		t.Fatalf("unexpected rms %v.", rolled)
+	}
+	if !floats.EqualApprox(means, []float64{1.3, 1.35, 1.4, 1.5, 1.6, 1.7}, 1e-9) {
+		t.Fatalf("unexpected means %v.", means)
+	}
+}
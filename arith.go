@@ -0,0 +1,200 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Multiplies every value of a float64 or vector variable by factor.
+func (df *DataFrame) ScaleColumn(name string, factor float64) error {
+
+	return df.Broadcast(name, func(x float64) float64 { return x * factor })
+}
+
+// Adds offset to every value of a float64 or vector variable.
+func (df *DataFrame) OffsetColumn(name string, offset float64) error {
+
+	return df.Broadcast(name, func(x float64) float64 { return x + offset })
+}
+
+// Applies fn to every value of a float64 variable, or to every element of a
+// vector variable. Nil values are left unchanged. Vectors are replaced, not
+// modified in place, so frames that share cells with df, such as the output
+// of Concat or Join, are not modified. Views of df share its rows and see
+// the new values, see View.
+func (df *DataFrame) Broadcast(name string, fn func(x float64) float64) error {
+
+	indices, err := df.indices(name)
+	if err != nil {
+		return err
+	}
+	idx := indices[0]
+
+	// Check types first so the frame is not left half modified.
+	for i, row := range df.Data {
+		switch v := row[idx].(type) {
+		case nil, float64:
+		case []float64, []interface{}:
+			if _, err := toFloat64Slice(v); err != nil {
+				return fmt.Errorf("In frame %d, variable [%s]: %s", i, name, err)
+			}
+		default:
+			return fmt.Errorf("In frame %d, variable [%s] is of type [%s]. Must be float64 or a vector.",
+				i, name, reflect.TypeOf(v).String())
+		}
+	}
+
+	defer df.Invalidate(name)
+	for _, row := range df.Data {
+		switch v := row[idx].(type) {
+		case float64:
+			row[idx] = fn(v)
+		case []float64:
+			// Vectors may be shared with views and copies of the frame.
+			out := make([]float64, len(v))
+			for k := range v {
+				out[k] = fn(v[k])
+			}
+			row[idx] = out
+		case []interface{}:
+			vec, _ := toFloat64Slice(v)
+			for k := range vec {
+				vec[k] = fn(vec[k])
+			}
+			row[idx] = vec
+		}
+	}
+	return nil
+}
+
+// Returns df + other, see Combine.
+func (df *DataFrame) Add(other *DataFrame, names ...string) (*DataFrame, error) {
+
+	return df.Combine(other, func(a, b float64) float64 { return a + b }, names...)
+}
+
+// Returns df - other, see Combine. For example, the residuals of a model
+// are predicted.Sub(truth).
+func (df *DataFrame) Sub(other *DataFrame, names ...string) (*DataFrame, error) {
+
+	return df.Combine(other, func(a, b float64) float64 { return a - b }, names...)
+}
+
+// Returns a new data frame with fn applied to the values of the named
+// variables in df and other, row by row. Variables are matched by name and
+// must be float64 or vectors of the same dimension; both frames must have
+// the same number of rows. When no names are given, the float64 and vector
+// variables of df that are also in other are used. The variables must have
+// the same units in both frames, see ConvertUnit. Other variables are
+// copied from df. The result is nil where either value is nil.
+func (df *DataFrame) Combine(other *DataFrame, fn func(a, b float64) float64, names ...string) (*DataFrame, error) {
+
+	if df.N() != other.N() {
+		return nil, fmt.Errorf("Data frames have %d and %d rows.", df.N(), other.N())
+	}
+	if len(names) == 0 {
+		for j, name := range df.VarNames {
+			if _, ok := other.varMap[name]; ok && df.isNumeric(j) {
+				names = append(names, name)
+			}
+		}
+	}
+	left, err := df.indices(names...)
+	if err != nil {
+		return nil, err
+	}
+	right, err := other.indices(names...)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if u, v := df.VarUnits[name], other.VarUnits[name]; u != v {
+			return nil, fmt.Errorf("Variable [%s] has units [%s] and [%s].", name, u, v)
+		}
+	}
+
+	out := df.deepCopy()
+	for i, row := range out.Data {
+		for k, name := range names {
+			a, b := row[left[k]], other.Data[i][right[k]]
+			if a == nil || b == nil {
+				row[left[k]] = nil
+				continue
+			}
+			if x, ok := a.(float64); ok {
+				y, ok := b.(float64)
+				if !ok {
+					return nil, fmt.Errorf("In frame %d, variable [%s] is of type [%s]. Must be of type float64.",
+						i, name, reflect.TypeOf(b).String())
+				}
+				row[left[k]] = fn(x, y)
+				continue
+			}
+			x, err := toFloat64Slice(a)
+			if err != nil {
+				return nil, fmt.Errorf("In frame %d, variable [%s]: %s", i, name, err)
+			}
+			y, err := toFloat64Slice(b)
+			if err != nil {
+				return nil, fmt.Errorf("In frame %d, variable [%s]: %s", i, name, err)
+			}
+			if len(x) != len(y) {
+				return nil, fmt.Errorf("In frame %d, variable [%s] has dimensions %d and %d.", i, name, len(x), len(y))
+			}
+			z := make([]float64, len(x))
+			for e := range z {
+				z[e] = fn(x[e], y[e])
+			}
+			row[left[k]] = z
+		}
+	}
+	return out, nil
+}
+
+// Returns true if the non-nil values of variable j are float64 or vectors.
+func (df *DataFrame) isNumeric(j int) bool {
+
+	for _, row := range df.Data {
+		switch row[j].(type) {
+		case nil, float64, []float64, []interface{}:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Returns a copy of the data frame that shares no rows, vectors, or maps
+// with df.
+func (df *DataFrame) deepCopy() *DataFrame {
+
+	out := &DataFrame{
+		Description: df.Description,
+		BatchID:     df.BatchID,
+		VarNames:    append([]string(nil), df.VarNames...),
+		Data:        make([][]interface{}, len(df.Data)),
+		VarUnits:    copyProperties(df.VarUnits),
+		Properties:  copyProperties(df.Properties),
+	}
+	for i, src := range df.Data {
+		row := make([]interface{}, len(src))
+		for j, x := range src {
+			switch v := x.(type) {
+			case []float64:
+				row[j] = append([]float64(nil), v...)
+			case []interface{}:
+				row[j] = append([]interface{}(nil), v...)
+			default:
+				row[j] = x
+			}
+		}
+		out.Data[i] = row
+	}
+	out.resetVarMap()
+	return out
+}
@@ -0,0 +1,142 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/gonum/floats"
+)
+
+func TestBroadcast(t *testing.T) {
+
+	tmpDir := getTempDir()
+	f1, _ := createDataFiles(t, tmpDir)
+
+	df, dfe := ReadDataFrameFile(f1)
+	CheckError(t, dfe)
+
+	st, e := df.ColStats("acceleration")
+	CheckError(t, e)
+
+	CheckError(t, df.ScaleColumn("acceleration", 10))
+	CheckError(t, df.OffsetColumn("acceleration", -1))
+	CheckError(t, df.OffsetColumn("wifi", 40))
+	CheckError(t, df.ScaleColumn("wifi", 2))
+
+	sl, sle := df.Float64Slice(1, "wifi", "acceleration")
+	CheckError(t, sle)
+	if !floats.EqualApprox(sl, []float64{-3.6, -2.2, 13}, 1e-9) {
+		t.Fatalf("vector %v doesn't match.", sl)
+	}
+
+	// Cached stats are invalidated.
+	st2, e := df.ColStats("acceleration")
+	CheckError(t, e)
+	if st2.Max == st.Max {
+		t.Fatalf("stats were not invalidated.")
+	}
+
+	if e = df.ScaleColumn("room", 2); e == nil {
+		t.Fatalf("expected error for string variable.")
+	}
+}
+
+func TestBroadcastView(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	wifi, e := df.Float64SliceCol("wifi")
+	CheckError(t, e)
+	want := append([]float64(nil), wifi[0]...)
+	acc, e := df.Float64Col("acceleration")
+	CheckError(t, e)
+
+	// Frames from Head, Slice, and Materialize share cells with the parent.
+	for _, v := range []*DataFrame{
+		df.Head(2),
+		df.Slice(0, 3),
+		df.View().Filter(func(frame int) bool { return frame == 0 }).Materialize(),
+	} {
+		CheckError(t, v.ScaleColumn("wifi", 100))
+		CheckError(t, v.ScaleColumn("acceleration", 100))
+	}
+	got, e := df.Float64SliceCol("wifi")
+	CheckError(t, e)
+	if !floats.Equal(got[0], want) {
+		t.Fatalf("parent modified: expected %v, got %v.", want, got[0])
+	}
+	if x, _ := df.Row(0).Float64("acceleration"); x != acc[0] {
+		t.Fatalf("parent modified: expected %v, got %v.", acc[0], x)
+	}
+
+	// Views share the rows of the parent and see the new values.
+	view := df.View()
+	CheckError(t, df.ScaleColumn("acceleration", 2))
+	x, e := view.Float64Slice(0, "acceleration")
+	CheckError(t, e)
+	if x[0] != 2*acc[0] {
+		t.Fatalf("expected %v in view, got %v.", 2*acc[0], x[0])
+	}
+}
+
+func TestCombine(t *testing.T) {
+
+	truth, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	pred, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	CheckError(t, pred.OffsetColumn("acceleration", 0.5))
+	CheckError(t, pred.ScaleColumn("wifi", 2))
+	pred.Data[1][2] = nil
+
+	res, e := pred.Sub(truth)
+	CheckError(t, e)
+	if res.Data[0][0] != "BED5" || res.Data[1][2] != nil {
+		t.Fatalf("unexpected residuals %v.", res.Data[:2])
+	}
+	for i, row := range res.Data {
+		if i != 1 && math.Abs(row[2].(float64)-0.5) > 1e-9 {
+			t.Fatalf("row %d: expected residual 0.5, got %v.", i, row[2])
+		}
+		want, _ := truth.Float64Slice(i, "wifi")
+		if !floats.EqualApprox(row[1].([]float64), want, 1e-9) {
+			t.Fatalf("row %d: expected %v, got %v.", i, want, row[1])
+		}
+	}
+	if pred.Data[0][2] != 1.8 {
+		t.Fatalf("input must not be modified.")
+	}
+
+	sum, e := pred.Add(truth, "acceleration")
+	CheckError(t, e)
+	if math.Abs(sum.Data[0][2].(float64)-3.1) > 1e-9 || sum.Data[0][1].([]float64)[0] != -81.6 {
+		t.Fatalf("unexpected sum %v.", sum.Data[0])
+	}
+
+	if _, e = pred.Sub(truth, "room"); e == nil {
+		t.Fatalf("expected error for string variable.")
+	}
+	short := truth.Filter(func(row Row) bool { return row.Frame() < 3 })
+	if _, e = pred.Sub(short); e == nil {
+		t.Fatalf("expected error for different number of rows.")
+	}
+
+	// Units must match.
+	pred.VarUnits = map[string]string{"acceleration": "g"}
+	truth.VarUnits = map[string]string{"acceleration": "m/s^2"}
+	if _, e = pred.Sub(truth); e == nil {
+		t.Fatalf("expected error for different units.")
+	}
+	CheckError(t, pred.ConvertUnit("acceleration", "m/s^2"))
+	res, e = pred.Sub(truth)
+	CheckError(t, e)
+	if res.VarUnits["acceleration"] != "m/s^2" {
+		t.Fatalf("expected unit m/s^2, got %s.", res.VarUnits["acceleration"])
+	}
+}
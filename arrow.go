@@ -0,0 +1,953 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"sort"
+)
+
+// Arrow IPC files start and end with the magic number. See
+// https://arrow.apache.org/docs/format/Columnar.html#ipc-file-format
+var arrowMagic = []byte("ARROW1")
+
+// Keys of the Arrow schema metadata that hold the data frame metadata.
+const (
+	arrowDescriptionKey = "dataframe.description"
+	arrowBatchIDKey     = "dataframe.batchid"
+	arrowUnitsKey       = "dataframe.var_units"
+	arrowPropertiesKey  = "dataframe.properties"
+	// Key of the field metadata with the kind of the variable.
+	arrowKindKey = "dataframe.kind"
+)
+
+// How the values of a variable are stored in an Arrow column.
+const (
+	// Float64 column.
+	arrowFloat64 = "float64"
+	// Utf8 column.
+	arrowString = "string"
+	// Bool column.
+	arrowBool = "bool"
+	// List<Float64> column read as []float64.
+	arrowFloat64s = "float64s"
+	// List<Float64> column read as []interface{}, as vectors decoded from
+	// JSON.
+	arrowVector = "vector"
+	// Utf8 column of JSON values, for variables with mixed types.
+	arrowJSON = "json"
+)
+
+// Values of the Arrow metadata, see Schema.fbs and Message.fbs.
+const (
+	arrowV5                = 4
+	arrowHeaderSchema      = 1
+	arrowHeaderRecordBatch = 3
+	arrowTypeFloatingPoint = 3
+	arrowTypeUtf8          = 5
+	arrowTypeBool          = 6
+	arrowTypeList          = 12
+	arrowPrecisionDouble   = 2
+)
+
+// Writes the data frame as an Arrow IPC file with one record batch.
+// Variables whose values are all float64, string, bool, or vectors of
+// float64 are written as Float64, Utf8, Bool, and List<Float64> columns.
+// Other variables, for example with mixed types, are written as Utf8
+// columns of JSON values. Nil values are nulls. Description, batch id,
+// units, and properties are stored in the schema metadata. See
+// ReadDataFrameArrow.
+func (df *DataFrame) WriteArrow(w io.Writer) error {
+
+	if err := df.checkWrite(); err != nil {
+		return err
+	}
+	return df.writeArrow(w, nil)
+}
+
+// Writes the data frame to file fn in Arrow IPC format, see WriteArrow.
+// The file is replaced atomically so readers never see a partial file.
+func (df *DataFrame) WriteArrowFile(fn string) error {
+
+	return writeFileAtomic(fn, 0644, df.WriteArrow)
+}
+
+// Writes the data frame as an Arrow IPC file with extra schema metadata.
+func (df *DataFrame) writeArrow(w io.Writer, extra map[string]string) error {
+
+	meta := map[string]string{
+		arrowDescriptionKey: df.Description,
+		arrowBatchIDKey:     df.BatchID,
+	}
+	if len(df.VarUnits) > 0 {
+		b, err := json.Marshal(df.VarUnits)
+		if err != nil {
+			return err
+		}
+		meta[arrowUnitsKey] = string(b)
+	}
+	if len(df.Properties) > 0 {
+		b, err := json.Marshal(df.Properties)
+		if err != nil {
+			return err
+		}
+		meta[arrowPropertiesKey] = string(b)
+	}
+	for k, v := range extra {
+		meta[k] = v
+	}
+	for i, row := range df.Data {
+		if len(row) != len(df.VarNames) {
+			return fmt.Errorf("In frame %d, expected %d values, got %d.", i, len(df.VarNames), len(row))
+		}
+	}
+
+	var body, nodes, buffers bytes.Buffer
+	addNode := func(length, nulls int) {
+		binary.Write(&nodes, binary.LittleEndian, [2]int64{int64(length), int64(nulls)})
+	}
+	addBuffer := func(b []byte) {
+		binary.Write(&buffers, binary.LittleEndian, [2]int64{int64(body.Len()), int64(len(b))})
+		body.Write(b)
+		body.Write(make([]byte, pad8(len(b))-len(b)))
+	}
+	n := df.N()
+	fields := make([]*fbTable, len(df.VarNames))
+	for j, name := range df.VarNames {
+		kind := df.arrowKind(j)
+		fields[j] = arrowField(name, kind).
+			ref(6, arrowKeyValues(map[string]string{arrowKindKey: kind}, []string{arrowKindKey}))
+		validity := make([]byte, (n+7)/8)
+		var nulls int
+		for i, row := range df.Data {
+			if row[j] == nil {
+				nulls++
+			} else {
+				validity[i/8] |= 1 << uint(i%8)
+			}
+		}
+		addNode(n, nulls)
+		addBuffer(validity)
+		switch kind {
+		case arrowFloat64:
+			values := make([]byte, 8*n)
+			for i, row := range df.Data {
+				if f, ok := row[j].(float64); ok {
+					binary.LittleEndian.PutUint64(values[8*i:], math.Float64bits(f))
+				}
+			}
+			addBuffer(values)
+		case arrowBool:
+			values := make([]byte, (n+7)/8)
+			for i, row := range df.Data {
+				if b, ok := row[j].(bool); ok && b {
+					values[i/8] |= 1 << uint(i%8)
+				}
+			}
+			addBuffer(values)
+		case arrowString, arrowJSON:
+			offsets := make([]byte, 4*(n+1))
+			var data bytes.Buffer
+			for i, row := range df.Data {
+				if x, ok := row[j].(string); ok && kind == arrowString {
+					data.WriteString(x)
+				} else if row[j] != nil {
+					v, _ := finiteValue(row[j])
+					b, err := json.Marshal(v)
+					if err != nil {
+						return fmt.Errorf("In frame %d, variable [%s]: %s", i, name, err)
+					}
+					data.Write(b)
+				}
+				if data.Len() > math.MaxInt32 {
+					return fmt.Errorf("Variable [%s] has more than %d bytes.", name, math.MaxInt32)
+				}
+				binary.LittleEndian.PutUint32(offsets[4*(i+1):], uint32(data.Len()))
+			}
+			addBuffer(offsets)
+			addBuffer(data.Bytes())
+		default:
+			offsets := make([]byte, 4*(n+1))
+			var values bytes.Buffer
+			var m int
+			for i, row := range df.Data {
+				if row[j] != nil {
+					vec, _ := toFloat64Slice(row[j])
+					for _, f := range vec {
+						binary.Write(&values, binary.LittleEndian, f)
+					}
+					m += len(vec)
+				}
+				if m > math.MaxInt32 {
+					return fmt.Errorf("Variable [%s] has more than %d values.", name, math.MaxInt32)
+				}
+				binary.LittleEndian.PutUint32(offsets[4*(i+1):], uint32(m))
+			}
+			addBuffer(offsets)
+			addNode(m, 0)
+			addBuffer(nil)
+			addBuffer(values.Bytes())
+		}
+	}
+
+	keys := make([]string, 0, len(meta))
+	for k := range meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	schema := (&fbTable{}).
+		scalar(0, 2, 0).
+		ref(1, fields).
+		ref(2, arrowKeyValues(meta, keys))
+	batch := (&fbTable{}).
+		scalar(0, 8, uint64(n)).
+		ref(1, fbStructs{nodes.Bytes(), 16, 8}).
+		ref(2, fbStructs{buffers.Bytes(), 16, 8})
+
+	var out bytes.Buffer
+	out.Write(arrowMagic)
+	out.Write(make([]byte, 2))
+	writeArrowMessage(&out, arrowHeaderSchema, schema, nil)
+	offset := out.Len()
+	metaLen := writeArrowMessage(&out, arrowHeaderRecordBatch, batch, body.Bytes())
+	// End of stream marker.
+	binary.Write(&out, binary.LittleEndian, [2]uint32{0xFFFFFFFF, 0})
+
+	var blocks bytes.Buffer
+	binary.Write(&blocks, binary.LittleEndian, struct {
+		Offset  int64
+		MetaLen int32
+		_       int32
+		BodyLen int64
+	}{Offset: int64(offset), MetaLen: int32(metaLen), BodyLen: int64(body.Len())})
+	footer := buildFlatBuffer((&fbTable{}).
+		scalar(0, 2, arrowV5).
+		ref(1, schema).
+		ref(3, fbStructs{blocks.Bytes(), 24, 8}))
+	out.Write(footer)
+	binary.Write(&out, binary.LittleEndian, int32(len(footer)))
+	out.Write(arrowMagic)
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// Returns the kind of variable j, see arrowFloat64.
+func (df *DataFrame) arrowKind(j int) string {
+
+	var kind string
+	for _, row := range df.Data {
+		var k string
+		switch x := row[j].(type) {
+		case nil:
+			continue
+		case float64:
+			k = arrowFloat64
+		case string:
+			k = arrowString
+		case bool:
+			k = arrowBool
+		case []float64:
+			k = arrowFloat64s
+		case []interface{}:
+			k = arrowJSON
+			if valueType(x) == TypeVector {
+				k = arrowVector
+			}
+		default:
+			k = arrowJSON
+		}
+		if kind != "" && k != kind {
+			return arrowJSON
+		}
+		kind = k
+	}
+	if kind == "" {
+		return arrowJSON
+	}
+	return kind
+}
+
+// Returns the Arrow field for a variable of the given kind.
+func arrowField(name, kind string) *fbTable {
+
+	field := (&fbTable{}).ref(0, name).scalar(1, 1, 1)
+	switch kind {
+	case arrowFloat64:
+		field.scalar(2, 1, arrowTypeFloatingPoint).ref(3, (&fbTable{}).scalar(0, 2, arrowPrecisionDouble))
+	case arrowBool:
+		field.scalar(2, 1, arrowTypeBool).ref(3, &fbTable{})
+	case arrowString, arrowJSON:
+		field.scalar(2, 1, arrowTypeUtf8).ref(3, &fbTable{})
+	default:
+		field.scalar(2, 1, arrowTypeList).ref(3, &fbTable{}).
+			ref(5, []*fbTable{arrowField("item", arrowFloat64)})
+	}
+	return field
+}
+
+func arrowKeyValues(m map[string]string, keys []string) []*fbTable {
+
+	kvs := make([]*fbTable, len(keys))
+	for i, k := range keys {
+		kvs[i] = (&fbTable{}).ref(0, k).ref(1, m[k])
+	}
+	return kvs
+}
+
+// Writes an encapsulated message and returns the length of its metadata,
+// including the prefix and padding.
+func writeArrowMessage(out *bytes.Buffer, typ uint64, header *fbTable, body []byte) int {
+
+	msg := buildFlatBuffer((&fbTable{}).
+		scalar(0, 2, arrowV5).
+		scalar(1, 1, typ).
+		ref(2, header).
+		scalar(3, 8, uint64(len(body))))
+	n := pad8(len(msg))
+	binary.Write(out, binary.LittleEndian, [2]uint32{0xFFFFFFFF, uint32(n)})
+	out.Write(msg)
+	out.Write(make([]byte, n-len(msg)))
+	out.Write(body)
+	return 8 + n
+}
+
+func pad8(n int) int {
+
+	return (n + 7) &^ 7
+}
+
+// Reads a data frame from an Arrow IPC file. Float64, Utf8, Bool, and
+// List<Float64> columns are supported; lists are read as []float64.
+// Compressed files are not supported. Data frame metadata written by
+// WriteArrow is restored.
+func ReadDataFrameArrow(r io.Reader) (*DataFrame, error) {
+
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	f, err := parseArrow(b)
+	if err != nil {
+		return nil, err
+	}
+	return f.frame()
+}
+
+// Reads a data frame from a file in Arrow IPC format.
+func ReadDataFrameArrowFile(fn string) (*DataFrame, error) {
+
+	f, err := openFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	df, err := ReadDataFrameArrow(f)
+	if err != nil {
+		return nil, fmt.Errorf("file %s: %s", fn, err)
+	}
+	return df, nil
+}
+
+// An Arrow IPC file with a parsed footer.
+type arrowFile struct {
+	b      []byte
+	names  []string
+	kinds  []string
+	meta   map[string]string
+	blocks [][3]int64
+}
+
+// Parses the footer and schema of an Arrow IPC file.
+func parseArrow(b []byte) (*arrowFile, error) {
+
+	n := len(b) - len(arrowMagic) - 4
+	if n < 8 || !bytes.HasPrefix(b, arrowMagic) || !bytes.HasSuffix(b, arrowMagic) {
+		return nil, fmt.Errorf("not an Arrow file.")
+	}
+	size := int(int32(binary.LittleEndian.Uint32(b[n:])))
+	if size <= 0 || size > n-8 {
+		return nil, fmt.Errorf("invalid Arrow footer length %d.", size)
+	}
+	r := &fbReader{b: b[n-size : n]}
+	footer := r.root()
+	schema := r.table(r.ref(footer, 1))
+	f := &arrowFile{b: b, meta: r.keyValues(r.ref(schema, 2))}
+	nf, pos := r.vector(r.ref(schema, 1), 4)
+	for i := 0; i < nf && r.err == nil; i++ {
+		field := r.table(r.deref(pos + 4*i))
+		name := r.str(r.ref(field, 0))
+		kind, err := arrowFieldKind(r, field)
+		if err != nil {
+			return nil, fmt.Errorf("variable [%s]: %s", name, err)
+		}
+		f.names = append(f.names, name)
+		f.kinds = append(f.kinds, kind)
+	}
+	nb, pos := r.vector(r.ref(footer, 3), 24)
+	for i := 0; i < nb && r.err == nil; i++ {
+		p := pos + 24*i
+		f.blocks = append(f.blocks, [3]int64{int64(r.u64(p)), int64(int32(r.u32(p + 8))), int64(r.u64(p + 16))})
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return f, nil
+}
+
+// Returns the kind of a field, see arrowFloat64, and checks that the
+// Arrow type is supported.
+func arrowFieldKind(r *fbReader, field fbTableRef) (string, error) {
+
+	// Returns true if the field is a Float64.
+	isFloat64 := func(field fbTableRef) bool {
+		typ := r.table(r.ref(field, 3))
+		return r.scalar(field, 2, 1) == arrowTypeFloatingPoint && r.scalar(typ, 0, 2) == arrowPrecisionDouble
+	}
+	kind := r.keyValues(r.ref(field, 6))[arrowKindKey]
+	var kinds []string
+	switch typ := r.scalar(field, 2, 1); {
+	case isFloat64(field):
+		kinds = []string{arrowFloat64}
+	case typ == arrowTypeUtf8:
+		kinds = []string{arrowString, arrowJSON}
+	case typ == arrowTypeBool:
+		kinds = []string{arrowBool}
+	case typ == arrowTypeList:
+		n, pos := r.vector(r.ref(field, 5), 4)
+		if n != 1 || !isFloat64(r.table(r.deref(pos))) {
+			return "", fmt.Errorf("lists must have Float64 values.")
+		}
+		kinds = []string{arrowFloat64s, arrowVector}
+	default:
+		return "", fmt.Errorf("Arrow type %d is not supported.", typ)
+	}
+	if r.err != nil {
+		return "", r.err
+	}
+	if kind == "" {
+		return kinds[0], nil
+	}
+	for _, k := range kinds {
+		if k == kind {
+			return kind, nil
+		}
+	}
+	return "", fmt.Errorf("invalid kind [%s].", kind)
+}
+
+// Decodes the record batches.
+func (f *arrowFile) frame() (*DataFrame, error) {
+
+	df := &DataFrame{
+		Description: f.meta[arrowDescriptionKey],
+		BatchID:     f.meta[arrowBatchIDKey],
+		VarNames:    append(make([]string, 0, len(f.names)), f.names...),
+		Data:        make([][]interface{}, 0),
+	}
+	if s, ok := f.meta[arrowUnitsKey]; ok {
+		if err := json.Unmarshal([]byte(s), &df.VarUnits); err != nil {
+			return nil, err
+		}
+	}
+	if s, ok := f.meta[arrowPropertiesKey]; ok {
+		if err := json.Unmarshal([]byte(s), &df.Properties); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkUnique(df.VarNames); err != nil {
+		return nil, err
+	}
+	for k, block := range f.blocks {
+		if err := f.decodeBatch(df, block); err != nil {
+			return nil, fmt.Errorf("record batch %d: %s", k, err)
+		}
+	}
+	df.resetVarMap()
+	return df, nil
+}
+
+// Appends the rows of a record batch. The block has the offset, metadata
+// length, and body length of the message.
+func (f *arrowFile) decodeBatch(df *DataFrame, block [3]int64) error {
+
+	offset, metaLen, bodyLen := block[0], block[1], block[2]
+	size := int64(len(f.b))
+	if offset < 0 || metaLen < 8 || bodyLen < 0 || offset > size || metaLen > size-offset || bodyLen > size-offset-metaLen {
+		return fmt.Errorf("invalid block.")
+	}
+	start := int(offset)
+	n := int(int32(binary.LittleEndian.Uint32(f.b[start+4:])))
+	if binary.LittleEndian.Uint32(f.b[start:]) != 0xFFFFFFFF || n <= 0 || int64(n) > metaLen-8 {
+		return fmt.Errorf("invalid message.")
+	}
+	r := &fbReader{b: f.b[start+8 : start+8+n]}
+	msg := r.root()
+	if r.scalar(msg, 1, 1) != arrowHeaderRecordBatch {
+		return fmt.Errorf("not a record batch.")
+	}
+	batch := r.table(r.ref(msg, 2))
+	if r.field(batch, 3) != 0 {
+		return fmt.Errorf("compressed record batches are not supported.")
+	}
+	body := f.b[start+int(metaLen) : start+int(metaLen)+int(bodyLen)]
+	length := int64(r.scalar(batch, 0, 8))
+	nn, nodes := r.vector(r.ref(batch, 1), 16)
+	nb, buffers := r.vector(r.ref(batch, 2), 16)
+	if r.err != nil {
+		return r.err
+	}
+	// Every column has at least one bit per row.
+	if length < 0 || length > 8*int64(len(body)) || len(df.VarNames) == 0 && length > 0 {
+		return fmt.Errorf("invalid length %d.", length)
+	}
+	rows := int(length)
+
+	var k, m int
+	node := func() int64 {
+		if k >= nn {
+			r.fail()
+			return 0
+		}
+		k++
+		return int64(r.u64(nodes + 16*(k-1)))
+	}
+	buffer := func(min int) []byte {
+		if m >= nb {
+			r.fail()
+			return nil
+		}
+		m++
+		p := buffers + 16*(m-1)
+		off, n := int64(r.u64(p)), int64(r.u64(p+8))
+		if off < 0 || n < int64(min) || off > int64(len(body)) || n > int64(len(body))-off {
+			r.fail()
+			return nil
+		}
+		return body[off : off+n]
+	}
+	// Returns the offsets of a Utf8 or List column, checked against max.
+	offsets := func(max int) []int {
+		b := buffer(4 * (rows + 1))
+		if b == nil {
+			return nil
+		}
+		o := make([]int, rows+1)
+		for i := range o {
+			o[i] = int(int32(binary.LittleEndian.Uint32(b[4*i:])))
+			if o[i] < 0 || i > 0 && o[i] < o[i-1] {
+				r.fail()
+				return nil
+			}
+		}
+		if o[rows] > max {
+			r.fail()
+			return nil
+		}
+		return o
+	}
+
+	cells := make([]interface{}, rows*len(df.VarNames))
+	for j, kind := range f.kinds {
+		if node() != length {
+			r.fail()
+		}
+		validity := buffer(0)
+		if len(validity) > 0 && len(validity) < (rows+7)/8 {
+			r.fail()
+		}
+		if r.err != nil {
+			return r.err
+		}
+		valid := func(i int) bool {
+			return len(validity) == 0 || validity[i/8]&(1<<uint(i%8)) != 0
+		}
+		cell := func(i int) *interface{} {
+			return &cells[i*len(df.VarNames)+j]
+		}
+		switch kind {
+		case arrowFloat64:
+			values := buffer(8 * rows)
+			for i := 0; i < rows && r.err == nil; i++ {
+				if valid(i) {
+					*cell(i) = math.Float64frombits(binary.LittleEndian.Uint64(values[8*i:]))
+				}
+			}
+		case arrowBool:
+			values := buffer((rows + 7) / 8)
+			for i := 0; i < rows && r.err == nil; i++ {
+				if valid(i) {
+					*cell(i) = values[i/8]&(1<<uint(i%8)) != 0
+				}
+			}
+		case arrowString, arrowJSON:
+			o := offsets(math.MaxInt32)
+			data := buffer(0)
+			if o != nil && o[rows] > len(data) {
+				r.fail()
+			}
+			for i := 0; i < rows && r.err == nil; i++ {
+				if !valid(i) {
+					continue
+				}
+				s := data[o[i]:o[i+1]]
+				if kind == arrowString {
+					*cell(i) = string(s)
+					continue
+				}
+				v := make([]interface{}, 1)
+				if err := json.Unmarshal(s, &v[0]); err != nil {
+					return fmt.Errorf("In frame %d, variable [%s]: %s", len(df.Data)+i, df.VarNames[j], err)
+				}
+				restoreRow(v)
+				*cell(i) = v[0]
+			}
+		default:
+			o := offsets(math.MaxInt32)
+			n := node()
+			if o != nil && int64(o[rows]) != n {
+				r.fail()
+			}
+			if childValidity := buffer(0); len(childValidity) > 0 {
+				return fmt.Errorf("variable [%s] has null vector elements.", df.VarNames[j])
+			}
+			values := buffer(8 * int(n))
+			for i := 0; i < rows && r.err == nil; i++ {
+				if !valid(i) {
+					continue
+				}
+				vec := make([]float64, o[i+1]-o[i])
+				for e := range vec {
+					vec[e] = math.Float64frombits(binary.LittleEndian.Uint64(values[8*(o[i]+e):]))
+				}
+				if kind == arrowFloat64s {
+					*cell(i) = vec
+					continue
+				}
+				v := make([]interface{}, len(vec))
+				for e, x := range vec {
+					v[e] = x
+				}
+				*cell(i) = v
+			}
+		}
+		if r.err != nil {
+			return r.err
+		}
+	}
+	for i := 0; i < rows; i++ {
+		df.Data = append(df.Data, cells[i*len(df.VarNames):(i+1)*len(df.VarNames)])
+	}
+	return nil
+}
+
+// A FlatBuffers table, see https://flatbuffers.dev. Only the features used
+// by the Arrow metadata are supported.
+type fbTable struct {
+	fields []fbField
+}
+
+type fbField struct {
+	slot int
+	// Size of a scalar in bytes, 0 for a reference.
+	size  int
+	value uint64
+	// A *fbTable, string, []*fbTable, or fbStructs.
+	ref interface{}
+}
+
+// A vector of structs.
+type fbStructs struct {
+	data  []byte
+	size  int
+	align int
+}
+
+func (t *fbTable) scalar(slot, size int, v uint64) *fbTable {
+
+	t.fields = append(t.fields, fbField{slot: slot, size: size, value: v})
+	return t
+}
+
+func (t *fbTable) ref(slot int, v interface{}) *fbTable {
+
+	t.fields = append(t.fields, fbField{slot: slot, ref: v})
+	return t
+}
+
+// Returns the FlatBuffer with root table t. Objects are written before the
+// objects they reference so offsets are positive, as required.
+func buildFlatBuffer(t *fbTable) []byte {
+
+	b := &fbBuilder{buf: make([]byte, 4)}
+	b.patch(0, b.write(t))
+	return b.buf
+}
+
+type fbBuilder struct {
+	buf []byte
+}
+
+func (b *fbBuilder) align(n, extra int) {
+
+	for (len(b.buf)+extra)%n != 0 {
+		b.buf = append(b.buf, 0)
+	}
+}
+
+func (b *fbBuilder) put(size int, v uint64) {
+
+	for k := 0; k < size; k++ {
+		b.buf = append(b.buf, byte(v>>uint(8*k)))
+	}
+}
+
+// Sets the offset at pos to point to target.
+func (b *fbBuilder) patch(pos, target int) {
+
+	binary.LittleEndian.PutUint32(b.buf[pos:], uint32(target-pos))
+}
+
+// Writes an object and returns its position.
+func (b *fbBuilder) write(v interface{}) int {
+
+	switch x := v.(type) {
+	case string:
+		b.align(4, 0)
+		pos := len(b.buf)
+		b.put(4, uint64(len(x)))
+		b.buf = append(b.buf, x...)
+		b.buf = append(b.buf, 0)
+		return pos
+	case fbStructs:
+		b.align(x.align, 4)
+		pos := len(b.buf)
+		b.put(4, uint64(len(x.data)/x.size))
+		b.buf = append(b.buf, x.data...)
+		return pos
+	case []*fbTable:
+		b.align(4, 0)
+		pos := len(b.buf)
+		b.put(4, uint64(len(x)))
+		b.buf = append(b.buf, make([]byte, 4*len(x))...)
+		for i, t := range x {
+			b.patch(pos+4+4*i, b.write(t))
+		}
+		return pos
+	case *fbTable:
+		return b.writeTable(x)
+	}
+	panic(fmt.Sprintf("unsupported FlatBuffers value of type %T", v))
+}
+
+func (b *fbBuilder) writeTable(t *fbTable) int {
+
+	// Lay out the fields after the vtable offset, largest first so they
+	// are aligned.
+	offsets := make([]int, len(t.fields))
+	size, align, slots := 4, 4, 0
+	for _, width := range []int{8, 4, 2, 1} {
+		for i, f := range t.fields {
+			w := f.size
+			if f.ref != nil {
+				w = 4
+			}
+			if w != width {
+				continue
+			}
+			if w > align {
+				align = w
+			}
+			for size%w != 0 {
+				size++
+			}
+			offsets[i] = size
+			size += w
+		}
+	}
+	for _, f := range t.fields {
+		if f.slot >= slots {
+			slots = f.slot + 1
+		}
+	}
+	vtable := make([]int, slots)
+	for i, f := range t.fields {
+		vtable[f.slot] = offsets[i]
+	}
+
+	b.align(2, 0)
+	vt := len(b.buf)
+	b.put(2, uint64(4+2*slots))
+	b.put(2, uint64(size))
+	for _, off := range vtable {
+		b.put(2, uint64(off))
+	}
+	b.align(align, 0)
+	pos := len(b.buf)
+	b.buf = append(b.buf, make([]byte, size)...)
+	binary.LittleEndian.PutUint32(b.buf[pos:], uint32(pos-vt))
+	for i, f := range t.fields {
+		if f.ref == nil {
+			for k := 0; k < f.size; k++ {
+				b.buf[pos+offsets[i]+k] = byte(f.value >> uint(8*k))
+			}
+		}
+	}
+	for i, f := range t.fields {
+		if f.ref != nil {
+			b.patch(pos+offsets[i], b.write(f.ref))
+		}
+	}
+	return pos
+}
+
+// Reads a FlatBuffer with bounds checks. After the first error, reads
+// return zero values.
+type fbReader struct {
+	b   []byte
+	err error
+}
+
+// The position of a table and its vtable.
+type fbTableRef struct {
+	pos, vt, vtSize int
+}
+
+func (r *fbReader) fail() {
+
+	if r.err == nil {
+		r.err = fmt.Errorf("invalid Arrow metadata.")
+	}
+}
+
+// Returns false if the n bytes at pos are out of bounds.
+func (r *fbReader) check(pos, n int) bool {
+
+	if pos < 0 || n < 0 || pos > len(r.b)-n {
+		r.fail()
+	}
+	return r.err == nil
+}
+
+func (r *fbReader) read(pos, size int) uint64 {
+
+	if !r.check(pos, size) {
+		return 0
+	}
+	var v uint64
+	for k := size - 1; k >= 0; k-- {
+		v = v<<8 | uint64(r.b[pos+k])
+	}
+	return v
+}
+
+func (r *fbReader) u32(pos int) uint32 {
+
+	return uint32(r.read(pos, 4))
+}
+
+func (r *fbReader) u64(pos int) uint64 {
+
+	return r.read(pos, 8)
+}
+
+func (r *fbReader) root() fbTableRef {
+
+	return r.table(int(r.u32(0)))
+}
+
+// Returns the table at pos. A zero pos is an absent table.
+func (r *fbReader) table(pos int) fbTableRef {
+
+	if pos == 0 {
+		r.fail()
+		return fbTableRef{}
+	}
+	vt := pos - int(int32(r.u32(pos)))
+	return fbTableRef{pos: pos, vt: vt, vtSize: int(r.read(vt, 2))}
+}
+
+// Returns the position of a field or 0 if it is absent.
+func (r *fbReader) field(t fbTableRef, slot int) int {
+
+	o := 4 + 2*slot
+	if r.err != nil || o+2 > t.vtSize {
+		return 0
+	}
+	if off := int(r.read(t.vt+o, 2)); off != 0 {
+		return t.pos + off
+	}
+	return 0
+}
+
+// Returns a scalar field or 0 if it is absent.
+func (r *fbReader) scalar(t fbTableRef, slot, size int) uint64 {
+
+	if p := r.field(t, slot); p != 0 {
+		return r.read(p, size)
+	}
+	return 0
+}
+
+// Returns the position of the object referenced at pos.
+func (r *fbReader) deref(pos int) int {
+
+	if off := int(r.u32(pos)); off != 0 {
+		return pos + off
+	}
+	r.fail()
+	return 0
+}
+
+// Returns the position of the object referenced by a field or 0 if it is
+// absent.
+func (r *fbReader) ref(t fbTableRef, slot int) int {
+
+	if p := r.field(t, slot); p != 0 {
+		return r.deref(p)
+	}
+	return 0
+}
+
+// Returns the length of the vector at pos and the position of its first
+// element. A zero pos is an empty vector.
+func (r *fbReader) vector(pos, size int) (int, int) {
+
+	if pos == 0 {
+		return 0, 0
+	}
+	n := int(r.u32(pos))
+	if !r.check(pos+4, n*size) {
+		return 0, 0
+	}
+	return n, pos + 4
+}
+
+func (r *fbReader) str(pos int) string {
+
+	n, p := r.vector(pos, 1)
+	if n == 0 {
+		return ""
+	}
+	return string(r.b[p : p+n])
+}
+
+// Returns the vector of KeyValue tables at pos as a map.
+func (r *fbReader) keyValues(pos int) map[string]string {
+
+	m := make(map[string]string)
+	n, p := r.vector(pos, 4)
+	for i := 0; i < n && r.err == nil; i++ {
+		kv := r.table(r.deref(p + 4*i))
+		m[r.str(r.ref(kv, 0))] = r.str(r.ref(kv, 1))
+	}
+	return m
+}
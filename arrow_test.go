@@ -0,0 +1,155 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestArrow(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	df.VarUnits = map[string]string{"acceleration": "m/s2"}
+	df.SetProperty("sensor", "ak-100")
+	df.Data[1][1] = nil
+	df.Data[2][2] = "x"
+	CheckError(t, df.AddVar("moving", []interface{}{true, false, nil, true, true, false}))
+	CheckError(t, df.AddVar("empty", make([]interface{}, 6)))
+	CheckError(t, df.AddVar("gyro", []interface{}{[]float64{1, 2}, nil, []float64{}, []float64{3}, []float64{4, 5}, []float64{6}}))
+
+	var buf bytes.Buffer
+	CheckError(t, df.WriteArrow(&buf))
+	var again bytes.Buffer
+	CheckError(t, df.WriteArrow(&again))
+	if !bytes.Equal(buf.Bytes(), again.Bytes()) {
+		t.Fatalf("output must be deterministic.")
+	}
+	got, e := ReadDataFrameArrow(&buf)
+	CheckError(t, e)
+	if !reflect.DeepEqual(df.VarNames, got.VarNames) || !reflect.DeepEqual(df.Data, got.Data) {
+		t.Fatalf("round trip is not lossless:\n%v\n%v", df.Data, got.Data)
+	}
+	if got.BatchID != df.BatchID || got.Description != df.Description ||
+		got.Properties["sensor"] != "ak-100" || got.VarUnits["acceleration"] != "m/s2" {
+		t.Fatalf("metadata not preserved: %+v.", got)
+	}
+	if _, e = ReadDataFrameArrow(strings.NewReader(file1)); e == nil {
+		t.Fatalf("expected error for JSON input.")
+	}
+
+	// Non-finite values in float and mixed variables.
+	df.Data[0][2] = math.NaN()
+	df.Data[3][2] = math.Inf(-1)
+	df.Data[0][0] = math.Inf(1)
+	buf.Reset()
+	CheckError(t, df.WriteArrow(&buf))
+	got, e = ReadDataFrameArrow(&buf)
+	CheckError(t, e)
+	if x, ok := got.Data[0][2].(float64); !ok || !math.IsNaN(x) {
+		t.Fatalf("expected NaN, got %v.", got.Data[0][2])
+	}
+	if x, ok := got.Data[0][0].(float64); !ok || !math.IsInf(x, 1) || got.Data[1][0] != "BED5" {
+		t.Fatalf("expected +Inf and BED5, got %v and %v.", got.Data[0][0], got.Data[1][0])
+	}
+
+	// Empty frames.
+	buf.Reset()
+	CheckError(t, Empty("a", "b").WriteArrow(&buf))
+	got, e = ReadDataFrameArrow(&buf)
+	CheckError(t, e)
+	if got.N() != 0 || got.NumVariables() != 2 {
+		t.Fatalf("dims must be 0x2, not %dx%d.", got.N(), got.NumVariables())
+	}
+
+	dir, e := ioutil.TempDir("", "dataframe-arrow")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	fn := filepath.Join(dir, "file1.arrow")
+	CheckError(t, df.WriteArrowFile(fn))
+	_, e = ReadDataFrameArrowFile(fn)
+	CheckError(t, e)
+}
+
+// Checks the layout of the file against the Arrow IPC file format.
+func TestArrowLayout(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	var buf bytes.Buffer
+	CheckError(t, df.WriteArrow(&buf))
+	b := buf.Bytes()
+	if string(b[:8]) != "ARROW1\x00\x00" || string(b[len(b)-6:]) != "ARROW1" {
+		t.Fatalf("invalid magic numbers.")
+	}
+	// The schema message follows the magic number.
+	if binary.LittleEndian.Uint32(b[8:]) != 0xFFFFFFFF {
+		t.Fatalf("expected continuation marker.")
+	}
+	n := int(binary.LittleEndian.Uint32(b[12:]))
+	if n%8 != 0 {
+		t.Fatalf("metadata length %d is not a multiple of 8.", n)
+	}
+	r := &fbReader{b: b[16 : 16+n]}
+	msg := r.root()
+	if v := r.scalar(msg, 0, 2); v != arrowV5 {
+		t.Fatalf("expected version V5, got %d.", v)
+	}
+	if h := r.scalar(msg, 1, 1); h != arrowHeaderSchema {
+		t.Fatalf("expected schema header, got %d.", h)
+	}
+	schema := r.table(r.ref(msg, 2))
+	nf, pos := r.vector(r.ref(schema, 1), 4)
+	CheckError(t, r.err)
+	if nf != 3 {
+		t.Fatalf("expected 3 fields, got %d.", nf)
+	}
+	wifi := r.table(r.deref(pos + 4))
+	if name, typ := r.str(r.ref(wifi, 0)), r.scalar(wifi, 2, 1); name != "wifi" || typ != arrowTypeList {
+		t.Fatalf("expected wifi list, got %s %d.", name, typ)
+	}
+	CheckError(t, r.err)
+
+	f, e := parseArrow(b)
+	CheckError(t, e)
+	if len(f.blocks) != 1 || f.blocks[0][0] != int64(16+n) || f.blocks[0][0]%8 != 0 {
+		t.Fatalf("unexpected blocks %v.", f.blocks)
+	}
+}
+
+func TestArrowCorrupt(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	CheckError(t, df.AddVar("moving", []interface{}{true, false, nil, true, true, false}))
+	var buf bytes.Buffer
+	CheckError(t, df.WriteArrow(&buf))
+	b := buf.Bytes()
+
+	// Truncated and modified files return an error or a frame, and never
+	// panic.
+	for n := 0; n < len(b); n++ {
+		if _, e = ReadDataFrameArrow(bytes.NewReader(b[:n])); e == nil {
+			t.Fatalf("expected error for %d bytes.", n)
+		}
+	}
+	c := make([]byte, len(b))
+	for i := range b {
+		for _, x := range []byte{0x01, 0x80, 0xFF} {
+			copy(c, b)
+			c[i] ^= x
+			ReadDataFrameArrow(bytes.NewReader(c))
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// Names of the variables with the validity interval of the rows of
+// reference frames, see AsOf.
+const (
+	ValidFrom = "valid_from"
+	ValidTo   = "valid_to"
+)
+
+// Returns a new data frame with the rows that were valid at time t. A row is
+// valid from the time in variable ValidFrom, inclusive, to the time in
+// variable ValidTo, exclusive. A nil ValidFrom means the row was always
+// valid in the past, a nil ValidTo means the row is still valid. Times are
+// float64 seconds or RFC 3339 strings. Cell values are not deep copied.
+func (df *DataFrame) AsOf(t time.Time) (*DataFrame, error) {
+
+	valid, err := df.validity()
+	if err != nil {
+		return nil, err
+	}
+	ts := float64(t.UnixNano()) / 1e9
+	return df.View().Filter(func(frame int) bool {
+		return valid[frame][0] <= ts && ts < valid[frame][1]
+	}).Materialize(), nil
+}
+
+// Adds the named variables of a reference frame to the data frame, taking
+// for each row the reference row with the same key that was valid at the
+// time in timeVar, see AsOf. The key variable must exist in both frames.
+// Values are nil when there is no valid reference row; more than one valid
+// row is an error.
+func (df *DataFrame) LookupAsOf(ref *DataFrame, timeVar, key string, names ...string) error {
+
+	times, err := df.timeColumn(timeVar)
+	if err != nil {
+		return err
+	}
+	keys, err := df.indices(key)
+	if err != nil {
+		return err
+	}
+	refKeys, err := ref.indices(key)
+	if err != nil {
+		return err
+	}
+	cols, err := ref.indices(names...)
+	if err != nil {
+		return err
+	}
+	valid, err := ref.validity()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	versions := make(map[string][]int)
+	for i, row := range ref.Data {
+		buf.Reset()
+		if !writeKey(&buf, row[refKeys[0]]) {
+			return fmt.Errorf("In reference frame %d, key variable [%s] is of type [%s]. Must be of type string, float64, or bool.",
+				i, key, reflect.TypeOf(row[refKeys[0]]).String())
+		}
+		versions[buf.String()] = append(versions[buf.String()], i)
+	}
+
+	values := make([][]interface{}, len(names))
+	for j := range values {
+		values[j] = make([]interface{}, df.N())
+	}
+	for i, row := range df.Data {
+		buf.Reset()
+		writeKey(&buf, row[keys[0]])
+		match := -1
+		for _, r := range versions[buf.String()] {
+			if valid[r][0] <= times[i] && times[i] < valid[r][1] {
+				if match >= 0 {
+					return fmt.Errorf("In frame %d, key %v matches reference frames %d and %d.", i, row[keys[0]], match, r)
+				}
+				match = r
+			}
+		}
+		if match < 0 {
+			continue
+		}
+		for j, c := range cols {
+			values[j][i] = ref.Data[match][c]
+		}
+	}
+	for j, name := range names {
+		if err = df.addVar(name, values[j]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the validity interval of each row in seconds.
+func (df *DataFrame) validity() ([][2]float64, error) {
+
+	indices, err := df.indices(ValidFrom, ValidTo)
+	if err != nil {
+		return nil, err
+	}
+	valid := make([][2]float64, df.N())
+	for i, row := range df.Data {
+		valid[i] = [2]float64{math.Inf(-1), math.Inf(1)}
+		for k, idx := range indices {
+			if row[idx] == nil {
+				continue
+			}
+			if valid[i][k], err = timeSeconds(row[idx]); err != nil {
+				return nil, fmt.Errorf("In frame %d, variable [%s]: %s", i, df.VarNames[idx], err)
+			}
+		}
+	}
+	return valid, nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const roomsRef = `{
+"var_names": ["room", "x", "y", "valid_from", "valid_to"],
+"data": [
+["BED5", 1, 2, null, "2013-06-01T00:00:00Z"],
+["BED5", 1.5, 2, "2013-06-01T00:00:00Z", null],
+["DINING", 5, 6, "2013-01-01T00:00:00Z", null]
+]
+}`
+
+func TestAsOf(t *testing.T) {
+
+	ref, e := ReadDataFrame(strings.NewReader(roomsRef))
+	CheckError(t, e)
+
+	may, e := ref.AsOf(time.Date(2013, 5, 1, 0, 0, 0, 0, time.UTC))
+	CheckError(t, e)
+	if may.N() != 2 || may.Data[0][1] != 1.0 {
+		t.Fatalf("unexpected rows %v.", may.Data)
+	}
+	june, e := ref.AsOf(time.Date(2013, 6, 1, 0, 0, 0, 0, time.UTC))
+	CheckError(t, e)
+	if june.N() != 2 || june.Data[0][1] != 1.5 {
+		t.Fatalf("unexpected rows %v.", june.Data)
+	}
+	old, e := ref.AsOf(time.Date(2012, 1, 1, 0, 0, 0, 0, time.UTC))
+	CheckError(t, e)
+	if old.N() != 1 {
+		t.Fatalf("unexpected rows %v.", old.Data)
+	}
+
+	df, e := ReadDataFrame(strings.NewReader(`{
+"var_names": ["time", "room"],
+"data": [
+["2012-12-01T00:00:00Z", "DINING"],
+["2013-05-31T23:59:59Z", "BED5"],
+["2013-06-02T00:00:00Z", "BED5"],
+["2013-06-02T00:00:00Z", "KITCHEN"]
+]
+}`))
+	CheckError(t, e)
+	CheckError(t, df.LookupAsOf(ref, "time", "room", "x", "y"))
+	want := []interface{}{nil, 1.0, 1.5, nil}
+	for i, w := range want {
+		if df.Data[i][2] != w {
+			t.Fatalf("row %d: expected x %v, got %v.", i, w, df.Data[i][2])
+		}
+	}
+
+	// Overlapping versions are ambiguous.
+	ref.Data[0][4] = nil
+	df.Data = df.Data[1:3]
+	df.removeVar("x")
+	df.removeVar("y")
+	if e = df.LookupAsOf(ref, "time", "room", "x"); e == nil {
+		t.Fatalf("expected error for overlapping versions.")
+	}
+	t.Log(e)
+}
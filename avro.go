@@ -0,0 +1,567 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"sort"
+)
+
+// Extension of data frame files in Avro object container format.
+const AvroExt = ".avro"
+
+var avroMagic = []byte("Obj\x01")
+
+// Number of rows per block written by WriteAvro.
+var AvroBlockRows = 1000
+
+// Keys of the Avro file metadata that hold the data frame metadata.
+const (
+	avroSchemaKey      = "avro.schema"
+	avroCodecKey       = "avro.codec"
+	avroDescriptionKey = "dataframe.description"
+	avroBatchIDKey     = "dataframe.batchid"
+	avroUnitsKey       = "dataframe.var_units"
+	avroPropertiesKey  = "dataframe.properties"
+)
+
+const avroSyncSize = 16
+
+// Writes the data frame as an Avro object container file with one record
+// per row, see Schema.Avro. The schema is inferred from the data, see
+// InferSchema; variables whose values are all nil are written as nullable
+// doubles. Blocks are compressed with the deflate codec. Description,
+// batch id, units, and properties are stored in the file metadata. The
+// output is deterministic. See ReadDataFrameAvro.
+func (df *DataFrame) WriteAvro(w io.Writer) error {
+
+	if err := df.checkWrite(); err != nil {
+		return err
+	}
+
+	schema := InferSchema(df)
+	for j := range schema {
+		if schema[j].Type == TypeAny {
+			schema[j].Type = TypeFloat64
+		}
+	}
+	sb, err := schema.Avro("Row", "")
+	if err != nil {
+		return err
+	}
+	meta := map[string][]byte{
+		avroSchemaKey:      sb,
+		avroCodecKey:       []byte("deflate"),
+		avroDescriptionKey: []byte(df.Description),
+		avroBatchIDKey:     []byte(df.BatchID),
+	}
+	if len(df.VarUnits) > 0 {
+		if meta[avroUnitsKey], err = json.Marshal(df.VarUnits); err != nil {
+			return err
+		}
+	}
+	if len(df.Properties) > 0 {
+		if meta[avroPropertiesKey], err = json.Marshal(df.Properties); err != nil {
+			return err
+		}
+	}
+	keys := make([]string, 0, len(meta))
+	for k := range meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// The sync marker is derived from the header so the output is
+	// deterministic.
+	bw := bufio.NewWriter(w)
+	var header bytes.Buffer
+	header.Write(avroMagic)
+	putAvroLong(&header, int64(len(keys)))
+	for _, k := range keys {
+		putAvroBytes(&header, []byte(k))
+		putAvroBytes(&header, meta[k])
+	}
+	putAvroLong(&header, 0)
+	sum := sha256.Sum256(header.Bytes())
+	sync := sum[:avroSyncSize]
+	bw.Write(header.Bytes())
+	bw.Write(sync)
+
+	var block, compressed bytes.Buffer
+	zw, _ := flate.NewWriter(&compressed, flate.DefaultCompression)
+	flush := func(n int) error {
+		compressed.Reset()
+		zw.Reset(&compressed)
+		zw.Write(block.Bytes())
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		var head bytes.Buffer
+		putAvroLong(&head, int64(n))
+		putAvroLong(&head, int64(compressed.Len()))
+		bw.Write(head.Bytes())
+		bw.Write(compressed.Bytes())
+		bw.Write(sync)
+		block.Reset()
+		return nil
+	}
+	var n int
+	for i, row := range df.Data {
+		if len(row) != len(df.VarNames) {
+			return fmt.Errorf("In frame %d, expected %d values, got %d.", i, len(df.VarNames), len(row))
+		}
+		for j, v := range row {
+			if v == nil {
+				putAvroLong(&block, 0)
+				continue
+			}
+			if typ := valueType(v); typ != schema[j].Type {
+				return colTypeError(i, df.VarNames[j], v, schema[j].Type)
+			}
+			putAvroLong(&block, 1)
+			switch x := v.(type) {
+			case float64:
+				putAvroDouble(&block, x)
+			case string:
+				putAvroBytes(&block, []byte(x))
+			case bool:
+				if x {
+					block.WriteByte(1)
+				} else {
+					block.WriteByte(0)
+				}
+			default:
+				vec, err := toFloat64Slice(x)
+				if err != nil {
+					return fmt.Errorf("In frame %d, variable [%s]: %s", i, df.VarNames[j], err)
+				}
+				if len(vec) > 0 {
+					putAvroLong(&block, int64(len(vec)))
+					for _, f := range vec {
+						putAvroDouble(&block, f)
+					}
+				}
+				putAvroLong(&block, 0)
+			}
+		}
+		if n++; n == AvroBlockRows {
+			if err = flush(n); err != nil {
+				return err
+			}
+			n = 0
+		}
+	}
+	if n > 0 {
+		if err = flush(n); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// Writes the data frame to file fn in Avro format, see WriteAvro. The file
+// is replaced atomically so readers never see a partial file.
+func (df *DataFrame) WriteAvroFile(fn string) error {
+
+	return writeFileAtomic(fn, 0644, df.WriteAvro)
+}
+
+// Like WriteAvroFile, with a policy for an existing file.
+func (df *DataFrame) WriteAvroFileWith(fn string, policy OverwritePolicy) error {
+
+	_, err := writeFilePolicy(fn, 0644, policy, df.WriteAvro)
+	return err
+}
+
+// Reads a data frame from an Avro object container file whose schema is a
+// record, with one row per record and one variable per field. Fields may be
+// null, boolean, int, long, float, double, string, bytes, enums, arrays of
+// numbers, or unions of these types. Numbers are read as float64, bytes and
+// enum symbols as strings, and arrays as []float64. The null and deflate
+// codecs are supported. Data frame metadata written by WriteAvro is
+// restored.
+func ReadDataFrameAvro(r io.Reader) (*DataFrame, error) {
+
+	return ReadDataFrameAvroLimits(r, ReadLimits{})
+}
+
+// Reads a data frame from an Avro object container file enforcing limits,
+// see ReadDataFrameAvro and ReadLimits. Rows are checked as they are
+// decoded, and counts and lengths in the input are checked against the
+// input that is left, so corrupt files return an error.
+func ReadDataFrameAvroLimits(r io.Reader, limits ReadLimits) (*DataFrame, error) {
+
+	lr := &limitedReader{r: r, n: limits.MaxBytes}
+	df, err := readAvro(bufio.NewReader(lr), limits)
+	if lr.exceeded {
+		return nil, fmt.Errorf("Input exceeds limit of %d bytes.", limits.MaxBytes)
+	}
+	return df, err
+}
+
+func readAvro(br *bufio.Reader, limits ReadLimits) (*DataFrame, error) {
+
+	magic := make([]byte, len(avroMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(magic, avroMagic) {
+		return nil, fmt.Errorf("not an Avro object container file.")
+	}
+	meta := make(map[string][]byte)
+	for {
+		n, err := readAvroLong(br)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			break
+		}
+		if n < 0 {
+			// Negative counts are followed by the size of the block.
+			n = -n
+			if _, err = readAvroLong(br); err != nil {
+				return nil, err
+			}
+		}
+		for k := int64(0); k < n; k++ {
+			key, err := readAvroBytes(br)
+			if err != nil {
+				return nil, err
+			}
+			if meta[string(key)], err = readAvroBytes(br); err != nil {
+				return nil, err
+			}
+		}
+	}
+	sync := make([]byte, avroSyncSize)
+	if _, err := io.ReadFull(br, sync); err != nil {
+		return nil, err
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(meta[avroSchemaKey], &schema); err != nil {
+		return nil, fmt.Errorf("invalid Avro schema: %s", err)
+	}
+	fields, ok := schema["fields"].([]interface{})
+	if schema["type"] != "record" || !ok {
+		return nil, fmt.Errorf("Avro schema must be a record.")
+	}
+	df := &DataFrame{
+		Description: string(meta[avroDescriptionKey]),
+		BatchID:     string(meta[avroBatchIDKey]),
+		VarNames:    make([]string, len(fields)),
+		Data:        make([][]interface{}, 0),
+	}
+	types := make([]*avroType, len(fields))
+	for j, f := range fields {
+		field, _ := f.(map[string]interface{})
+		name, _ := field["name"].(string)
+		if name == "" {
+			return nil, fmt.Errorf("Avro field %d has no name.", j)
+		}
+		df.VarNames[j] = name
+		var err error
+		if types[j], err = parseAvroType(field["type"]); err != nil {
+			return nil, fmt.Errorf("Avro field [%s]: %s", name, err)
+		}
+	}
+	if err := checkUnique(df.VarNames); err != nil {
+		return nil, err
+	}
+	if err := limits.checkHeader(df); err != nil {
+		return nil, err
+	}
+	if b, ok := meta[avroUnitsKey]; ok {
+		if err := json.Unmarshal(b, &df.VarUnits); err != nil {
+			return nil, err
+		}
+	}
+	if b, ok := meta[avroPropertiesKey]; ok {
+		if err := json.Unmarshal(b, &df.Properties); err != nil {
+			return nil, err
+		}
+	}
+
+	codec := string(meta[avroCodecKey])
+	if codec != "" && codec != "null" && codec != "deflate" {
+		return nil, fmt.Errorf("unsupported Avro codec [%s].", codec)
+	}
+	marker := make([]byte, avroSyncSize)
+	for {
+		n, err := readAvroLong(br)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		b, err := readAvroBytes(br)
+		if err != nil {
+			return nil, err
+		}
+		if codec == "deflate" {
+			fr := io.LimitReader(flate.NewReader(bytes.NewReader(b)), maxAvroLen+1)
+			if b, err = ioutil.ReadAll(fr); err != nil {
+				return nil, err
+			}
+			if len(b) > maxAvroLen {
+				return nil, fmt.Errorf("Avro block exceeds %d bytes.", maxAvroLen)
+			}
+		}
+		// Every record is written with at least one byte.
+		if n < 0 || n > int64(len(b)) {
+			return nil, fmt.Errorf("invalid Avro block of %d records in %d bytes.", n, len(b))
+		}
+		block := bytes.NewReader(b)
+		for k := int64(0); k < n; k++ {
+			if limits.MaxRows > 0 && df.N() >= limits.MaxRows {
+				return nil, fmt.Errorf("Number of rows exceeds limit of %d.", limits.MaxRows)
+			}
+			row := make([]interface{}, len(types))
+			for j, t := range types {
+				if row[j], err = t.decode(block); err != nil {
+					return nil, fmt.Errorf("In frame %d, variable [%s]: %s", df.N(), df.VarNames[j], err)
+				}
+			}
+			if err = limits.checkRow(row); err != nil {
+				return nil, fmt.Errorf("In frame %d, %s", df.N(), err)
+			}
+			df.Data = append(df.Data, row)
+		}
+		if _, err = io.ReadFull(br, marker); err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(marker, sync) {
+			return nil, fmt.Errorf("invalid Avro sync marker after frame %d.", df.N())
+		}
+	}
+	df.resetVarMap()
+	return df, nil
+}
+
+// Reads a data frame from a file in Avro format.
+func ReadDataFrameAvroFile(fn string) (*DataFrame, error) {
+
+	f, err := openFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	df, err := ReadDataFrameAvro(f)
+	if err != nil {
+		return nil, fmt.Errorf("file %s: %s", fn, err)
+	}
+	return df, nil
+}
+
+// Returns a reader for r and true if r starts with the Avro magic number.
+func isAvro(r io.Reader) (io.Reader, bool) {
+
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	b, err := br.Peek(len(avroMagic))
+	return br, err == nil && bytes.Equal(b, avroMagic)
+}
+
+// A parsed Avro type.
+type avroType struct {
+	// Primitive type name, "array", "enum", or "union".
+	kind     string
+	items    *avroType
+	symbols  []string
+	branches []*avroType
+}
+
+func parseAvroType(v interface{}) (*avroType, error) {
+
+	switch x := v.(type) {
+	case string:
+		switch x {
+		case "null", "boolean", "int", "long", "float", "double", "string", "bytes":
+			return &avroType{kind: x}, nil
+		}
+		return nil, fmt.Errorf("unsupported Avro type [%s].", x)
+	case []interface{}:
+		t := &avroType{kind: "union", branches: make([]*avroType, len(x))}
+		for k, b := range x {
+			var err error
+			if t.branches[k], err = parseAvroType(b); err != nil {
+				return nil, err
+			}
+		}
+		return t, nil
+	case map[string]interface{}:
+		switch x["type"] {
+		case "array":
+			items, err := parseAvroType(x["items"])
+			if err != nil {
+				return nil, err
+			}
+			switch items.kind {
+			case "int", "long", "float", "double":
+			default:
+				return nil, fmt.Errorf("unsupported Avro array of [%s].", items.kind)
+			}
+			return &avroType{kind: "array", items: items}, nil
+		case "enum":
+			symbols, _ := x["symbols"].([]interface{})
+			t := &avroType{kind: "enum", symbols: make([]string, len(symbols))}
+			for k, s := range symbols {
+				t.symbols[k], _ = s.(string)
+			}
+			return t, nil
+		default:
+			// A primitive type written as {"type": "double"}.
+			return parseAvroType(x["type"])
+		}
+	}
+	return nil, fmt.Errorf("unsupported Avro type %v.", v)
+}
+
+// Decodes a value of the type.
+func (t *avroType) decode(r *bytes.Reader) (interface{}, error) {
+
+	switch t.kind {
+	case "null":
+		return nil, nil
+	case "boolean":
+		b, err := r.ReadByte()
+		return b != 0, err
+	case "int", "long":
+		n, err := readAvroLong(r)
+		return float64(n), err
+	case "float":
+		b := make([]byte, 4)
+		_, err := io.ReadFull(r, b)
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b))), err
+	case "double":
+		return readAvroDouble(r)
+	case "string", "bytes":
+		b, err := readAvroBytes(r)
+		return string(b), err
+	case "enum":
+		k, err := readAvroLong(r)
+		if err != nil {
+			return nil, err
+		}
+		if k < 0 || k >= int64(len(t.symbols)) {
+			return nil, fmt.Errorf("invalid enum index %d.", k)
+		}
+		return t.symbols[k], nil
+	case "union":
+		k, err := readAvroLong(r)
+		if err != nil {
+			return nil, err
+		}
+		if k < 0 || k >= int64(len(t.branches)) {
+			return nil, fmt.Errorf("invalid union index %d.", k)
+		}
+		return t.branches[k].decode(r)
+	case "array":
+		vec := make([]float64, 0)
+		for {
+			n, err := readAvroLong(r)
+			if err != nil {
+				return nil, err
+			}
+			if n == 0 {
+				return vec, nil
+			}
+			if n < 0 {
+				n = -n
+				if _, err = readAvroLong(r); err != nil {
+					return nil, err
+				}
+			}
+			// Every item is written with at least one byte.
+			if n < 0 || n > int64(r.Len()) {
+				return nil, fmt.Errorf("invalid Avro array of %d items in %d bytes.", n, r.Len())
+			}
+			for k := int64(0); k < n; k++ {
+				v, err := t.items.decode(r)
+				if err != nil {
+					return nil, err
+				}
+				vec = append(vec, v.(float64))
+			}
+		}
+	}
+	return nil, fmt.Errorf("unsupported Avro type [%s].", t.kind)
+}
+
+// Avro longs are zig-zag varints, as encoding/binary signed varints.
+func putAvroLong(buf *bytes.Buffer, x int64) {
+
+	var b [binary.MaxVarintLen64]byte
+	buf.Write(b[:binary.PutVarint(b[:], x)])
+}
+
+func putAvroDouble(buf *bytes.Buffer, x float64) {
+
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], math.Float64bits(x))
+	buf.Write(b[:])
+}
+
+func putAvroBytes(buf *bytes.Buffer, b []byte) {
+
+	putAvroLong(buf, int64(len(b)))
+	buf.Write(b)
+}
+
+func readAvroLong(r io.ByteReader) (int64, error) {
+
+	return binary.ReadVarint(r)
+}
+
+func readAvroDouble(r io.Reader) (float64, error) {
+
+	var b [8]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, err
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(b[:])), nil
+}
+
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+func readAvroBytes(r byteReader) ([]byte, error) {
+
+	n, err := readAvroLong(r)
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 || n > maxAvroLen {
+		return nil, fmt.Errorf("invalid Avro length %d.", n)
+	}
+	// Grow with the input instead of trusting the length.
+	var b bytes.Buffer
+	if _, err = io.CopyN(&b, r, n); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Maximum length of an Avro string, bytes value, or decompressed block.
+const maxAvroLen = 1 << 26
@@ -0,0 +1,177 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bytes"
+	"compress/flate"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestAvro(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	df.VarUnits = map[string]string{"acceleration": "m/s2"}
+	df.SetProperty("sensor", "ak-100")
+	df.Data[1][2] = nil
+	CheckError(t, df.AddVar("moving", []interface{}{true, false, nil, true, true, false}))
+	CheckError(t, df.AddVar("empty", make([]interface{}, 6)))
+
+	defer func(n int) { AvroBlockRows = n }(AvroBlockRows)
+	AvroBlockRows = 4
+	var buf bytes.Buffer
+	CheckError(t, df.WriteAvro(&buf))
+	var again bytes.Buffer
+	CheckError(t, df.WriteAvro(&again))
+	if !bytes.Equal(buf.Bytes(), again.Bytes()) {
+		t.Fatalf("output must be deterministic.")
+	}
+	got, e := ReadDataFrameAvro(&buf)
+	CheckError(t, e)
+	assertSameJSON(t, df, got)
+	if got.BatchID != df.BatchID || got.Properties["sensor"] != "ak-100" || got.VarUnits["acceleration"] != "m/s2" {
+		t.Fatalf("metadata not preserved: %+v.", got)
+	}
+	if _, e = ReadDataFrameAvro(strings.NewReader(file1)); e == nil {
+		t.Fatalf("expected error for JSON input.")
+	}
+
+	// Mixed types in a variable can't be written.
+	df.Data[0][2] = "fast"
+	if e = df.WriteAvro(&buf); e == nil {
+		t.Fatalf("expected type error.")
+	}
+	df.Data[0][2] = 1.3
+
+	// Data sets detect the format of each file.
+	dir, e := ioutil.TempDir("", "dataframe-avro")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	CheckError(t, df.WriteAvroFile(filepath.Join(dir, "file1"+AvroExt)))
+	ds := &DataSet{Path: dir, Dir: "."}
+	CheckError(t, ds.Resolve())
+	df1, e := ds.Next()
+	CheckError(t, e)
+	assertSameJSON(t, df, df1)
+}
+
+// Reads a file written by another tool with the null codec and types that
+// WriteAvro doesn't use.
+func TestReadAvroTypes(t *testing.T) {
+
+	schema := `{"type": "record", "name": "Event", "fields": [
+  {"name": "id", "type": "long"},
+  {"name": "room", "type": {"type": "enum", "name": "Room", "symbols": ["BED5", "DINING"]}},
+  {"name": "temp", "type": ["null", "float"]},
+  {"name": "counts", "type": {"type": "array", "items": "int"}},
+  {"name": "tag", "type": "bytes"}]}`
+	var b bytes.Buffer
+	b.Write(avroMagic)
+	putAvroLong(&b, 1)
+	putAvroBytes(&b, []byte(avroSchemaKey))
+	putAvroBytes(&b, []byte(schema))
+	putAvroLong(&b, 0)
+	sync := bytes.Repeat([]byte{7}, avroSyncSize)
+	b.Write(sync)
+
+	var block bytes.Buffer
+	for _, id := range []int64{-3, 300} {
+		putAvroLong(&block, id)
+		putAvroLong(&block, 1)
+		putAvroLong(&block, 1)
+		block.Write([]byte{0, 0, 0xc0, 0x3f}) // 1.5 as float
+		putAvroLong(&block, -2)               // negative count with size
+		putAvroLong(&block, 2)
+		putAvroLong(&block, 4)
+		putAvroLong(&block, 5)
+		putAvroLong(&block, 0)
+		putAvroBytes(&block, []byte("x"))
+	}
+	putAvroLong(&b, 2)
+	putAvroBytes(&b, block.Bytes())
+	b.Write(sync)
+
+	df, e := ReadDataFrameAvro(&b)
+	CheckError(t, e)
+	if df.N() != 2 || strings.Join(df.VarNames, ",") != "id,room,temp,counts,tag" {
+		t.Fatalf("unexpected frame %+v.", df)
+	}
+	row := df.Data[0]
+	vec, _ := row[3].([]float64)
+	if row[0] != -3.0 || df.Data[1][0] != 300.0 || row[1] != "DINING" || row[2] != 1.5 ||
+		len(vec) != 2 || vec[1] != 5 || row[4] != "x" {
+		t.Fatalf("unexpected row %v.", row)
+	}
+}
+
+// Returns an Avro file with the schema and one block.
+func avroInput(schema, codec string, count int64, block []byte) []byte {
+
+	var b bytes.Buffer
+	b.Write(avroMagic)
+	putAvroLong(&b, 2)
+	putAvroBytes(&b, []byte(avroSchemaKey))
+	putAvroBytes(&b, []byte(schema))
+	putAvroBytes(&b, []byte(avroCodecKey))
+	putAvroBytes(&b, []byte(codec))
+	putAvroLong(&b, 0)
+	sync := bytes.Repeat([]byte{7}, avroSyncSize)
+	b.Write(sync)
+	putAvroLong(&b, count)
+	putAvroBytes(&b, block)
+	b.Write(sync)
+	return b.Bytes()
+}
+
+func TestAvroCorrupt(t *testing.T) {
+
+	empty := `{"type": "record", "name": "E", "fields": []}`
+	vec := `{"type": "record", "name": "E", "fields": [{"name": "v", "type": {"type": "array", "items": "int"}}]}`
+	str := `{"type": "record", "name": "E", "fields": [{"name": "s", "type": "string"}]}`
+	var arr, long, zeros bytes.Buffer
+	putAvroLong(&arr, 1<<40)
+	putAvroLong(&long, 1<<40)
+	fw, _ := flate.NewWriter(&zeros, flate.BestCompression)
+	fw.Write(make([]byte, maxAvroLen+1))
+	fw.Close()
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	for _, b := range [][]byte{
+		avroInput(empty, "null", 1<<25, nil),
+		avroInput(empty, "null", -1, nil),
+		avroInput(vec, "null", 1, arr.Bytes()),
+		avroInput(str, "null", 1, long.Bytes()),
+		avroInput(str, "deflate", 1, zeros.Bytes()),
+	} {
+		if _, e := ReadDataFrameAvro(bytes.NewReader(b)); e == nil {
+			t.Fatalf("expected error for input %q.", b)
+		}
+	}
+	runtime.ReadMemStats(&after)
+	if n := after.TotalAlloc - before.TotalAlloc; n > 3*maxAvroLen {
+		t.Fatalf("corrupt input allocated %d bytes.", n)
+	}
+
+	// Limits are checked while decoding.
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	var buf bytes.Buffer
+	CheckError(t, df.WriteAvro(&buf))
+	for _, limits := range []ReadLimits{{MaxRows: 5}, {MaxStringLen: 4}, {MaxVecLen: 1}, {MaxBytes: 100}} {
+		if _, e = ReadDataFrameAvroLimits(bytes.NewReader(buf.Bytes()), limits); e == nil {
+			t.Fatalf("expected error for limits %+v.", limits)
+		}
+	}
+	_, e = ReadDataFrameAvroLimits(bytes.NewReader(buf.Bytes()), ReadLimits{MaxRows: 6, MaxStringLen: 100, MaxVecLen: 2})
+	CheckError(t, e)
+}
@@ -0,0 +1,231 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"testing"
+)
+
+// Spec for synthetic frames with the same schema as the test files.
+// Each row has a room, a wifi vector of dimension dim, and an acceleration.
+func synthSpec(rows, dim int, seed int64) GenSpec {
+
+	return GenSpec{
+		Rows: rows,
+		Seed: seed,
+		Vars: []GenVar{
+			{Name: "room", Type: "string", Levels: []string{"BED5", "DINING", "KITCHEN", "BATH"}},
+			{Name: "wifi", Type: "[]float64", Dim: dim, Mean: -40, StdDev: 10},
+			{Name: "acceleration", Type: "float64", Mean: 1.5, StdDev: 0.2},
+		},
+	}
+}
+
+// Creates a synthetic frame.
+func synthFrame(rows, dim int, seed int64) *DataFrame {
+
+	df, err := Generate(synthSpec(rows, dim, seed))
+	if err != nil {
+		panic(err)
+	}
+	return df
+}
+
+// Encodes a synthetic frame as JSON.
+func synthJSON(b testing.TB, rows, dim int, seed int64) []byte {
+
+	data, err := json.Marshal(synthFrame(rows, dim, seed))
+	if err != nil {
+		b.Fatal(err)
+	}
+	return data
+}
+
+// Writes a synthetic corpus of nfiles files to a temp dir.
+func synthDataSet(b testing.TB, nfiles, rows, dim int) (ds *DataSet, cleanup func()) {
+
+	dir, err := ioutil.TempDir("", "dataframe-bench")
+	if err != nil {
+		b.Fatal(err)
+	}
+	spec := synthSpec(rows, dim, 0)
+	spec.Files = nfiles
+	ds, err = GenerateDataSet(spec, dir)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return ds, func() { os.RemoveAll(dir) }
+}
+
+func BenchmarkReadDataFrame(b *testing.B) {
+
+	data := synthJSON(b, 10000, 8, 0)
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for k := 0; k < b.N; k++ {
+		if _, e := ReadDataFrame(bytes.NewReader(data)); e != nil {
+			b.Fatal(e)
+		}
+	}
+}
+
+func BenchmarkRowIterator(b *testing.B) {
+
+	data := synthJSON(b, 10000, 8, 0)
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for k := 0; k < b.N; k++ {
+		it, e := NewRowIterator(bytes.NewReader(data))
+		if e != nil {
+			b.Fatal(e)
+		}
+		for {
+			if _, e = it.Next(); e == io.EOF {
+				break
+			}
+			if e != nil {
+				b.Fatal(e)
+			}
+		}
+	}
+}
+
+func BenchmarkReadDataFrameBinary(b *testing.B) {
+
+	df, err := ReadDataFrame(bytes.NewReader(synthJSON(b, 10000, 8, 0)))
+	if err != nil {
+		b.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = df.WriteBinary(&buf); err != nil {
+		b.Fatal(err)
+	}
+	data := buf.Bytes()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for k := 0; k < b.N; k++ {
+		if _, e := ReadDataFrameBinary(bytes.NewReader(data)); e != nil {
+			b.Fatal(e)
+		}
+	}
+}
+
+func BenchmarkFloat64Slice(b *testing.B) {
+
+	df := synthFrame(1000, 8, 0)
+	b.ResetTimer()
+	for k := 0; k < b.N; k++ {
+		if _, e := df.Float64Slice(k%df.N(), "wifi", "acceleration"); e != nil {
+			b.Fatal(e)
+		}
+	}
+}
+
+func BenchmarkFloat64SliceChannel(b *testing.B) {
+
+	df := synthFrame(100000, 8, 0)
+	b.ResetTimer()
+	for k := 0; k < b.N; k++ {
+		for _ = range df.Float64SliceChannel("wifi", "acceleration") {
+		}
+	}
+}
+
+func BenchmarkDataSetScan(b *testing.B) {
+
+	ds, cleanup := synthDataSet(b, 10, 10000, 8)
+	defer cleanup()
+	b.ResetTimer()
+	for k := 0; k < b.N; k++ {
+		for {
+			_, e := ds.Next()
+			if e == io.EOF {
+				break
+			}
+			if e != nil {
+				b.Fatal(e)
+			}
+		}
+	}
+}
+
+func BenchmarkDataSetChannel(b *testing.B) {
+
+	ds, cleanup := synthDataSet(b, 10, 10000, 8)
+	defer cleanup()
+	b.ResetTimer()
+	for k := 0; k < b.N; k++ {
+		for _ = range ds.Float64SliceChannel("wifi", "acceleration") {
+		}
+	}
+}
+
+func BenchmarkDataSetChannelParallel(b *testing.B) {
+
+	ds, cleanup := synthDataSet(b, 10, 10000, 8)
+	defer cleanup()
+	b.ResetTimer()
+	for k := 0; k < b.N; k++ {
+		ch, errc := ds.Float64SliceChanParallel(runtime.NumCPU(), true, "wifi", "acceleration")
+		for _ = range ch {
+		}
+		if err := <-errc; err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFloat64Col(b *testing.B) {
+
+	df := synthFrame(100000, 8, 0)
+	b.ResetTimer()
+	for k := 0; k < b.N; k++ {
+		if _, e := df.Float64Col("acceleration"); e != nil {
+			b.Fatal(e)
+		}
+	}
+}
+
+func BenchmarkColumnarFloat64Col(b *testing.B) {
+
+	c, err := synthFrame(100000, 8, 0).Columnar()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for k := 0; k < b.N; k++ {
+		if _, e := c.Float64Col("acceleration"); e != nil {
+			b.Fatal(e)
+		}
+	}
+}
+
+// Allocation counts are deterministic, unlike timings, so they are used as
+// regression gates that run with the regular tests.
+func TestAllocationGates(t *testing.T) {
+
+	df := synthFrame(1000, 8, 0)
+
+	allocs := testing.AllocsPerRun(10, func() {
+		df.Float64Slice(0, "wifi", "acceleration")
+	})
+	if allocs > 8 {
+		t.Fatalf("Float64Slice: %.0f allocs per row, expected at most 8.", allocs)
+	}
+
+	allocs = testing.AllocsPerRun(10, func() {
+		df.Float64Matrix("wifi", "acceleration")
+	})
+	if allocs > 16 {
+		t.Fatalf("Float64Matrix: %.0f allocs per call, expected at most 16.", allocs)
+	}
+}
@@ -0,0 +1,323 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+)
+
+// Extension of data frame files in binary format.
+const BinaryExt = ".dfb"
+
+var binaryMagic = []byte("DFB1")
+
+// Cell tags in the binary format.
+const (
+	binNil byte = iota
+	binFloat64
+	binString
+	binTrue
+	binFalse
+	binVector
+)
+
+// Metadata of a binary data frame.
+type binaryHeader struct {
+	Description string            `json:"description"`
+	BatchID     string            `json:"batchid"`
+	VarNames    []string          `json:"var_names"`
+	VarUnits    map[string]string `json:"var_units,omitempty"`
+	Properties  map[string]string `json:"properties,omitempty"`
+	Rows        int               `json:"rows"`
+}
+
+// Writes the data frame in a compact binary format that is much faster to
+// read than JSON. The format is a magic number and a JSON header with the
+// metadata followed by the rows. Each cell is a type tag followed by the
+// value: float64 values use 8 bytes, strings and vectors are length
+// prefixed. Cells must be nil, float64, string, bool, or vectors of float64.
+// See ReadDataFrameBinary.
+func (df *DataFrame) WriteBinary(w io.Writer) error {
+
+	if err := df.checkWrite(); err != nil {
+		return err
+	}
+
+	hb, err := json.Marshal(binaryHeader{
+		Description: df.Description,
+		BatchID:     df.BatchID,
+		VarNames:    df.VarNames,
+		VarUnits:    df.VarUnits,
+		Properties:  df.Properties,
+		Rows:        df.N(),
+	})
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	scratch := make([]byte, binary.MaxVarintLen64)
+	putUvarint := func(x int) {
+		n := binary.PutUvarint(scratch, uint64(x))
+		bw.Write(scratch[:n])
+	}
+	putFloat := func(x float64) {
+		binary.LittleEndian.PutUint64(scratch, math.Float64bits(x))
+		bw.Write(scratch[:8])
+	}
+	bw.Write(binaryMagic)
+	putUvarint(len(hb))
+	bw.Write(hb)
+	for i, row := range df.Data {
+		if len(row) != len(df.VarNames) {
+			return fmt.Errorf("In frame %d, expected %d values, got %d.", i, len(df.VarNames), len(row))
+		}
+		for j, v := range row {
+			switch x := v.(type) {
+			case nil:
+				bw.WriteByte(binNil)
+			case float64:
+				bw.WriteByte(binFloat64)
+				putFloat(x)
+			case string:
+				bw.WriteByte(binString)
+				putUvarint(len(x))
+				bw.WriteString(x)
+			case bool:
+				if x {
+					bw.WriteByte(binTrue)
+				} else {
+					bw.WriteByte(binFalse)
+				}
+			case []float64, []interface{}:
+				vec, err := toFloat64Slice(x)
+				if err != nil {
+					return fmt.Errorf("In frame %d, variable [%s]: %s", i, df.VarNames[j], err)
+				}
+				bw.WriteByte(binVector)
+				putUvarint(len(vec))
+				for _, f := range vec {
+					putFloat(f)
+				}
+			default:
+				return fmt.Errorf("In frame %d, variable [%s] has unsupported type [%s].",
+					i, df.VarNames[j], reflect.TypeOf(v).String())
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// Writes the data frame to file fn in binary format, see WriteBinary. The
+// file is replaced atomically so readers never see a partial file.
+func (df *DataFrame) WriteBinaryFile(fn string) error {
+
+	return writeFileAtomic(fn, 0644, df.WriteBinary)
+}
+
+// Like WriteBinaryFile, with a policy for an existing file.
+func (df *DataFrame) WriteBinaryFileWith(fn string, policy OverwritePolicy) error {
+
+	_, err := writeFilePolicy(fn, 0644, policy, df.WriteBinary)
+	return err
+}
+
+// Maximum number of cells and length of a string or vector in a binary
+// data frame. Larger values are rejected as corrupt. Memory for rows and
+// values is allocated in chunks of up to binaryChunk elements as the input
+// is read, never from the counts alone.
+const (
+	maxBinaryCells = 1 << 28
+	maxBinaryLen   = 1 << 26
+	binaryChunk    = 1024
+)
+
+func minInt(a, b int) int {
+
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Reads a data frame written by WriteBinary. Vectors are read as []float64.
+func ReadDataFrameBinary(r io.Reader) (*DataFrame, error) {
+
+	return ReadDataFrameBinaryLimits(r, ReadLimits{})
+}
+
+// Reads a data frame written by WriteBinary enforcing limits, see
+// ReadLimits. Memory grows with the input actually read, so corrupt lengths
+// and row counts return an error instead of exhausting memory.
+func ReadDataFrameBinaryLimits(r io.Reader, limits ReadLimits) (*DataFrame, error) {
+
+	lr := &limitedReader{r: r, n: limits.MaxBytes}
+	df, err := readBinary(bufio.NewReader(lr), limits)
+	if lr.exceeded {
+		return nil, fmt.Errorf("Input exceeds limit of %d bytes.", limits.MaxBytes)
+	}
+	return df, err
+}
+
+func readBinary(br *bufio.Reader, limits ReadLimits) (*DataFrame, error) {
+
+	magic := make([]byte, len(binaryMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(magic, binaryMagic) {
+		return nil, fmt.Errorf("not a binary data frame.")
+	}
+	n, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	if n > maxBinaryLen {
+		return nil, fmt.Errorf("invalid header length %d.", n)
+	}
+	hb := make([]byte, n)
+	if _, err = io.ReadFull(br, hb); err != nil {
+		return nil, err
+	}
+	var h binaryHeader
+	if err = json.Unmarshal(hb, &h); err != nil {
+		return nil, err
+	}
+	if h.Rows < 0 || h.Rows > 0 && len(h.VarNames) == 0 {
+		return nil, fmt.Errorf("invalid number of rows %d.", h.Rows)
+	}
+	if len(h.VarNames) > 0 && h.Rows > maxBinaryCells/len(h.VarNames) {
+		return nil, fmt.Errorf("%d rows of %d variables exceed %d cells.", h.Rows, len(h.VarNames), maxBinaryCells)
+	}
+	if limits.MaxRows > 0 && h.Rows > limits.MaxRows {
+		return nil, fmt.Errorf("Number of rows exceeds limit of %d.", limits.MaxRows)
+	}
+	for _, s := range append([]string{h.Description, h.BatchID}, h.VarNames...) {
+		if err = limits.checkString(s); err != nil {
+			return nil, err
+		}
+	}
+	df := &DataFrame{
+		Description: h.Description,
+		BatchID:     h.BatchID,
+		VarNames:    h.VarNames,
+		VarUnits:    h.VarUnits,
+		Properties:  h.Properties,
+		Data:        make([][]interface{}, 0, minInt(h.Rows, binaryChunk)),
+	}
+
+	scratch := make([]byte, 8)
+	readFloat := func() (float64, error) {
+		if _, err := io.ReadFull(br, scratch); err != nil {
+			return 0, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(scratch)), nil
+	}
+	// Reads a length and checks it against the format and user limits.
+	readLen := func(limit int, what string) (int, error) {
+		n, err := binary.ReadUvarint(br)
+		if err != nil {
+			return 0, err
+		}
+		if n > maxBinaryLen {
+			return 0, fmt.Errorf("invalid %s length %d.", what, n)
+		}
+		if limit > 0 && n > uint64(limit) {
+			return 0, fmt.Errorf("%s length %d exceeds limit of %d.", what, n, limit)
+		}
+		return int(n), nil
+	}
+	// Rows and values are allocated as they are read, so the counts in a
+	// corrupt header can't exhaust memory. Cells of up to binaryChunk rows
+	// share one backing array.
+	nv := len(h.VarNames)
+	var cells []interface{}
+	for i := 0; i < h.Rows; i++ {
+		if len(cells) == 0 {
+			cells = make([]interface{}, minInt(h.Rows-i, binaryChunk)*nv)
+		}
+		row := cells[:nv:nv]
+		cells = cells[nv:]
+		for j := range row {
+			tag, err := br.ReadByte()
+			if err != nil {
+				return nil, fmt.Errorf("In frame %d: %s", i, err)
+			}
+			switch tag {
+			case binNil:
+			case binFloat64:
+				if row[j], err = readFloat(); err != nil {
+					return nil, fmt.Errorf("In frame %d: %s", i, err)
+				}
+			case binString:
+				n, err := readLen(limits.MaxStringLen, "string")
+				if err != nil {
+					return nil, fmt.Errorf("In frame %d, %s", i, err)
+				}
+				var b bytes.Buffer
+				if _, err = io.CopyN(&b, br, int64(n)); err != nil {
+					if err == io.EOF {
+						err = io.ErrUnexpectedEOF
+					}
+					return nil, fmt.Errorf("In frame %d: %s", i, err)
+				}
+				row[j] = b.String()
+			case binTrue:
+				row[j] = true
+			case binFalse:
+				row[j] = false
+			case binVector:
+				n, err := readLen(limits.MaxVecLen, "vector")
+				if err != nil {
+					return nil, fmt.Errorf("In frame %d, %s", i, err)
+				}
+				vec := make([]float64, 0, minInt(n, binaryChunk))
+				for k := 0; k < n; k++ {
+					x, err := readFloat()
+					if err != nil {
+						return nil, fmt.Errorf("In frame %d: %s", i, err)
+					}
+					vec = append(vec, x)
+				}
+				row[j] = vec
+			default:
+				return nil, fmt.Errorf("In frame %d, invalid cell tag %d.", i, tag)
+			}
+		}
+		df.Data = append(df.Data, row)
+	}
+	df.resetVarMap()
+	return df, nil
+}
+
+// Reads a data frame from a file in binary format.
+func ReadDataFrameBinaryFile(fn string) (*DataFrame, error) {
+
+	f, err := openFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	df, err := ReadDataFrameBinary(f)
+	if err != nil {
+		return nil, fmt.Errorf("file %s: %s", fn, err)
+	}
+	return df, nil
+}
+
+// Returns a reader for r and true if r starts with the binary magic number.
+func isBinary(r io.Reader) (io.Reader, bool) {
+
+	br := bufio.NewReader(r)
+	b, err := br.Peek(len(binaryMagic))
+	return br, err == nil && bytes.Equal(b, binaryMagic)
+}
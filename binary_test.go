@@ -0,0 +1,167 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestBinary(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	df.VarUnits = map[string]string{"acceleration": "m/s2"}
+	df.SetProperty("sensor", "ak-100")
+
+	var buf bytes.Buffer
+	CheckError(t, df.WriteBinary(&buf))
+	got, e := ReadDataFrameBinary(&buf)
+	CheckError(t, e)
+	assertSameJSON(t, df, got)
+	if got.BatchID != df.BatchID || got.Properties["sensor"] != "ak-100" {
+		t.Fatalf("metadata not preserved: %+v.", got)
+	}
+	if _, e = ReadDataFrameBinary(strings.NewReader(file1)); e == nil {
+		t.Fatalf("expected error for JSON input.")
+	}
+
+	// Data sets detect the format of each file.
+	dir, e := ioutil.TempDir("", "dataframe-binary")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	CheckError(t, df.WriteBinaryFile(filepath.Join(dir, "file1"+BinaryExt)))
+	CheckError(t, ioutil.WriteFile(filepath.Join(dir, "file2.json"), []byte(file2), 0644))
+	ds := &DataSet{Path: dir, Files: []string{"file1" + BinaryExt, "file2.json"}}
+	df1, e := ds.Next()
+	CheckError(t, e)
+	assertSameJSON(t, df, df1)
+	df2, e := ds.Next()
+	CheckError(t, e)
+	if df2.BatchID != "24001-016" {
+		t.Fatalf("unexpected batch id %s.", df2.BatchID)
+	}
+	p, e := ds.Preview(2)
+	CheckError(t, e)
+	if p.N() != 4 {
+		t.Fatalf("expected 4 preview rows, got %d.", p.N())
+	}
+}
+
+// Returns a binary data frame with the header and cells.
+func binaryInput(header string, cells ...byte) []byte {
+
+	var buf bytes.Buffer
+	buf.Write(binaryMagic)
+	var n [binary.MaxVarintLen64]byte
+	buf.Write(n[:binary.PutUvarint(n[:], uint64(len(header)))])
+	buf.WriteString(header)
+	buf.Write(cells)
+	return buf.Bytes()
+}
+
+func TestBinaryCorrupt(t *testing.T) {
+
+	varint := func(n uint64) []byte {
+		b := make([]byte, binary.MaxVarintLen64)
+		return b[:binary.PutUvarint(b, n)]
+	}
+	huge := varint(1 << 62)
+	large := varint(maxBinaryLen)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	for _, b := range [][]byte{
+		binaryInput(`{"var_names": ["a"], "rows": -1}`),
+		binaryInput(`{"var_names": ["a", "b"], "rows": 1000000000}`),
+		binaryInput(`{"var_names": ["a"], "rows": 268435455}`),
+		binaryInput(`{"var_names": [], "rows": 100000000000}`),
+		binaryInput(`{"var_names": ["a"], "rows": 1}`, append([]byte{binString}, huge...)...),
+		binaryInput(`{"var_names": ["a"], "rows": 1}`, append([]byte{binVector}, huge...)...),
+		binaryInput(`{"var_names": ["a"], "rows": 1}`, append([]byte{binString}, large...)...),
+		binaryInput(`{"var_names": ["a"], "rows": 1}`, append([]byte{binVector}, large...)...),
+		binaryInput(`{"var_names": ["a"], "rows": 2}`, binTrue),
+	} {
+		if _, e := ReadDataFrameBinary(bytes.NewReader(b)); e == nil {
+			t.Fatalf("expected error for input %q.", b)
+		}
+	}
+	// Memory must not be allocated from the counts in the input.
+	runtime.ReadMemStats(&after)
+	if n := after.TotalAlloc - before.TotalAlloc; n > 16<<20 {
+		t.Fatalf("corrupt input allocated %d bytes.", n)
+	}
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	var buf bytes.Buffer
+	CheckError(t, df.WriteBinary(&buf))
+	for _, limits := range []ReadLimits{{MaxRows: 5}, {MaxStringLen: 4}, {MaxVecLen: 1}, {MaxBytes: 100}} {
+		if _, e = ReadDataFrameBinaryLimits(bytes.NewReader(buf.Bytes()), limits); e == nil {
+			t.Fatalf("expected error for limits %+v.", limits)
+		}
+	}
+	_, e = ReadDataFrameBinaryLimits(bytes.NewReader(buf.Bytes()), ReadLimits{MaxRows: 6, MaxStringLen: 100, MaxVecLen: 2})
+	CheckError(t, e)
+
+	// Data sets apply their limits to binary files.
+	dir, e := ioutil.TempDir("", "dataframe-binary")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	CheckError(t, df.WriteBinaryFile(filepath.Join(dir, "file1.json")))
+	ds := &DataSet{Path: dir, Files: []string{"file1.json"}, Limits: &ReadLimits{MaxRows: 2}}
+	if _, e = ds.Next(); e == nil {
+		t.Fatalf("expected limit error.")
+	}
+
+	// Data sets detect binary files by content, so a corrupt header in any
+	// file must return an error.
+	CheckError(t, ioutil.WriteFile(filepath.Join(dir, "bad.json"),
+		binaryInput(`{"var_names":["a"],"rows":268435455}`), 0644))
+	ds = &DataSet{Path: dir, Files: []string{"bad.json"}}
+	if _, e = ds.Next(); e == nil {
+		t.Fatalf("expected error for corrupt file.")
+	}
+}
+
+func FuzzReadDataFrameBinary(f *testing.F) {
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	if e != nil {
+		f.Fatal(e)
+	}
+	var buf bytes.Buffer
+	if e = df.WriteBinary(&buf); e != nil {
+		f.Fatal(e)
+	}
+	f.Add(buf.Bytes())
+	f.Add(binaryInput(`{"var_names": ["a"], "rows": 1}`, binVector, 1, 0, 0, 0, 0, 0, 0, 0, 0))
+	limits := ReadLimits{MaxBytes: 1 << 16, MaxRows: 100, MaxVecLen: 100, MaxStringLen: 100}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		df, e := ReadDataFrameBinaryLimits(bytes.NewReader(b), limits)
+		if e != nil {
+			return
+		}
+		if df.N() > limits.MaxRows {
+			t.Fatalf("read %d rows, limit is %d.", df.N(), limits.MaxRows)
+		}
+	})
+}
+
+func assertSameJSON(t *testing.T, want, got *DataFrame) {
+
+	var wb, gb bytes.Buffer
+	CheckError(t, want.WriteDataFrame(&wb))
+	CheckError(t, got.WriteDataFrame(&gb))
+	if wb.String() != gb.String() {
+		t.Fatalf("expected:\n%s\ngot:\n%s", wb.String(), gb.String())
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Distribution of a statistic over bootstrap resamples.
+type BootstrapResult struct {
+	// Statistic of each resample in ascending order.
+	Samples []float64
+	// Mean of the samples.
+	Mean float64
+	// Standard deviation of the samples, the bootstrap standard error.
+	StdErr float64
+}
+
+// Estimates the distribution of a statistic by computing it on iters
+// resamples of n rows drawn with replacement. If n is zero, resamples have
+// the same number of rows as df. The seed makes the result reproducible.
+// For example, a 95% confidence interval of the mean acceleration:
+//
+//	r, _ := df.Bootstrap(0, func(s *dataframe.DataFrame) float64 {
+//		st, _ := s.ColStats("acceleration")
+//		return st.Mean
+//	}, 1000, 0)
+//	lo, hi, _ := r.Interval(0.95)
+func (df *DataFrame) Bootstrap(n int, statFn func(*DataFrame) float64, iters int, seed int64) (*BootstrapResult, error) {
+
+	if n == 0 {
+		n = df.N()
+	}
+	if n < 0 || iters <= 0 {
+		return nil, fmt.Errorf("Number of rows and iterations must be positive, got %d and %d.", n, iters)
+	}
+	if df.N() == 0 {
+		return nil, fmt.Errorf("Can't resample a data frame without rows.")
+	}
+	r := rand.New(rand.NewSource(seed))
+	acc := NewAccumulator(1)
+	samples := make([]float64, iters)
+	rows := make([]int, n)
+	for k := range samples {
+		for i := range rows {
+			rows[i] = r.Intn(df.N())
+		}
+		v := &View{parent: df, rows: rows, names: df.VarNames}
+		s := statFn(v.Materialize())
+		if math.IsNaN(s) {
+			return nil, fmt.Errorf("Statistic of resample %d is NaN.", k)
+		}
+		samples[k] = s
+		acc.Add(samples[k : k+1])
+	}
+	sort.Float64s(samples)
+	return &BootstrapResult{Samples: samples, Mean: acc.Mean()[0], StdErr: acc.StdDev()[0]}, nil
+}
+
+// Returns the percentile confidence interval of the statistic, for example
+// a confidence of 0.95 returns the 2.5th and 97.5th percentiles.
+func (r *BootstrapResult) Interval(confidence float64) (lo, hi float64, err error) {
+
+	if confidence <= 0 || confidence >= 1 {
+		return 0, 0, fmt.Errorf("Confidence must be between 0 and 1, got %g.", confidence)
+	}
+	tail := (1 - confidence) / 2 * 100
+	if lo, err = percentile(r.Samples, tail); err != nil {
+		return
+	}
+	hi, err = percentile(r.Samples, 100-tail)
+	return
+}
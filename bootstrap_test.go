@@ -0,0 +1,58 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestBootstrap(t *testing.T) {
+
+	df := synthFrame(200, 2, 0)
+	mean := func(s *DataFrame) float64 {
+		st, err := s.ColStats("acceleration")
+		if err != nil {
+			return math.NaN()
+		}
+		return st.Mean
+	}
+
+	r, e := df.Bootstrap(0, mean, 500, 1)
+	CheckError(t, e)
+	lo, hi, e := r.Interval(0.95)
+	CheckError(t, e)
+	t.Logf("mean %f, stderr %f, interval [%f, %f]", r.Mean, r.StdErr, lo, hi)
+	if len(r.Samples) != 500 || lo >= hi {
+		t.Fatalf("unexpected result %d samples, [%f, %f].", len(r.Samples), lo, hi)
+	}
+	if m := mean(df); m < lo || m > hi {
+		t.Fatalf("sample mean %f is outside the interval [%f, %f].", m, lo, hi)
+	}
+	st, e := df.ColStats("acceleration")
+	CheckError(t, e)
+	// The standard error of the mean is about stddev/sqrt(n).
+	if se := st.StdDev / math.Sqrt(200); math.Abs(r.StdErr-se) > se/4 {
+		t.Fatalf("expected standard error near %f, got %f.", se, r.StdErr)
+	}
+
+	r2, e := df.Bootstrap(0, mean, 500, 1)
+	CheckError(t, e)
+	if !reflect.DeepEqual(r.Samples, r2.Samples) {
+		t.Fatalf("same seed must give the same samples.")
+	}
+
+	if _, _, e = r.Interval(1); e == nil {
+		t.Fatalf("expected error for confidence 1.")
+	}
+	if _, e = df.Bootstrap(0, mean, 0, 1); e == nil {
+		t.Fatalf("expected error for zero iterations.")
+	}
+	if _, e = df.Bootstrap(1, func(*DataFrame) float64 { return math.NaN() }, 10, 1); e == nil {
+		t.Fatalf("expected error for NaN statistic.")
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Creates a data frame with the given variables and no rows. Rows are added
+// with AppendRow. Returns an error if a name is repeated.
+func NewDataFrame(varNames ...string) (*DataFrame, error) {
+
+	if err := checkUnique(varNames); err != nil {
+		return nil, err
+	}
+	return Empty(varNames...), nil
+}
+
+// Appends a row with one value per variable, in variable order. Values must
+// be nil, float64, string, bool, or []float64; other integer and float types
+// are converted to float64. The type of each value must match the type of the
+// existing values of the variable. If the frame has an index, the key must
+// not be in the index. The index and cached statistics, see ColStats, are
+// updated incrementally.
+func (df *DataFrame) AppendRow(values ...interface{}) error {
+
+	frame := df.N()
+	if len(values) != len(df.VarNames) {
+		return fmt.Errorf("In frame %d, expected %d values, got %d.", frame, len(df.VarNames), len(values))
+	}
+	row := make([]interface{}, len(values))
+	for j, v := range values {
+		x, err := rowValue(v)
+		if err != nil {
+			return fmt.Errorf("In frame %d, variable [%s]: %s", frame, df.VarNames[j], err)
+		}
+		if x != nil {
+			if typ := df.varType(j); typ != "" && typ != valueType(x) {
+				return fmt.Errorf("In frame %d, variable [%s] is of type [%s]. Must be of type %s.",
+					frame, df.VarNames[j], valueType(x), typ)
+			}
+		}
+		row[j] = x
+	}
+
+	var key interface{}
+	if df.index != nil {
+		if v := row[df.varMap[df.index.name]]; v != nil {
+			key, _ = indexKey(v)
+			if i, ok := df.index.rows[key]; ok {
+				return fmt.Errorf("Duplicate key %v in frames %d and %d.", key, i, frame)
+			}
+		}
+	}
+	df.Data = append(df.Data, row)
+	if key != nil {
+		df.index.rows[key] = frame
+	}
+	df.updateStats(row)
+	return nil
+}
+
+// Returns the type of the first non-nil value of variable j, or an empty
+// string if all the values are nil.
+func (df *DataFrame) varType(j int) string {
+
+	for _, row := range df.Data {
+		if row[j] != nil {
+			return valueType(row[j])
+		}
+	}
+	return ""
+}
+
+// Converts a value to one of the types stored in data frames.
+func rowValue(v interface{}) (interface{}, error) {
+
+	switch x := v.(type) {
+	case nil, float64, string, bool, []float64:
+		return x, nil
+	case []interface{}:
+		if valueType(x) != TypeVector {
+			return nil, fmt.Errorf("vector elements must be float64.")
+		}
+		return x, nil
+	case int:
+		return float64(x), nil
+	case int64:
+		return float64(x), nil
+	case int32:
+		return float64(x), nil
+	case float32:
+		return float64(x), nil
+	}
+	return nil, fmt.Errorf("values of type [%s] are not supported.", reflect.TypeOf(v).String())
+}
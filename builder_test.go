@@ -0,0 +1,74 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestNewDataFrame(t *testing.T) {
+
+	df, e := NewDataFrame("room", "wifi", "acceleration")
+	CheckError(t, e)
+	df.BatchID = "sensor-1"
+	df.SetProperty("sensor", "ak-100")
+	CheckError(t, df.AppendRow("BED5", []float64{-40.8, -41.2}, 1.3))
+	CheckError(t, df.AppendRow("BED5", nil, 1))
+	CheckError(t, df.AppendRow("DINING", []interface{}{-42.9, -40.11}, float32(1.5)))
+	if df.N() != 3 || df.Data[1][2] != 1.0 {
+		t.Fatalf("unexpected data %v.", df.Data)
+	}
+
+	// Type checks.
+	if e = df.AppendRow(1.0, []float64{0, 0}, 1.0); e == nil {
+		t.Fatalf("expected type error.")
+	}
+	t.Log(e)
+	if e = df.AppendRow("BED5", []float64{0, 0}); e == nil {
+		t.Fatalf("expected error for missing value.")
+	}
+	if e = df.AppendRow("BED5", []float64{0, 0}, uint8(1)); e == nil {
+		t.Fatalf("expected error for unsupported type.")
+	}
+	if df.N() != 3 {
+		t.Fatalf("failed appends must not add rows.")
+	}
+
+	// Statistics and index are updated.
+	st, e := df.ColStats("acceleration")
+	CheckError(t, e)
+	CheckError(t, df.SetIndex("acceleration"))
+	CheckError(t, df.AppendRow("KITCHEN", nil, 2))
+	st2, e := df.ColStats("acceleration")
+	CheckError(t, e)
+	df.Invalidate()
+	st3, e := df.ColStats("acceleration")
+	CheckError(t, e)
+	if st2.N != st.N+1 || st2.Max != 2 || math.Abs(st2.Mean-st3.Mean) > 1e-12 || math.Abs(st2.StdDev-st3.StdDev) > 1e-12 {
+		t.Fatalf("statistics not updated: got %+v, expected %+v.", st2, st3)
+	}
+	if i, e := df.RowByKey(2); e != nil || i != 3 {
+		t.Fatalf("expected row 3, got %d %v.", i, e)
+	}
+	if e = df.AppendRow("KITCHEN", nil, 2); e == nil {
+		t.Fatalf("expected error for duplicate key.")
+	}
+
+	// Round trip.
+	var buf bytes.Buffer
+	CheckError(t, df.WriteDataFrame(&buf))
+	df2, e := ReadDataFrame(&buf)
+	CheckError(t, e)
+	if df2.N() != 4 || df2.Properties["sensor"] != "ak-100" {
+		t.Fatalf("unexpected frame %v.", df2)
+	}
+
+	if _, e = NewDataFrame("a", "a"); e == nil {
+		t.Fatalf("expected error for duplicate names.")
+	}
+}
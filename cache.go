@@ -0,0 +1,169 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/golang/glog"
+)
+
+// Extension of cache files. The cache of file f is stored in f + CacheExt
+// as an Arrow IPC file, see WriteArrow.
+const CacheExt = ".arrow"
+
+// Keys of the Arrow schema metadata of cache files.
+const (
+	cacheHeaderKey    = "dataframe.cache"
+	cacheCoercionsKey = "dataframe.coercions"
+)
+
+// Identifies the source of a cache file.
+type cacheHeader struct {
+	Size    int64
+	ModTime int64
+	SHA256  string
+	// Hash of the options used to decode the source, see decodeOptions.
+	Options string
+}
+
+// Returns a hash of the data set options that change how a file is
+// decoded. Caches written with different options are invalid.
+func (ds *DataSet) decodeOptions() string {
+
+	b, err := json.Marshal(struct {
+		Encoding Encoding
+		CSV      CSVOptions
+		Influx   InfluxOptions
+		Limits   *ReadLimits
+	}{ds.Encoding, ds.CSV, ds.Influx, ds.Limits})
+	if err != nil {
+		// Never matches a cache.
+		return ""
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+// Reads the cache of file fn if it is valid, otherwise reads the file and
+// writes the cache. A cache is valid if it was written with the same decode
+// options, and the source has the same size and either the same
+// modification time or the same content hash. Failures writing the cache
+// are logged and don't affect the result.
+func (ds *DataSet) readCached(fn string) (*DataFrame, error) {
+
+	info, err := os.Stat(fn)
+	if err != nil {
+		return nil, err
+	}
+	h := cacheHeader{
+		Size:    info.Size(),
+		ModTime: info.ModTime().UnixNano(),
+		Options: ds.decodeOptions(),
+	}
+	df, ok := ds.loadCache(fn, &h)
+	ds.metrics.observeCache(ok)
+	if ok {
+		return df, nil
+	}
+	df, err = ds.decodeFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	if err = ds.writeCache(fn, h, df); err != nil {
+		glog.Warningf("can't write cache for %s: %s", fn, err)
+	}
+	return df, nil
+}
+
+// Loads a valid cache. Fills the hash in h if it was computed.
+func (ds *DataSet) loadCache(fn string, h *cacheHeader) (*DataFrame, bool) {
+
+	b, err := ioutil.ReadFile(fn + CacheExt)
+	if err != nil {
+		return nil, false
+	}
+	f, err := parseArrow(b)
+	if err != nil {
+		glog.Warningf("invalid cache for %s: %s", fn, err)
+		return nil, false
+	}
+	var cached cacheHeader
+	if err = json.Unmarshal([]byte(f.meta[cacheHeaderKey]), &cached); err != nil ||
+		cached.Size != h.Size || cached.Options == "" || cached.Options != h.Options {
+		return nil, false
+	}
+	if cached.ModTime != h.ModTime {
+		fh, err := hashFile(fn)
+		if err != nil {
+			return nil, false
+		}
+		h.SHA256 = fh.SHA256
+		if cached.SHA256 != h.SHA256 {
+			return nil, false
+		}
+	}
+	df, err := f.frame()
+	if err == nil && f.meta[cacheCoercionsKey] != "" {
+		err = json.Unmarshal([]byte(f.meta[cacheCoercionsKey]), &df.coercions)
+	}
+	if err != nil {
+		glog.Warningf("invalid cache for %s: %s", fn, err)
+		return nil, false
+	}
+	df.setReportFile(fn)
+	glog.V(2).Infof("read cache for %s", fn)
+	return df, true
+}
+
+func (ds *DataSet) writeCache(fn string, h cacheHeader, df *DataFrame) error {
+
+	if h.SHA256 == "" {
+		fh, err := hashFile(fn)
+		if err != nil {
+			return err
+		}
+		h.SHA256 = fh.SHA256
+	}
+	hb, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	meta := map[string]string{cacheHeaderKey: string(hb)}
+	if len(df.coercions) > 0 {
+		cb, err := json.Marshal(df.coercions)
+		if err != nil {
+			return err
+		}
+		meta[cacheCoercionsKey] = string(cb)
+	}
+	var buf bytes.Buffer
+	if err = df.writeArrow(&buf, meta); err != nil {
+		return err
+	}
+	// Write to a temporary file so readers never see a partial cache.
+	f, err := ioutil.TempFile(filepath.Dir(fn), "."+filepath.Base(fn)+CacheExt)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(buf.Bytes())
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), fn+CacheExt)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
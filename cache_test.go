@@ -0,0 +1,79 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-cache")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	fn := filepath.Join(dir, "file1.json")
+	data := strings.Replace(file1, `["BED5",[-41.8,-41.1],1.4]`, `["BED5",null,"x"]`, 1)
+	CheckError(t, ioutil.WriteFile(fn, []byte(data), 0644))
+
+	ds := &DataSet{Path: dir, Files: []string{"file1.json"}, Cache: true}
+	df1, e := ds.Next()
+	CheckError(t, e)
+	if _, e = os.Stat(fn + CacheExt); e != nil {
+		t.Fatalf("cache was not written: %s", e)
+	}
+
+	// Read from cache.
+	ds.Reset()
+	df2, e := ds.Next()
+	CheckError(t, e)
+	if df2.N() != df1.N() || df2.BatchID != df1.BatchID || df2.Data[1][1] != nil {
+		t.Fatalf("unexpected cached frame %+v.", df2)
+	}
+	v, e := df2.Float64Slice(0, "wifi", "acceleration")
+	CheckError(t, e)
+	if v[0] != -40.8 || v[2] != 1.3 {
+		t.Fatalf("unexpected values %v.", v)
+	}
+	if len(df2.CoercionReport()) != 1 || df2.CoercionReport()[0].File != fn {
+		t.Fatalf("coercion report not cached: %v.", df2.CoercionReport())
+	}
+
+	// Same content with a new modification time is still valid.
+	later := time.Now().Add(time.Hour)
+	CheckError(t, os.Chtimes(fn, later, later))
+	h := cacheHeader{Options: ds.decodeOptions()}
+	info, _ := os.Stat(fn)
+	h.Size, h.ModTime = info.Size(), info.ModTime().UnixNano()
+	if _, ok := ds.loadCache(fn, &h); !ok {
+		t.Fatalf("cache must be valid when only the modification time changes.")
+	}
+
+	// Different decode options invalidate the cache.
+	ds.Limits = &ReadLimits{MaxRows: 3}
+	h.Options = ds.decodeOptions()
+	if _, ok := ds.loadCache(fn, &h); ok {
+		t.Fatalf("cache must be invalid when the decode options change.")
+	}
+	ds.Reset()
+	if _, e = ds.Next(); e == nil || !strings.Contains(e.Error(), "limit") {
+		t.Fatalf("expected row limit error, got %v.", e)
+	}
+	ds.Limits = nil
+
+	// Modified source invalidates the cache.
+	CheckError(t, ioutil.WriteFile(fn, []byte(strings.Replace(data, "BED5", "BED6", -1)), 0644))
+	ds.Reset()
+	df3, e := ds.Next()
+	CheckError(t, e)
+	if s, _ := df3.String(0, "room"); s != "BED6" {
+		t.Fatalf("stale cache, got room %s.", s)
+	}
+}
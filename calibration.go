@@ -0,0 +1,156 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+)
+
+// Gain and offset applied to a variable: x' = x*Gain + Offset.
+type Calibration struct {
+	// Defaults to 1 when omitted.
+	Gain   float64 `json:"gain"`
+	Offset float64 `json:"offset"`
+}
+
+func (c *Calibration) UnmarshalJSON(b []byte) error {
+
+	type calibration Calibration
+	v := calibration{Gain: 1}
+	if e := json.Unmarshal(b, &v); e != nil {
+		return e
+	}
+	*c = Calibration(v)
+	return nil
+}
+
+// A CalibrationProfile holds per-device calibrations. The device of a data
+// frame is identified by one of its properties. For example:
+//
+//	{
+//	  "property": "device_id",
+//	  "devices": {
+//	    "phone-01": {"acceleration": {"gain": 1.02, "offset": -0.1}},
+//	    "phone-02": {"wifi": {"offset": 3}}
+//	  },
+//	  "default": {}
+//	}
+type CalibrationProfile struct {
+	// Name of the data frame property with the device id.
+	Property string `json:"property"`
+	// Calibrations by device id and variable name.
+	Devices map[string]map[string]Calibration `json:"devices"`
+	// Calibrations for devices not listed in Devices. Optional.
+	Default map[string]Calibration `json:"default"`
+}
+
+// Reads a calibration profile from a JSON file.
+func ReadCalibrationProfileFile(fn string) (p *CalibrationProfile, e error) {
+
+	f, e := os.Open(fn)
+	if e != nil {
+		return
+	}
+	defer f.Close()
+	return ReadCalibrationProfile(f)
+}
+
+// Reads a calibration profile from an io.Reader.
+func ReadCalibrationProfile(r io.Reader) (p *CalibrationProfile, e error) {
+
+	b, e := ioutil.ReadAll(r)
+	if e != nil {
+		return
+	}
+	p = &CalibrationProfile{}
+	if e = json.Unmarshal(b, p); e != nil {
+		return nil, e
+	}
+	if p.Property == "" {
+		return nil, fmt.Errorf("calibration profile must specify the device property.")
+	}
+	return
+}
+
+// Applies the calibration for the device of the data frame in place.
+// Returns an error if the frame has no device property or if the device has
+// no calibration and the profile has no default. Calibrations are applied
+// to a copy of the rows first, so the frame is not modified on error.
+func (p *CalibrationProfile) Apply(df *DataFrame) error {
+
+	device, ok := df.Properties[p.Property]
+	if !ok {
+		return fmt.Errorf("Data frame %s has no property [%s].", df.BatchID, p.Property)
+	}
+	cal, ok := p.Devices[device]
+	if !ok {
+		if p.Default == nil {
+			return fmt.Errorf("No calibration for device [%s].", device)
+		}
+		cal = p.Default
+	}
+	names := make([]string, 0, len(cal))
+	for name := range cal {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	c := &DataFrame{VarNames: df.VarNames, Data: make([][]interface{}, len(df.Data))}
+	for i, row := range df.Data {
+		c.Data[i] = append([]interface{}(nil), row...)
+	}
+	c.resetVarMap()
+	for _, name := range names {
+		g := cal[name]
+		e := c.Broadcast(name, func(x float64) float64 { return x*g.Gain + g.Offset })
+		if e != nil {
+			return fmt.Errorf("device [%s]: %s", device, e)
+		}
+	}
+	for i, row := range c.Data {
+		copy(df.Data[i], row)
+	}
+	df.Invalidate(names...)
+	return nil
+}
+
+// Sets a calibration profile that is applied to every data frame returned
+// by Next. Overrides the calibration file in the data set description.
+func (ds *DataSet) SetCalibration(p *CalibrationProfile) {
+
+	ds.calibration = p
+}
+
+// Applies the calibration profile, loading it on first use.
+func (ds *DataSet) calibrate(df *DataFrame) error {
+
+	if e := ds.loadCalibration(); e != nil {
+		return e
+	}
+	if ds.calibration == nil {
+		return nil
+	}
+	return ds.calibration.Apply(df)
+}
+
+// Reads the calibration profile file, if any, the first time it is needed.
+func (ds *DataSet) loadCalibration() error {
+
+	if ds.calibration != nil || ds.Calibration == "" {
+		return nil
+	}
+	p, e := ReadCalibrationProfileFile(ds.Calibration)
+	if e != nil {
+		return e
+	}
+	ds.calibration = p
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gonum/floats"
+)
+
+const profileData = `{
+"property": "device_id",
+"devices": {
+  "dev1": {"acceleration": {"gain": 10, "offset": 1}},
+  "dev2": {"wifi": {"offset": 100}}
+}
+}`
+
+func TestCalibrationProfile(t *testing.T) {
+
+	p, e := ReadCalibrationProfile(strings.NewReader(profileData))
+	CheckError(t, e)
+
+	if g := p.Devices["dev2"]["wifi"].Gain; g != 1 {
+		t.Fatalf("default gain must be 1, not %f.", g)
+	}
+
+	dir, e := ioutil.TempDir("", "dataframe-cal")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+
+	d1 := strings.Replace(file1, `"batchid"`, `"properties": {"device_id": "dev1"}, "batchid"`, 1)
+	d2 := strings.Replace(file2, `"batchid"`, `"properties": {"device_id": "dev2"}, "batchid"`, 1)
+	CheckError(t, ioutil.WriteFile(dir+"/f1.json", []byte(d1), 0644))
+	CheckError(t, ioutil.WriteFile(dir+"/f2.json", []byte(d2), 0644))
+	CheckError(t, ioutil.WriteFile(dir+"/profile.json", []byte(profileData), 0644))
+
+	ds := &DataSet{Path: dir, Files: []string{"f1.json", "f2.json"}, Calibration: dir + "/profile.json"}
+	var got [][]float64
+	for {
+		df, e := ds.Next()
+		if e == io.EOF {
+			break
+		}
+		CheckError(t, e)
+		sl, e := df.Float64Slice(0, "wifi", "acceleration")
+		CheckError(t, e)
+		got = append(got, sl)
+	}
+	if !floats.EqualApprox(got[0], []float64{-40.8, -41.2, 14}, 1e-9) {
+		t.Fatalf("dev1 calibration not applied: %v.", got[0])
+	}
+	if !floats.EqualApprox(got[1], []float64{79.9, 68.7, 1.3}, 1e-9) {
+		t.Fatalf("dev2 calibration not applied: %v.", got[1])
+	}
+
+	// Unknown device without default.
+	df, e := ReadDataFrame(strings.NewReader(strings.Replace(d1, "dev1", "dev3", 1)))
+	CheckError(t, e)
+	if e = p.Apply(df); e == nil {
+		t.Fatalf("expected error for unknown device.")
+	}
+	p.Default = map[string]Calibration{}
+	CheckError(t, p.Apply(df))
+
+	// A failed calibration leaves the frame unchanged.
+	p.Default = map[string]Calibration{"acceleration": {Gain: 2}, "room": {Gain: 2}}
+	if e = p.Apply(df); e == nil {
+		t.Fatalf("expected error for string variable.")
+	}
+	if df.Data[0][2] != 1.3 {
+		t.Fatalf("frame must not be modified, got %v.", df.Data[0][2])
+	}
+
+	// Relative paths are resolved against the data set file.
+	CheckError(t, ioutil.WriteFile(dir+"/ds.yaml", []byte("path: "+dir+"\nfiles: [f1.json]\ncalibration: profile.json\n"), 0644))
+	ds, e = ReadDataSetFile(dir + "/ds.yaml")
+	CheckError(t, e)
+	df, e = ds.Next()
+	CheckError(t, e)
+	if x := df.Data[0][2]; x != 14.0 {
+		t.Fatalf("dev1 calibration not applied: %v.", x)
+	}
+}
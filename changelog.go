@@ -0,0 +1,162 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+)
+
+// Name of the variable with the operation of each row in a changelog.
+const ChangelogOp = "op"
+
+// Changelog operations.
+const (
+	// Inserts a row or supersedes the current row with the same key.
+	OpUpsert = "upsert"
+	// Deletes the current row with the key.
+	OpDelete = "delete"
+)
+
+// A Changelog is an append-only log of row versions identified by the
+// values of one or more key variables. Rows are never modified; a new row
+// supersedes the previous row with the same key and a tombstone deletes it.
+// Use Compact to get the current state.
+//
+// The log is a data frame with an extra variable, see ChangelogOp, so it can
+// be written and read like any other data frame.
+type Changelog struct {
+	log  *DataFrame
+	keys []int
+}
+
+// Returns an empty changelog for rows with the given variables. Keys are
+// the names of the variables that identify a row.
+func NewChangelog(varNames []string, keys ...string) (*Changelog, error) {
+
+	log := Empty(append(append([]string(nil), varNames...), ChangelogOp)...)
+	if err := checkUnique(log.VarNames); err != nil {
+		return nil, err
+	}
+	return newChangelog(log, keys)
+}
+
+// Returns a changelog backed by a data frame previously returned by
+// Changelog.DataFrame. New rows are appended to the data frame.
+func ChangelogFromDataFrame(df *DataFrame, keys ...string) (*Changelog, error) {
+
+	indices, err := df.indices(ChangelogOp)
+	if err != nil {
+		return nil, err
+	}
+	if indices[0] != len(df.VarNames)-1 {
+		return nil, fmt.Errorf("Variable [%s] must be the last variable.", ChangelogOp)
+	}
+	for i, row := range df.Data {
+		if op := row[indices[0]]; op != OpUpsert && op != OpDelete {
+			return nil, fmt.Errorf("In frame %d, unknown changelog operation [%v].", i, op)
+		}
+	}
+	return newChangelog(df, keys)
+}
+
+func newChangelog(log *DataFrame, keys []string) (*Changelog, error) {
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("Changelog requires at least one key variable.")
+	}
+	indices, err := log.indices(keys...)
+	if err != nil {
+		return nil, err
+	}
+	for _, idx := range indices {
+		if idx == len(log.VarNames)-1 {
+			return nil, fmt.Errorf("Variable [%s] can't be a key.", ChangelogOp)
+		}
+	}
+	return &Changelog{log: log, keys: indices}, nil
+}
+
+// Appends a row version. Values are in variable order, without the
+// operation.
+func (c *Changelog) Upsert(values ...interface{}) error {
+
+	if len(values) != len(c.log.VarNames)-1 {
+		return fmt.Errorf("Expected %d values, got %d.", len(c.log.VarNames)-1, len(values))
+	}
+	row := append(append(make([]interface{}, 0, len(values)+1), values...), OpUpsert)
+	return c.append(row)
+}
+
+// Appends a tombstone for the row with the key values, in the order of the
+// key variables.
+func (c *Changelog) Delete(key ...interface{}) error {
+
+	if len(key) != len(c.keys) {
+		return fmt.Errorf("Expected %d key values, got %d.", len(c.keys), len(key))
+	}
+	row := make([]interface{}, len(c.log.VarNames))
+	for j, idx := range c.keys {
+		row[idx] = key[j]
+	}
+	row[len(row)-1] = OpDelete
+	return c.append(row)
+}
+
+func (c *Changelog) append(row []interface{}) error {
+
+	var buf bytes.Buffer
+	for _, idx := range c.keys {
+		if row[idx] == nil || !writeKey(&buf, row[idx]) {
+			return fmt.Errorf("Key variable [%s] must be a string, float64, or bool, got %v.",
+				c.log.VarNames[idx], row[idx])
+		}
+	}
+	c.log.Data = append(c.log.Data, row)
+	c.log.updateStats(row)
+	return nil
+}
+
+// Returns the number of entries in the log.
+func (c *Changelog) Len() int { return c.log.N() }
+
+// Returns the log as a data frame. The data frame is shared with the
+// changelog.
+func (c *Changelog) DataFrame() *DataFrame { return c.log }
+
+// Returns the current state: the latest version of each row that has not
+// been deleted, in log order, without the operation variable.
+func (c *Changelog) Compact() (*DataFrame, error) {
+
+	op := len(c.log.VarNames) - 1
+	latest := make(map[string]int)
+	var buf bytes.Buffer
+	for i, row := range c.log.Data {
+		buf.Reset()
+		for _, idx := range c.keys {
+			if !writeKey(&buf, row[idx]) {
+				return nil, fmt.Errorf("In frame %d, key variable [%s] is of type [%s]. Must be of type string, float64, or bool.",
+					i, c.log.VarNames[idx], reflect.TypeOf(row[idx]).String())
+			}
+		}
+		if row[op] == OpDelete {
+			delete(latest, buf.String())
+			continue
+		}
+		latest[buf.String()] = i
+	}
+	v := &View{parent: c.log, names: c.log.VarNames[:op]}
+	v = v.Filter(func(frame int) bool {
+		buf.Reset()
+		for _, idx := range c.keys {
+			writeKey(&buf, c.log.Data[frame][idx])
+		}
+		i, ok := latest[buf.String()]
+		return ok && i == frame
+	})
+	return v.Materialize(), nil
+}
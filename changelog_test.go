@@ -0,0 +1,63 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestChangelog(t *testing.T) {
+
+	c, e := NewChangelog([]string{"id", "room", "temp"}, "id")
+	CheckError(t, e)
+	CheckError(t, c.Upsert("a", "BED5", 20.0))
+	CheckError(t, c.Upsert("b", "DINING", 21.0))
+	CheckError(t, c.Upsert("c", "KITCHEN", 22.0))
+	// Correction and deletion.
+	CheckError(t, c.Upsert("a", "BED5", 19.5))
+	CheckError(t, c.Delete("b"))
+	if c.Len() != 5 {
+		t.Fatalf("expected 5 log entries, got %d.", c.Len())
+	}
+
+	df, e := c.Compact()
+	CheckError(t, e)
+	if len(df.VarNames) != 3 || df.N() != 2 {
+		t.Fatalf("unexpected state %v %v.", df.VarNames, df.Data)
+	}
+	if df.Data[0][0] != "c" || df.Data[1][0] != "a" || df.Data[1][2] != 19.5 {
+		t.Fatalf("unexpected state %v.", df.Data)
+	}
+
+	// Round trip through JSON and reinsert a deleted key.
+	var buf bytes.Buffer
+	CheckError(t, c.DataFrame().WriteDataFrame(&buf))
+	log, e := ReadDataFrame(&buf)
+	CheckError(t, e)
+	c, e = ChangelogFromDataFrame(log, "id")
+	CheckError(t, e)
+	CheckError(t, c.Upsert("b", "DINING", 23.0))
+	df, e = c.Compact()
+	CheckError(t, e)
+	if df.N() != 3 || df.Data[2][0] != "b" || df.Data[2][2] != 23.0 {
+		t.Fatalf("unexpected state %v.", df.Data)
+	}
+
+	if e = c.Upsert("d", "BED5"); e == nil {
+		t.Fatalf("expected error for missing value.")
+	}
+	if e = c.Delete([]float64{1}); e == nil {
+		t.Fatalf("expected error for vector key.")
+	}
+	if _, e = NewChangelog([]string{"id", ChangelogOp}, "id"); e == nil {
+		t.Fatalf("expected error for reserved variable name.")
+	}
+	log.Data[0][3] = "update"
+	if _, e = ChangelogFromDataFrame(log, "id"); e == nil {
+		t.Fatalf("expected error for unknown operation.")
+	}
+}
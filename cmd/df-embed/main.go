@@ -0,0 +1,63 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command df-embed converts a data frame file into a Go source file that
+// declares the frame as a ready-made *dataframe.DataFrame variable. It is
+// meant to be used with go:generate, for example:
+//
+//	//go:generate df-embed -var Rooms -o rooms_frame.go data/rooms.json
+//
+// Files with extension .csv are read as CSV, other files as JSON.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/akualab/dataframe"
+)
+
+func main() {
+
+	varName := flag.String("var", "Frame", "name of the generated variable")
+	pkg := flag.String("pkg", os.Getenv("GOPACKAGE"), "package name, defaults to $GOPACKAGE")
+	out := flag.String("o", "", "output file, defaults to stdout")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: df-embed [flags] file\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *pkg == "" {
+		*pkg = "main"
+	}
+	if err := run(flag.Arg(0), *out, *pkg, *varName); err != nil {
+		fmt.Fprintf(os.Stderr, "df-embed: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(in, out, pkg, varName string) error {
+
+	var df *dataframe.DataFrame
+	var err error
+	if strings.HasSuffix(in, ".csv") {
+		df, err = dataframe.ReadCSVFile(in, dataframe.CSVOptions{})
+	} else {
+		df, err = dataframe.ReadDataFrameFile(in)
+	}
+	if err != nil {
+		return err
+	}
+	if out != "" {
+		return df.WriteGoSourceFile(out, pkg, varName)
+	}
+	return df.WriteGoSource(os.Stdout, pkg, varName)
+}
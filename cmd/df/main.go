@@ -0,0 +1,192 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command df is a tool for managing data frame files.
+//
+// Usage:
+//
+//	df meta set [-description text] [-batchid id] [-property key=value]... file...
+//	df pipeline run spec.yaml...
+//	df pipeline explain spec.yaml...
+//
+// The meta set command edits the metadata of JSON data frame files in place.
+// Repeat -property to set several properties; an empty value deletes the
+// property. The data is not modified.
+//
+// The pipeline run command runs pipeline spec files, see
+// dataframe.PipelineSpec. The pipeline explain command prints the plan of
+// each spec without writing any output.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/akualab/dataframe"
+)
+
+func usage() {
+
+	fmt.Fprintf(os.Stderr, "usage: df meta set [-description text] [-batchid id] [-property key=value]... file...\n")
+	fmt.Fprintf(os.Stderr, "       df pipeline run spec.yaml...\n")
+	fmt.Fprintf(os.Stderr, "       df pipeline explain spec.yaml...\n")
+	fmt.Fprintf(os.Stderr, "       df schema avro [-name Record] [-namespace ns] file\n")
+	fmt.Fprintf(os.Stderr, "       df schema proto [-package pkg] [-message Record] file\n")
+	os.Exit(2)
+}
+
+func main() {
+
+	if len(os.Args) < 3 {
+		usage()
+	}
+	var err error
+	switch os.Args[1] + " " + os.Args[2] {
+	case "meta set":
+		err = metaSet(os.Args[3:])
+	case "pipeline run":
+		err = pipelineRun(os.Args[3:])
+	case "pipeline explain":
+		err = pipelineExplain(os.Args[3:])
+	case "schema avro", "schema proto":
+		err = schemaExport(os.Args[2], os.Args[3:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "df: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// Repeatable key=value flag.
+type properties map[string]string
+
+func (p properties) String() string {
+
+	return fmt.Sprint(map[string]string(p))
+}
+
+func (p properties) Set(s string) error {
+
+	kv := strings.SplitN(s, "=", 2)
+	if len(kv) != 2 || kv[0] == "" {
+		return fmt.Errorf("property must be key=value, got %q", s)
+	}
+	p[kv[0]] = kv[1]
+	return nil
+}
+
+func metaSet(args []string) error {
+
+	fs := flag.NewFlagSet("meta set", flag.ExitOnError)
+	description := fs.String("description", "", "new description")
+	batchID := fs.String("batchid", "", "new batch id")
+	props := properties{}
+	fs.Var(props, "property", "property as key=value, can be repeated")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		usage()
+	}
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if len(set) == 0 {
+		return fmt.Errorf("nothing to set")
+	}
+	for _, fn := range fs.Args() {
+		err := dataframe.EditDataFrameFile(fn, func(df *dataframe.DataFrame) error {
+			if set["description"] {
+				df.SetDescription(*description)
+			}
+			if set["batchid"] {
+				df.SetBatchID(*batchID)
+			}
+			for k, v := range props {
+				df.SetProperty(k, v)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func pipelineRun(args []string) error {
+
+	if len(args) == 0 {
+		usage()
+	}
+	// Stop on interrupt and remove the partial output.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	defer signal.Stop(sig)
+	go func() {
+		select {
+		case <-sig:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	for _, fn := range args {
+		if err := dataframe.RunPipelineContext(ctx, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func pipelineExplain(args []string) error {
+
+	if len(args) == 0 {
+		usage()
+	}
+	for _, fn := range args {
+		plan, err := dataframe.ExplainPipeline(fn)
+		if err != nil {
+			return err
+		}
+		fmt.Print(plan)
+	}
+	return nil
+}
+
+// Prints the Avro schema or proto definition of the schema inferred from a
+// data frame file.
+func schemaExport(format string, args []string) error {
+
+	fs := flag.NewFlagSet("schema "+format, flag.ExitOnError)
+	name := fs.String("name", "Record", "Avro record name")
+	namespace := fs.String("namespace", "", "Avro namespace")
+	pkg := fs.String("package", "", "proto package")
+	message := fs.String("message", "Record", "proto message name")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		usage()
+	}
+	df, err := dataframe.ReadDataFrameFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	schema := dataframe.InferSchema(df)
+	var b []byte
+	if format == "avro" {
+		b, err = schema.Avro(*name, *namespace)
+	} else {
+		b, err = schema.Proto(*pkg, *message)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n", strings.TrimSpace(string(b)))
+	return nil
+}
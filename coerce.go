@@ -0,0 +1,120 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// A cell that could not be converted to the type inferred for its variable.
+type CoercionError struct {
+	// File name, if the frame was read from a file.
+	File string
+	// Row number.
+	Row int
+	// Variable name.
+	Var string
+	// Raw value.
+	Raw string
+	// Type inferred for the variable.
+	Type string
+}
+
+func (c CoercionError) String() string {
+
+	return fmt.Sprintf("%s row %d, variable [%s]: cannot convert %q to %s", c.File, c.Row, c.Var, c.Raw, c.Type)
+}
+
+// Returns the cells that failed type coercion when the frame was read.
+func (df *DataFrame) CoercionReport() []CoercionError {
+
+	return df.coercions
+}
+
+func (df *DataFrame) setReportFile(fn string) {
+
+	for i := range df.coercions {
+		df.coercions[i].File = fn
+	}
+}
+
+// Infers the type of each variable in a decoded JSON frame and adds the
+// string values of float64 variables that are not numbers to the coercion
+// report. The data is not modified, see CoerceNumbers.
+func (df *DataFrame) coerceJSON() {
+
+	for j, name := range df.VarNames {
+		if !df.isNumberColumn(j) {
+			continue
+		}
+		for i, row := range df.Data {
+			if j >= len(row) {
+				continue
+			}
+			s, ok := row[j].(string)
+			if !ok {
+				continue
+			}
+			if _, err := strconv.ParseFloat(s, 64); err != nil {
+				df.coercions = append(df.coercions, CoercionError{Row: i, Var: name, Raw: s, Type: "float64"})
+			}
+		}
+	}
+}
+
+// Converts string values that parse as numbers to float64 in the variables
+// inferred as float64 when the frame was read: variables that contain
+// numbers and where most values are numbers or numeric strings. Other
+// string values are left unchanged; they are in the coercion report. Files
+// often mix numbers and numeric strings, but strings such as the id "007"
+// are also numeric, so the conversion is not done unless requested, see
+// DataSet.CoerceNumbers.
+func (df *DataFrame) CoerceNumbers() {
+
+	for j := range df.VarNames {
+		if !df.isNumberColumn(j) {
+			continue
+		}
+		for _, row := range df.Data {
+			if j >= len(row) {
+				continue
+			}
+			s, ok := row[j].(string)
+			if !ok {
+				continue
+			}
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				row[j] = f
+			}
+		}
+		df.Invalidate(df.VarNames[j])
+	}
+}
+
+// Returns true if variable j contains numbers and most of its values are
+// numbers or numeric strings.
+func (df *DataFrame) isNumberColumn(j int) bool {
+
+	var nnumber, nfloat, nstring int
+	for _, row := range df.Data {
+		if j >= len(row) {
+			continue
+		}
+		switch v := row[j].(type) {
+		case float64:
+			nnumber++
+			nfloat++
+		case string:
+			if _, err := strconv.ParseFloat(v, 64); err == nil {
+				nfloat++
+			} else {
+				nstring++
+			}
+		}
+	}
+	return nnumber > 0 && nfloat > nstring
+}
@@ -0,0 +1,85 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCoercionReportCSV(t *testing.T) {
+
+	df, e := ReadCSV(strings.NewReader("room,acceleration\nBED5,1.3\nBED5,1.4\nBED5,oops\nDINING,1.6\n"), CSVOptions{})
+	CheckError(t, e)
+
+	if df.Data[2][1] != nil || df.Data[3][1] != 1.6 {
+		t.Fatalf("unexpected data %v.", df.Data)
+	}
+	report := df.CoercionReport()
+	expected := []CoercionError{{Row: 2, Var: "acceleration", Raw: "oops", Type: "float64"}}
+	if !reflect.DeepEqual(report, expected) {
+		t.Fatalf("got report %v, expected %v.", report, expected)
+	}
+	t.Log(report[0])
+}
+
+func TestCoercionReportJSON(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(`{
+"var_names": ["room", "acceleration"],
+"data": [["BED5", 1.3], ["BED5", "1.4"], ["BED5", "n/a"], ["DINING", 1.6]]
+}`))
+	CheckError(t, e)
+
+	// The data is not modified unless requested.
+	if df.Data[1][1] != "1.4" {
+		t.Fatalf("numeric string must be kept: %v.", df.Data[1])
+	}
+	report := df.CoercionReport()
+	if len(report) != 1 || report[0].Row != 2 || report[0].Raw != "n/a" {
+		t.Fatalf("unexpected report %v.", report)
+	}
+	df.CoerceNumbers()
+	if df.Data[1][1] != 1.4 {
+		t.Fatalf("numeric string not converted: %v.", df.Data[1])
+	}
+	if df.Data[2][1] != "n/a" {
+		t.Fatalf("raw value must be kept: %v.", df.Data[2])
+	}
+
+	// Ids that look like numbers are kept.
+	df, e = ReadDataFrame(strings.NewReader(`{"var_names": ["id", "x"], "data": [["007", 1], [8, 2], ["009", 3]]}`))
+	CheckError(t, e)
+	if df.Data[0][0] != "007" || df.Data[2][0] != "009" || len(df.CoercionReport()) != 0 {
+		t.Fatalf("ids must not be converted: %v %v.", df.Data, df.CoercionReport())
+	}
+
+	// Data sets convert on request.
+	dir, e := ioutil.TempDir("", "dataframe-coerce")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	CheckError(t, ioutil.WriteFile(filepath.Join(dir, "a.json"),
+		[]byte(`{"var_names": ["x"], "data": [[1], ["2"], [3]]}`), 0644))
+	ds := &DataSet{Path: dir, Files: []string{"a.json"}, CoerceNumbers: true}
+	df, e = ds.Next()
+	CheckError(t, e)
+	if df.Data[1][0] != 2.0 {
+		t.Fatalf("numeric string not converted: %v.", df.Data)
+	}
+
+	// Clean files have an empty report.
+	tmpDir := getTempDir()
+	f1, _ := createDataFiles(t, tmpDir)
+	df, e = ReadDataFrameFile(f1)
+	CheckError(t, e)
+	if len(df.CoercionReport()) != 0 {
+		t.Fatalf("unexpected report %v.", df.CoercionReport())
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+)
+
+// A Column holds the values of a variable in a typed slice. Only the slice
+// that matches Type is set.
+type Column struct {
+	Name string
+	// TypeFloat64, TypeString, TypeBool, or TypeVector.
+	Type    string
+	Float64 []float64
+	String  []string
+	Bool    []bool
+	Vector  [][]float64
+	// False for nil values; nil if no value is nil.
+	Valid []bool
+}
+
+// Returns true if the value of row i is not nil.
+func (c *Column) IsValid(i int) bool {
+
+	return c.Valid == nil || c.Valid[i]
+}
+
+// A Columnar data frame stores each variable in a typed slice instead of
+// a row of interface values, which avoids boxing every cell and makes
+// column access much faster. It is a read-only copy of a DataFrame, see
+// DataFrame.Columnar; the row storage in DataFrame.Data stays the primary
+// format, since it is part of the API.
+type Columnar struct {
+	Description string
+	BatchID     string
+	VarUnits    map[string]string
+	Properties  map[string]string
+	Columns     []Column
+	n           int
+	index       map[string]int
+}
+
+// Returns a columnar copy of the data frame. The type of each variable is
+// inferred, see InferSchema; variables whose values are all nil are float64.
+// Every value of a variable must have the same type or be nil. Vectors are
+// copied to []float64.
+func (df *DataFrame) Columnar() (*Columnar, error) {
+
+	schema := InferSchema(df)
+	c := &Columnar{
+		Description: df.Description,
+		BatchID:     df.BatchID,
+		VarUnits:    copyProperties(df.VarUnits),
+		Properties:  copyProperties(df.Properties),
+		Columns:     make([]Column, len(schema)),
+		n:           df.N(),
+		index:       make(map[string]int, len(schema)),
+	}
+	n := df.N()
+	for j, v := range schema {
+		col := Column{Name: v.Name, Type: v.Type}
+		switch v.Type {
+		case TypeFloat64, TypeAny:
+			col.Type = TypeFloat64
+			col.Float64 = make([]float64, n)
+		case TypeString:
+			col.String = make([]string, n)
+		case TypeBool:
+			col.Bool = make([]bool, n)
+		case TypeVector:
+			col.Vector = make([][]float64, n)
+		default:
+			return nil, fmt.Errorf("Variable [%s] of type [%s] can't be stored in a column.", v.Name, v.Type)
+		}
+		for i, row := range df.Data {
+			x := row[j]
+			if x == nil {
+				if col.Valid == nil {
+					col.Valid = make([]bool, n)
+					for k := range col.Valid {
+						col.Valid[k] = true
+					}
+				}
+				col.Valid[i] = false
+				continue
+			}
+			if valueType(x) != col.Type {
+				return nil, colTypeError(i, v.Name, x, col.Type)
+			}
+			switch col.Type {
+			case TypeFloat64:
+				col.Float64[i] = x.(float64)
+			case TypeString:
+				col.String[i] = x.(string)
+			case TypeBool:
+				col.Bool[i] = x.(bool)
+			case TypeVector:
+				vec, err := toFloat64Slice(x)
+				if err != nil {
+					return nil, fmt.Errorf("In frame %d, variable [%s]: %s", i, v.Name, err)
+				}
+				if _, ok := x.([]float64); ok {
+					vec = append([]float64(nil), vec...)
+				}
+				col.Vector[i] = vec
+			}
+		}
+		c.Columns[j] = col
+		c.index[v.Name] = j
+	}
+	return c, nil
+}
+
+// Returns the number of rows.
+func (c *Columnar) N() int { return c.n }
+
+// Returns a column by name.
+func (c *Columnar) Column(name string) (*Column, error) {
+
+	j, ok := c.index[name]
+	if !ok {
+		return nil, fmt.Errorf("Failed to find a variable with name: [%s]", name)
+	}
+	return &c.Columns[j], nil
+}
+
+// Returns the values of a float64 variable without copying. Returns an
+// error if a value is nil, as DataFrame.Float64Col.
+func (c *Columnar) Float64Col(name string) ([]float64, error) {
+
+	col, err := c.typedColumn(name, TypeFloat64)
+	if err != nil {
+		return nil, err
+	}
+	return col.Float64, nil
+}
+
+// Returns the values of a string variable without copying.
+func (c *Columnar) StringCol(name string) ([]string, error) {
+
+	col, err := c.typedColumn(name, TypeString)
+	if err != nil {
+		return nil, err
+	}
+	return col.String, nil
+}
+
+// Returns the values of a vector variable without copying.
+func (c *Columnar) Float64SliceCol(name string) ([][]float64, error) {
+
+	col, err := c.typedColumn(name, TypeVector)
+	if err != nil {
+		return nil, err
+	}
+	return col.Vector, nil
+}
+
+// Returns a column of the given type without nil values.
+func (c *Columnar) typedColumn(name, typ string) (*Column, error) {
+
+	col, err := c.Column(name)
+	if err != nil {
+		return nil, err
+	}
+	if col.Type != typ {
+		return nil, fmt.Errorf("Variable [%s] is of type [%s]. Must be of type %s.", name, col.Type, typ)
+	}
+	for i := range col.Valid {
+		if !col.Valid[i] {
+			return nil, fmt.Errorf("In frame %d, variable [%s] is nil.", i, name)
+		}
+	}
+	return col, nil
+}
+
+// Returns the data frame with the rows of the columnar copy.
+func (c *Columnar) DataFrame() *DataFrame {
+
+	df := &DataFrame{
+		Description: c.Description,
+		BatchID:     c.BatchID,
+		VarNames:    make([]string, len(c.Columns)),
+		Data:        make([][]interface{}, c.n),
+		VarUnits:    copyProperties(c.VarUnits),
+		Properties:  copyProperties(c.Properties),
+	}
+	for j, col := range c.Columns {
+		df.VarNames[j] = col.Name
+	}
+	// Cells of all rows share one backing array.
+	cells := make([]interface{}, c.n*len(c.Columns))
+	for i := range df.Data {
+		row := cells[i*len(c.Columns) : (i+1)*len(c.Columns) : (i+1)*len(c.Columns)]
+		for j := range c.Columns {
+			col := &c.Columns[j]
+			if !col.IsValid(i) {
+				continue
+			}
+			switch col.Type {
+			case TypeFloat64:
+				row[j] = col.Float64[i]
+			case TypeString:
+				row[j] = col.String[i]
+			case TypeBool:
+				row[j] = col.Bool[i]
+			case TypeVector:
+				row[j] = append([]float64(nil), col.Vector[i]...)
+			}
+		}
+		df.Data[i] = row
+	}
+	df.resetVarMap()
+	return df
+}
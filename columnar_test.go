@@ -0,0 +1,59 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestColumnar(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	df.VarUnits = map[string]string{"acceleration": "m/s2"}
+	CheckError(t, df.AddVar("moving", []interface{}{true, false, nil, true, true, false}))
+
+	c, e := df.Columnar()
+	CheckError(t, e)
+	if c.N() != 6 || len(c.Columns) != 4 {
+		t.Fatalf("unexpected columns %+v.", c.Columns)
+	}
+	acc, e := c.Float64Col("acceleration")
+	CheckError(t, e)
+	want, e := df.Float64Col("acceleration")
+	CheckError(t, e)
+	for i := range want {
+		if acc[i] != want[i] {
+			t.Fatalf("row %d: expected %v, got %v.", i, want[i], acc[i])
+		}
+	}
+	rooms, e := c.StringCol("room")
+	CheckError(t, e)
+	wifi, e := c.Float64SliceCol("wifi")
+	CheckError(t, e)
+	if rooms[3] != "DINING" || len(wifi[0]) != 2 {
+		t.Fatalf("unexpected columns %v %v.", rooms, wifi)
+	}
+
+	moving, e := c.Column("moving")
+	CheckError(t, e)
+	if moving.Type != TypeBool || moving.IsValid(2) || !moving.Bool[0] {
+		t.Fatalf("unexpected column %+v.", moving)
+	}
+	if _, e = c.StringCol("acceleration"); e == nil {
+		t.Fatalf("expected type error.")
+	}
+	if _, e = c.Column("nope"); e == nil {
+		t.Fatalf("expected error for unknown variable.")
+	}
+	assertSameJSON(t, df, c.DataFrame())
+
+	df.Data[0][2] = "fast"
+	if _, e = df.Columnar(); e == nil {
+		t.Fatalf("expected error for mixed types.")
+	}
+}
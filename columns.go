@@ -0,0 +1,81 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Returns the values of a float64 variable for all the rows.
+func (df *DataFrame) Float64Col(name string) ([]float64, error) {
+
+	indices, err := df.indices(name)
+	if err != nil {
+		return nil, err
+	}
+	idx := indices[0]
+	col := make([]float64, len(df.Data))
+	for i, row := range df.Data {
+		v, ok := row[idx].(float64)
+		if !ok {
+			return nil, colTypeError(i, name, row[idx], "float64")
+		}
+		col[i] = v
+	}
+	return col, nil
+}
+
+// Returns the values of a string variable for all the rows.
+func (df *DataFrame) StringCol(name string) ([]string, error) {
+
+	indices, err := df.indices(name)
+	if err != nil {
+		return nil, err
+	}
+	idx := indices[0]
+	col := make([]string, len(df.Data))
+	for i, row := range df.Data {
+		v, ok := row[idx].(string)
+		if !ok {
+			return nil, colTypeError(i, name, row[idx], "string")
+		}
+		col[i] = v
+	}
+	return col, nil
+}
+
+// Returns the values of a vector variable for all the rows. Values that
+// are []float64 are not copied.
+func (df *DataFrame) Float64SliceCol(name string) ([][]float64, error) {
+
+	indices, err := df.indices(name)
+	if err != nil {
+		return nil, err
+	}
+	idx := indices[0]
+	col := make([][]float64, len(df.Data))
+	for i, row := range df.Data {
+		switch v := row[idx].(type) {
+		case []float64, []interface{}:
+			if col[i], err = toFloat64Slice(v); err != nil {
+				return nil, fmt.Errorf("In frame %d, variable [%s]: %s", i, name, err)
+			}
+		default:
+			return nil, colTypeError(i, name, v, "[]float64")
+		}
+	}
+	return col, nil
+}
+
+func colTypeError(frame int, name string, v interface{}, typ string) error {
+
+	if v == nil {
+		return fmt.Errorf("In frame %d, variable [%s] is nil.", frame, name)
+	}
+	return fmt.Errorf("In frame %d, variable [%s] is of type [%s]. Must be of type %s.",
+		frame, name, reflect.TypeOf(v).String(), typ)
+}
@@ -0,0 +1,49 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gonum/floats"
+)
+
+func TestColumns(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+
+	acc, e := df.Float64Col("acceleration")
+	CheckError(t, e)
+	if !floats.Equal(acc, []float64{1.3, 1.4, 1.5, 1.6, 1.7, 1.8}) {
+		t.Fatalf("unexpected column %v.", acc)
+	}
+	rooms, e := df.StringCol("room")
+	CheckError(t, e)
+	if len(rooms) != 6 || rooms[0] != "BED5" || rooms[5] != "DINING" {
+		t.Fatalf("unexpected column %v.", rooms)
+	}
+	wifi, e := df.Float64SliceCol("wifi")
+	CheckError(t, e)
+	if len(wifi) != 6 || !floats.Equal(wifi[2], []float64{-42.8, -40.34}) {
+		t.Fatalf("unexpected column %v.", wifi)
+	}
+
+	if _, e = df.Float64Col("room"); e == nil {
+		t.Fatalf("expected type error.")
+	}
+	if _, e = df.StringCol("wifi"); e == nil {
+		t.Fatalf("expected type error.")
+	}
+	if _, e = df.Float64SliceCol("acceleration"); e == nil {
+		t.Fatalf("expected type error.")
+	}
+	df.Data[3][2] = nil
+	if _, e = df.Float64Col("acceleration"); e == nil {
+		t.Fatalf("expected error for nil value.")
+	}
+}
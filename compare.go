@@ -0,0 +1,113 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"math"
+	"reflect"
+)
+
+// Returns true if a and b differ by at most tol. NaN values are equal to
+// each other and infinite values are equal if they have the same sign.
+func FloatAlmostEqual(a, b, tol float64) bool {
+
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.IsNaN(a) && math.IsNaN(b)
+	}
+	return a == b || math.Abs(a-b) <= tol
+}
+
+// Returns true if the slices have the same length and the elements are
+// equal within tol, see FloatAlmostEqual.
+func FloatsAlmostEqual(a, b []float64, tol float64) bool {
+
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !FloatAlmostEqual(a[i], b[i], tol) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns true if two cell values are equal. Float64 values and vector
+// elements are compared within tol, see FloatAlmostEqual; []float64 and
+// []interface{} vectors with the same elements are equal. Other values are
+// compared with reflect.DeepEqual.
+func ValuesAlmostEqual(a, b interface{}, tol float64) bool {
+
+	if fa, ok := a.(float64); ok {
+		fb, ok := b.(float64)
+		return ok && FloatAlmostEqual(fa, fb, tol)
+	}
+	va, okA := cellVector(a)
+	vb, okB := cellVector(b)
+	if okA || okB {
+		if !okA || !okB || len(va) != len(vb) {
+			return false
+		}
+		for i := range va {
+			if !ValuesAlmostEqual(va[i], vb[i], tol) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// Returns true if the frames have the same variables and units, matched by
+// name so the order doesn't matter, the same number of rows, and equal cell
+// values within tol, see ValuesAlmostEqual. Batch id, description, and
+// properties are not compared.
+func (df *DataFrame) AlmostEqual(other *DataFrame, tol float64) bool {
+
+	if df == nil || other == nil {
+		return df == other
+	}
+	if len(df.VarNames) != len(other.VarNames) || df.N() != other.N() || len(df.VarUnits) != len(other.VarUnits) {
+		return false
+	}
+	for name, u := range df.VarUnits {
+		if other.VarUnits[name] != u {
+			return false
+		}
+	}
+	cols := make([]int, len(df.VarNames))
+	for j, name := range df.VarNames {
+		indices, err := other.indices(name)
+		if err != nil {
+			return false
+		}
+		cols[j] = indices[0]
+	}
+	for i, row := range df.Data {
+		for j, k := range cols {
+			if !ValuesAlmostEqual(row[j], other.Data[i][k], tol) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Returns the elements of []float64 and []interface{} values.
+func cellVector(v interface{}) ([]interface{}, bool) {
+
+	switch x := v.(type) {
+	case []interface{}:
+		return x, true
+	case []float64:
+		out := make([]interface{}, len(x))
+		for i, f := range x {
+			out[i] = f
+		}
+		return out, true
+	}
+	return nil, false
+}
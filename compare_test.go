@@ -0,0 +1,48 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestAlmostEqual(t *testing.T) {
+
+	nan, inf := math.NaN(), math.Inf(1)
+	if !FloatAlmostEqual(nan, nan, 0) || FloatAlmostEqual(nan, 1, 1) || !FloatAlmostEqual(inf, inf, 0) ||
+		FloatAlmostEqual(inf, -inf, 1) || !FloatAlmostEqual(1, 1.05, 0.1) || FloatAlmostEqual(1, 1.2, 0.1) {
+		t.Fatalf("unexpected float comparison.")
+	}
+	if !FloatsAlmostEqual([]float64{1, nan}, []float64{1.01, nan}, 0.1) || FloatsAlmostEqual([]float64{1}, []float64{1, 2}, 0.1) {
+		t.Fatalf("unexpected slice comparison.")
+	}
+	if !ValuesAlmostEqual([]float64{1, 2}, []interface{}{1.0, 2.001}, 0.01) || ValuesAlmostEqual("1", 1.0, 1) ||
+		!ValuesAlmostEqual(nil, nil, 0) || ValuesAlmostEqual([]float64{1}, 1.0, 0) {
+		t.Fatalf("unexpected value comparison.")
+	}
+
+	a, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	b, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	if !a.AlmostEqual(b, 0) {
+		t.Fatalf("frames must be equal.")
+	}
+	b.Data[2][2] = 1.5001
+	if a.AlmostEqual(b, 1e-6) || !a.AlmostEqual(b, 1e-3) {
+		t.Fatalf("unexpected frame comparison.")
+	}
+	b = a.View().Filter(func(int) bool { return true }).Materialize()
+	b.VarUnits = map[string]string{"acceleration": "m/s2"}
+	if a.AlmostEqual(b, 0) {
+		t.Fatalf("frames with different units must not be equal.")
+	}
+	if a.AlmostEqual(a.Filter(func(Row) bool { return false }), 0) {
+		t.Fatalf("frames with different rows must not be equal.")
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Returns a new data frame with the rows of the data frames in order, as
+// R's rbind. The data frames must have the same variables in the same order
+// and the same units. Nil data frames are skipped. Description, BatchID,
+// properties, and the index, see SetIndex, are those of the first data
+// frame; an error is returned if a key is repeated. Cell values are not deep
+// copied.
+func Concat(dfs ...*DataFrame) (*DataFrame, error) {
+
+	var out *DataFrame
+	for k, df := range dfs {
+		if df == nil {
+			continue
+		}
+		if out == nil {
+			out = &DataFrame{
+				Description: df.Description,
+				BatchID:     df.BatchID,
+				VarNames:    append([]string(nil), df.VarNames...),
+				Properties:  copyProperties(df.Properties),
+			}
+		} else if strings.Join(df.VarNames, "\x00") != strings.Join(out.VarNames, "\x00") {
+			return nil, fmt.Errorf("Data frame %d has variables %v, expected %v.", k, df.VarNames, out.VarNames)
+		}
+		if err := mergeUnits(out, df, df.VarNames, out.VarNames); err != nil {
+			return nil, fmt.Errorf("Data frame %d: %s", k, err)
+		}
+	}
+	if out == nil {
+		return Empty(), nil
+	}
+	var n int
+	for _, df := range dfs {
+		if df != nil {
+			n += df.N()
+		}
+	}
+	out.Data = make([][]interface{}, 0, n)
+	for _, df := range dfs {
+		if df == nil {
+			continue
+		}
+		for _, row := range df.Data {
+			out.Data = append(out.Data, append([]interface{}(nil), row...))
+		}
+	}
+	out.resetVarMap()
+	for _, df := range dfs {
+		if df == nil {
+			continue
+		}
+		if df.index != nil {
+			if err := out.SetIndex(df.index.name); err != nil {
+				return nil, err
+			}
+		}
+		break
+	}
+	return out, nil
+}
+
+// Reads every file in the data set and returns a data frame with all the
+// rows in data set order, see Concat. Files are checked against the data set
+// schema as they are read, see Validate; if the data set has no schema, all
+// files must match the schema inferred from the files read before them.
+// Data set options such as provenance are applied. The position of Next is
+// not changed.
+func (ds *DataSet) Collect() (*DataFrame, error) {
+
+	dfs := make([]*DataFrame, len(ds.Files))
+	schema := ds.Schema
+	err := ds.readParallel(context.Background(), ExecOptions{}, true, func(i int, df *DataFrame) error {
+		var err error
+		if schema, err = ds.checkSchema(schema, df); err != nil {
+			return fmt.Errorf("file %s: %s", ds.Files[i], err)
+		}
+		dfs[i] = df
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return Concat(dfs...)
+}
+
+// Returns a new data frame with the variables of the data frames in order,
+// as R's cbind. The data frames must have the same number of rows and
+// distinct variable names, see Prefix and BindWith. Description, BatchID,
+// and properties are those of the first data frame. Cell values are not
+// deep copied.
+func Bind(dfs ...*DataFrame) (*DataFrame, error) {
+
+	names := make([][]string, len(dfs))
+	for k, df := range dfs {
+		names[k] = df.VarNames
+	}
+	return bind(dfs, names)
+}
+
+// Binds the variables of two data frames, see Bind. Duplicate names are
+// resolved by nr.
+func BindWith(a, b *DataFrame, nr *NameResolver) (*DataFrame, error) {
+
+	l, r, err := nr.resolve(a.VarNames, b.VarNames, nil)
+	if err != nil {
+		return nil, err
+	}
+	return bind([]*DataFrame{a, b}, [][]string{l, r})
+}
+
+// Binds the data frames with the given output names.
+func bind(dfs []*DataFrame, names [][]string) (*DataFrame, error) {
+
+	if len(dfs) == 0 {
+		return Empty(), nil
+	}
+	first := dfs[0]
+	out := &DataFrame{
+		Description: first.Description,
+		BatchID:     first.BatchID,
+		Data:        make([][]interface{}, first.N()),
+		Properties:  copyProperties(first.Properties),
+	}
+	for k, df := range dfs {
+		if df.N() != first.N() {
+			return nil, fmt.Errorf("Data frame %d has %d rows, expected %d.", k, df.N(), first.N())
+		}
+		out.VarNames = append(out.VarNames, names[k]...)
+		if err := mergeUnits(out, df, df.VarNames, names[k]); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkUnique(out.VarNames); err != nil {
+		return nil, err
+	}
+	for i := range out.Data {
+		row := make([]interface{}, 0, len(out.VarNames))
+		for _, df := range dfs {
+			row = append(row, df.Data[i]...)
+		}
+		out.Data[i] = row
+	}
+	out.resetVarMap()
+	return out, nil
+}
+
+// Copies the units of the variables of df to out, where names are the
+// names of the variables in out. Returns an error if a unit conflicts.
+func mergeUnits(out, df *DataFrame, vars, names []string) error {
+
+	for j, name := range vars {
+		u, ok := df.VarUnits[name]
+		if !ok {
+			continue
+		}
+		if out.VarUnits == nil {
+			out.VarUnits = make(map[string]string)
+		}
+		if prev, ok := out.VarUnits[names[j]]; ok && prev != u {
+			return fmt.Errorf("Variable [%s] has units [%s] and [%s].", names[j], prev, u)
+		}
+		out.VarUnits[names[j]] = u
+	}
+	return nil
+}
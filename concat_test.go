@@ -0,0 +1,111 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConcat(t *testing.T) {
+
+	df1, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	df2, e := ReadDataFrame(strings.NewReader(file2))
+	CheckError(t, e)
+
+	out, e := Concat(df1, nil, df2)
+	CheckError(t, e)
+	if out.N() != 12 || out.BatchID != df1.BatchID || out.Data[6][0] != "KITCHEN" {
+		t.Fatalf("unexpected frame %+v.", out)
+	}
+	out.Data[0][0] = "X"
+	if df1.Data[0][0] != "BED5" {
+		t.Fatalf("rows must be copied.")
+	}
+
+	if out, e = Concat(); e != nil || out.N() != 0 {
+		t.Fatalf("expected empty frame, got %v %v.", out, e)
+	}
+	df2.Rename(map[string]string{"acceleration": "acc"})
+	if _, e = Concat(df1, df2); e == nil {
+		t.Fatalf("expected error for different variables.")
+	}
+	df2.Rename(map[string]string{"acc": "acceleration"})
+	df1.VarUnits = map[string]string{"acceleration": "g"}
+	df2.VarUnits = map[string]string{"acceleration": "m/s^2"}
+	if _, e = Concat(df1, df2); e == nil {
+		t.Fatalf("expected error for different units.")
+	}
+
+	// The index of the first frame is kept.
+	a := Empty("id", "x")
+	CheckError(t, a.AppendRow("a", 1.0))
+	CheckError(t, a.SetIndex("id"))
+	b := Empty("id", "x")
+	CheckError(t, b.AppendRow("b", 2.0))
+	out, e = Concat(a, b)
+	CheckError(t, e)
+	if r, e := out.RowByKey("b"); e != nil || r != 1 {
+		t.Fatalf("expected row 1 for key b, got %d %v.", r, e)
+	}
+	if _, e = Concat(a, a); e == nil {
+		t.Fatalf("expected error for duplicate key.")
+	}
+}
+
+func TestBind(t *testing.T) {
+
+	df1, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	df2, e := ReadDataFrame(strings.NewReader(file2))
+	CheckError(t, e)
+
+	if _, e = Bind(df1, df2); e == nil {
+		t.Fatalf("expected error for duplicate names.")
+	}
+	CheckError(t, df2.Prefix("k_"))
+	out, e := Bind(df1, df2)
+	CheckError(t, e)
+	if out.N() != 6 || len(out.VarNames) != 6 || out.Data[0][3] != "KITCHEN" {
+		t.Fatalf("unexpected frame %v %v.", out.VarNames, out.Data[0])
+	}
+
+	df2.Rename(map[string]string{"k_room": "room"})
+	out, e = BindWith(df1, df2, nil)
+	CheckError(t, e)
+	if out.VarNames[0] != "room_x" || out.VarNames[3] != "room_y" {
+		t.Fatalf("unexpected names %v.", out.VarNames)
+	}
+
+	if _, e = Bind(df1, df1.Head(2)); e == nil {
+		t.Fatalf("expected error for different row counts.")
+	}
+}
+
+func TestCollect(t *testing.T) {
+
+	tmpDir := getTempDir()
+	createDataFiles(t, tmpDir)
+	ds := &DataSet{Path: tmpDir + "data", Files: []string{"file1.json", "file2.json"}, Provenance: true}
+	df, e := ds.Collect()
+	CheckError(t, e)
+	if df.N() != 12 || df.Data[6][0] != "KITCHEN" {
+		t.Fatalf("unexpected frame %+v.", df)
+	}
+	if r, _ := df.Row(11).Float64(ProvenanceGlobalRow); r != 11 {
+		t.Fatalf("expected global row 11, got %v.", r)
+	}
+	if next, e := ds.Next(); e != nil || next.N() != 6 {
+		t.Fatalf("Collect must not change the position of Next.")
+	}
+
+	ds = &DataSet{Path: tmpDir + "data", Files: []string{"file1.json", "file2.json"},
+		Schema: Schema{{Name: "room", Type: TypeString}, {Name: "wifi", Type: TypeVector}, {Name: "acceleration", Type: TypeString}}}
+	if _, e = ds.Collect(); e == nil || !strings.Contains(e.Error(), "file1.json") {
+		t.Fatalf("expected schema error, got %v.", e)
+	}
+}
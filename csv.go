@@ -0,0 +1,163 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// IndexPolicy determines what happens with a leading unnamed index column,
+// as written by pandas to_csv.
+type IndexPolicy string
+
+const (
+	// Drop the index column.
+	IndexDrop IndexPolicy = "drop"
+	// Keep the index column as a variable named "index".
+	IndexKeep IndexPolicy = "keep"
+)
+
+// Cell values treated as missing by default, matching pandas.
+var DefaultNATokens = []string{"", "NaN", "nan", "NA", "N/A", "None", "null"}
+
+// Options for reading CSV files. The zero value reads comma separated files
+// with a header, drops a leading unnamed index column, and uses
+// DefaultNATokens.
+type CSVOptions struct {
+	// Field delimiter, a single character. Defaults to ",".
+	Comma string `yaml:"comma"`
+	// What to do with a leading unnamed index column. Defaults to IndexDrop.
+	Index IndexPolicy `yaml:"index"`
+	// Cell values that are read as missing (nil). Defaults to DefaultNATokens.
+	NATokens []string `yaml:"na_tokens"`
+}
+
+// Reads a data frame from a CSV file. The batch id is set to the file name
+// without extension. See ReadCSV.
+func ReadCSVFile(fn string, opts CSVOptions) (df *DataFrame, e error) {
+
+	f, e := openFile(fn)
+	if e != nil {
+		return
+	}
+	defer f.Close()
+	df, e = ReadCSV(f, opts)
+	if e != nil {
+		return nil, fmt.Errorf("file %s: %s", fn, e)
+	}
+	df.BatchID = csvBatchID(fn)
+	df.setReportFile(fn)
+	return
+}
+
+// Returns the file name without directory and extension.
+func csvBatchID(fn string) string {
+
+	base := filepath.Base(strings.TrimSuffix(fn, GzipExt))
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// Reads a data frame from CSV. The first record must be a header with the
+// variable names. Columns where most values parse as numbers are read as
+// float64 variables, other columns are read as strings. Missing values
+// are read as nil. Values in float64 columns that are not numbers are
+// read as nil and added to the coercion report, see CoercionReport.
+func ReadCSV(r io.Reader, opts CSVOptions) (df *DataFrame, e error) {
+
+	cr := csv.NewReader(stripBOM(r))
+	if opts.Comma != "" {
+		c := []rune(opts.Comma)
+		if len(c) != 1 {
+			return nil, fmt.Errorf("delimiter must be a single character, got [%s].", opts.Comma)
+		}
+		cr.Comma = c[0]
+	}
+	records, e := cr.ReadAll()
+	if e != nil {
+		return
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("missing CSV header.")
+	}
+	header := records[0]
+	records = records[1:]
+
+	// Leading unnamed column is a pandas index.
+	first := 0
+	if len(header) > 0 && header[0] == "" {
+		switch opts.Index {
+		case "", IndexDrop:
+			first = 1
+		case IndexKeep:
+			header[0] = "index"
+		default:
+			return nil, fmt.Errorf("unknown index policy [%s].", opts.Index)
+		}
+	}
+
+	na := opts.NATokens
+	if na == nil {
+		na = DefaultNATokens
+	}
+	isNA := make(map[string]bool)
+	for _, t := range na {
+		isNA[t] = true
+	}
+
+	df = &DataFrame{
+		VarNames: append([]string(nil), header[first:]...),
+		Data:     make([][]interface{}, len(records)),
+	}
+	df.resetVarMap()
+	if len(df.varMap) != len(df.VarNames) {
+		return nil, fmt.Errorf("duplicate variable names in CSV header.")
+	}
+
+	// A column is numeric if most non-missing values parse as numbers.
+	numeric := make([]bool, len(df.VarNames))
+	for j := range numeric {
+		var nfloat, nstring int
+		for _, rec := range records {
+			s := rec[first+j]
+			if isNA[s] {
+				continue
+			}
+			if _, err := strconv.ParseFloat(s, 64); err != nil {
+				nstring++
+			} else {
+				nfloat++
+			}
+		}
+		numeric[j] = nfloat > nstring
+	}
+
+	for i, rec := range records {
+		row := make([]interface{}, len(df.VarNames))
+		for j := range row {
+			s := rec[first+j]
+			switch {
+			case isNA[s]:
+			case numeric[j]:
+				f, err := strconv.ParseFloat(s, 64)
+				if err != nil {
+					df.coercions = append(df.coercions,
+						CoercionError{Row: i, Var: df.VarNames[j], Raw: s, Type: "float64"})
+					continue
+				}
+				row[j] = f
+			default:
+				row[j] = s
+			}
+		}
+		df.Data[i] = row
+	}
+	return
+}
@@ -0,0 +1,75 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const pandasCSV = `,room,acceleration,label
+0,BED5,1.3,a
+1,BED5,NaN,
+2,None,1.5,c
+3,DINING,,d
+`
+
+func TestReadCSV(t *testing.T) {
+
+	df, e := ReadCSV(strings.NewReader(pandasCSV), CSVOptions{})
+	CheckError(t, e)
+	t.Logf("df: %+v", df)
+
+	if !reflect.DeepEqual(df.VarNames, []string{"room", "acceleration", "label"}) {
+		t.Fatalf("unexpected var names %v.", df.VarNames)
+	}
+	expected := [][]interface{}{
+		{"BED5", 1.3, "a"},
+		{"BED5", nil, nil},
+		{nil, 1.5, "c"},
+		{"DINING", nil, "d"},
+	}
+	if !reflect.DeepEqual(df.Data, expected) {
+		t.Fatalf("got %v, expected %v.", df.Data, expected)
+	}
+	room, e := df.String(0, "room")
+	CheckError(t, e)
+	if room != "BED5" {
+		t.Fatalf("room must be BED5, not %s.", room)
+	}
+
+	// Keep the index.
+	df, e = ReadCSV(strings.NewReader(pandasCSV), CSVOptions{Index: IndexKeep})
+	CheckError(t, e)
+	if df.VarNames[0] != "index" || df.Data[3][0] != 3.0 {
+		t.Fatalf("index not kept: %v %v.", df.VarNames, df.Data[3])
+	}
+
+	// Custom delimiter and NA tokens.
+	df, e = ReadCSV(strings.NewReader("a;b\n1;x\n-;y\n"), CSVOptions{Comma: ";", NATokens: []string{"-"}})
+	CheckError(t, e)
+	if !reflect.DeepEqual(df.Data, [][]interface{}{{1.0, "x"}, {nil, "y"}}) {
+		t.Fatalf("unexpected data %v.", df.Data)
+	}
+}
+
+func TestDataSetCSV(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-csv")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	CheckError(t, ioutil.WriteFile(dir+"/s1.csv", []byte(pandasCSV), 0644))
+
+	ds := &DataSet{Path: dir, Files: []string{"s1.csv"}}
+	df, e := ds.Next()
+	CheckError(t, e)
+	if df.N() != 4 || df.BatchID != "s1" {
+		t.Fatalf("unexpected frame %+v.", df)
+	}
+}
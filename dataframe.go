@@ -6,12 +6,17 @@
 package dataframe
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math/rand"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
+	"time"
 
 	"github.com/golang/glog"
 	"launchpad.net/goyaml"
@@ -23,13 +28,63 @@ const (
 
 // A list of dataframe files. Each file must have the same dataframe schema.
 type DataSet struct {
-	Path  string   `yaml:"path"`
+	Path string `yaml:"path"`
+	// File names relative to Path. Names can be glob patterns such as
+	// "*.json", see Resolve.
 	Files []string `yaml:"files"`
-	index int
+	// Directory relative to Path scanned recursively for data files, see
+	// Resolve. Optional.
+	Dir string `yaml:"dir"`
+	// Expected dimension of vector variables, enforced when files are read.
+	Dims map[string]VecDim `yaml:"dims"`
+	// Limits enforced when files of any format are read. Optional.
+	Limits *ReadLimits `yaml:"limits"`
+	// Options for files with extension ".csv".
+	CSV CSVOptions `yaml:"csv"`
+	// Variable names and types of the files, see Validate.
+	Schema Schema `yaml:"schema"`
+	// Character set of the files, see Encoding. A leading UTF-8 byte order
+	// mark is always removed.
+	Encoding Encoding `yaml:"encoding"`
+	// Converts numeric strings to float64 in the float64 variables of every
+	// data frame, see DataFrame.CoerceNumbers. Off by default.
+	CoerceNumbers bool `yaml:"coerce_numbers"`
+	// NaN and infinite value options applied to every data frame.
+	NonFinite NonFiniteOptions `yaml:"non_finite"`
+	// Missing value options applied to every data frame.
+	NA NAOptions `yaml:"na"`
+	// Options for files in InfluxDB line protocol, with extension ".lp".
+	Influx InfluxOptions `yaml:"influx"`
+	// Calibration profile file applied to every data frame. Optional.
+	// ReadDataSetFile resolves relative paths against the directory of the
+	// data set file.
+	Calibration string `yaml:"calibration"`
+	// Adds provenance variables to every data frame, see ProvenanceFile.
+	Provenance bool `yaml:"provenance"`
+	// Properties merged into the properties of every data frame. Values
+	// in the data frame take precedence.
+	Properties map[string]string `yaml:"properties"`
+	// Caches decoded data frames next to the source files, see CacheExt.
+	Cache       bool `yaml:"cache"`
+	metrics     *Metrics
+	hooks       *Hooks
+	calibration *CalibrationProfile
+	verifier    Verifier
+	decrypter   Decrypter
+	index       int
+	// global row number of the next data frame.
+	row int
+	// shuffle options, see SetShuffle.
+	shuffle ShuffleMode
+	rand    *rand.Rand
+	// file order of the current pass.
+	order []int
 }
 
 // A DataFrame is a table where columns are variables and rows are measurements.
 // Each row contains an instance. Each variable can have a different type.
+// N and NumVariables return zero for a nil *DataFrame, and Float64Slice and
+// String return an error; other methods require a non-nil data frame.
 type DataFrame struct {
 
 	// Describes the data.
@@ -44,11 +99,43 @@ type DataFrame struct {
 	// Ordered list of variables.
 	Data [][]interface{} `json:"data"`
 
+	// Units of the variables by variable name. Optional.
+	VarUnits map[string]string `json:"var_units,omitempty"`
+
 	// Can be used to store custom properties related to the data frame.
 	Properties map[string]string `json:"properties"`
 
 	// maps var name to var index for faster access.
 	varMap map[string]int
+
+	// cached column statistics.
+	cache statsCache
+
+	// cells that failed type coercion on read.
+	coercions []CoercionError
+
+	// NaN and infinite value options.
+	nonFinite NonFiniteOptions
+
+	// missing value options.
+	na NAOptions
+
+	// optional unique row index.
+	index *rowIndex
+
+	// schema checked by the writers.
+	schema Schema
+}
+
+// Creates a data frame with the given variables and no rows.
+func Empty(varNames ...string) *DataFrame {
+
+	df := &DataFrame{
+		VarNames: append([]string(nil), varNames...),
+		Data:     make([][]interface{}, 0),
+	}
+	df.resetVarMap()
+	return df
 }
 
 // Reads a list of filenames from a file. See ReadDataSetReader()
@@ -59,10 +146,14 @@ func ReadDataSetFile(fn string) (ds *DataSet, e error) {
 		return
 	}
 	ds, e = ReadDataSet(f)
+	if ds != nil && ds.Calibration != "" {
+		ds.Calibration = resolvePath(filepath.Dir(fn), ds.Calibration)
+	}
 	return
 }
 
-// Reads a list of filenames from an io.Reader.
+// Reads a list of filenames from an io.Reader. Glob patterns and directories
+// are resolved, see Resolve.
 func ReadDataSet(r io.Reader) (ds *DataSet, e error) {
 
 	var b []byte
@@ -74,13 +165,18 @@ func ReadDataSet(r io.Reader) (ds *DataSet, e error) {
 	if e != nil {
 		return
 	}
-
+	if ds != nil {
+		if e = ds.Resolve(); e != nil {
+			return nil, e
+		}
+	}
 	return
 }
 
 // Go back to the beginning of the data set.
 func (ds *DataSet) Reset() {
 	ds.index = 0
+	ds.row = 0
 }
 
 // Reads attributes from the next file in the data set.
@@ -88,27 +184,182 @@ func (ds *DataSet) Reset() {
 func (ds *DataSet) Next() (df *DataFrame, e error) {
 
 	if ds.index == len(ds.Files) {
-		ds.index = 0
+		ds.Reset()
 		return nil, io.EOF
 	}
+	i := ds.fileAt(ds.index)
+	if df, e = ds.loadFile(i); e != nil {
+		return nil, e
+	}
+	if ds.Provenance {
+		if e = addProvenance(df, ds.Files[i], ds.row); e != nil {
+			return nil, e
+		}
+		ds.row += df.N()
+	}
+	ds.shuffleRows(df)
+	ds.index++
+	return
+}
+
+// Reads file i and applies the data set options, except provenance which
+// depends on the order of the files.
+func (ds *DataSet) loadFile(i int) (df *DataFrame, e error) {
+
 	sep := string(os.PathSeparator)
-	glog.V(2).Infof("feature file: %s", ds.Path+sep+ds.Files[ds.index])
-	df, e = ReadDataFrameFile(ds.Path + sep + ds.Files[ds.index])
+	fn := ds.Path + sep + ds.Files[i]
+	glog.V(2).Infof("feature file: %s", fn)
+	ds.hooks.fileStart(fn)
+	start := time.Now()
+	df, e = ds.readFile(fn)
+	d := time.Since(start)
+	ds.metrics.observeRead(df, e, d)
+	ds.hooks.fileEnd(fn, df, e, d)
 	if e != nil {
 		return
 	}
-	ds.index++
+	ds.mergeProperties(df)
+	if ds.CoerceNumbers {
+		df.CoerceNumbers()
+	}
+	if e = df.SetNonFinite(ds.NonFinite); e != nil {
+		return nil, e
+	}
+	if e = df.SetNA(ds.NA); e != nil {
+		return nil, e
+	}
+	for name, dim := range ds.Dims {
+		if e = df.EnforceDim(name, dim); e != nil {
+			return nil, fmt.Errorf("file %s: %s", ds.Files[i], e)
+		}
+	}
+	if e = ds.calibrate(df); e != nil {
+		return nil, fmt.Errorf("file %s: %s", ds.Files[i], e)
+	}
 	return
 }
 
-// Reads feature from file.
-func ReadDataFrameFile(fn string) (df *DataFrame, e error) {
+// Adds the data set properties that are not defined in the data frame.
+func (ds *DataSet) mergeProperties(df *DataFrame) {
+
+	if len(ds.Properties) == 0 {
+		return
+	}
+	if df.Properties == nil {
+		df.Properties = make(map[string]string, len(ds.Properties))
+	}
+	for k, v := range ds.Properties {
+		if _, ok := df.Properties[k]; !ok {
+			df.Properties[k] = v
+		}
+	}
+}
+
+// Reads a file, from the cache if enabled.
+func (ds *DataSet) readFile(fn string) (*DataFrame, error) {
+
+	if !ds.Cache || ds.verifier != nil || ds.decrypter != nil {
+		return ds.decodeFile(fn)
+	}
+	return ds.readCached(fn)
+}
+
+// Reads a file using the format implied by its extension. Verification
+// and decryption hooks, if any, are applied to the raw content first, then
+// gzip files are decompressed.
+func (ds *DataSet) decodeFile(fn string) (*DataFrame, error) {
 
 	f, e := os.Open(fn)
+	if e != nil {
+		return nil, e
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if ds.verifier != nil || ds.decrypter != nil {
+		b, e := ds.applyHooks(fn, f)
+		if e != nil {
+			return nil, e
+		}
+		r = bytes.NewReader(b)
+	}
+	r, name, e := decompress(fn, r)
+	if e != nil {
+		return nil, fmt.Errorf("file %s: %s", fn, e)
+	}
+	r, binary := isBinary(r)
+	var avro bool
+	if !binary {
+		r, avro = isAvro(r)
+	}
+	if !binary && !avro {
+		if r, e = NewDecodingReader(r, ds.Encoding); e != nil {
+			return nil, e
+		}
+	}
+
+	// The binary, Avro, and JSON readers enforce the limits while parsing.
+	// Other readers are limited in size and checked after parsing.
+	var lr *limitedReader
+	limited := r
+	if ds.Limits != nil {
+		lr = &limitedReader{r: r, n: ds.Limits.MaxBytes}
+		limited = lr
+	}
+	var df *DataFrame
+	switch {
+	case binary && ds.Limits != nil:
+		df, e = ReadDataFrameBinaryLimits(r, *ds.Limits)
+	case binary:
+		df, e = ReadDataFrameBinary(r)
+	case avro && ds.Limits != nil:
+		df, e = ReadDataFrameAvroLimits(r, *ds.Limits)
+	case avro:
+		df, e = ReadDataFrameAvro(r)
+	case strings.HasSuffix(name, ".csv"):
+		df, e = ReadCSV(limited, ds.CSV)
+		if e == nil {
+			df.BatchID = csvBatchID(fn)
+		}
+	case strings.HasSuffix(name, ".lp"):
+		df, e = ReadInflux(limited, ds.Influx)
+		if e == nil {
+			df.BatchID = csvBatchID(fn)
+		}
+	case ds.Limits != nil:
+		df, e = ReadDataFrameLimits(r, *ds.Limits)
+	default:
+		df, e = ReadDataFrame(r)
+	}
+	if lr != nil && lr.exceeded {
+		return nil, fmt.Errorf("file %s: Input exceeds limit of %d bytes.", fn, ds.Limits.MaxBytes)
+	}
+	if e == nil && ds.Limits != nil {
+		e = ds.Limits.checkDataFrame(df)
+	}
+	if e == nil {
+		e = checkRowWidths(df)
+	}
+	if e != nil {
+		return nil, fmt.Errorf("file %s: %s", fn, e)
+	}
+	df.setReportFile(fn)
+	return df, nil
+}
+
+// Reads feature from file. Files with extension GzipExt are decompressed.
+func ReadDataFrameFile(fn string) (df *DataFrame, e error) {
+
+	f, e := openFile(fn)
 	if e != nil {
 		return
 	}
-	return ReadDataFrame(f)
+	defer f.Close()
+	df, e = ReadDataFrame(f)
+	if e != nil {
+		return
+	}
+	df.setReportFile(fn)
+	return
 }
 
 // Reads features from io.Reader.
@@ -120,16 +371,19 @@ func ReadDataFrame(r io.Reader) (df *DataFrame, e error) {
 		return
 	}
 	df = &DataFrame{}
-	e = json.Unmarshal(b, df)
+	e = json.Unmarshal(bytes.TrimPrefix(b, utf8BOM), df)
 	if e != nil {
 		return nil, e
 	}
-
-	m := make(map[string]int)
-	for k, v := range df.VarNames {
-		m[v] = k
+	if e = checkRowWidths(df); e != nil {
+		return nil, e
 	}
-	df.varMap = m
+	for _, row := range df.Data {
+		restoreRow(row)
+	}
+
+	df.resetVarMap()
+	df.coerceJSON()
 	return
 }
 
@@ -140,49 +394,72 @@ func (df *DataFrame) Float64Slice(frame int, names ...string) (floats []float64,
 		return nil, fmt.Errorf("No variable names were specified, must provide at least one var name.")
 	}
 
-	floats = make([]float64, 0)
-
 	var indices []int
 	indices, err = df.indices(names...)
 	if err != nil {
 		return
 	}
+	if err = df.checkFrame(frame); err != nil {
+		return nil, err
+	}
+	if floats, err = df.appendFloat64s(make([]float64, 0), frame, indices); err != nil {
+		return nil, err
+	}
+	if err = df.nonFinite.apply(frame, floats); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Appends the float64 and vector values of the variables with the given
+// indices in a frame to floats. The non-finite policy is not applied.
+func (df *DataFrame) appendFloat64s(floats []float64, frame int, indices []int) ([]float64, error) {
+
 	for _, v := range indices {
 		value := df.Data[frame][v]
 		switch i := value.(type) {
 		case nil:
-			return nil, fmt.Errorf("variable for index %d is nil.", v)
+			fill, err := df.naValues(frame, v)
+			if err != nil {
+				return nil, err
+			}
+			floats = append(floats, fill...)
 		case float64:
 			floats = append(floats, i)
+		case []float64:
+			floats = append(floats, i...)
 		case []interface{}:
-			for _, v := range i {
-				floats = append(floats, v.(float64))
+			for j, x := range i {
+				f, ok := x.(float64)
+				if !ok {
+					s, _ := x.(string)
+					if f, ok = parseNonFinite(s); !ok {
+						return nil, fmt.Errorf("In frame %d, element %d of type %T is not a float64.", frame, j, x)
+					}
+				}
+				floats = append(floats, f)
 			}
+		case string:
+			f, ok := parseNonFinite(i)
+			if !ok {
+				return nil, fmt.Errorf("In frame %d, variable of type string is not a float64.", frame)
+			}
+			floats = append(floats, f)
 		default:
 			return nil, fmt.Errorf("In frame %d, Vector of type %s in not supported.",
 				frame, reflect.TypeOf(i).String())
 		}
 	}
-	return
+	return floats, nil
 }
 
 // Joins float64 and []float64 variables. Returns a channel of []float64 frames.
+//
+// Deprecated: the program exits if a row can't be read. Use
+// Float64SliceChan or Float64Iterator, which return errors.
 func (df *DataFrame) Float64SliceChannel(names ...string) (ch chan []float64) {
 
-	ch = make(chan []float64, BUFFER_SIZE)
-	go func() {
-		// Iterate through all the rows.
-		for i := 0; i < df.N(); i++ {
-			sl, err := df.Float64Slice(i, names...)
-			if err != nil {
-				glog.Fatalf("Reading float64 vector failed: %s", err)
-			}
-			ch <- sl
-		}
-		close(ch)
-	}()
-
-	return
+	return fatalChan(df.Float64Iterator(names...))
 }
 
 // Returns value of a string variable.
@@ -198,6 +475,10 @@ func (df *DataFrame) String(frame int, name string) (value string, err error) {
 		return
 	}
 
+	if err = df.checkFrame(frame); err != nil {
+		return
+	}
+
 	var ok bool
 	v := df.Data[frame][indices[0]]
 	value, ok = v.(string)
@@ -205,66 +486,136 @@ func (df *DataFrame) String(frame int, name string) (value string, err error) {
 		return
 	}
 
-	err = fmt.Errorf("In frame %d, variable [%d] is of type [%s]. Must be of type string.",
+	if v == nil {
+		err = fmt.Errorf("In frame %d, variable [%s] is nil.", frame, name)
+		return
+	}
+	err = fmt.Errorf("In frame %d, variable [%s] is of type [%s]. Must be of type string.",
 		frame, name, reflect.TypeOf(v).String())
 	return
 }
 
 // Resets data set and starts reading data. Returns a channel to be used to
 // get all the frames.
+//
+// Deprecated: the program exits if a file or row can't be read. Use
+// Float64SliceChan or Float64Iterator, which return errors.
 func (ds *DataSet) Float64SliceChannel(names ...string) (ch chan []float64) {
 
-	ch = make(chan []float64, BUFFER_SIZE)
-	go func() {
-		for {
-			// Get a data frame.
-			df, e := ds.Next()
-			if e == io.EOF {
-				close(ch)
-				break
-			}
-			if e != nil {
-				glog.Fatalf("Getting data frame failed: %s", e)
-			}
+	return fatalChan(ds.Float64Iterator(names...))
+}
 
-			// Iterate through all the rows.
-			for i := 0; i < len(df.Data); i++ {
-				sl, err := df.Float64Slice(i, names...)
-				if err != nil {
-					glog.Fatalf("Reading float64 vector failed: %s", err)
-				}
-				ch <- sl
-			}
+func fatalChan(it *Float64Iterator) chan []float64 {
+
+	ch := make(chan []float64, BUFFER_SIZE)
+	go func() {
+		for it.Next() {
+			ch <- it.Value()
+		}
+		if it.Err() != nil {
+			glog.Fatalf("Reading float64 vector failed: %s", it.Err())
 		}
+		close(ch)
 	}()
-
-	return
+	return ch
 }
 
 // Returns number of data instances (rows) in data frame.
+// A nil data frame has zero rows.
 func (df *DataFrame) N() int {
 
+	if df == nil {
+		return 0
+	}
 	return len(df.Data)
 }
 
 // Returns number of variables (columns) in data frame.
+// A nil data frame has zero variables.
 func (df *DataFrame) NumVariables() int {
 
-	return len(df.Data[0])
+	if df == nil {
+		return 0
+	}
+	return len(df.VarNames)
+}
+
+// Returns an error if frame is not a valid row number.
+func (df *DataFrame) checkFrame(frame int) error {
+
+	if frame < 0 || frame >= df.N() {
+		return fmt.Errorf("Frame %d is out of range, the data frame has %d rows.", frame, df.N())
+	}
+	return nil
 }
 
 // Returns the indices for the variable names.
 func (df *DataFrame) indices(names ...string) (indices []int, err error) {
 
+	if df == nil {
+		return nil, fmt.Errorf("The data frame is nil.")
+	}
 	indices = make([]int, 0)
 	var idx int
 	var ok bool
 	for _, v := range names {
 		if idx, ok = df.varMap[v]; !ok {
-			err = fmt.Errorf("There is no variable [%s] in the data frame.")
+			err = fmt.Errorf("There is no variable [%s] in the data frame.", v)
 			return
 		}
 		indices = append(indices, idx)
 	}
 	return
 }
+
+// Appends a new variable to the data frame. The number of values must match
+// the number of rows.
+func (df *DataFrame) addVar(name string, values []interface{}) error {
+
+	if _, ok := df.varMap[name]; ok {
+		return fmt.Errorf("Variable [%s] already exists in the data frame.", name)
+	}
+	if len(values) != df.N() {
+		return fmt.Errorf("Number of values (%d) does not match number of rows (%d).", len(values), df.N())
+	}
+	if df.varMap == nil {
+		df.varMap = make(map[string]int)
+	}
+	df.varMap[name] = len(df.VarNames)
+	df.VarNames = append(df.VarNames, name)
+	for i := range df.Data {
+		df.Data[i] = append(df.Data[i], values[i])
+	}
+	return nil
+}
+
+// Removes a variable from the data frame.
+func (df *DataFrame) removeVar(name string) error {
+
+	indices, err := df.indices(name)
+	if err != nil {
+		return err
+	}
+	idx := indices[0]
+	df.VarNames = append(df.VarNames[:idx:idx], df.VarNames[idx+1:]...)
+	for i, row := range df.Data {
+		df.Data[i] = append(row[:idx:idx], row[idx+1:]...)
+	}
+	delete(df.VarUnits, name)
+	if df.IndexName() == name {
+		df.ResetIndex()
+	}
+	df.Invalidate(name)
+	df.resetVarMap()
+	return nil
+}
+
+// Rebuilds the map from variable names to indices.
+func (df *DataFrame) resetVarMap() {
+
+	m := make(map[string]int)
+	for k, v := range df.VarNames {
+		m[v] = k
+	}
+	df.varMap = m
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/gonum/floats"
@@ -83,6 +84,35 @@ func TestDataSet(t *testing.T) {
 	}
 }
 
+func TestDataSetProperties(t *testing.T) {
+
+	tmpDir := getTempDir()
+	createDataFiles(t, tmpDir)
+	fn := tmpDir + "data" + string(os.PathSeparator) + "props.json"
+	props := strings.Replace(file1, `"data":`, `"properties": {"sensor": "ak-200"},
+"data":`, 1)
+	CheckError(t, ioutil.WriteFile(fn, []byte(props), 0644))
+
+	ds := &DataSet{
+		Path:       tmpDir + "data",
+		Files:      []string{"file1.json", "props.json"},
+		Properties: map[string]string{"sensor": "ak-100", "sample_rate": "50"},
+	}
+	df, e := ds.Next()
+	CheckError(t, e)
+	if df.Properties["sensor"] != "ak-100" || df.Properties["sample_rate"] != "50" {
+		t.Fatalf("unexpected properties %v.", df.Properties)
+	}
+	df, e = ds.Next()
+	CheckError(t, e)
+	if df.Properties["sensor"] != "ak-200" || df.Properties["sample_rate"] != "50" {
+		t.Fatalf("frame properties must take precedence, got %v.", df.Properties)
+	}
+	if ds.Properties["sensor"] != "ak-100" {
+		t.Fatalf("data set properties must not be modified.")
+	}
+}
+
 func TestDimensions(t *testing.T) {
 
 	tmpDir := getTempDir()
@@ -153,6 +183,53 @@ func TestDataFrameChan(t *testing.T) {
 	}
 }
 
+func TestEmpty(t *testing.T) {
+
+	df := Empty("room", "wifi", "acceleration")
+	if df.N() != 0 || df.NumVariables() != 3 {
+		t.Fatalf("dims must be 0x3, not %dx%d.", df.N(), df.NumVariables())
+	}
+	if _, e := df.Float64Slice(0, "wifi"); e == nil {
+		t.Fatalf("expected out of range error.")
+	}
+	if _, e := df.String(0, "room"); e == nil {
+		t.Fatalf("expected out of range error.")
+	}
+	for _ = range df.Float64SliceChannel("wifi") {
+		t.Fatalf("channel must be empty.")
+	}
+	data, rows, cols, e := df.Float64Matrix("wifi")
+	CheckError(t, e)
+	if len(data) != 0 || rows != 0 || cols != 0 {
+		t.Fatalf("matrix must be empty.")
+	}
+
+	// No variables.
+	df = Empty()
+	if df.N() != 0 || df.NumVariables() != 0 {
+		t.Fatalf("dims must be 0x0, not %dx%d.", df.N(), df.NumVariables())
+	}
+
+	// A nil frame has no rows or variables, and the accessors return an error.
+	var null *DataFrame
+	if null.N() != 0 || null.NumVariables() != 0 {
+		t.Fatalf("nil frame must be empty.")
+	}
+	if _, e := null.Float64Slice(0, "wifi"); e == nil {
+		t.Fatalf("expected error for nil frame.")
+	}
+	if _, e := null.String(0, "room"); e == nil {
+		t.Fatalf("expected error for nil frame.")
+	}
+
+	// Zero rows in JSON.
+	df, e = ReadDataFrame(strings.NewReader(`{"var_names": ["a", "b"], "data": []}`))
+	CheckError(t, e)
+	if df.N() != 0 || df.NumVariables() != 2 {
+		t.Fatalf("dims must be 0x2, not %dx%d.", df.N(), df.NumVariables())
+	}
+}
+
 func CheckError(t *testing.T, e error) {
 
 	if e != nil {
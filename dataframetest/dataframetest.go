@@ -0,0 +1,123 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package dataframetest provides utilities for testing code that produces
+// data frames.
+package dataframetest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/akualab/dataframe"
+)
+
+// Maximum number of differences reported by AssertEqual.
+var MaxDiffs = 20
+
+// T is the subset of testing.TB used by the assertions.
+type T interface {
+	Errorf(format string, args ...interface{})
+}
+
+// Compares two data frames and reports the differences as a test error.
+// Float values, including vector elements, are equal if they differ by at
+// most tolerance. NaN values are equal to each other. See
+// dataframe.ValuesAlmostEqual.
+func AssertEqual(t T, want, got *dataframe.DataFrame, tolerance float64) bool {
+
+	if h, ok := t.(interface {
+		Helper()
+	}); ok {
+		h.Helper()
+	}
+	diffs := Diff(want, got, tolerance)
+	if len(diffs) == 0 {
+		return true
+	}
+	n := len(diffs)
+	if n > MaxDiffs {
+		diffs = append(diffs[:MaxDiffs], fmt.Sprintf("... and %d more differences", n-MaxDiffs))
+	}
+	t.Errorf("data frames differ (%d differences):\n\t%s", n, strings.Join(diffs, "\n\t"))
+	return false
+}
+
+// Returns a human readable description of each difference between two data
+// frames. Returns nil if the frames are equal. Rows are compared in order;
+// variables are matched by name so the variable order doesn't matter.
+func Diff(want, got *dataframe.DataFrame, tolerance float64) []string {
+
+	var diffs []string
+	add := func(format string, args ...interface{}) {
+		diffs = append(diffs, fmt.Sprintf(format, args...))
+	}
+	if want == nil || got == nil {
+		if want != got {
+			add("want frame %v, got %v", want, got)
+		}
+		return diffs
+	}
+
+	gotIdx := indexOf(got.VarNames)
+	wantIdx := indexOf(want.VarNames)
+	for _, name := range want.VarNames {
+		if _, ok := gotIdx[name]; !ok {
+			add("missing variable [%s]", name)
+		}
+	}
+	for _, name := range got.VarNames {
+		if _, ok := wantIdx[name]; !ok {
+			add("unexpected variable [%s]", name)
+		}
+	}
+	for name, u := range want.VarUnits {
+		if got.VarUnits[name] != u {
+			add("variable [%s]: want unit %q, got %q", name, u, got.VarUnits[name])
+		}
+	}
+	if want.N() != got.N() {
+		add("want %d rows, got %d", want.N(), got.N())
+	}
+
+	n := want.N()
+	if got.N() < n {
+		n = got.N()
+	}
+	for i := 0; i < n; i++ {
+		for j, name := range want.VarNames {
+			k, ok := gotIdx[name]
+			if !ok {
+				continue
+			}
+			w, g := want.Data[i][j], got.Data[i][k]
+			if !dataframe.ValuesAlmostEqual(w, g, tolerance) {
+				add("row %d, variable [%s]: want %s, got %s", i, name, format(w), format(g))
+			}
+		}
+	}
+	return diffs
+}
+
+func indexOf(names []string) map[string]int {
+
+	m := make(map[string]int, len(names))
+	for i, name := range names {
+		m[name] = i
+	}
+	return m
+}
+
+func format(v interface{}) string {
+
+	switch x := v.(type) {
+	case nil:
+		return "nil"
+	case string:
+		return fmt.Sprintf("%q", x)
+	default:
+		return fmt.Sprintf("%v (%T)", x, x)
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframetest
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/akualab/dataframe"
+)
+
+type recorder struct {
+	msgs []string
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.msgs = append(r.msgs, fmt.Sprintf(format, args...))
+}
+
+func frame() *dataframe.DataFrame {
+
+	df := dataframe.Empty("room", "wifi", "acceleration")
+	df.Data = append(df.Data,
+		[]interface{}{"BED5", []interface{}{-56.0, -83.0}, 1.3},
+		[]interface{}{"DINING", []interface{}{-58.0, -84.0}, math.NaN()},
+	)
+	return df
+}
+
+func TestAssertEqual(t *testing.T) {
+
+	want, got := frame(), frame()
+	got.Data[0][2] = 1.3 + 1e-12
+	if !AssertEqual(t, want, got, 1e-9) {
+		t.Fatalf("frames must be equal.")
+	}
+
+	got.Data[0][1] = []float64{-56, -80}
+	got.Data[1][0] = "KITCHEN"
+	r := &recorder{}
+	if AssertEqual(r, want, got, 1e-9) {
+		t.Fatalf("frames must differ.")
+	}
+	msg := strings.Join(r.msgs, "")
+	t.Log(msg)
+	for _, s := range []string{"2 differences", `row 1, variable [room]: want "DINING", got "KITCHEN"`, "row 0, variable [wifi]"} {
+		if !strings.Contains(msg, s) {
+			t.Fatalf("message doesn't contain %s.", s)
+		}
+	}
+}
+
+func TestDiffShape(t *testing.T) {
+
+	want, got := frame(), dataframe.Empty("room", "acceleration", "rssi")
+	got.Data = append(got.Data, []interface{}{"BED5", 1.3, nil})
+	diffs := Diff(want, got, 0)
+	t.Log(diffs)
+	if len(diffs) != 3 {
+		t.Fatalf("expected 3 differences, got %d.", len(diffs))
+	}
+}
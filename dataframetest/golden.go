@@ -0,0 +1,100 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframetest
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/akualab/dataframe"
+)
+
+// When set, AssertGolden writes the golden files instead of comparing them.
+// Run tests with -update-golden to regenerate the golden files.
+var Update = flag.Bool("update-golden", false, "update golden files")
+
+// Returns the canonical JSON encoding of a data frame, as written by
+// DataFrame.WriteDataFrame: fixed field order, sorted map keys, and one row
+// per line. Equal frames have identical encodings so golden files produce
+// minimal line diffs. The schema set with SetSchema is not checked, so
+// frames that violate it can be compared too.
+func Canonical(df *dataframe.DataFrame) ([]byte, error) {
+
+	c := &dataframe.DataFrame{
+		Description: df.Description,
+		BatchID:     df.BatchID,
+		VarNames:    df.VarNames,
+		Data:        df.Data,
+		VarUnits:    df.VarUnits,
+		Properties:  df.Properties,
+	}
+	var buf bytes.Buffer
+	if err := c.WriteDataFrame(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Compares a data frame with the golden file fn using the canonical
+// encoding. When the Update flag is set, the golden file is written instead.
+// Differences are reported at the cell level when the golden file can be
+// decoded, and as the first differing line otherwise.
+func AssertGolden(t T, fn string, got *dataframe.DataFrame) bool {
+
+	if h, ok := t.(interface {
+		Helper()
+	}); ok {
+		h.Helper()
+	}
+	b, err := Canonical(got)
+	if err != nil {
+		t.Errorf("can't encode data frame: %s", err)
+		return false
+	}
+	if *Update {
+		if err = os.MkdirAll(filepath.Dir(fn), 0755); err == nil {
+			err = ioutil.WriteFile(fn, b, 0644)
+		}
+		if err != nil {
+			t.Errorf("can't write golden file: %s", err)
+			return false
+		}
+		return true
+	}
+	golden, err := ioutil.ReadFile(fn)
+	if err != nil {
+		t.Errorf("can't read golden file (run with -update-golden to create it): %s", err)
+		return false
+	}
+	if bytes.Equal(golden, b) {
+		return true
+	}
+	if want, err := dataframe.ReadDataFrame(bytes.NewReader(golden)); err == nil {
+		if diffs := Diff(want, got, 0); len(diffs) > 0 {
+			return AssertEqual(t, want, got, 0)
+		}
+	}
+	wantLines := strings.Split(string(golden), "\n")
+	gotLines := strings.Split(string(b), "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			t.Errorf("golden file %s differs at line %d:\n\twant: %s\n\tgot:  %s", fn, i+1, w, g)
+			break
+		}
+	}
+	return false
+}
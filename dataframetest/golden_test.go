@@ -0,0 +1,109 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframetest
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/akualab/dataframe"
+)
+
+func goldenFrame() *dataframe.DataFrame {
+
+	df := dataframe.Empty("room", "wifi", "acceleration")
+	df.BatchID = "golden"
+	df.VarUnits = map[string]string{"acceleration": "m/s^2"}
+	df.Properties = map[string]string{"b": "2", "a": "1"}
+	df.Data = append(df.Data,
+		[]interface{}{"BED5", []interface{}{-56.0, -83.0}, 1.3},
+		[]interface{}{"DINING", []interface{}{-58.0, -84.0}, 1.4},
+	)
+	return df
+}
+
+func TestCanonical(t *testing.T) {
+
+	b1, e := Canonical(goldenFrame())
+	if e != nil {
+		t.Fatal(e)
+	}
+	df, e := dataframe.ReadDataFrame(bytes.NewReader(b1))
+	if e != nil {
+		t.Fatal(e)
+	}
+	b2, e := Canonical(df)
+	if e != nil {
+		t.Fatal(e)
+	}
+	t.Logf("%s", b1)
+	if !bytes.Equal(b1, b2) {
+		t.Fatalf("canonical encoding is not stable:\n%s\n%s", b1, b2)
+	}
+	if !strings.Contains(string(b1), `"properties": {"a":"1","b":"2"}`) {
+		t.Fatalf("map keys must be sorted.")
+	}
+
+	// The schema of the frame is not checked.
+	df.SetSchema(dataframe.Schema{{Name: "room", Type: dataframe.TypeFloat64}})
+	b2, e = Canonical(df)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if !bytes.Equal(b1, b2) {
+		t.Fatalf("schema must not change the encoding.")
+	}
+}
+
+func TestAssertGolden(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframetest")
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer os.RemoveAll(dir)
+	fn := filepath.Join(dir, "testdata", "frame.golden")
+
+	r := &recorder{}
+	if AssertGolden(r, fn, goldenFrame()) {
+		t.Fatalf("expected error for missing golden file.")
+	}
+
+	*Update = true
+	ok := AssertGolden(t, fn, goldenFrame())
+	*Update = false
+	if !ok {
+		t.Fatalf("can't write golden file.")
+	}
+	if !AssertGolden(t, fn, goldenFrame()) {
+		t.Fatalf("frame must match golden file.")
+	}
+
+	df := goldenFrame()
+	df.Data[1][2] = 1.5
+	r = &recorder{}
+	if AssertGolden(r, fn, df) {
+		t.Fatalf("frame must not match golden file.")
+	}
+	if !strings.Contains(r.msgs[0], "row 1, variable [acceleration]") {
+		t.Fatalf("unexpected message %s.", r.msgs[0])
+	}
+
+	df = goldenFrame()
+	df.BatchID = "other"
+	r = &recorder{}
+	if AssertGolden(r, fn, df) {
+		t.Fatalf("frame must not match golden file.")
+	}
+	t.Log(r.msgs)
+	if !strings.Contains(r.msgs[0], "line 3") {
+		t.Fatalf("unexpected message %s.", r.msgs[0])
+	}
+}
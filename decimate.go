@@ -0,0 +1,173 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// How rows are combined when downsampling.
+type DecimateMode string
+
+const (
+	// Keeps the first row of each group.
+	DecimateKeep DecimateMode = "keep"
+	// Averages float64 and vector variables over the rows of each group.
+	// Other variables take the value of the first row.
+	DecimateMean DecimateMode = "mean"
+)
+
+// Returns a new data frame with one row for every factor rows. The last
+// group may have fewer than factor rows.
+func (df *DataFrame) Decimate(factor int, mode DecimateMode) (*DataFrame, error) {
+
+	if factor < 1 {
+		return nil, fmt.Errorf("Decimation factor must be positive, got %d.", factor)
+	}
+	groups := make([][]int, 0, df.N()/factor+1)
+	for start := 0; start < df.N(); start += factor {
+		end := start + factor
+		if end > df.N() {
+			end = df.N()
+		}
+		groups = append(groups, rowRange(start, end))
+	}
+	return df.combineRows(groups, mode, "")
+}
+
+// Returns a new data frame with one row per period of the time variable.
+// Rows must be in time order; a group starts at the first row of each
+// period. The time of a group is the time of its first row.
+func (df *DataFrame) Downsample(timeVar string, period time.Duration, mode DecimateMode) (*DataFrame, error) {
+
+	if period <= 0 {
+		return nil, fmt.Errorf("Period must be positive, got %s.", period)
+	}
+	times, err := df.timeColumn(timeVar)
+	if err != nil {
+		return nil, err
+	}
+	p := period.Seconds()
+	groups := make([][]int, 0)
+	var bucket float64
+	for i, t := range times {
+		b := math.Floor(t / p)
+		if i > 0 && b < bucket {
+			return nil, fmt.Errorf("In frame %d, variable [%s] is not in time order.", i, timeVar)
+		}
+		if i == 0 || b != bucket {
+			groups = append(groups, []int{})
+			bucket = b
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], i)
+	}
+	return df.combineRows(groups, mode, timeVar)
+}
+
+// Returns a transform that decimates every data frame, see DataFrame.Decimate.
+func Decimate(factor int, mode DecimateMode) Transform {
+
+	return func(df *DataFrame) (*DataFrame, error) {
+		return df.Decimate(factor, mode)
+	}
+}
+
+// Returns a transform that downsamples every data frame, see
+// DataFrame.Downsample.
+func Downsample(timeVar string, period time.Duration, mode DecimateMode) Transform {
+
+	return func(df *DataFrame) (*DataFrame, error) {
+		return df.Downsample(timeVar, period, mode)
+	}
+}
+
+func rowRange(start, end int) []int {
+
+	rows := make([]int, end-start)
+	for i := range rows {
+		rows[i] = start + i
+	}
+	return rows
+}
+
+// Combines each group of rows into a single row. The keep variable, if
+// any, always takes the value of the first row.
+func (df *DataFrame) combineRows(groups [][]int, mode DecimateMode, keep string) (*DataFrame, error) {
+
+	if mode != DecimateKeep && mode != DecimateMean {
+		return nil, fmt.Errorf("Unknown decimation mode [%s].", mode)
+	}
+	out := df.View().Filter(func(int) bool { return false }).Materialize()
+	out.Data = make([][]interface{}, 0, len(groups))
+	for _, g := range groups {
+		row := make([]interface{}, len(df.VarNames))
+		copy(row, df.Data[g[0]])
+		if mode == DecimateMean {
+			for j, name := range df.VarNames {
+				if name == keep {
+					continue
+				}
+				v, err := df.meanCell(g, j)
+				if err != nil {
+					return nil, fmt.Errorf("In frame %d, variable [%s]: %s", g[0], name, err)
+				}
+				row[j] = v
+			}
+		}
+		out.Data = append(out.Data, row)
+	}
+	return out, nil
+}
+
+// Returns the mean of a float64 or vector variable over the rows, ignoring
+// nil values. Returns the value of the first row for other types.
+func (df *DataFrame) meanCell(rows []int, j int) (interface{}, error) {
+
+	var sum []float64
+	var n float64
+	var scalar bool
+	for _, i := range rows {
+		switch x := df.Data[i][j].(type) {
+		case nil:
+			continue
+		case float64:
+			if sum == nil {
+				sum = make([]float64, 1)
+				scalar = true
+			}
+			sum[0] += x
+		case []float64, []interface{}:
+			vec, err := toFloat64Slice(x)
+			if err != nil {
+				return nil, err
+			}
+			if sum == nil {
+				sum = make([]float64, len(vec))
+			}
+			if len(vec) != len(sum) {
+				return nil, fmt.Errorf("vector dimensions %d and %d don't match.", len(sum), len(vec))
+			}
+			for k, f := range vec {
+				sum[k] += f
+			}
+		default:
+			return df.Data[rows[0]][j], nil
+		}
+		n++
+	}
+	if sum == nil {
+		return nil, nil
+	}
+	for k := range sum {
+		sum[k] /= n
+	}
+	if scalar {
+		return sum[0], nil
+	}
+	return sum, nil
+}
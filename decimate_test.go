@@ -0,0 +1,85 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gonum/floats"
+)
+
+func TestDecimate(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+
+	kept, e := df.Decimate(4, DecimateKeep)
+	CheckError(t, e)
+	if kept.N() != 2 {
+		t.Fatalf("expected 2 rows, got %d.", kept.N())
+	}
+	if v, _ := kept.Float64Slice(1, "acceleration"); v[0] != 1.7 {
+		t.Fatalf("expected row 4, got %v.", v)
+	}
+
+	mean, e := df.Decimate(3, DecimateMean)
+	CheckError(t, e)
+	if mean.N() != 2 {
+		t.Fatalf("expected 2 rows, got %d.", mean.N())
+	}
+	v, e := mean.Float64Slice(1, "wifi", "acceleration")
+	CheckError(t, e)
+	want := []float64{(-42.9 - 42.764 - 42.209) / 3, (-40.11 - 39.98 - 39.6) / 3, 1.7}
+	if !floats.EqualApprox(v, want, 1e-9) {
+		t.Fatalf("expected %v, got %v.", want, v)
+	}
+	if room, _ := mean.String(1, "room"); room != "DINING" {
+		t.Fatalf("unexpected room %s.", room)
+	}
+	if df.N() != 6 {
+		t.Fatalf("Decimate must not modify the frame.")
+	}
+	if _, e = df.Decimate(0, DecimateKeep); e == nil {
+		t.Fatalf("expected error for invalid factor.")
+	}
+}
+
+func TestDownsample(t *testing.T) {
+
+	df := Empty("t", "x")
+	for i, ts := range []float64{0, 0.4, 0.9, 1.0, 1.2, 3.5} {
+		df.Data = append(df.Data, []interface{}{ts, float64(i)})
+	}
+	out, e := df.Downsample("t", time.Second, DecimateMean)
+	CheckError(t, e)
+	if out.N() != 3 {
+		t.Fatalf("expected 3 rows, got %d.", out.N())
+	}
+	for i, want := range [][]float64{{0, 1}, {1, 3.5}, {3.5, 5}} {
+		v, _ := out.Float64Slice(i, "t", "x")
+		if math.Abs(v[0]-want[0]) > 1e-9 || math.Abs(v[1]-want[1]) > 1e-9 {
+			t.Fatalf("row %d: expected %v, got %v.", i, want, v)
+		}
+	}
+
+	// RFC 3339 times.
+	df = Empty("t", "x")
+	for i, ts := range []string{"2014-01-01T00:00:00Z", "2014-01-01T00:00:30Z", "2014-01-01T00:01:10Z"} {
+		df.Data = append(df.Data, []interface{}{ts, float64(i)})
+	}
+	out, e = df.Downsample("t", time.Minute, DecimateKeep)
+	CheckError(t, e)
+	if out.N() != 2 {
+		t.Fatalf("expected 2 rows, got %d.", out.N())
+	}
+	df.Data[0][0], df.Data[2][0] = df.Data[2][0], df.Data[0][0]
+	if _, e = df.Downsample("t", time.Minute, DecimateKeep); e == nil {
+		t.Fatalf("expected error for unordered times.")
+	}
+}
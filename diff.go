@@ -0,0 +1,120 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+)
+
+// Status of a file in a data set diff.
+const (
+	FileAdded    = "added"
+	FileRemoved  = "removed"
+	FileModified = "modified"
+)
+
+// A file that differs between two data sets.
+type FileChange struct {
+	// File name relative to the data set path.
+	File string
+	// One of FileAdded, FileRemoved, or FileModified.
+	Status string
+	// Content hashes, empty when the file doesn't exist in the data set.
+	OldSHA256 string
+	NewSHA256 string
+	// Number of rows in each data set.
+	OldRows int
+	NewRows int
+}
+
+// Differences between two data sets, see DiffDataSets.
+type DataSetDiff struct {
+	// Changed files in the order of the new data set, followed by removed
+	// files in the order of the old data set.
+	Changes []FileChange
+	// Number of files with the same content.
+	Unchanged int
+	// Net change in the number of rows.
+	RowDelta int
+}
+
+// Compares data set a, the old version, with data set b. Files are matched
+// by name and compared by content hash. Only files that changed are read to
+// count rows.
+func DiffDataSets(a, b *DataSet) (*DataSetDiff, error) {
+
+	ha, err := a.FileHashes()
+	if err != nil {
+		return nil, err
+	}
+	hb, err := b.FileHashes()
+	if err != nil {
+		return nil, err
+	}
+	old := make(map[string]FileHash, len(ha))
+	for _, h := range ha {
+		old[h.File] = h
+	}
+	diff := &DataSetDiff{Changes: make([]FileChange, 0)}
+	seen := make(map[string]bool, len(hb))
+	for _, h := range hb {
+		seen[h.File] = true
+		o, ok := old[h.File]
+		switch {
+		case !ok:
+			diff.Changes = append(diff.Changes, FileChange{File: h.File, Status: FileAdded, NewSHA256: h.SHA256})
+		case o.SHA256 != h.SHA256:
+			diff.Changes = append(diff.Changes, FileChange{File: h.File, Status: FileModified,
+				OldSHA256: o.SHA256, NewSHA256: h.SHA256})
+		default:
+			diff.Unchanged++
+		}
+	}
+	for _, h := range ha {
+		if !seen[h.File] {
+			diff.Changes = append(diff.Changes, FileChange{File: h.File, Status: FileRemoved, OldSHA256: h.SHA256})
+		}
+	}
+
+	for i := range diff.Changes {
+		c := &diff.Changes[i]
+		if c.Status != FileAdded {
+			if c.OldRows, err = a.countRows(c.File); err != nil {
+				return nil, err
+			}
+		}
+		if c.Status != FileRemoved {
+			if c.NewRows, err = b.countRows(c.File); err != nil {
+				return nil, err
+			}
+		}
+		diff.RowDelta += c.NewRows - c.OldRows
+	}
+	return diff, nil
+}
+
+// Returns the number of rows in a data set file.
+func (ds *DataSet) countRows(fn string) (int, error) {
+
+	df, err := ds.readFile(ds.Path + string(os.PathSeparator) + fn)
+	if err != nil {
+		return 0, err
+	}
+	return df.N(), nil
+}
+
+// Returns a report with one line per changed file.
+func (d *DataSetDiff) String() string {
+
+	var buf bytes.Buffer
+	for _, c := range d.Changes {
+		fmt.Fprintf(&buf, "%-8s %s rows %d -> %d\n", c.Status, c.File, c.OldRows, c.NewRows)
+	}
+	fmt.Fprintf(&buf, "%d changed, %d unchanged, %+d rows\n", len(d.Changes), d.Unchanged, d.RowDelta)
+	return buf.String()
+}
@@ -0,0 +1,61 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiffDataSets(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-diff")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+
+	write := func(sub, name string, df *DataFrame) {
+		CheckError(t, os.MkdirAll(filepath.Join(dir, sub), 0755))
+		CheckError(t, df.WriteDataFrameFile(filepath.Join(dir, sub, name)))
+	}
+	write("v1", "a.json", synthFrame(10, 2, 1))
+	write("v1", "b.json", synthFrame(10, 2, 2))
+	write("v1", "c.json", synthFrame(10, 2, 3))
+	old := &DataSet{Path: filepath.Join(dir, "v1"), Files: []string{"a.json", "b.json", "c.json"}}
+
+	diff, e := DiffDataSets(old, old)
+	CheckError(t, e)
+	if len(diff.Changes) != 0 || diff.Unchanged != 3 || diff.RowDelta != 0 {
+		t.Fatalf("unexpected diff %v.", diff)
+	}
+
+	// Same a, modified b, removed c, added d.
+	write("v2", "a.json", synthFrame(10, 2, 1))
+	write("v2", "b.json", synthFrame(15, 2, 2))
+	write("v2", "d.json", synthFrame(4, 2, 4))
+	updated := &DataSet{Path: filepath.Join(dir, "v2"), Files: []string{"a.json", "b.json", "d.json"}}
+
+	diff, e = DiffDataSets(old, updated)
+	CheckError(t, e)
+	t.Log("\n" + diff.String())
+	if len(diff.Changes) != 3 || diff.Unchanged != 1 {
+		t.Fatalf("unexpected diff %v.", diff)
+	}
+	want := []struct {
+		file, status string
+		rows         int
+	}{{"b.json", FileModified, 5}, {"d.json", FileAdded, 4}, {"c.json", FileRemoved, -10}}
+	for i, w := range want {
+		c := diff.Changes[i]
+		if c.File != w.file || c.Status != w.status || c.NewRows-c.OldRows != w.rows {
+			t.Fatalf("change %d: expected %v, got %+v.", i, w, c)
+		}
+	}
+	if diff.RowDelta != -1 {
+		t.Fatalf("expected row delta -1, got %d.", diff.RowDelta)
+	}
+}
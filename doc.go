@@ -35,5 +35,64 @@ DataSet
 A DataSet is a collection of DataFrame files. All files must have the same schema.
 The API provides methods to iterate over the DataSet which hides teh details about files from
 the end user.
+
+Files can be listed explicitly or with glob patterns. Data files in a directory, including
+its subdirectories, can be added with "dir". Patterns and directories are resolved in sorted
+order when the DataSet file is read:
+
+  path: data
+  files:
+    - "2013-*.json"
+  dir: sessions
+
+The expected dimension of vector variables can be declared in the DataSet file. Vectors
+that don't match are padded, truncated, or rejected according to the policy:
+
+  path: data
+  files:
+    - file1.json
+  dims:
+    wifi: {dim: 3, policy: pad, pad: -100}
+
+Properties shared by all the files, such as the sensor model or the sampling rate, can be
+declared once in the DataSet file. They are merged into the properties of every DataFrame
+when it is read; values defined in the DataFrame file take precedence:
+
+  properties:
+    sensor: ak-100
+    sample_rate: "50"
+
+Decoded DataFrames can be cached next to the source files by setting "cache: true". Cache
+files are read instead of parsing the source when the source has not changed, as determined by
+its size, modification time, and content hash, and the decode options (encoding, csv, influx,
+and limits) are the same. Cache files are Arrow IPC files, see WriteArrow.
+
+Files written with WriteBinaryFile use a compact binary format that is much faster to read
+than JSON. DataSets detect the format of each file so JSON and binary files can be mixed.
+
+Files with extension ".gz", for example "session.json.gz" or "session.csv.gz", are decompressed
+when read, and compressed when written with WriteDataFrameFile.
+
+JSON files sometimes store numbers as strings. Values are read as they are written; string
+values of variables that are mostly numbers and can't be parsed as numbers are listed in the
+coercion report, see CoercionReport. Setting "coerce_numbers: true" converts the numeric strings
+of these variables to float64.
+
+Files are read as UTF-8 and a leading byte order mark is removed. Legacy files in ISO-8859-1
+are converted to UTF-8 by setting "encoding: latin-1".
+
+JSON has no NaN or infinity so sources often encode them as strings such as "NaN" or
+"-Inf". Float64Slice, iterators, and channels read these strings as float64 values.
+WriteDataFrame writes them as {"float64": "NaN"} which the readers restore as float64 cells. What
+happens with NaN and infinite values is set with "non_finite"; the policy is one of pass
+(default), replace, drop (the row is skipped), or error:
+
+  non_finite: {policy: replace, value: 0}
+
+Missing values are written as null and read as nil. By default Float64Slice returns an error
+for a missing value. The "na" policy is one of error (default), drop (the row is skipped),
+mean (the variable mean is used), or fill (a constant is used):
+
+  na: {policy: mean}
 */
 package dataframe
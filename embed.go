@@ -0,0 +1,191 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Prepares a data frame declared as a Go literal for use. It is called by the
+// source code generated by WriteGoSource.
+func Embed(df *DataFrame) *DataFrame {
+
+	if df.Data == nil {
+		df.Data = make([][]interface{}, 0)
+	}
+	df.resetVarMap()
+	return df
+}
+
+// Writes a Go source file in package pkg that declares the data frame as a
+// variable named varName. Used by the df-embed generator to embed small
+// frames, such as lookup tables and test fixtures, in Go programs. Supported
+// cell types are nil, float64, string, bool, []float64, and []interface{}.
+func (df *DataFrame) WriteGoSource(w io.Writer, pkg, varName string) error {
+
+	if err := df.checkWrite(); err != nil {
+		return err
+	}
+
+	g := &goWriter{}
+	g.printf("var %s = dataframe.Embed(&dataframe.DataFrame{\n", varName)
+	g.printf("Description: %s,\n", strconv.Quote(df.Description))
+	g.printf("BatchID: %s,\n", strconv.Quote(df.BatchID))
+	g.printf("VarNames: %s,\n", goStrings(df.VarNames))
+	g.printf("Data: [][]interface{}{\n")
+	for i, row := range df.Data {
+		g.printf("{")
+		for j, v := range row {
+			if j > 0 {
+				g.printf(", ")
+			}
+			if err := g.value(v); err != nil {
+				return fmt.Errorf("In frame %d, variable [%s]: %s", i, df.VarNames[j], err)
+			}
+		}
+		g.printf("},\n")
+	}
+	g.printf("},\n")
+	if len(df.VarUnits) > 0 {
+		g.printf("VarUnits: %s,\n", goStringMap(df.VarUnits))
+	}
+	if len(df.Properties) > 0 {
+		g.printf("Properties: %s,\n", goStringMap(df.Properties))
+	}
+	g.printf("})\n")
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by df-embed. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	if g.math {
+		src.WriteString("import (\n\t\"math\"\n\n\t\"github.com/akualab/dataframe\"\n)\n\n")
+	} else {
+		src.WriteString("import \"github.com/akualab/dataframe\"\n\n")
+	}
+	src.Write(g.buf.Bytes())
+	b, err := format.Source(src.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// Writes the Go source file, see WriteGoSource, to file fn. The file is
+// replaced atomically so a failed run never leaves a partial source file
+// that breaks the build.
+func (df *DataFrame) WriteGoSourceFile(fn, pkg, varName string) error {
+
+	return writeFileAtomic(fn, 0644, func(w io.Writer) error {
+		return df.WriteGoSource(w, pkg, varName)
+	})
+}
+
+type goWriter struct {
+	buf bytes.Buffer
+	// true if the source uses the math package.
+	math bool
+}
+
+func (g *goWriter) printf(format string, args ...interface{}) {
+
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *goWriter) value(v interface{}) error {
+
+	switch x := v.(type) {
+	case nil:
+		g.printf("nil")
+	case float64:
+		g.printf("%s", g.float(x))
+	case string:
+		g.printf("%s", strconv.Quote(x))
+	case bool:
+		g.printf("%t", x)
+	case []float64:
+		g.printf("[]float64{")
+		for i, f := range x {
+			if i > 0 {
+				g.printf(", ")
+			}
+			g.printf("%s", g.float(f))
+		}
+		g.printf("}")
+	case []interface{}:
+		g.printf("[]interface{}{")
+		for i, e := range x {
+			if i > 0 {
+				g.printf(", ")
+			}
+			if err := g.value(e); err != nil {
+				return err
+			}
+		}
+		g.printf("}")
+	default:
+		return fmt.Errorf("unsupported type [%s].", reflect.TypeOf(v).String())
+	}
+	return nil
+}
+
+// Returns a float64 literal. Integral values get a decimal point so that they
+// are not typed as int when stored in an interface{}.
+func (g *goWriter) float(x float64) string {
+
+	switch {
+	case math.IsNaN(x):
+		g.math = true
+		return "math.NaN()"
+	case math.IsInf(x, 1):
+		g.math = true
+		return "math.Inf(1)"
+	case math.IsInf(x, -1):
+		g.math = true
+		return "math.Inf(-1)"
+	}
+	s := strconv.FormatFloat(x, 'g', -1, 64)
+	if !bytes.ContainsAny([]byte(s), ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+func goStrings(values []string) string {
+
+	var buf bytes.Buffer
+	buf.WriteString("[]string{")
+	for i, s := range values {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(strconv.Quote(s))
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+func goStringMap(m map[string]string) string {
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var buf bytes.Buffer
+	buf.WriteString("map[string]string{\n")
+	for _, k := range keys {
+		fmt.Fprintf(&buf, "%s: %s,\n", strconv.Quote(k), strconv.Quote(m[k]))
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
@@ -0,0 +1,85 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteGoSource(t *testing.T) {
+
+	df := Empty("room", "wifi", "acceleration", "ok")
+	df.Description = "rooms"
+	df.VarUnits = map[string]string{"acceleration": "m/s^2"}
+	df.Data = append(df.Data,
+		[]interface{}{"BED5", []interface{}{-56.0, -83.0}, 1.0, true},
+		[]interface{}{"DINING \"A\"", []float64{math.NaN(), -80.5}, nil, false},
+	)
+
+	var buf bytes.Buffer
+	CheckError(t, df.WriteGoSource(&buf, "rooms", "Rooms"))
+	src := buf.String()
+	t.Log(src)
+	if _, e := parser.ParseFile(token.NewFileSet(), "rooms.go", src, 0); e != nil {
+		t.Fatalf("generated source doesn't parse: %s", e)
+	}
+	for _, s := range []string{
+		"package rooms",
+		"var Rooms = dataframe.Embed(",
+		"[]interface{}{-56.0, -83.0}",
+		"math.NaN()",
+		`"DINING \"A\""`,
+		`"acceleration": "m/s^2"`,
+	} {
+		if !strings.Contains(src, s) {
+			t.Fatalf("generated source doesn't contain %s.", s)
+		}
+	}
+
+	dir, e := ioutil.TempDir("", "dataframe-embed")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	fn := filepath.Join(dir, "rooms_frame.go")
+	CheckError(t, df.WriteGoSourceFile(fn, "rooms", "Rooms"))
+	b, e := ioutil.ReadFile(fn)
+	CheckError(t, e)
+	if string(b) != src {
+		t.Fatalf("file content differs from WriteGoSource output.")
+	}
+
+	df.Data[0][2] = int(1)
+	if e := df.WriteGoSource(&buf, "rooms", "Rooms"); e == nil {
+		t.Fatalf("expected error for unsupported type.")
+	}
+	// A failed write leaves the previous file.
+	if e := df.WriteGoSourceFile(fn, "rooms", "Rooms"); e == nil {
+		t.Fatalf("expected error for unsupported type.")
+	}
+	if b, e = ioutil.ReadFile(fn); e != nil || string(b) != src {
+		t.Fatalf("file was modified by a failed write.")
+	}
+}
+
+func TestEmbed(t *testing.T) {
+
+	df := Embed(&DataFrame{
+		VarNames: []string{"room", "acceleration"},
+		Data:     [][]interface{}{{"BED5", 1.3}},
+	})
+	v, e := df.Float64Slice(0, "acceleration")
+	CheckError(t, e)
+	if v[0] != 1.3 {
+		t.Fatalf("unexpected value %v.", v)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Encoding is the character set of a text input file.
+type Encoding string
+
+const (
+	// UTF-8, the default.
+	EncodingUTF8 Encoding = "utf-8"
+	// ISO-8859-1, converted to UTF-8 when read.
+	EncodingLatin1 Encoding = "latin-1"
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// Returns a reader that converts text in the given encoding to UTF-8 and
+// strips a leading UTF-8 byte order mark. The encoding names are case
+// insensitive; "utf8", "latin1", and "iso-8859-1" are also accepted. An
+// empty encoding is UTF-8.
+func NewDecodingReader(r io.Reader, enc Encoding) (io.Reader, error) {
+
+	switch strings.ToLower(string(enc)) {
+	case "", "utf-8", "utf8":
+		return stripBOM(r), nil
+	case "latin-1", "latin1", "iso-8859-1":
+		return &latin1Reader{r: stripBOM(r)}, nil
+	}
+	return nil, fmt.Errorf("Unknown encoding [%s].", enc)
+}
+
+// Returns a reader without the leading UTF-8 byte order mark, if any.
+func stripBOM(r io.Reader) io.Reader {
+
+	br := bufio.NewReader(r)
+	if b, err := br.Peek(len(utf8BOM)); err == nil && bytes.Equal(b, utf8BOM) {
+		br.Discard(len(utf8BOM))
+	}
+	return br
+}
+
+// Converts ISO-8859-1 to UTF-8. Each byte is a code point.
+type latin1Reader struct {
+	r   io.Reader
+	buf []byte
+	out []byte
+}
+
+func (lr *latin1Reader) Read(p []byte) (int, error) {
+
+	if len(lr.out) == 0 {
+		if cap(lr.buf) < len(p) {
+			lr.buf = make([]byte, len(p))
+		}
+		n, err := lr.r.Read(lr.buf[:len(p)])
+		if n == 0 {
+			return 0, err
+		}
+		out := lr.out[:0]
+		for _, b := range lr.buf[:n] {
+			if b < utf8.RuneSelf {
+				out = append(out, b)
+				continue
+			}
+			var enc [2]byte
+			utf8.EncodeRune(enc[:], rune(b))
+			out = append(out, enc[:]...)
+		}
+		lr.out = out
+	}
+	n := copy(p, lr.out)
+	lr.out = lr.out[n:]
+	return n, nil
+}
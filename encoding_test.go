@@ -0,0 +1,55 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestEncoding(t *testing.T) {
+
+	// Byte order mark in JSON and CSV.
+	df, e := ReadDataFrame(strings.NewReader("\xEF\xBB\xBF" + file1))
+	CheckError(t, e)
+	if df.N() != 6 {
+		t.Fatalf("expected 6 rows, got %d.", df.N())
+	}
+	df, e = ReadDataFrameLimits(strings.NewReader("\xEF\xBB\xBF"+file1), ReadLimits{})
+	CheckError(t, e)
+	df, e = ReadCSV(strings.NewReader("\xEF\xBB\xBFroom,x\nBED5,1\n"), CSVOptions{})
+	CheckError(t, e)
+	if df.VarNames[0] != "room" {
+		t.Fatalf("byte order mark not removed from header: %q.", df.VarNames[0])
+	}
+
+	// Latin-1 conversion, one byte at a time.
+	r, e := NewDecodingReader(iotest.OneByteReader(strings.NewReader("caf\xE9 \xFCber")), EncodingLatin1)
+	CheckError(t, e)
+	b, e := ioutil.ReadAll(r)
+	CheckError(t, e)
+	if string(b) != "café über" {
+		t.Fatalf("unexpected conversion %q.", b)
+	}
+	if _, e = NewDecodingReader(r, "ebcdic"); e == nil {
+		t.Fatalf("expected error for unknown encoding.")
+	}
+
+	dir, e := ioutil.TempDir("", "dataframe-encoding")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	CheckError(t, ioutil.WriteFile(filepath.Join(dir, "legacy.csv"), []byte("room,x\nSAL\xD3N,1\n"), 0644))
+	ds := &DataSet{Path: dir, Files: []string{"legacy.csv"}, Encoding: "ISO-8859-1"}
+	df, e = ds.Next()
+	CheckError(t, e)
+	if s, _ := df.String(0, "room"); s != "SALÓN" {
+		t.Fatalf("expected SALÓN, got %q.", s)
+	}
+}
@@ -0,0 +1,424 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"text/tabwriter"
+)
+
+// Adds model predictions as a new variable. Predictions must be a
+// []float64, for regression, or a []string, for classification, with one
+// value per row.
+func (df *DataFrame) AttachPredictions(name string, preds interface{}) error {
+
+	var values []interface{}
+	switch p := preds.(type) {
+	case []float64:
+		values = make([]interface{}, len(p))
+		for i, v := range p {
+			values[i] = v
+		}
+	case []string:
+		values = make([]interface{}, len(p))
+		for i, v := range p {
+			values[i] = v
+		}
+	default:
+		return fmt.Errorf("Predictions of type [%s] are not supported. Must be []float64 or []string.",
+			reflect.TypeOf(preds).String())
+	}
+	if len(values) != df.N() {
+		return fmt.Errorf("Number of predictions (%d) does not match number of rows (%d).", len(values), df.N())
+	}
+	return df.addVar(name, values)
+}
+
+// Returns the root mean squared error between two float64 variables, for
+// example a target and a prediction. Rows where either value is nil are
+// skipped.
+func (df *DataFrame) RMSE(target, pred string) (float64, error) {
+
+	indices, err := df.indices(target, pred)
+	if err != nil {
+		return 0, err
+	}
+	var ss float64
+	var n int
+	for i, row := range df.Data {
+		a, b := row[indices[0]], row[indices[1]]
+		if a == nil || b == nil {
+			continue
+		}
+		x, ok := a.(float64)
+		if !ok {
+			return 0, colTypeError(i, target, a, "float64")
+		}
+		y, ok := b.(float64)
+		if !ok {
+			return 0, colTypeError(i, pred, b, "float64")
+		}
+		ss += (x - y) * (x - y)
+		n++
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("Variables [%s] and [%s] have no values.", target, pred)
+	}
+	return math.Sqrt(ss / float64(n)), nil
+}
+
+// Counts of predicted labels for each true label.
+type ConfusionMatrix struct {
+	// Labels found in the target or the prediction, sorted.
+	Labels []string
+	// Counts[i][j] is the number of rows with target Labels[i] and
+	// prediction Labels[j].
+	Counts [][]int
+}
+
+// Returns the confusion matrix of two string variables with the actual and
+// the predicted labels. Rows where either value is nil are skipped.
+func (df *DataFrame) ConfusionMatrix(actual, predicted string) (*ConfusionMatrix, error) {
+
+	indices, err := df.indices(actual, predicted)
+	if err != nil {
+		return nil, err
+	}
+	type pair struct{ t, p string }
+	pairs := make([]pair, 0, df.N())
+	seen := make(map[string]bool)
+	for i, row := range df.Data {
+		a, b := row[indices[0]], row[indices[1]]
+		if a == nil || b == nil {
+			continue
+		}
+		t, ok := a.(string)
+		if !ok {
+			return nil, colTypeError(i, actual, a, "string")
+		}
+		p, ok := b.(string)
+		if !ok {
+			return nil, colTypeError(i, predicted, b, "string")
+		}
+		pairs = append(pairs, pair{t, p})
+		seen[t], seen[p] = true, true
+	}
+	cm := &ConfusionMatrix{Labels: make([]string, 0, len(seen))}
+	for l := range seen {
+		cm.Labels = append(cm.Labels, l)
+	}
+	sort.Strings(cm.Labels)
+	index := make(map[string]int, len(cm.Labels))
+	cm.Counts = make([][]int, len(cm.Labels))
+	for i, l := range cm.Labels {
+		index[l] = i
+		cm.Counts[i] = make([]int, len(cm.Labels))
+	}
+	for _, p := range pairs {
+		cm.Counts[index[p.t]][index[p.p]]++
+	}
+	return cm, nil
+}
+
+// Returns the number of rows in the matrix.
+func (cm *ConfusionMatrix) Total() int {
+
+	var n int
+	for _, row := range cm.Counts {
+		for _, c := range row {
+			n += c
+		}
+	}
+	return n
+}
+
+// Returns the fraction of rows where the prediction equals the target, or
+// NaN if the matrix is empty.
+func (cm *ConfusionMatrix) Accuracy() float64 {
+
+	var correct int
+	for i := range cm.Counts {
+		correct += cm.Counts[i][i]
+	}
+	if cm.Total() == 0 {
+		return math.NaN()
+	}
+	return float64(correct) / float64(cm.Total())
+}
+
+// Name of the variable with the actual labels in ConfusionMatrix.DataFrame.
+const ConfusionActual = "actual"
+
+// Returns the matrix as a data frame with one row per actual label. The
+// first variable is ConfusionActual and the other variables, one per
+// predicted label, have the counts.
+func (cm *ConfusionMatrix) DataFrame() (*DataFrame, error) {
+
+	names := append([]string{ConfusionActual}, cm.Labels...)
+	if err := checkUnique(names); err != nil {
+		return nil, err
+	}
+	df := Empty(names...)
+	for i, l := range cm.Labels {
+		row := make([]interface{}, 0, len(names))
+		row = append(row, l)
+		for _, c := range cm.Counts[i] {
+			row = append(row, float64(c))
+		}
+		df.Data = append(df.Data, row)
+	}
+	return df, nil
+}
+
+// Precision, recall and F1 score of a class.
+type ClassMetrics struct {
+	Label     string
+	Precision float64
+	Recall    float64
+	F1        float64
+	// Number of rows with this actual label.
+	Support int
+}
+
+// Per-class metrics of a classifier.
+type ClassificationReport struct {
+	Classes  []ClassMetrics
+	Accuracy float64
+	// Unweighted means of the class metrics.
+	MacroPrecision float64
+	MacroRecall    float64
+	MacroF1        float64
+}
+
+// Returns the precision, recall and F1 score of each label. Metrics with a
+// zero denominator, for example the precision of a label that is never
+// predicted, are zero.
+func (cm *ConfusionMatrix) Report() *ClassificationReport {
+
+	r := &ClassificationReport{Classes: make([]ClassMetrics, len(cm.Labels)), Accuracy: cm.Accuracy()}
+	for i, l := range cm.Labels {
+		var actual, predicted int
+		for j := range cm.Labels {
+			actual += cm.Counts[i][j]
+			predicted += cm.Counts[j][i]
+		}
+		c := ClassMetrics{Label: l, Support: actual}
+		tp := float64(cm.Counts[i][i])
+		if predicted > 0 {
+			c.Precision = tp / float64(predicted)
+		}
+		if actual > 0 {
+			c.Recall = tp / float64(actual)
+		}
+		if c.Precision+c.Recall > 0 {
+			c.F1 = 2 * c.Precision * c.Recall / (c.Precision + c.Recall)
+		}
+		r.Classes[i] = c
+		r.MacroPrecision += c.Precision
+		r.MacroRecall += c.Recall
+		r.MacroF1 += c.F1
+	}
+	if n := float64(len(cm.Labels)); n > 0 {
+		r.MacroPrecision /= n
+		r.MacroRecall /= n
+		r.MacroF1 /= n
+	}
+	return r
+}
+
+// Returns the report as a table.
+func (r *ClassificationReport) String() string {
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "label\tprecision\trecall\tf1\tsupport")
+	var support int
+	for _, c := range r.Classes {
+		fmt.Fprintf(w, "%s\t%.4f\t%.4f\t%.4f\t%d\n", c.Label, c.Precision, c.Recall, c.F1, c.Support)
+		support += c.Support
+	}
+	fmt.Fprintf(w, "macro avg\t%.4f\t%.4f\t%.4f\t%d\n", r.MacroPrecision, r.MacroRecall, r.MacroF1, support)
+	fmt.Fprintf(w, "accuracy\t\t\t%.4f\t%d\n", r.Accuracy, support)
+	w.Flush()
+	return buf.String()
+}
+
+// Returns the matrix as a table with actual labels in rows and predicted
+// labels in columns.
+func (cm *ConfusionMatrix) String() string {
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(w, "\t")
+	for _, l := range cm.Labels {
+		fmt.Fprintf(w, "%s\t", l)
+	}
+	fmt.Fprintln(w)
+	for i, l := range cm.Labels {
+		fmt.Fprintf(w, "%s\t", l)
+		for _, c := range cm.Counts[i] {
+			fmt.Fprintf(w, "%d\t", c)
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// Returns the accuracy of a prediction, see ConfusionMatrix.
+func (df *DataFrame) Accuracy(target, pred string) (float64, error) {
+
+	cm, err := df.ConfusionMatrix(target, pred)
+	if err != nil {
+		return 0, err
+	}
+	return cm.Accuracy(), nil
+}
+
+// Returns the classification report of a prediction, see ConfusionMatrix.
+func (df *DataFrame) ClassificationReport(actual, predicted string) (*ClassificationReport, error) {
+
+	cm, err := df.ConfusionMatrix(actual, predicted)
+	if err != nil {
+		return nil, err
+	}
+	return cm.Report(), nil
+}
+
+// Names of the variables of the frames returned by ROC and PR.
+const (
+	CurveThreshold = "threshold"
+	CurveTPR       = "tpr"
+	CurveFPR       = "fpr"
+	CurvePrecision = "precision"
+	CurveRecall    = "recall"
+)
+
+// Returns the receiver operating characteristic of a score. Rows where the
+// label variable equals positive are positive examples. Returns a data frame
+// with variables CurveThreshold, CurveTPR and CurveFPR, with one row per
+// distinct score in decreasing order, and the area under the curve. A row
+// is predicted positive when its score is greater than or equal to the
+// threshold. The first point, (0, 0), has a nil threshold. Rows where
+// either value is nil are skipped.
+func (df *DataFrame) ROC(label, score, positive string) (*DataFrame, float64, error) {
+
+	points, pos, neg, err := df.scorePoints(label, score, positive)
+	if err != nil {
+		return nil, 0, err
+	}
+	if pos == 0 || neg == 0 {
+		return nil, 0, fmt.Errorf("Variable [%s] must have positive and negative examples.", label)
+	}
+	roc := Empty(CurveThreshold, CurveTPR, CurveFPR)
+	roc.Data = append(roc.Data, []interface{}{nil, 0.0, 0.0})
+	var auc, tpr0, fpr0 float64
+	for _, p := range points {
+		tpr := float64(p.tp) / float64(pos)
+		fpr := float64(p.fp) / float64(neg)
+		auc += (fpr - fpr0) * (tpr + tpr0) / 2
+		tpr0, fpr0 = tpr, fpr
+		roc.Data = append(roc.Data, []interface{}{p.threshold, tpr, fpr})
+	}
+	return roc, auc, nil
+}
+
+// Returns the precision-recall curve of a score, see ROC. Returns a data
+// frame with variables CurveThreshold, CurvePrecision and CurveRecall, and
+// the average precision. The first point, with recall 0 and precision 1, has
+// a nil threshold.
+func (df *DataFrame) PR(label, score, positive string) (*DataFrame, float64, error) {
+
+	points, pos, _, err := df.scorePoints(label, score, positive)
+	if err != nil {
+		return nil, 0, err
+	}
+	if pos == 0 {
+		return nil, 0, fmt.Errorf("Variable [%s] has no positive examples.", label)
+	}
+	pr := Empty(CurveThreshold, CurvePrecision, CurveRecall)
+	pr.Data = append(pr.Data, []interface{}{nil, 1.0, 0.0})
+	var ap, recall0 float64
+	for _, p := range points {
+		precision := float64(p.tp) / float64(p.tp+p.fp)
+		recall := float64(p.tp) / float64(pos)
+		ap += (recall - recall0) * precision
+		recall0 = recall
+		pr.Data = append(pr.Data, []interface{}{p.threshold, precision, recall})
+	}
+	return pr, ap, nil
+}
+
+// Counts of true and false positives at a threshold.
+type scorePoint struct {
+	threshold float64
+	tp, fp    int
+}
+
+type scoredRow struct {
+	score    float64
+	positive bool
+}
+
+type byScoreDesc []scoredRow
+
+func (b byScoreDesc) Len() int           { return len(b) }
+func (b byScoreDesc) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byScoreDesc) Less(i, j int) bool { return b[i].score > b[j].score }
+
+// Returns the cumulative counts at each distinct score in decreasing order
+// and the number of positive and negative rows.
+func (df *DataFrame) scorePoints(label, score, positive string) (points []scorePoint, pos, neg int, err error) {
+
+	indices, err := df.indices(label, score)
+	if err != nil {
+		return
+	}
+	rows := make([]scoredRow, 0, df.N())
+	for i, row := range df.Data {
+		a, b := row[indices[0]], row[indices[1]]
+		if a == nil || b == nil {
+			continue
+		}
+		l, ok := a.(string)
+		if !ok {
+			err = colTypeError(i, label, a, "string")
+			return
+		}
+		s, ok := b.(float64)
+		if !ok {
+			err = colTypeError(i, score, b, "float64")
+			return
+		}
+		if math.IsNaN(s) {
+			err = fmt.Errorf("In frame %d, variable [%s] is NaN.", i, score)
+			return
+		}
+		rows = append(rows, scoredRow{s, l == positive})
+		if l == positive {
+			pos++
+		} else {
+			neg++
+		}
+	}
+	sort.Sort(byScoreDesc(rows))
+	var tp, fp int
+	for i, r := range rows {
+		if r.positive {
+			tp++
+		} else {
+			fp++
+		}
+		if i == len(rows)-1 || rows[i+1].score != r.score {
+			points = append(points, scorePoint{r.score, tp, fp})
+		}
+	}
+	return
+}
@@ -0,0 +1,109 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"io/ioutil"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEvaluation(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+
+	CheckError(t, df.AttachPredictions("room_pred", []string{"BED5", "DINING", "BED5", "DINING", "DINING", "BED5"}))
+	cm, e := df.ConfusionMatrix("room", "room_pred")
+	CheckError(t, e)
+	t.Log("\n" + cm.String())
+	if len(cm.Labels) != 2 || cm.Counts[0][0] != 2 || cm.Counts[0][1] != 1 || cm.Counts[1][0] != 1 || cm.Counts[1][1] != 2 {
+		t.Fatalf("unexpected matrix %v.", cm.Counts)
+	}
+	acc, e := df.Accuracy("room", "room_pred")
+	CheckError(t, e)
+	if math.Abs(acc-4.0/6) > 1e-12 {
+		t.Fatalf("expected accuracy 0.667, got %f.", acc)
+	}
+
+	// BED5: precision 2/3, recall 2/3. DINING: same.
+	r, e := df.ClassificationReport("room", "room_pred")
+	CheckError(t, e)
+	t.Log("\n" + r.String())
+	if len(r.Classes) != 2 || r.Classes[0].Label != "BED5" || r.Classes[0].Support != 3 ||
+		math.Abs(r.Classes[0].Precision-2.0/3) > 1e-12 || math.Abs(r.Classes[1].F1-2.0/3) > 1e-12 ||
+		math.Abs(r.MacroF1-2.0/3) > 1e-12 {
+		t.Fatalf("unexpected report %+v.", r)
+	}
+	m, e := cm.DataFrame()
+	CheckError(t, e)
+	want, e := ReadDataFrame(strings.NewReader(`{"var_names":["actual","BED5","DINING"],"data":[["BED5",2,1],["DINING",1,2]]}`))
+	CheckError(t, e)
+	assertSameJSON(t, want, m)
+
+	CheckError(t, df.AttachPredictions("acc_pred", []float64{1.4, 1.4, 1.5, 1.6, 1.7, 1.6}))
+	rmse, e := df.RMSE("acceleration", "acc_pred")
+	CheckError(t, e)
+	if math.Abs(rmse-math.Sqrt(0.05/6)) > 1e-9 {
+		t.Fatalf("unexpected RMSE %f.", rmse)
+	}
+
+	if e = df.AttachPredictions("short", []float64{1}); e == nil {
+		t.Fatalf("expected error for wrong length.")
+	}
+	if e = df.AttachPredictions("ints", []int{1, 2, 3, 4, 5, 6}); e == nil {
+		t.Fatalf("expected error for unsupported type.")
+	}
+	if e = df.AttachPredictions("acc_pred", make([]float64, 6)); e == nil {
+		t.Fatalf("expected error for existing variable.")
+	}
+	if _, e = df.RMSE("room", "acc_pred"); e == nil {
+		t.Fatalf("expected type error.")
+	}
+}
+
+func TestROC(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(`{"var_names": ["label", "score"], "data": [
+  ["fall", 0.9], ["fall", 0.8], ["ok", 0.7], ["fall", 0.6],
+  ["ok", 0.55], ["ok", 0.4], [null, 0.3], ["ok", null]
+]}`))
+	CheckError(t, e)
+
+	roc, auc, e := df.ROC("label", "score", "fall")
+	CheckError(t, e)
+	t.Log(roc.Data)
+	if roc.N() != 7 || roc.Data[0][0] != nil {
+		t.Fatalf("unexpected curve %v.", roc.Data)
+	}
+	if last := roc.Data[6]; last[1] != 1.0 || last[2] != 1.0 {
+		t.Fatalf("curve must end at (1, 1), got %v.", last)
+	}
+	// 8 of 9 positive-negative pairs are ordered correctly.
+	if math.Abs(auc-8.0/9) > 1e-12 {
+		t.Fatalf("expected AUC 0.889, got %f.", auc)
+	}
+
+	pr, ap, e := df.PR("label", "score", "fall")
+	CheckError(t, e)
+	if pr.N() != 7 || pr.Data[3][1] != 2.0/3 {
+		t.Fatalf("unexpected curve %v.", pr.Data)
+	}
+	if want := (1 + 1 + 0.75) / 3; math.Abs(ap-want) > 1e-12 {
+		t.Fatalf("expected average precision %f, got %f.", want, ap)
+	}
+
+	// Write the curve for plotting.
+	CheckError(t, roc.WriteDataFrame(ioutil.Discard))
+
+	if _, _, e = df.ROC("label", "score", "missing"); e == nil {
+		t.Fatalf("expected error without positive examples.")
+	}
+	if _, _, e = df.ROC("score", "label", "fall"); e == nil {
+		t.Fatalf("expected type error.")
+	}
+}
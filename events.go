@@ -0,0 +1,120 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// A StateFunc is a state machine transition function. It returns the state
+// after processing a row of the data frame given the current state. Rows
+// are processed in order, so the data frame must be sorted by time.
+type StateFunc func(df *DataFrame, frame int, state string) (string, error)
+
+// Runs the state machine over the rows and adds a string variable stateVar
+// with the state after each row.
+func (df *DataFrame) RunStates(stateVar, initial string, fn StateFunc) error {
+
+	states, err := df.runStates(initial, fn)
+	if err != nil {
+		return err
+	}
+	values := make([]interface{}, len(states))
+	for i, s := range states {
+		values[i] = s
+	}
+	return df.addVar(stateVar, values)
+}
+
+// Runs the state machine over the rows and returns a data frame with one
+// row per state change with variables "frame" (the row number in df),
+// "from", and "to". If timeVar is not empty, the time of the row is added
+// as the first variable.
+func (df *DataFrame) Events(timeVar, initial string, fn StateFunc) (*DataFrame, error) {
+
+	tidx := -1
+	if timeVar != "" {
+		indices, err := df.indices(timeVar)
+		if err != nil {
+			return nil, err
+		}
+		tidx = indices[0]
+	}
+	states, err := df.runStates(initial, fn)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{"frame", "from", "to"}
+	if tidx >= 0 {
+		names = append([]string{timeVar}, names...)
+	}
+	events := Empty(names...)
+	events.BatchID = df.BatchID
+	events.Properties = copyProperties(df.Properties)
+	prev := initial
+	for i, s := range states {
+		if s == prev {
+			continue
+		}
+		row := []interface{}{float64(i), prev, s}
+		if tidx >= 0 {
+			row = append([]interface{}{df.Data[i][tidx]}, row...)
+		}
+		events.Data = append(events.Data, row)
+		prev = s
+	}
+	return events, nil
+}
+
+func (df *DataFrame) runStates(initial string, fn StateFunc) ([]string, error) {
+
+	states := make([]string, df.N())
+	state := initial
+	for i := range df.Data {
+		s, err := fn(df, i, state)
+		if err != nil {
+			return nil, fmt.Errorf("In frame %d: %s", i, err)
+		}
+		state = s
+		states[i] = state
+	}
+	return states, nil
+}
+
+// Returns a two state machine with hysteresis over a float64 variable. The
+// state changes from off to on when the value crosses the enter threshold
+// and from on to off when it crosses the exit threshold. If enter is
+// greater than exit, values at or above enter turn the state on and values
+// at or below exit turn it off; otherwise the comparisons are reversed.
+// Nil values don't change the state.
+func Hysteresis(name string, enter, exit float64, on, off string) StateFunc {
+
+	rising := enter > exit
+	return func(df *DataFrame, frame int, state string) (string, error) {
+		indices, err := df.indices(name)
+		if err != nil {
+			return state, err
+		}
+		var x float64
+		switch v := df.Data[frame][indices[0]].(type) {
+		case nil:
+			return state, nil
+		case float64:
+			x = v
+		default:
+			return state, fmt.Errorf("variable [%s] is of type [%s]. Must be of type float64.",
+				name, reflect.TypeOf(v).String())
+		}
+		switch {
+		case state != on && ((rising && x >= enter) || (!rising && x <= enter)):
+			return on, nil
+		case state == on && ((rising && x <= exit) || (!rising && x >= exit)):
+			return off, nil
+		}
+		return state, nil
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"testing"
+)
+
+func eventFrame() *DataFrame {
+
+	df := Empty("t", "acceleration")
+	for i, a := range []interface{}{1.0, 1.6, 2.1, 1.8, 1.6, nil, 1.4, 2.2, 1.0} {
+		df.Data = append(df.Data, []interface{}{float64(i), a})
+	}
+	return df
+}
+
+func TestRunStates(t *testing.T) {
+
+	df := eventFrame()
+	CheckError(t, df.RunStates("moving", "still", Hysteresis("acceleration", 2, 1.5, "moving", "still")))
+	want := []string{"still", "still", "moving", "moving", "moving", "moving", "still", "moving", "still"}
+	for i, w := range want {
+		if s, _ := df.String(i, "moving"); s != w {
+			t.Fatalf("row %d: expected %s, got %s.", i, w, s)
+		}
+	}
+}
+
+func TestEvents(t *testing.T) {
+
+	df := eventFrame()
+	events, e := df.Events("t", "still", Hysteresis("acceleration", 2, 1.5, "moving", "still"))
+	CheckError(t, e)
+	if events.N() != 4 {
+		t.Fatalf("expected 4 events, got %d.", events.N())
+	}
+	v, e := events.Float64Slice(1, "t", "frame")
+	CheckError(t, e)
+	if v[0] != 6 || v[1] != 6 {
+		t.Fatalf("unexpected event %v.", events.Data[1])
+	}
+	if s, _ := events.String(1, "to"); s != "still" {
+		t.Fatalf("expected still, got %s.", s)
+	}
+
+	// Falling thresholds.
+	events, e = df.Events("", "high", Hysteresis("acceleration", 1.2, 2.0, "low", "high"))
+	CheckError(t, e)
+	if events.NumVariables() != 3 || events.N() != 3 {
+		t.Fatalf("unexpected events %v.", events.Data)
+	}
+
+	if _, e = df.Events("t", "still", Hysteresis("t2", 2, 1.5, "moving", "still")); e == nil {
+		t.Fatalf("expected error for unknown variable.")
+	}
+}
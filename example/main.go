@@ -21,7 +21,7 @@ func main() {
 	}
 
 	// Get the float variables as a single float64 slice.
-	ch1 := df.GetChanFloat64("chemical_concentrations", "algae")
+	ch1, errc1 := df.Float64SliceChan("chemical_concentrations", "algae")
 
 	// Print slices.
 	var count int
@@ -29,19 +29,25 @@ func main() {
 		fmt.Printf("n: %3d, values: %+v\n", count, v)
 		count++
 	}
+	if err = <-errc1; err != nil {
+		panic(err)
+	}
 
 	// Read list of files.
-	ds, e := dataframe.ReadDataSet("dataset.yaml")
+	ds, e := dataframe.ReadDataSetFile("dataset.yaml")
 	if e != nil {
 		panic(e)
 	}
 
 	// Count total number of instances on all files.
-	ch2 := ds.GetChanFloat64("chemical_concentrations", "algae")
+	ch2, errc2 := ds.Float64SliceChan("chemical_concentrations", "algae")
 	count = 0
 	for _ = range ch2 {
 		count++
 	}
+	if e = <-errc2; e != nil {
+		panic(e)
+	}
 
 	fmt.Printf("Total number of instances is %d.\n", count)
 }
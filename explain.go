@@ -0,0 +1,197 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Number of rows of each file used by Explain to estimate the output of a
+// pipeline.
+var ExplainPreviewRows = 1000
+
+// The plan of a pipeline returned by Explain.
+type PipelinePlan struct {
+	Sources []SourcePlan
+	// Transforms in order, with their parameters.
+	Steps []string
+	// Sink directories and formats.
+	Sinks []string
+	// Variables of the first input and output frames.
+	InputVars  []string
+	OutputVars []string
+	// Input variables that are not in the output.
+	PrunedVars []string
+	// Output variables that are not in the input.
+	AddedVars []string
+	// Number of input rows.
+	InputRows int
+	// Number of output rows estimated from the previews.
+	EstimatedRows int
+}
+
+// The files of a pipeline source.
+type SourcePlan struct {
+	DataSet string
+	Files   int
+	Rows    int
+	// Files whose preview was dropped by the transforms.
+	Skipped []string
+}
+
+// Reads a pipeline spec file and explains it, see PipelineSpec.Explain.
+func ExplainPipeline(specFile string) (*PipelinePlan, error) {
+
+	spec, err := ReadPipelineSpecFile(specFile)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := spec.Explain(filepath.Dir(specFile))
+	if err != nil {
+		return nil, fmt.Errorf("pipeline %s: %s", specFile, err)
+	}
+	return plan, nil
+}
+
+// Returns the plan of the pipeline without writing any output. Rows are
+// counted by reading every input file. The transforms run on the first
+// ExplainPreviewRows rows of each file, see DataSet.Preview, to find the
+// output variables, the files that would be dropped, and the number of
+// output rows, which is exact for files with fewer rows than the preview.
+// Relative paths are resolved against dir.
+func (spec *PipelineSpec) Explain(dir string) (*PipelinePlan, error) {
+
+	transforms, err := spec.transforms()
+	if err != nil {
+		return nil, err
+	}
+	plan := &PipelinePlan{}
+	for _, ts := range spec.Transforms {
+		plan.Steps = append(plan.Steps, ts.Name+formatParams(ts.Params))
+	}
+	for _, sink := range spec.Sinks {
+		format := sink.Format
+		if format == "" {
+			format = SinkJSON
+		}
+		plan.Sinks = append(plan.Sinks, fmt.Sprintf("%s (%s)", resolvePath(dir, sink.Dir), format))
+	}
+
+	var estimate float64
+	for _, src := range spec.Sources {
+		fn := resolvePath(dir, src.DataSet)
+		ds, err := ReadDataSetFile(fn)
+		if err != nil {
+			return nil, err
+		}
+		sp := SourcePlan{DataSet: fn, Files: len(ds.Files)}
+		sep := string(os.PathSeparator)
+		for _, name := range ds.Files {
+			rows, err := ds.countRows(name)
+			if err != nil {
+				return nil, fmt.Errorf("file %s: %s", name, err)
+			}
+			sp.Rows += rows
+			df, err := ds.previewFile(ds.Path+sep+name, ExplainPreviewRows)
+			if err != nil {
+				return nil, fmt.Errorf("file %s: %s", name, err)
+			}
+			if plan.InputVars == nil {
+				plan.InputVars = append([]string(nil), df.VarNames...)
+			}
+			in := df.N()
+			for _, t := range transforms {
+				if df, err = t(df); err != nil {
+					return nil, fmt.Errorf("file %s: %s", name, err)
+				}
+				if df == nil {
+					break
+				}
+			}
+			if df == nil {
+				sp.Skipped = append(sp.Skipped, name)
+				continue
+			}
+			if plan.OutputVars == nil {
+				plan.OutputVars = append([]string(nil), df.VarNames...)
+			}
+			if in > 0 {
+				estimate += float64(rows) * float64(df.N()) / float64(in)
+			}
+		}
+		plan.InputRows += sp.Rows
+		plan.Sources = append(plan.Sources, sp)
+	}
+	plan.EstimatedRows = int(estimate + 0.5)
+	plan.PrunedVars = missingNames(plan.InputVars, plan.OutputVars)
+	plan.AddedVars = missingNames(plan.OutputVars, plan.InputVars)
+	return plan, nil
+}
+
+// Returns the names in a that are not in b.
+func missingNames(a, b []string) []string {
+
+	in := make(map[string]bool, len(b))
+	for _, name := range b {
+		in[name] = true
+	}
+	var missing []string
+	for _, name := range a {
+		if !in[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// Formats parameters in sorted order.
+func formatParams(p Params) string {
+
+	if len(p) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(p))
+	for k := range p {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for i, k := range keys {
+		keys[i] = fmt.Sprintf("%s=%v", k, p[k])
+	}
+	return " " + strings.Join(keys, " ")
+}
+
+// Returns the plan as text.
+func (plan *PipelinePlan) String() string {
+
+	var buf bytes.Buffer
+	for _, sp := range plan.Sources {
+		fmt.Fprintf(&buf, "source %s: %d files, %d rows\n", sp.DataSet, sp.Files, sp.Rows)
+		for _, name := range sp.Skipped {
+			fmt.Fprintf(&buf, "  skip %s\n", name)
+		}
+	}
+	for i, step := range plan.Steps {
+		fmt.Fprintf(&buf, "transform %d: %s\n", i+1, step)
+	}
+	for _, sink := range plan.Sinks {
+		fmt.Fprintf(&buf, "sink %s\n", sink)
+	}
+	fmt.Fprintf(&buf, "variables: %d in, %d out\n", len(plan.InputVars), len(plan.OutputVars))
+	if len(plan.PrunedVars) > 0 {
+		fmt.Fprintf(&buf, "  pruned: %s\n", strings.Join(plan.PrunedVars, ", "))
+	}
+	if len(plan.AddedVars) > 0 {
+		fmt.Fprintf(&buf, "  added: %s\n", strings.Join(plan.AddedVars, ", "))
+	}
+	fmt.Fprintf(&buf, "rows: %d in, about %d out\n", plan.InputRows, plan.EstimatedRows)
+	return buf.String()
+}
@@ -0,0 +1,88 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+// A Row gives access to the values of one row of a data frame by variable
+// name. The getters return false when the variable doesn't exist, the value
+// is nil, or the value has a different type.
+type Row struct {
+	df    *DataFrame
+	frame int
+}
+
+// Returns row i of the data frame.
+func (df *DataFrame) Row(i int) Row {
+
+	return Row{df: df, frame: i}
+}
+
+// Returns the row number in the data frame.
+func (r Row) Frame() int { return r.frame }
+
+// Returns the raw value of a variable, or nil if the variable doesn't exist.
+func (r Row) Value(name string) interface{} {
+
+	idx, ok := r.df.varMap[name]
+	if !ok {
+		return nil
+	}
+	return r.df.Data[r.frame][idx]
+}
+
+// Returns true if the variable is missing or its value is nil.
+func (r Row) IsNil(name string) bool {
+
+	return r.Value(name) == nil
+}
+
+// Returns the value of a float64 variable.
+func (r Row) Float64(name string) (float64, bool) {
+
+	v, ok := r.Value(name).(float64)
+	return v, ok
+}
+
+// Returns the value of a string variable.
+func (r Row) String(name string) (string, bool) {
+
+	v, ok := r.Value(name).(string)
+	return v, ok
+}
+
+// Returns the value of a bool variable.
+func (r Row) Bool(name string) (bool, bool) {
+
+	v, ok := r.Value(name).(bool)
+	return v, ok
+}
+
+// Returns the value of a vector variable. Values that are []float64 are
+// not copied.
+func (r Row) Float64Slice(name string) ([]float64, bool) {
+
+	switch v := r.Value(name).(type) {
+	case []float64, []interface{}:
+		s, err := toFloat64Slice(v)
+		return s, err == nil
+	}
+	return nil, false
+}
+
+// Returns a new data frame with the rows for which fn returns true. For
+// example, to keep the rows in the kitchen:
+//
+//	kitchen := df.Filter(func(row Row) bool {
+//		room, _ := row.String("room")
+//		return room == "KITCHEN"
+//	})
+//
+// Cell values are not deep copied.
+func (df *DataFrame) Filter(fn func(row Row) bool) *DataFrame {
+
+	return df.View().Filter(func(frame int) bool {
+		return fn(Row{df: df, frame: frame})
+	}).Materialize()
+}
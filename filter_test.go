@@ -0,0 +1,63 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gonum/floats"
+)
+
+func TestFilter(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(file2))
+	CheckError(t, e)
+
+	kitchen := df.Filter(func(row Row) bool {
+		room, _ := row.String("room")
+		return room == "KITCHEN"
+	})
+	if kitchen.N() != 3 || df.N() != 6 {
+		t.Fatalf("expected 3 rows in filtered frame and 6 in original, got %d and %d.", kitchen.N(), df.N())
+	}
+	if kitchen.BatchID != df.BatchID || len(kitchen.VarNames) != len(df.VarNames) {
+		t.Fatalf("filtered frame must keep batch id and variables.")
+	}
+	rooms, e := kitchen.StringCol("room")
+	CheckError(t, e)
+	for _, r := range rooms {
+		if r != "KITCHEN" {
+			t.Fatalf("unexpected room %s.", r)
+		}
+	}
+
+	// Typed getters.
+	row := df.Row(3)
+	if row.Frame() != 3 {
+		t.Fatalf("expected frame 3, got %d.", row.Frame())
+	}
+	if _, ok := row.Float64("acceleration"); !ok {
+		t.Fatalf("expected float64 acceleration.")
+	}
+	if _, ok := row.Float64("room"); ok {
+		t.Fatalf("room is not a float64.")
+	}
+	if _, ok := row.String("missing"); ok || !row.IsNil("missing") {
+		t.Fatalf("missing variable must not be found.")
+	}
+	wifi, ok := row.Float64Slice("wifi")
+	want, e := df.Float64Slice(3, "wifi")
+	CheckError(t, e)
+	if !ok || !floats.Equal(wifi, want) {
+		t.Fatalf("expected wifi %v, got %v.", want, wifi)
+	}
+
+	none := df.Filter(func(row Row) bool { return false })
+	if none.N() != 0 || len(none.VarNames) != len(df.VarNames) {
+		t.Fatalf("expected empty frame with the same variables.")
+	}
+}
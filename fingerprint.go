@@ -0,0 +1,149 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"launchpad.net/goyaml"
+)
+
+// Content hash of a data set file.
+type FileHash struct {
+	// File name relative to the data set path.
+	File string `yaml:"file"`
+	// Hex encoded SHA-256 of the file content.
+	SHA256 string `yaml:"sha256"`
+	// File size in bytes.
+	Size int64 `yaml:"size"`
+}
+
+// A Lock records the content of a data set at a point in time.
+type Lock struct {
+	Fingerprint string     `yaml:"fingerprint"`
+	Files       []FileHash `yaml:"files"`
+}
+
+// Computes the SHA-256 hash of every file in the data set, in data set order.
+func (ds *DataSet) FileHashes() ([]FileHash, error) {
+
+	sep := string(os.PathSeparator)
+	hashes := make([]FileHash, len(ds.Files))
+	for i, fn := range ds.Files {
+		h, err := hashFile(ds.Path + sep + fn)
+		if err != nil {
+			return nil, err
+		}
+		h.File = fn
+		hashes[i] = h
+	}
+	return hashes, nil
+}
+
+func hashFile(fn string) (FileHash, error) {
+
+	f, err := os.Open(fn)
+	if err != nil {
+		return FileHash{}, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return FileHash{}, err
+	}
+	return FileHash{SHA256: hex.EncodeToString(h.Sum(nil)), Size: n}, nil
+}
+
+// Returns a digest of the names and contents of all the files in the data
+// set. The digest changes if a file is added, removed, renamed, reordered,
+// or modified.
+func (ds *DataSet) Fingerprint() (string, error) {
+
+	hashes, err := ds.FileHashes()
+	if err != nil {
+		return "", err
+	}
+	return fingerprint(hashes), nil
+}
+
+func fingerprint(hashes []FileHash) string {
+
+	h := sha256.New()
+	for _, fh := range hashes {
+		fmt.Fprintf(h, "%s\x00%s\n", fh.File, fh.SHA256)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Returns a lock with the current content of the data set.
+func (ds *DataSet) Lock() (*Lock, error) {
+
+	hashes, err := ds.FileHashes()
+	if err != nil {
+		return nil, err
+	}
+	return &Lock{Fingerprint: fingerprint(hashes), Files: hashes}, nil
+}
+
+// Writes a lock file for the data set in YAML format.
+func (ds *DataSet) WriteLockFile(fn string) error {
+
+	lock, err := ds.Lock()
+	if err != nil {
+		return err
+	}
+	b, err := goyaml.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(fn, 0644, func(w io.Writer) error {
+		_, err := w.Write(b)
+		return err
+	})
+}
+
+// Reads a lock file.
+func ReadLockFile(fn string) (*Lock, error) {
+
+	b, err := ioutil.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	lock := &Lock{}
+	if err = goyaml.Unmarshal(b, lock); err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// Returns an error describing the first difference between the data set and
+// the lock, or nil if the data set matches the lock.
+func (ds *DataSet) VerifyLock(lock *Lock) error {
+
+	current, err := ds.Lock()
+	if err != nil {
+		return err
+	}
+	if current.Fingerprint == lock.Fingerprint {
+		return nil
+	}
+	if len(current.Files) != len(lock.Files) {
+		return fmt.Errorf("data set has %d files, lock has %d.", len(current.Files), len(lock.Files))
+	}
+	for i, fh := range current.Files {
+		if fh != lock.Files[i] {
+			return fmt.Errorf("file %d changed: %s (%s), lock has %s (%s).",
+				i, fh.File, fh.SHA256, lock.Files[i].File, lock.Files[i].SHA256)
+		}
+	}
+	return fmt.Errorf("fingerprint %s doesn't match lock fingerprint %s.", current.Fingerprint, lock.Fingerprint)
+}
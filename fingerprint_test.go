@@ -0,0 +1,57 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-lock")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+
+	spec := synthSpec(10, 2, 0)
+	spec.Files = 3
+	ds, e := GenerateDataSet(spec, dir)
+	CheckError(t, e)
+
+	fp1, e := ds.Fingerprint()
+	CheckError(t, e)
+	fp2, e := ds.Fingerprint()
+	CheckError(t, e)
+	if fp1 != fp2 {
+		t.Fatalf("fingerprint is not deterministic.")
+	}
+
+	lockFile := filepath.Join(dir, "dataset.lock")
+	CheckError(t, ds.WriteLockFile(lockFile))
+	lock, e := ReadLockFile(lockFile)
+	CheckError(t, e)
+	if lock.Fingerprint != fp1 || len(lock.Files) != 3 {
+		t.Fatalf("unexpected lock %+v.", lock)
+	}
+	CheckError(t, ds.VerifyLock(lock))
+
+	// Modify a file.
+	CheckError(t, ioutil.WriteFile(filepath.Join(dir, ds.Files[1]), []byte("{}"), 0644))
+	if e = ds.VerifyLock(lock); e == nil {
+		t.Fatalf("expected error for modified file.")
+	}
+	t.Log(e)
+
+	// Reordering changes the fingerprint.
+	ds.Files[0], ds.Files[2] = ds.Files[2], ds.Files[0]
+	fp3, e := ds.Fingerprint()
+	CheckError(t, e)
+	if fp3 == fp1 {
+		t.Fatalf("fingerprint must change when files are reordered.")
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+)
+
+// Distributions supported by the generator.
+const (
+	DistNormal  = "normal"
+	DistUniform = "uniform"
+)
+
+// Describes a variable generated by Generate.
+type GenVar struct {
+	// Variable name.
+	Name string `yaml:"name"`
+	// One of "float64", "[]float64", or "string".
+	Type string `yaml:"type"`
+	// Dimension of []float64 variables.
+	Dim int `yaml:"dim"`
+	// Distribution of numeric values, DistNormal (default) or DistUniform.
+	Dist string `yaml:"dist"`
+	// Parameters of the normal distribution.
+	Mean   float64 `yaml:"mean"`
+	StdDev float64 `yaml:"stddev"`
+	// Parameters of the uniform distribution.
+	Min float64 `yaml:"min"`
+	Max float64 `yaml:"max"`
+	// Levels of string variables, chosen uniformly.
+	Levels []string `yaml:"levels"`
+	// Probability that a cell is missing (nil).
+	NARate float64 `yaml:"na_rate"`
+}
+
+// Describes random data frames created by Generate.
+type GenSpec struct {
+	// Variables in the data frame.
+	Vars []GenVar `yaml:"vars"`
+	// Number of rows per data frame.
+	Rows int `yaml:"rows"`
+	// Number of files created by GenerateDataSet.
+	Files int `yaml:"files"`
+	// Seed for the random number generator.
+	Seed int64 `yaml:"seed"`
+	// Description of the generated data frames.
+	Description string `yaml:"description"`
+}
+
+// Creates a data frame with random data. The same spec always produces the
+// same data frame.
+func Generate(spec GenSpec) (*DataFrame, error) {
+
+	return generate(spec, rand.New(rand.NewSource(spec.Seed)), "gen-0")
+}
+
+// Creates a data set with random data in directory dir. Each file has
+// spec.Rows rows. File i is generated using seed spec.Seed + i.
+func GenerateDataSet(spec GenSpec, dir string) (*DataSet, error) {
+
+	if spec.Files <= 0 {
+		return nil, fmt.Errorf("Number of files must be positive, got %d.", spec.Files)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	ds := &DataSet{Path: dir}
+	sep := string(os.PathSeparator)
+	for i := 0; i < spec.Files; i++ {
+		r := rand.New(rand.NewSource(spec.Seed + int64(i)))
+		df, err := generate(spec, r, fmt.Sprintf("gen-%d", i))
+		if err != nil {
+			return nil, err
+		}
+		fn := fmt.Sprintf("gen-%04d.json", i)
+		if err = df.WriteDataFrameFile(dir + sep + fn); err != nil {
+			return nil, err
+		}
+		ds.Files = append(ds.Files, fn)
+	}
+	return ds, nil
+}
+
+func generate(spec GenSpec, r *rand.Rand, batchID string) (*DataFrame, error) {
+
+	if spec.Rows < 0 {
+		return nil, fmt.Errorf("Number of rows must not be negative, got %d.", spec.Rows)
+	}
+	df := &DataFrame{
+		Description: spec.Description,
+		BatchID:     batchID,
+		Data:        make([][]interface{}, spec.Rows),
+	}
+	for _, v := range spec.Vars {
+		switch v.Type {
+		case "float64", "string":
+		case "[]float64":
+			if v.Dim <= 0 {
+				return nil, fmt.Errorf("Variable [%s] must have a positive dimension.", v.Name)
+			}
+		default:
+			return nil, fmt.Errorf("Variable [%s] has unsupported type [%s].", v.Name, v.Type)
+		}
+		if v.Type == "string" && len(v.Levels) == 0 {
+			return nil, fmt.Errorf("Variable [%s] must have levels.", v.Name)
+		}
+		if v.Dist != "" && v.Dist != DistNormal && v.Dist != DistUniform {
+			return nil, fmt.Errorf("Variable [%s] has unsupported distribution [%s].", v.Name, v.Dist)
+		}
+		df.VarNames = append(df.VarNames, v.Name)
+	}
+	df.resetVarMap()
+	if len(df.varMap) != len(df.VarNames) {
+		return nil, fmt.Errorf("Variable names must be unique.")
+	}
+
+	for i := range df.Data {
+		row := make([]interface{}, len(spec.Vars))
+		for j, v := range spec.Vars {
+			if v.NARate > 0 && r.Float64() < v.NARate {
+				continue
+			}
+			switch v.Type {
+			case "float64":
+				row[j] = v.sample(r)
+			case "[]float64":
+				vec := make([]float64, v.Dim)
+				for k := range vec {
+					vec[k] = v.sample(r)
+				}
+				row[j] = vec
+			case "string":
+				row[j] = v.Levels[r.Intn(len(v.Levels))]
+			}
+		}
+		df.Data[i] = row
+	}
+	return df, nil
+}
+
+func (v GenVar) sample(r *rand.Rand) float64 {
+
+	if v.Dist == DistUniform {
+		return v.Min + (v.Max-v.Min)*r.Float64()
+	}
+	return v.Mean + v.StdDev*r.NormFloat64()
+}
@@ -0,0 +1,93 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+
+	spec := GenSpec{
+		Rows: 1000,
+		Seed: 33,
+		Vars: []GenVar{
+			{Name: "room", Type: "string", Levels: []string{"KITCHEN", "BATH"}},
+			{Name: "wifi", Type: "[]float64", Dim: 3, Dist: DistUniform, Min: -90, Max: -30},
+			{Name: "acceleration", Type: "float64", Mean: 1, StdDev: 0.1, NARate: 0.5},
+		},
+	}
+	df, e := Generate(spec)
+	CheckError(t, e)
+
+	if df.N() != 1000 || df.NumVariables() != 3 {
+		t.Fatalf("dims must be 1000x3, not %dx%d.", df.N(), df.NumVariables())
+	}
+	var na int
+	for i := 0; i < df.N(); i++ {
+		wifi, e := df.Float64Slice(i, "wifi")
+		CheckError(t, e)
+		for _, v := range wifi {
+			if v < -90 || v > -30 {
+				t.Fatalf("value %f out of range in row %d.", v, i)
+			}
+		}
+		if df.Data[i][2] == nil {
+			na++
+		}
+	}
+	if na < 400 || na > 600 {
+		t.Fatalf("expected about 500 NA values, got %d.", na)
+	}
+
+	// Same seed, same data.
+	df2, e := Generate(spec)
+	CheckError(t, e)
+	if !reflect.DeepEqual(df.Data, df2.Data) {
+		t.Fatalf("generator is not deterministic.")
+	}
+
+	spec.Vars[0].Levels = nil
+	if _, e = Generate(spec); e == nil {
+		t.Fatalf("expected error for string variable without levels.")
+	}
+	spec.Rows = -1
+	if _, e = Generate(spec); e == nil {
+		t.Fatalf("expected error for negative rows.")
+	}
+}
+
+func TestGenerateDataSet(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-gen")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+
+	spec := GenSpec{
+		Rows:  10,
+		Files: 3,
+		Vars:  []GenVar{{Name: "x", Type: "float64", StdDev: 1}},
+	}
+	ds, e := GenerateDataSet(spec, dir)
+	CheckError(t, e)
+
+	var n int
+	for {
+		df, e := ds.Next()
+		if e == io.EOF {
+			break
+		}
+		CheckError(t, e)
+		n += df.N()
+	}
+	if n != 30 {
+		t.Fatalf("expected 30 rows, got %d.", n)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Returns the value of an integer variable. Float64 values must be whole
+// numbers, as JSON numbers are decoded as float64. Strings are parsed in
+// base 10.
+func (df *DataFrame) Int(frame int, name string) (int, error) {
+
+	v, err := df.value(frame, name)
+	if err != nil {
+		return 0, err
+	}
+	switch x := v.(type) {
+	case float64:
+		if x != math.Trunc(x) || x < math.MinInt64 || x >= math.MaxInt64 {
+			return 0, fmt.Errorf("In frame %d, variable [%s] value %v is not an integer.", frame, name, x)
+		}
+		return int(x), nil
+	case string:
+		n, err := strconv.Atoi(strings.TrimSpace(x))
+		if err != nil {
+			return 0, fmt.Errorf("In frame %d, variable [%s] value %q is not an integer.", frame, name, x)
+		}
+		return n, nil
+	}
+	return 0, colTypeError(frame, name, v, "int")
+}
+
+// Returns the value of a bool variable. Float64 values must be 0 or 1.
+// Strings are parsed with strconv.ParseBool, which accepts "true", "false",
+// "1", "0", and variants.
+func (df *DataFrame) Bool(frame int, name string) (bool, error) {
+
+	v, err := df.value(frame, name)
+	if err != nil {
+		return false, err
+	}
+	switch x := v.(type) {
+	case bool:
+		return x, nil
+	case float64:
+		if x == 0 || x == 1 {
+			return x == 1, nil
+		}
+		return false, fmt.Errorf("In frame %d, variable [%s] value %v is not a bool.", frame, name, x)
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(x))
+		if err != nil {
+			return false, fmt.Errorf("In frame %d, variable [%s] value %q is not a bool.", frame, name, x)
+		}
+		return b, nil
+	}
+	return false, colTypeError(frame, name, v, "bool")
+}
+
+// Returns the value of a time variable. Strings are parsed with the layout,
+// see time.Parse, or as RFC 3339 if the layout is empty. Float64 values are
+// seconds since the Unix epoch and are returned in UTC.
+func (df *DataFrame) Time(frame int, name, layout string) (time.Time, error) {
+
+	v, err := df.value(frame, name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	switch x := v.(type) {
+	case float64:
+		sec, frac := math.Modf(x)
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC(), nil
+	case string:
+		if layout == "" {
+			layout = time.RFC3339Nano
+		}
+		t, err := time.Parse(layout, x)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("In frame %d, variable [%s]: %s", frame, name, err)
+		}
+		return t, nil
+	}
+	return time.Time{}, colTypeError(frame, name, v, "time")
+}
+
+// Returns the value of a variable. Nil values are an error.
+func (df *DataFrame) value(frame int, name string) (interface{}, error) {
+
+	indices, err := df.indices(name)
+	if err != nil {
+		return nil, err
+	}
+	if err = df.checkFrame(frame); err != nil {
+		return nil, err
+	}
+	v := df.Data[frame][indices[0]]
+	if v == nil {
+		return nil, colTypeError(frame, name, v, "")
+	}
+	return v, nil
+}
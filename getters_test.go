@@ -0,0 +1,67 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTypedGetters(t *testing.T) {
+
+	df := &DataFrame{
+		VarNames: []string{"n", "b", "t"},
+		Data: [][]interface{}{
+			{3.0, true, "2014-03-01T10:00:00Z"},
+			{"42", "false", 1393668000.5},
+			{2.5, 1.0, "01/03/2014"},
+			{nil, 2.0, []float64{1}},
+		},
+	}
+	df.resetVarMap()
+
+	if n, e := df.Int(0, "n"); e != nil || n != 3 {
+		t.Fatalf("expected 3, got %d %v.", n, e)
+	}
+	if n, e := df.Int(1, "n"); e != nil || n != 42 {
+		t.Fatalf("expected 42, got %d %v.", n, e)
+	}
+	if _, e := df.Int(2, "n"); e == nil {
+		t.Fatalf("expected error for fractional value.")
+	}
+	if _, e := df.Int(3, "n"); e == nil {
+		t.Fatalf("expected error for nil value.")
+	}
+
+	for i, want := range []bool{true, false, true} {
+		if b, e := df.Bool(i, "b"); e != nil || b != want {
+			t.Fatalf("frame %d: expected %v, got %v %v.", i, want, b, e)
+		}
+	}
+	if _, e := df.Bool(3, "b"); e == nil {
+		t.Fatalf("expected error for 2.")
+	}
+
+	want := time.Date(2014, 3, 1, 10, 0, 0, 0, time.UTC)
+	if ts, e := df.Time(0, "t", ""); e != nil || !ts.Equal(want) {
+		t.Fatalf("expected %v, got %v %v.", want, ts, e)
+	}
+	if ts, e := df.Time(1, "t", ""); e != nil || !ts.Equal(time.Unix(1393668000, 5e8)) {
+		t.Fatalf("unexpected time %v %v.", ts, e)
+	}
+	if ts, e := df.Time(2, "t", "02/01/2006"); e != nil || ts.Day() != 1 || ts.Month() != time.March {
+		t.Fatalf("unexpected time %v %v.", ts, e)
+	}
+	if _, e := df.Time(2, "t", ""); e == nil {
+		t.Fatalf("expected parse error.")
+	}
+	if _, e := df.Time(3, "t", ""); e == nil {
+		t.Fatalf("expected type error.")
+	}
+	if _, e := df.Int(9, "n"); e == nil {
+		t.Fatalf("expected error for out of range frame.")
+	}
+}
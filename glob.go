@@ -0,0 +1,96 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Extensions of the data files found when scanning a directory, see
+// DataSet.Dir. Compressed files with extension GzipExt are also found.
+var DataFileExts = []string{".json", ".csv", ".lp", BinaryExt, AvroExt}
+
+// Expands glob patterns in Files and adds the data files found in Dir.
+// Patterns use the syntax of filepath.Match and are relative to Path; the
+// matching files are added in sorted order and a pattern that matches no
+// files is an error. Dir is scanned recursively and the files with an
+// extension in DataFileExts are added in sorted order after Files. Files
+// are added once. Called by ReadDataSet.
+func (ds *DataSet) Resolve() error {
+
+	files := make([]string, 0, len(ds.Files))
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			files = append(files, name)
+		}
+	}
+	for _, f := range ds.Files {
+		if !strings.ContainsAny(f, "*?[") {
+			add(f)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(ds.Path, f))
+		if err != nil {
+			return fmt.Errorf("pattern %s: %s", f, err)
+		}
+		if len(matches) == 0 {
+			return fmt.Errorf("pattern %s matches no files in %s.", f, ds.Path)
+		}
+		sort.Strings(matches)
+		for _, m := range matches {
+			rel, err := filepath.Rel(ds.Path, m)
+			if err != nil {
+				return err
+			}
+			add(filepath.ToSlash(rel))
+		}
+	}
+
+	if ds.Dir != "" {
+		found := make([]string, 0)
+		root := filepath.Join(ds.Path, ds.Dir)
+		err := filepath.Walk(root, func(fn string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || !isDataFile(fn) {
+				return nil
+			}
+			rel, err := filepath.Rel(ds.Path, fn)
+			if err != nil {
+				return err
+			}
+			found = append(found, filepath.ToSlash(rel))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		sort.Strings(found)
+		for _, f := range found {
+			add(f)
+		}
+	}
+	ds.Files = files
+	return nil
+}
+
+func isDataFile(fn string) bool {
+
+	fn = strings.TrimSuffix(fn, GzipExt)
+	for _, ext := range DataFileExts {
+		if filepath.Ext(fn) == ext {
+			return true
+		}
+	}
+	return false
+}
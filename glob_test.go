@@ -0,0 +1,49 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-glob")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"s2.json", "s1.json", "s10.json", "notes.txt",
+		"sessions/b/x.json.gz", "sessions/a/y.csv", "sessions/a/y.csv" + CacheExt, "sessions/a/z.dfb"} {
+		fn := filepath.Join(dir, name)
+		CheckError(t, os.MkdirAll(filepath.Dir(fn), 0755))
+		CheckError(t, ioutil.WriteFile(fn, []byte(file1), 0644))
+	}
+
+	ds, e := ReadDataSet(strings.NewReader("path: " + dir + `
+files: [s10.json, "s*.json"]
+dir: sessions
+`))
+	CheckError(t, e)
+	want := "s10.json,s1.json,s2.json,sessions/a/y.csv,sessions/a/z.dfb,sessions/b/x.json.gz"
+	if got := strings.Join(ds.Files, ","); got != want {
+		t.Fatalf("expected files %s, got %s.", want, got)
+	}
+
+	// Resolving again doesn't change the list.
+	CheckError(t, ds.Resolve())
+	if got := strings.Join(ds.Files, ","); got != want {
+		t.Fatalf("expected files %s, got %s.", want, got)
+	}
+
+	ds = &DataSet{Path: dir, Files: []string{"*.csv"}}
+	if e = ds.Resolve(); e == nil {
+		t.Fatalf("expected error for pattern without matches.")
+	}
+	t.Log(e)
+}
@@ -0,0 +1,269 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Groups are the rows of a data frame partitioned by the values of one or
+// more key variables. Groups are in order of first appearance.
+type Groups struct {
+	df   *DataFrame
+	keys []string
+	// key values of each group.
+	values [][]interface{}
+	// rows of each group.
+	rows [][]int
+}
+
+// Partitions the rows of the data frame by the values of the key variables.
+// Key values must be strings, float64, or bool. Rows with a nil key form
+// their own group.
+func (df *DataFrame) GroupBy(names ...string) (*Groups, error) {
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("GroupBy requires at least one variable.")
+	}
+	indices, err := df.indices(names...)
+	if err != nil {
+		return nil, err
+	}
+	g := &Groups{df: df, keys: names}
+	groups := make(map[string]int)
+	var buf bytes.Buffer
+	for i, row := range df.Data {
+		buf.Reset()
+		key := make([]interface{}, len(indices))
+		for j, idx := range indices {
+			if !writeKey(&buf, row[idx]) {
+				return nil, fmt.Errorf("In frame %d, key variable [%s] is of type [%s]. Must be of type string, float64, or bool.",
+					i, names[j], reflect.TypeOf(row[idx]).String())
+			}
+			key[j] = row[idx]
+		}
+		k, ok := groups[buf.String()]
+		if !ok {
+			k = len(g.rows)
+			groups[buf.String()] = k
+			g.values = append(g.values, key)
+			g.rows = append(g.rows, nil)
+		}
+		g.rows[k] = append(g.rows[k], i)
+	}
+	return g, nil
+}
+
+// Writes the encoding of a key value to buf. Returns false if the value is
+// not nil, a string, a float64, or a bool.
+func writeKey(buf *bytes.Buffer, v interface{}) bool {
+
+	switch v.(type) {
+	case nil, string, float64, bool:
+		fmt.Fprintf(buf, "%T:%v\x00", v, v)
+		return true
+	}
+	return false
+}
+
+// Returns the number of groups.
+func (g *Groups) Len() int { return len(g.rows) }
+
+// Returns the names of the key variables.
+func (g *Groups) Keys() []string { return g.keys }
+
+// Returns the key values of group i.
+func (g *Groups) Key(i int) []interface{} { return g.values[i] }
+
+// Returns the rows of group i in the parent data frame.
+func (g *Groups) Rows(i int) []int { return g.rows[i] }
+
+// Returns a new data frame with the rows of group i. Cell values are not
+// deep copied.
+func (g *Groups) Frame(i int) *DataFrame {
+
+	return (&View{parent: g.df, rows: g.rows[i], names: g.df.VarNames}).Materialize()
+}
+
+// Returns the mean of the variables for each group. See Aggregate.
+func (g *Groups) Mean(names ...string) (*DataFrame, error) { return g.Aggregate("mean", names...) }
+
+// Returns the sum of the variables for each group. See Aggregate.
+func (g *Groups) Sum(names ...string) (*DataFrame, error) { return g.Aggregate("sum", names...) }
+
+// Returns the number of non-nil values of the variables for each group. See Aggregate.
+func (g *Groups) Count(names ...string) (*DataFrame, error) { return g.Aggregate("count", names...) }
+
+// Returns the minimum of the variables for each group. See Aggregate.
+func (g *Groups) Min(names ...string) (*DataFrame, error) { return g.Aggregate("min", names...) }
+
+// Returns the maximum of the variables for each group. See Aggregate.
+func (g *Groups) Max(names ...string) (*DataFrame, error) { return g.Aggregate("max", names...) }
+
+// Applies a registered aggregate to float64 variables for each group. See
+// RegisterAggregate and Reduce.
+func (g *Groups) Aggregate(agg string, names ...string) (*DataFrame, error) {
+
+	fn, err := LookupAggregate(agg)
+	if err != nil {
+		return nil, err
+	}
+	return g.Reduce(fn, names...)
+}
+
+// Applies fn to float64 variables for each group. Nil values are removed
+// before fn is called. When no names are given, all the float64 variables
+// that are not keys are reduced. Returns a data frame with one row per group
+// with the key variables followed by the reduced variables, which keep their
+// names and units.
+func (g *Groups) Reduce(fn AggregateFunc, names ...string) (*DataFrame, error) {
+
+	df := g.df
+	if len(names) == 0 {
+		names = g.numericVars()
+	}
+	indices, err := df.indices(names...)
+	if err != nil {
+		return nil, err
+	}
+	out := &DataFrame{
+		Description: df.Description,
+		BatchID:     df.BatchID,
+		VarNames:    append(append([]string(nil), g.keys...), names...),
+		Data:        make([][]interface{}, len(g.rows)),
+		Properties:  copyProperties(df.Properties),
+	}
+	if err = checkUnique(out.VarNames); err != nil {
+		return nil, err
+	}
+	values := make([]float64, 0)
+	for k, rows := range g.rows {
+		row := append(make([]interface{}, 0, len(out.VarNames)), g.values[k]...)
+		for j, idx := range indices {
+			values = values[:0]
+			for _, i := range rows {
+				switch v := df.Data[i][idx].(type) {
+				case nil:
+				case float64:
+					values = append(values, v)
+				default:
+					return nil, fmt.Errorf("In frame %d, variable [%s] is of type [%s]. Must be of type float64.",
+						i, names[j], reflect.TypeOf(v).String())
+				}
+			}
+			row = append(row, fn(values))
+		}
+		out.Data[k] = row
+	}
+	for _, name := range out.VarNames {
+		if u, ok := df.VarUnits[name]; ok {
+			if out.VarUnits == nil {
+				out.VarUnits = make(map[string]string)
+			}
+			out.VarUnits[name] = u
+		}
+	}
+	out.resetVarMap()
+	return out, nil
+}
+
+// Returns the variables that are not keys and whose values are all float64
+// or nil.
+func (g *Groups) numericVars() []string {
+
+	isKey := make(map[string]bool)
+	for _, k := range g.keys {
+		isKey[k] = true
+	}
+	names := make([]string, 0)
+	for j, name := range g.df.VarNames {
+		if isKey[name] {
+			continue
+		}
+		numeric := false
+		for _, row := range g.df.Data {
+			if v := row[j]; v != nil {
+				if _, numeric = v.(float64); !numeric {
+					break
+				}
+			}
+		}
+		if numeric {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// A GroupFunc computes a data frame from the key values and the rows of a
+// group. Returning nil drops the group from the output.
+type GroupFunc func(key []interface{}, df *DataFrame) (*DataFrame, error)
+
+// Calls fn with the frame of each group using n goroutines, for example to
+// fit a model per room, and concatenates the results in group order. All the
+// results must have the same variables. Returns the first error in group
+// order.
+func (g *Groups) ParallelApply(n int, fn GroupFunc) (*DataFrame, error) {
+
+	if n < 1 {
+		n = 1
+	}
+	results := make([]*DataFrame, g.Len())
+	errs := make([]error, g.Len())
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := range jobs {
+				results[k], errs[k] = fn(g.values[k], g.Frame(k))
+			}
+		}()
+	}
+	for k := range results {
+		jobs <- k
+	}
+	close(jobs)
+	wg.Wait()
+
+	var out *DataFrame
+	for k, df := range results {
+		if errs[k] != nil {
+			return nil, fmt.Errorf("In group %v, %s", g.values[k], errs[k])
+		}
+		if df == nil {
+			continue
+		}
+		if out == nil {
+			out = &DataFrame{
+				Description: df.Description,
+				BatchID:     df.BatchID,
+				VarNames:    append([]string(nil), df.VarNames...),
+				Data:        make([][]interface{}, 0, df.N()*g.Len()),
+				Properties:  copyProperties(df.Properties),
+			}
+		} else if strings.Join(df.VarNames, "\x00") != strings.Join(out.VarNames, "\x00") {
+			return nil, fmt.Errorf("In group %v, variables %v don't match %v.", g.values[k], df.VarNames, out.VarNames)
+		}
+		for name, u := range df.VarUnits {
+			if out.VarUnits == nil {
+				out.VarUnits = make(map[string]string)
+			}
+			out.VarUnits[name] = u
+		}
+		out.Data = append(out.Data, df.Data...)
+	}
+	if out == nil {
+		return Empty(), nil
+	}
+	out.resetVarMap()
+	return out, nil
+}
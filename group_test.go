@@ -0,0 +1,147 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestGroupBy(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	df.VarUnits = map[string]string{"acceleration": "m/s2"}
+
+	g, e := df.GroupBy("room")
+	CheckError(t, e)
+	if g.Len() != 2 || g.Key(0)[0] != "BED5" || g.Key(1)[0] != "DINING" {
+		t.Fatalf("unexpected groups %v.", g.values)
+	}
+	if f := g.Frame(1); f.N() != 3 || f.Data[0][2] != 1.6 {
+		t.Fatalf("unexpected group frame %v.", f.Data)
+	}
+
+	mean, e := g.Mean()
+	CheckError(t, e)
+	if len(mean.VarNames) != 2 || mean.VarNames[1] != "acceleration" {
+		t.Fatalf("unexpected variables %v.", mean.VarNames)
+	}
+	if mean.VarUnits["acceleration"] != "m/s2" {
+		t.Fatalf("units must be preserved.")
+	}
+	want := []float64{1.4, 1.7}
+	for i, row := range mean.Data {
+		if math.Abs(row[1].(float64)-want[i]) > 1e-9 {
+			t.Fatalf("row %d: expected mean %f, got %v.", i, want[i], row[1])
+		}
+	}
+
+	check := func(df *DataFrame, err error, want ...float64) {
+		CheckError(t, err)
+		for i, w := range want {
+			if x := df.Data[i][1].(float64); math.Abs(x-w) > 1e-9 {
+				t.Fatalf("row %d: expected %f, got %f.", i, w, x)
+			}
+		}
+	}
+	df.Data[0][2] = nil
+	sum, e := g.Sum("acceleration")
+	check(sum, e, 2.9, 5.1)
+	count, e := g.Count("acceleration")
+	check(count, e, 2, 3)
+	min, e := g.Min("acceleration")
+	check(min, e, 1.4, 1.6)
+	max, e := g.Max("acceleration")
+	check(max, e, 1.5, 1.8)
+	med, e := g.Aggregate("median", "acceleration")
+	check(med, e, 1.45, 1.7)
+	rng, e := g.Reduce(func(v []float64) float64 {
+		m, _ := LookupAggregate("max")
+		n, _ := LookupAggregate("min")
+		return m(v) - n(v)
+	}, "acceleration")
+	check(rng, e, 0.1, 0.2)
+
+	if _, e = g.Mean("wifi"); e == nil {
+		t.Fatalf("expected error for vector variable.")
+	}
+	if _, e = df.GroupBy("wifi"); e == nil {
+		t.Fatalf("expected error for vector key.")
+	}
+	if _, e = df.GroupBy("missing"); e == nil {
+		t.Fatalf("expected error for missing key.")
+	}
+}
+
+func TestGroupParallelApply(t *testing.T) {
+
+	df := synthFrame(1000, 2, 0)
+	g, e := df.GroupBy("room")
+	CheckError(t, e)
+
+	// Center the acceleration of each room.
+	center := func(key []interface{}, f *DataFrame) (*DataFrame, error) {
+		st, err := f.ColStats("acceleration")
+		if err != nil {
+			return nil, err
+		}
+		return f, f.Apply("acc_centered", func(r Row) interface{} {
+			a, _ := r.Float64("acceleration")
+			return a - st.Mean
+		})
+	}
+	out, e := g.ParallelApply(4, center)
+	CheckError(t, e)
+	if out.N() != df.N() {
+		t.Fatalf("expected %d rows, got %d.", df.N(), out.N())
+	}
+	// Rows are in group order.
+	if out.Data[0][0] != g.Key(0)[0] || out.Data[out.N()-1][0] != g.Key(g.Len() - 1)[0] {
+		t.Fatalf("rows are not in group order.")
+	}
+	mean, e := out.GroupBy("room")
+	CheckError(t, e)
+	m, e := mean.Mean("acc_centered")
+	CheckError(t, e)
+	for _, row := range m.Data {
+		if math.Abs(row[1].(float64)) > 1e-12 {
+			t.Fatalf("expected zero mean, got %v.", row)
+		}
+	}
+
+	// Dropped groups and errors.
+	out, e = g.ParallelApply(2, func(key []interface{}, f *DataFrame) (*DataFrame, error) {
+		if key[0] == "BED5" {
+			return f, nil
+		}
+		return nil, nil
+	})
+	CheckError(t, e)
+	if n, _ := df.SplitBy("room"); out.N() != n["BED5"].N() {
+		t.Fatalf("expected only BED5 rows, got %d.", out.N())
+	}
+	_, e = g.ParallelApply(2, func(key []interface{}, f *DataFrame) (*DataFrame, error) {
+		if key[0] == "BED5" {
+			return nil, fmt.Errorf("no model")
+		}
+		return f, nil
+	})
+	if e == nil || !strings.Contains(e.Error(), "no model") {
+		t.Fatalf("expected error from group function, got %v.", e)
+	}
+	_, e = g.ParallelApply(2, func(key []interface{}, f *DataFrame) (*DataFrame, error) {
+		if key[0] == "BED5" {
+			return f, f.AddVar("extra", make([]interface{}, f.N()))
+		}
+		return f, nil
+	})
+	if e == nil {
+		t.Fatalf("expected error for different variables.")
+	}
+}
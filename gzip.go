@@ -0,0 +1,57 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+)
+
+// Extension of gzip compressed files. Files with this extension are
+// decompressed when read and compressed when written; the format is implied
+// by the extension that precedes it, for example "session.json.gz".
+const GzipExt = ".gz"
+
+// Returns a reader that decompresses r if fn has extension GzipExt, and the
+// name without the extension.
+func decompress(fn string, r io.Reader) (io.Reader, string, error) {
+
+	if !strings.HasSuffix(fn, GzipExt) {
+		return r, fn, nil
+	}
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fn, err
+	}
+	return zr, strings.TrimSuffix(fn, GzipExt), nil
+}
+
+type gzipFile struct {
+	io.Reader
+	f *os.File
+}
+
+func (g gzipFile) Close() error { return g.f.Close() }
+
+// Opens file fn, decompressing it if needed.
+func openFile(fn string) (io.ReadCloser, error) {
+
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	r, _, err := decompress(fn, f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if r == io.Reader(f) {
+		return f, nil
+	}
+	return gzipFile{Reader: r, f: f}, nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGzip(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-gzip")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	fn := filepath.Join(dir, "file1.json.gz")
+	CheckError(t, df.WriteDataFrameFile(fn))
+	b, e := ioutil.ReadFile(fn)
+	CheckError(t, e)
+	if len(b) < 2 || b[0] != 0x1f || b[1] != 0x8b {
+		t.Fatalf("file is not compressed.")
+	}
+	got, e := ReadDataFrameFile(fn)
+	CheckError(t, e)
+	assertSameJSON(t, df, got)
+
+	f, e := os.Create(filepath.Join(dir, "s1.csv.gz"))
+	CheckError(t, e)
+	zw := gzip.NewWriter(f)
+	_, e = zw.Write([]byte("room,x\nBED5,1\nDINING,2\n"))
+	CheckError(t, e)
+	CheckError(t, zw.Close())
+	CheckError(t, f.Close())
+	csv, e := ReadCSVFile(filepath.Join(dir, "s1.csv.gz"), CSVOptions{})
+	CheckError(t, e)
+	if csv.BatchID != "s1" || csv.N() != 2 {
+		t.Fatalf("unexpected frame %+v.", csv)
+	}
+
+	ds := &DataSet{Path: dir, Files: []string{"file1.json.gz", "s1.csv.gz"}, Cache: true}
+	for _, want := range []int{6, 2} {
+		df, e := ds.Next()
+		CheckError(t, e)
+		if df.N() != want {
+			t.Fatalf("expected %d rows, got %d.", want, df.N())
+		}
+	}
+	ds.Files = ds.Files[:1]
+	p, e := ds.Preview(2)
+	CheckError(t, e)
+	if p.N() != 2 {
+		t.Fatalf("expected 2 rows, got %d.", p.N())
+	}
+
+	// Exported files stay compressed.
+	out, e := TransformDataSet(&DataSet{Path: dir, Files: []string{"file1.json.gz", "s1.csv.gz"}}, filepath.Join(dir, "out"))
+	CheckError(t, e)
+	if out.Files[0] != "file1.json.gz" || out.Files[1] != "s1.json.gz" {
+		t.Fatalf("unexpected files %v.", out.Files)
+	}
+	df, e = out.Next()
+	CheckError(t, e)
+
+	CheckError(t, ioutil.WriteFile(filepath.Join(dir, "bad.json.gz"), []byte(file1), 0644))
+	if _, e = ReadDataFrameFile(filepath.Join(dir, "bad.json.gz")); e == nil {
+		t.Fatalf("expected error for uncompressed file.")
+	}
+}
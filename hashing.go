@@ -0,0 +1,69 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"hash/fnv"
+	"reflect"
+)
+
+// Maps a string variable into a float64 vector of dimension dim using the
+// hashing trick and stores the result as a new variable named name + "_hash".
+// Each string adds +1 or -1 (chosen by a second hash bit to reduce collision
+// bias) to the element selected by its hash. Variables whose values are lists
+// of strings, such as SSIDs in a scan, add one term per string. Nil values
+// map to the zero vector.
+func (df *DataFrame) HashFeatures(name string, dim int) error {
+
+	if dim <= 0 {
+		return fmt.Errorf("Dimension must be positive, got %d.", dim)
+	}
+	indices, err := df.indices(name)
+	if err != nil {
+		return err
+	}
+	idx := indices[0]
+	values := make([]interface{}, df.N())
+	for i, row := range df.Data {
+		vec := make([]float64, dim)
+		switch v := row[idx].(type) {
+		case nil:
+		case string:
+			hashString(vec, v)
+		case []interface{}:
+			for j, x := range v {
+				s, ok := x.(string)
+				if !ok {
+					return fmt.Errorf("In frame %d, element %d of variable [%s] is of type %T. Must be of type string.",
+						i, j, name, x)
+				}
+				hashString(vec, s)
+			}
+		case []string:
+			for _, s := range v {
+				hashString(vec, s)
+			}
+		default:
+			return fmt.Errorf("In frame %d, variable [%s] is of type [%s]. Must be of type string.",
+				i, name, reflect.TypeOf(v).String())
+		}
+		values[i] = vec
+	}
+	return df.addVar(name+"_hash", values)
+}
+
+func hashString(vec []float64, s string) {
+
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	sum := h.Sum64()
+	sign := 1.0
+	if sum>>63 == 1 {
+		sign = -1
+	}
+	vec[(sum&(1<<63-1))%uint64(len(vec))] += sign
+}
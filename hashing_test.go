@@ -0,0 +1,49 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonum/floats"
+)
+
+func TestHashFeatures(t *testing.T) {
+
+	tmpDir := getTempDir()
+	f1, _ := createDataFiles(t, tmpDir)
+
+	df, dfe := ReadDataFrameFile(f1)
+	CheckError(t, dfe)
+
+	CheckError(t, df.HashFeatures("room", 16))
+	for i := 0; i < df.N(); i++ {
+		v, e := df.Float64Slice(i, "room_hash")
+		CheckError(t, e)
+		if len(v) != 16 {
+			t.Fatalf("dimension must be 16, not %d.", len(v))
+		}
+		var sum float64
+		for _, x := range v {
+			sum += math.Abs(x)
+		}
+		if sum != 1 {
+			t.Fatalf("expected a single non-zero element in %v.", v)
+		}
+	}
+
+	// Same string, same vector.
+	a, _ := df.Float64Slice(0, "room_hash")
+	b, _ := df.Float64Slice(1, "room_hash")
+	if !floats.Equal(a, b) {
+		t.Fatalf("vectors %v and %v must match.", a, b)
+	}
+
+	if e := df.HashFeatures("acceleration", 16); e == nil {
+		t.Fatalf("expected error for float variable.")
+	}
+}
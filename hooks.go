@@ -0,0 +1,50 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"time"
+)
+
+// Hooks are called around data set operations so that pipelines can be
+// traced, for example by starting and ending spans or by logging. Any hook
+// may be nil. Hooks are called from the goroutine that runs the operation.
+type Hooks struct {
+	// Called before a file is read.
+	OnFileStart func(fn string)
+	// Called after a file is read with the data frame or the error.
+	OnFileEnd func(fn string, df *DataFrame, err error, d time.Duration)
+	// Called after transform number i is applied to the data frame read
+	// from file fn, see TransformDataSet. df is the result of the transform.
+	OnTransform func(fn string, i int, df *DataFrame, err error, d time.Duration)
+}
+
+// Sets the hooks called by the data set. Nil removes the hooks.
+func (ds *DataSet) SetHooks(h *Hooks) {
+
+	ds.hooks = h
+}
+
+func (h *Hooks) fileStart(fn string) {
+
+	if h != nil && h.OnFileStart != nil {
+		h.OnFileStart(fn)
+	}
+}
+
+func (h *Hooks) fileEnd(fn string, df *DataFrame, err error, d time.Duration) {
+
+	if h != nil && h.OnFileEnd != nil {
+		h.OnFileEnd(fn, df, err, d)
+	}
+}
+
+func (h *Hooks) transform(fn string, i int, df *DataFrame, err error, d time.Duration) {
+
+	if h != nil && h.OnTransform != nil {
+		h.OnTransform(fn, i, df, err, d)
+	}
+}
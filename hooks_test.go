@@ -0,0 +1,62 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHooks(t *testing.T) {
+
+	tmpDir := getTempDir()
+	createDataFiles(t, tmpDir)
+	ds := &DataSet{Path: tmpDir + "data", Files: []string{"file1.json", "file2.json"}}
+
+	var events []string
+	ds.SetHooks(&Hooks{
+		OnFileStart: func(fn string) {
+			events = append(events, "start "+filepath.Base(fn))
+		},
+		OnFileEnd: func(fn string, df *DataFrame, err error, d time.Duration) {
+			events = append(events, fmt.Sprintf("end %s %d %v", filepath.Base(fn), df.N(), err))
+		},
+		OnTransform: func(fn string, i int, df *DataFrame, err error, d time.Duration) {
+			events = append(events, fmt.Sprintf("transform %s %d %v", fn, i, err))
+		},
+	})
+	out, e := ioutil.TempDir("", "dataframe-hooks")
+	CheckError(t, e)
+	defer os.RemoveAll(out)
+	fail := func(df *DataFrame) (*DataFrame, error) {
+		if df.BatchID == "24001-016" {
+			return nil, fmt.Errorf("bad batch")
+		}
+		return df, nil
+	}
+	_, e = TransformDataSet(ds, out, Decimate(2, DecimateKeep), fail)
+	if e == nil {
+		t.Fatalf("expected error.")
+	}
+	want := []string{
+		"start file1.json",
+		"end file1.json 6 <nil>",
+		"transform file1.json 0 <nil>",
+		"transform file1.json 1 <nil>",
+		"start file2.json",
+		"end file2.json 6 <nil>",
+		"transform file2.json 0 <nil>",
+		"transform file2.json 1 bad batch",
+	}
+	if strings.Join(events, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected events:\n%s", strings.Join(events, "\n"))
+	}
+}
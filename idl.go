@@ -0,0 +1,135 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// Names in Avro schemas and proto definitions.
+var idlName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+type avroField struct {
+	Name    string      `json:"name"`
+	Type    interface{} `json:"type"`
+	Default interface{} `json:"default"`
+}
+
+type avroRecord struct {
+	Type      string      `json:"type"`
+	Name      string      `json:"name"`
+	Namespace string      `json:"namespace,omitempty"`
+	Fields    []avroField `json:"fields"`
+}
+
+type avroArray struct {
+	Type  string `json:"type"`
+	Items string `json:"items"`
+}
+
+// Returns an Avro schema, in JSON, for the rows of data frames with the
+// schema. Rows are records with one field per variable. Every field is a
+// union with "null", so nil values are allowed, and defaults to null.
+// Vectors are arrays of doubles. Variables of type TypeAny are not
+// supported.
+func (s Schema) Avro(name, namespace string) ([]byte, error) {
+
+	if err := s.checkIDL(name); err != nil {
+		return nil, err
+	}
+	rec := avroRecord{Type: "record", Name: name, Namespace: namespace, Fields: make([]avroField, len(s))}
+	for j, v := range s {
+		var typ interface{}
+		switch v.Type {
+		case TypeFloat64:
+			typ = "double"
+		case TypeString:
+			typ = "string"
+		case TypeBool:
+			typ = "boolean"
+		case TypeVector:
+			typ = avroArray{Type: "array", Items: "double"}
+		}
+		rec.Fields[j] = avroField{Name: v.Name, Type: []interface{}{"null", typ}}
+	}
+	return json.MarshalIndent(rec, "", "  ")
+}
+
+// Returns a proto3 definition with a message for the rows of data frames
+// with the schema, and a message named message + "Frame" for whole data
+// frames, with the same fields as the JSON format. Scalars are optional so
+// nil values are distinguished from zero values; vectors are repeated
+// doubles. Fields are numbered in variable order. Variables of type TypeAny
+// are not supported.
+func (s Schema) Proto(pkg, message string) ([]byte, error) {
+
+	if err := s.checkIDL(message); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "syntax = \"proto3\";\n\n")
+	if pkg != "" {
+		fmt.Fprintf(&buf, "package %s;\n\n", pkg)
+	}
+	fmt.Fprintf(&buf, "message %s {\n", message)
+	for j, v := range s {
+		switch v.Type {
+		case TypeFloat64:
+			fmt.Fprintf(&buf, "  optional double %s = %d;\n", v.Name, j+1)
+		case TypeString:
+			fmt.Fprintf(&buf, "  optional string %s = %d;\n", v.Name, j+1)
+		case TypeBool:
+			fmt.Fprintf(&buf, "  optional bool %s = %d;\n", v.Name, j+1)
+		case TypeVector:
+			fmt.Fprintf(&buf, "  repeated double %s = %d;\n", v.Name, j+1)
+		}
+	}
+	fmt.Fprintf(&buf, "}\n\n")
+	fmt.Fprintf(&buf, "message %sFrame {\n", message)
+	fmt.Fprintf(&buf, "  string description = 1;\n")
+	fmt.Fprintf(&buf, "  string batchid = 2;\n")
+	fmt.Fprintf(&buf, "  repeated string var_names = 3;\n")
+	fmt.Fprintf(&buf, "  repeated %s data = 4;\n", message)
+	fmt.Fprintf(&buf, "  map<string, string> var_units = 5;\n")
+	fmt.Fprintf(&buf, "  map<string, string> properties = 6;\n")
+	fmt.Fprintf(&buf, "}\n")
+	return buf.Bytes(), nil
+}
+
+// Checks that the names and types can be expressed in an IDL.
+func (s Schema) checkIDL(name string) error {
+
+	if !idlName.MatchString(name) {
+		return fmt.Errorf("Invalid record name [%s].", name)
+	}
+	if err := checkUnique(s.names()); err != nil {
+		return err
+	}
+	for _, v := range s {
+		if !idlName.MatchString(v.Name) {
+			return fmt.Errorf("Variable name [%s] must start with a letter or underscore followed by letters, digits, or underscores.", v.Name)
+		}
+		switch v.Type {
+		case TypeFloat64, TypeString, TypeBool, TypeVector:
+		default:
+			return fmt.Errorf("Variable [%s] of type [%s] can't be exported.", v.Name, v.Type)
+		}
+	}
+	return nil
+}
+
+// Returns the variable names.
+func (s Schema) names() []string {
+
+	names := make([]string, len(s))
+	for j, v := range s {
+		names[j] = v.Name
+	}
+	return names
+}
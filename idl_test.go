@@ -0,0 +1,73 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSchemaAvro(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	b, e := InferSchema(df).Avro("Reading", "com.akualab.imu")
+	CheckError(t, e)
+
+	var rec struct {
+		Type, Name, Namespace string
+		Fields                []struct {
+			Name    string
+			Type    []interface{}
+			Default interface{}
+		}
+	}
+	CheckError(t, json.Unmarshal(b, &rec))
+	if rec.Type != "record" || rec.Name != "Reading" || rec.Namespace != "com.akualab.imu" || len(rec.Fields) != 3 {
+		t.Fatalf("unexpected schema %s.", b)
+	}
+	if rec.Fields[0].Type[1] != "string" || rec.Fields[2].Type[1] != "double" || rec.Fields[0].Default != nil {
+		t.Fatalf("unexpected fields %s.", b)
+	}
+	if arr, ok := rec.Fields[1].Type[1].(map[string]interface{}); !ok || arr["type"] != "array" || arr["items"] != "double" {
+		t.Fatalf("unexpected vector field %s.", b)
+	}
+
+	for _, s := range []Schema{
+		{{Name: "a-b", Type: TypeFloat64}},
+		{{Name: "a", Type: TypeAny}},
+		{{Name: "a", Type: TypeBool}, {Name: "a", Type: TypeBool}},
+	} {
+		if _, e = s.Avro("R", ""); e == nil {
+			t.Fatalf("expected error for schema %v.", s)
+		}
+	}
+	if _, e = InferSchema(df).Avro("my record", ""); e == nil {
+		t.Fatalf("expected error for record name.")
+	}
+}
+
+func TestSchemaProto(t *testing.T) {
+
+	s := Schema{{Name: "room", Type: TypeString}, {Name: "wifi", Type: TypeVector}, {Name: "moving", Type: TypeBool}}
+	b, e := s.Proto("akualab.imu", "Reading")
+	CheckError(t, e)
+	for _, want := range []string{
+		"syntax = \"proto3\";",
+		"package akualab.imu;",
+		"message Reading {\n  optional string room = 1;\n  repeated double wifi = 2;\n  optional bool moving = 3;\n}",
+		"message ReadingFrame {",
+		"repeated Reading data = 4;",
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Fatalf("expected %q in:\n%s", want, b)
+		}
+	}
+	if _, e = s.Proto("", "1Reading"); e == nil {
+		t.Fatalf("expected error for message name.")
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// A unique key index over a variable.
+type rowIndex struct {
+	name string
+	rows map[interface{}]int
+}
+
+// Uses variable name as a unique row key. Values must be strings or numbers
+// and must be unique. Nil values are not indexed.
+func (df *DataFrame) SetIndex(name string) error {
+
+	indices, err := df.indices(name)
+	if err != nil {
+		return err
+	}
+	idx := indices[0]
+	rows := make(map[interface{}]int, df.N())
+	for i, row := range df.Data {
+		if row[idx] == nil {
+			continue
+		}
+		key, err := indexKey(row[idx])
+		if err != nil {
+			return fmt.Errorf("In frame %d, variable [%s]: %s", i, name, err)
+		}
+		if j, ok := rows[key]; ok {
+			return fmt.Errorf("Duplicate key %v in frames %d and %d.", key, j, i)
+		}
+		rows[key] = i
+	}
+	df.index = &rowIndex{name: name, rows: rows}
+	return nil
+}
+
+// Returns the name of the index variable or an empty string if the frame
+// has no index.
+func (df *DataFrame) IndexName() string {
+
+	if df.index == nil {
+		return ""
+	}
+	return df.index.name
+}
+
+// Removes the row index.
+func (df *DataFrame) ResetIndex() {
+
+	df.index = nil
+}
+
+// Returns the row number for a key. Requires an index, see SetIndex.
+func (df *DataFrame) RowByKey(key interface{}) (int, error) {
+
+	if df.index == nil {
+		return -1, fmt.Errorf("The data frame has no index.")
+	}
+	k, err := indexKey(key)
+	if err != nil {
+		return -1, err
+	}
+	row, ok := df.index.rows[k]
+	if !ok {
+		return -1, fmt.Errorf("There is no row with key %v.", key)
+	}
+	return row, nil
+}
+
+// Normalizes a key value so that numbers of any type match float64 values
+// decoded from JSON.
+func indexKey(v interface{}) (interface{}, error) {
+
+	switch k := v.(type) {
+	case string, float64, bool:
+		return k, nil
+	case int:
+		return float64(k), nil
+	case int64:
+		return float64(k), nil
+	case int32:
+		return float64(k), nil
+	case float32:
+		return float64(k), nil
+	default:
+		return nil, fmt.Errorf("Key of type [%s] is not supported.", reflect.TypeOf(v))
+	}
+}
+
+// An Index maps the values of one or more variables to the matching row
+// numbers. The index is not updated when the frame changes; rebuild it
+// after adding or removing rows.
+type Index struct {
+	names []string
+	rows  map[string][]int
+}
+
+// Builds an index over the named variables, for example a batch id and a
+// timestamp. Keys don't need to be unique.
+func (df *DataFrame) BuildIndex(names ...string) (*Index, error) {
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("No variable names were specified, must provide at least one var name.")
+	}
+	indices, err := df.indices(names...)
+	if err != nil {
+		return nil, err
+	}
+	ix := &Index{names: names, rows: make(map[string][]int)}
+	values := make([]interface{}, len(indices))
+	for i, row := range df.Data {
+		for j, idx := range indices {
+			values[j] = row[idx]
+		}
+		key, err := compositeKey(values)
+		if err != nil {
+			return nil, fmt.Errorf("In frame %d: %s", i, err)
+		}
+		ix.rows[key] = append(ix.rows[key], i)
+	}
+	return ix, nil
+}
+
+// Returns the names of the indexed variables.
+func (ix *Index) Names() []string {
+
+	return ix.names
+}
+
+// Returns the row numbers, in ascending order, where the indexed variables
+// have the given values. There must be one value per indexed variable.
+func (ix *Index) LookupAll(values ...interface{}) ([]int, error) {
+
+	if len(values) != len(ix.names) {
+		return nil, fmt.Errorf("Got %d values, the index has %d variables.", len(values), len(ix.names))
+	}
+	key, err := compositeKey(values)
+	if err != nil {
+		return nil, err
+	}
+	return ix.rows[key], nil
+}
+
+// Encodes normalized values into a single map key.
+func compositeKey(values []interface{}) (string, error) {
+
+	var key []byte
+	for _, v := range values {
+		if v == nil {
+			key = append(key, "nil\x00"...)
+			continue
+		}
+		k, err := indexKey(v)
+		if err != nil {
+			return "", err
+		}
+		key = append(key, fmt.Sprintf("%T:%v\x00", k, k)...)
+	}
+	return string(key), nil
+}
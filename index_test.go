@@ -0,0 +1,84 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRowByKey(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(`{
+"var_names": ["uuid", "seq", "room"],
+"data": [["a1", 10, "BED5"], ["b2", 11, "BED5"], ["c3", 12, "DINING"]]
+}`))
+	CheckError(t, e)
+
+	if _, e = df.RowByKey("b2"); e == nil {
+		t.Fatalf("expected error without index.")
+	}
+	CheckError(t, df.SetIndex("uuid"))
+	if df.IndexName() != "uuid" {
+		t.Fatalf("index name must be uuid, not %s.", df.IndexName())
+	}
+	row, e := df.RowByKey("b2")
+	CheckError(t, e)
+	if row != 1 {
+		t.Fatalf("row must be 1, not %d.", row)
+	}
+	if _, e = df.RowByKey("zz"); e == nil {
+		t.Fatalf("expected error for missing key.")
+	}
+
+	// Numeric keys match any number type.
+	CheckError(t, df.SetIndex("seq"))
+	row, e = df.RowByKey(12)
+	CheckError(t, e)
+	if row != 2 {
+		t.Fatalf("row must be 2, not %d.", row)
+	}
+
+	// Keys must be unique.
+	if e = df.SetIndex("room"); e == nil {
+		t.Fatalf("expected error for duplicate keys.")
+	}
+	df.ResetIndex()
+	if df.IndexName() != "" {
+		t.Fatalf("index was not removed.")
+	}
+}
+
+func TestBuildIndex(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(`{
+"var_names": ["batchid", "ts", "room"],
+"data": [["b1", 1, "BED5"], ["b1", 2, "BED5"], ["b2", 1, "DINING"], ["b1", 2, "KITCHEN"], ["b2", 3, "BATH"]]
+}`))
+	CheckError(t, e)
+
+	ix, e := df.BuildIndex("batchid", "ts")
+	CheckError(t, e)
+
+	rows, e := ix.LookupAll("b1", 2)
+	CheckError(t, e)
+	if len(rows) != 2 || rows[0] != 1 || rows[1] != 3 {
+		t.Fatalf("expected rows [1 3], got %v.", rows)
+	}
+	rows, e = ix.LookupAll("b2", 1.0)
+	CheckError(t, e)
+	if len(rows) != 1 || rows[0] != 2 {
+		t.Fatalf("expected rows [2], got %v.", rows)
+	}
+	rows, e = ix.LookupAll("b3", 1)
+	CheckError(t, e)
+	if len(rows) != 0 {
+		t.Fatalf("expected no rows, got %v.", rows)
+	}
+	if _, e = ix.LookupAll("b1"); e == nil {
+		t.Fatalf("expected error for wrong number of values.")
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Names of the variables added by ReadInflux.
+const (
+	InfluxTime        = "time"
+	InfluxMeasurement = "measurement"
+)
+
+// Options for reading InfluxDB line protocol.
+type InfluxOptions struct {
+	// Timestamp precision: "ns" (default), "us", "ms", or "s".
+	Precision string `yaml:"precision"`
+}
+
+type influxPoint struct {
+	measurement string
+	values      map[string]interface{}
+	time        interface{}
+}
+
+// Reads a data frame from a file in InfluxDB line protocol, usually with
+// extension ".lp". The batch id
+// is set to the file name without extension. See ReadInflux.
+func ReadInfluxFile(fn string, opts InfluxOptions) (df *DataFrame, e error) {
+
+	f, e := openFile(fn)
+	if e != nil {
+		return
+	}
+	defer f.Close()
+	df, e = ReadInflux(f, opts)
+	if e != nil {
+		return nil, fmt.Errorf("file %s: %s", fn, e)
+	}
+	df.BatchID = csvBatchID(fn)
+	return
+}
+
+// Reads a data frame from InfluxDB line protocol, one row per point. The
+// variables are InfluxTime, an RFC 3339 string, and InfluxMeasurement,
+// followed by the tags as string variables and the fields in the order
+// they first appear. Float and integer fields are read as float64, boolean
+// fields as bool, and string fields as string. Keys that are missing in a
+// point are nil. Empty lines and comments starting with '#' are skipped.
+func ReadInflux(r io.Reader, opts InfluxOptions) (*DataFrame, error) {
+
+	var unit time.Duration
+	switch opts.Precision {
+	case "", "ns", "n":
+		unit = time.Nanosecond
+	case "us", "u":
+		unit = time.Microsecond
+	case "ms":
+		unit = time.Millisecond
+	case "s":
+		unit = time.Second
+	default:
+		return nil, fmt.Errorf("Unknown timestamp precision [%s].", opts.Precision)
+	}
+
+	tags := make([]string, 0)
+	fields := make([]string, 0)
+	kind := map[string]string{InfluxTime: "reserved", InfluxMeasurement: "reserved"}
+	points := make([]influxPoint, 0)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		s := strings.TrimSpace(scanner.Text())
+		if s == "" || s[0] == '#' {
+			continue
+		}
+		p, ptags, pfields, err := parseInfluxLine(s, unit)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", line, err)
+		}
+		for _, k := range ptags {
+			switch kind[k] {
+			case "":
+				kind[k] = "tag"
+				tags = append(tags, k)
+			case "tag":
+			default:
+				return nil, fmt.Errorf("line %d: tag [%s] conflicts with a field or reserved name.", line, k)
+			}
+		}
+		for _, k := range pfields {
+			switch kind[k] {
+			case "":
+				kind[k] = "field"
+				fields = append(fields, k)
+			case "field":
+			default:
+				return nil, fmt.Errorf("line %d: field [%s] conflicts with a tag or reserved name.", line, k)
+			}
+		}
+		points = append(points, p)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	names := append([]string{InfluxTime, InfluxMeasurement}, tags...)
+	names = append(names, fields...)
+	df := Empty(names...)
+	for _, p := range points {
+		row := make([]interface{}, len(names))
+		row[0] = p.time
+		row[1] = p.measurement
+		for j, name := range names[2:] {
+			row[j+2] = p.values[name]
+		}
+		df.Data = append(df.Data, row)
+	}
+	return df, nil
+}
+
+// Parses a line. Returns the point and the tag and field keys in order.
+func parseInfluxLine(s string, unit time.Duration) (p influxPoint, tags, fields []string, err error) {
+
+	sections := splitInflux(s, ' ', true)
+	if len(sections) < 2 || len(sections) > 3 {
+		err = fmt.Errorf("expected measurement, fields, and optional timestamp.")
+		return
+	}
+	p.values = make(map[string]interface{})
+
+	keys := splitInflux(sections[0], ',', false)
+	p.measurement = unescapeInflux(keys[0])
+	if p.measurement == "" {
+		err = fmt.Errorf("missing measurement.")
+		return
+	}
+	for _, kv := range keys[1:] {
+		k, v, ok := splitInfluxKV(kv)
+		if !ok {
+			err = fmt.Errorf("invalid tag [%s].", kv)
+			return
+		}
+		p.values[k] = v
+		tags = append(tags, k)
+	}
+
+	for _, kv := range splitInflux(sections[1], ',', true) {
+		k, raw, ok := splitInfluxKV(kv)
+		if !ok {
+			err = fmt.Errorf("invalid field [%s].", kv)
+			return
+		}
+		var v interface{}
+		if v, err = parseInfluxField(raw); err != nil {
+			err = fmt.Errorf("field [%s]: %s", k, err)
+			return
+		}
+		p.values[k] = v
+		fields = append(fields, k)
+	}
+
+	if len(sections) == 3 {
+		var ts int64
+		if ts, err = strconv.ParseInt(sections[2], 10, 64); err != nil {
+			err = fmt.Errorf("invalid timestamp [%s].", sections[2])
+			return
+		}
+		p.time = time.Unix(0, ts*int64(unit)).UTC().Format(time.RFC3339Nano)
+	}
+	return
+}
+
+// Splits s on sep characters that are not escaped with a backslash and, if
+// quotes is set, not inside double quotes.
+func splitInflux(s string, sep byte, quotes bool) []string {
+
+	parts := make([]string, 0)
+	var quoted bool
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\':
+			i++
+		case c == '"' && quotes:
+			quoted = !quoted
+		case c == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// Splits key=value at the first unescaped '=' and unescapes the key. The
+// value is unescaped unless it is a quoted string.
+func splitInfluxKV(s string) (k, v string, ok bool) {
+
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '=':
+			k, v = unescapeInflux(s[:i]), s[i+1:]
+			if !strings.HasPrefix(v, `"`) {
+				v = unescapeInflux(v)
+			}
+			return k, v, k != "" && v != ""
+		}
+	}
+	return
+}
+
+func unescapeInflux(s string) string {
+
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && strings.IndexByte(`, ="\`, s[i+1]) >= 0 {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func parseInfluxField(s string) (interface{}, error) {
+
+	switch {
+	case len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"':
+		return unescapeInflux(s[1 : len(s)-1]), nil
+	case s == "t" || s == "T" || s == "true" || s == "True" || s == "TRUE":
+		return true, nil
+	case s == "f" || s == "F" || s == "false" || s == "False" || s == "FALSE":
+		return false, nil
+	case strings.HasSuffix(s, "i"):
+		n, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+		return float64(n), err
+	case strings.HasSuffix(s, "u"):
+		n, err := strconv.ParseUint(s[:len(s)-1], 10, 64)
+		return float64(n), err
+	}
+	return strconv.ParseFloat(s, 64)
+}
@@ -0,0 +1,78 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const influxData = `# DML
+# CONTEXT-DATABASE: telemetry
+imu,device=ak-100,room=BED5 acceleration=1.3,rssi=-56i 1388534400000
+imu,device=ak-100,room=BED\ 5 acceleration=1.4,moving=t 1388534400020
+
+wifi,device=ak-100 ssid="home \"net\"",rssi=-80.5 1388534400040
+`
+
+func TestReadInflux(t *testing.T) {
+
+	df, e := ReadInflux(strings.NewReader(influxData), InfluxOptions{Precision: "ms"})
+	CheckError(t, e)
+	want := []string{"time", "measurement", "device", "room", "acceleration", "rssi", "moving", "ssid"}
+	if strings.Join(df.VarNames, ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected variables %v.", df.VarNames)
+	}
+	if df.N() != 3 {
+		t.Fatalf("expected 3 rows, got %d.", df.N())
+	}
+	if s, _ := df.String(1, "time"); s != "2014-01-01T00:00:00.02Z" {
+		t.Fatalf("unexpected time %s.", s)
+	}
+	if s, _ := df.String(1, "room"); s != "BED 5" {
+		t.Fatalf("unexpected room %s.", s)
+	}
+	v, e := df.Float64Slice(0, "acceleration", "rssi")
+	CheckError(t, e)
+	if v[0] != 1.3 || v[1] != -56 {
+		t.Fatalf("unexpected values %v.", v)
+	}
+	if df.Data[1][6] != true || df.Data[0][6] != nil || df.Data[2][3] != nil {
+		t.Fatalf("unexpected row %v.", df.Data)
+	}
+	if s, _ := df.String(2, "ssid"); s != `home "net"` {
+		t.Fatalf("unexpected ssid %s.", s)
+	}
+
+	for _, bad := range []string{
+		"imu",
+		"imu acceleration=x",
+		"imu,room=BED5 room=1",
+		"imu acceleration=1 123abc",
+	} {
+		if _, e = ReadInflux(strings.NewReader(bad), InfluxOptions{}); e == nil {
+			t.Fatalf("expected error for %q.", bad)
+		}
+	}
+}
+
+func TestDataSetInflux(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-influx")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	CheckError(t, ioutil.WriteFile(filepath.Join(dir, "imu.lp"), []byte(influxData), 0644))
+
+	ds := &DataSet{Path: dir, Files: []string{"imu.lp"}, Influx: InfluxOptions{Precision: "ms"}}
+	df, e := ds.Next()
+	CheckError(t, e)
+	if df.N() != 3 || df.BatchID != "imu" {
+		t.Fatalf("unexpected frame %+v.", df)
+	}
+}
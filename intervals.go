@@ -0,0 +1,73 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"sort"
+)
+
+type interval struct {
+	start, end float64
+	label      interface{}
+	row        int
+}
+
+type intervals []interval
+
+func (s intervals) Len() int           { return len(s) }
+func (s intervals) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s intervals) Less(i, j int) bool { return s[i].start < s[j].start }
+
+// Adds variable labelVar to the data frame with the label of the interval
+// that contains the value of timeVar in each row. Intervals are read from
+// the labels frame, one per row, with start time startVar, end time endVar,
+// and label labelVar. Intervals include the start and exclude the end; rows
+// outside every interval get a nil label. Times are float64 seconds or RFC
+// 3339 strings in both frames. Overlapping intervals return an error.
+func (df *DataFrame) ApplyIntervals(labels *DataFrame, timeVar, startVar, endVar, labelVar string) error {
+
+	starts, err := labels.timeColumn(startVar)
+	if err != nil {
+		return err
+	}
+	ends, err := labels.timeColumn(endVar)
+	if err != nil {
+		return err
+	}
+	indices, err := labels.indices(labelVar)
+	if err != nil {
+		return err
+	}
+	lidx := indices[0]
+	ivs := make(intervals, len(starts))
+	for i := range starts {
+		if ends[i] < starts[i] {
+			return fmt.Errorf("In label frame %d, interval ends before it starts.", i)
+		}
+		ivs[i] = interval{start: starts[i], end: ends[i], label: labels.Data[i][lidx], row: i}
+	}
+	sort.Sort(ivs)
+	for i := 1; i < len(ivs); i++ {
+		if ivs[i].start < ivs[i-1].end {
+			return fmt.Errorf("Label intervals in frames %d and %d overlap.", ivs[i-1].row, ivs[i].row)
+		}
+	}
+
+	times, err := df.timeColumn(timeVar)
+	if err != nil {
+		return err
+	}
+	values := make([]interface{}, len(times))
+	for i, t := range times {
+		// Last interval that starts at or before t.
+		k := sort.Search(len(ivs), func(k int) bool { return ivs[k].start > t }) - 1
+		if k >= 0 && t < ivs[k].end {
+			values[i] = ivs[k].label
+		}
+	}
+	return df.addVar(labelVar, values)
+}
@@ -0,0 +1,42 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"testing"
+)
+
+func TestApplyIntervals(t *testing.T) {
+
+	df := Empty("t", "wifi")
+	for _, ts := range []string{
+		"2014-01-01T10:00:00Z",
+		"2014-01-01T10:00:30Z",
+		"2014-01-01T10:01:00Z",
+		"2014-01-01T10:02:30Z",
+		"2014-01-01T10:05:00Z",
+	} {
+		df.Data = append(df.Data, []interface{}{ts, -50.0})
+	}
+
+	labels := Empty("start", "end", "room")
+	labels.Data = append(labels.Data,
+		[]interface{}{"2014-01-01T10:02:00Z", "2014-01-01T10:04:00Z", "DINING"},
+		[]interface{}{"2014-01-01T10:00:00Z", "2014-01-01T10:01:00Z", "BED5"},
+	)
+	CheckError(t, df.ApplyIntervals(labels, "t", "start", "end", "room"))
+	for i, want := range []interface{}{"BED5", "BED5", nil, "DINING", nil} {
+		if got := df.Data[i][2]; got != want {
+			t.Fatalf("row %d: expected %v, got %v.", i, want, got)
+		}
+	}
+
+	labels.Data = append(labels.Data, []interface{}{"2014-01-01T10:03:00Z", "2014-01-01T10:06:00Z", "BATH"})
+	df2 := Empty("t")
+	if e := df2.ApplyIntervals(labels, "t", "start", "end", "room"); e == nil {
+		t.Fatalf("expected error for overlapping intervals.")
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"io"
+)
+
+// A Float64Iterator returns the float64 variables of each row as a slice,
+// see Float64Slice. Rows dropped by the NonFiniteDrop or NADrop policies
+// are skipped. Iteration stops at the end of the data or at the first error:
+//
+//	it := ds.Float64Iterator("wifi", "acceleration")
+//	for it.Next() {
+//		v := it.Value()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Float64Iterator struct {
+	next  func() (*DataFrame, error)
+	names []string
+	df    *DataFrame
+	row   int
+	value []float64
+	err   error
+	done  bool
+}
+
+// Returns an iterator over the rows of the data frame.
+func (df *DataFrame) Float64Iterator(names ...string) *Float64Iterator {
+
+	var done bool
+	next := func() (*DataFrame, error) {
+		if done {
+			return nil, io.EOF
+		}
+		done = true
+		return df, nil
+	}
+	return &Float64Iterator{next: next, names: names}
+}
+
+// Resets the data set and returns an iterator over the rows of all the
+// files.
+func (ds *DataSet) Float64Iterator(names ...string) *Float64Iterator {
+
+	ds.Reset()
+	return &Float64Iterator{next: ds.Next, names: names}
+}
+
+// Advances to the next row. Returns false at the end of the data or when
+// an error occurs.
+func (it *Float64Iterator) Next() bool {
+
+	if it.done {
+		return false
+	}
+	for {
+		for it.df == nil || it.row >= it.df.N() {
+			df, err := it.next()
+			if err != nil {
+				if err != io.EOF {
+					it.err = err
+				}
+				it.done = true
+				it.value = nil
+				return false
+			}
+			it.df, it.row = df, 0
+		}
+		it.value, it.err = it.df.Float64Slice(it.row, it.names...)
+		it.row++
+		if it.err == ErrDroppedRow {
+			it.err = nil
+			continue
+		}
+		if it.err != nil {
+			it.done = true
+			it.value = nil
+			return false
+		}
+		return true
+	}
+}
+
+// Returns the values of the current row.
+func (it *Float64Iterator) Value() []float64 {
+
+	return it.value
+}
+
+// Returns the error that stopped the iteration, or nil at the end of the
+// data.
+func (it *Float64Iterator) Err() error {
+
+	return it.err
+}
+
+// Returns a channel with the float64 variables of each row, see
+// Float64Slice, and a channel that receives the error that stops the
+// iteration, if any. Both channels are closed at the end. Read the values
+// channel to the end before reading the error channel.
+func (df *DataFrame) Float64SliceChan(names ...string) (<-chan []float64, <-chan error) {
+
+	return iterChan(df.Float64Iterator(names...))
+}
+
+// Resets the data set and returns channels with the float64 variables of
+// every row and the error that stops the iteration, see
+// DataFrame.Float64SliceChan.
+func (ds *DataSet) Float64SliceChan(names ...string) (<-chan []float64, <-chan error) {
+
+	return iterChan(ds.Float64Iterator(names...))
+}
+
+// Applies fn to every vector received from in. The error channel receives
+// the first error returned by fn, after which the rest of in is discarded.
+func mapChan(in <-chan []float64, fn func([]float64) ([]float64, error)) (<-chan []float64, <-chan error) {
+
+	ch := make(chan []float64, BUFFER_SIZE)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		defer close(ch)
+		for x := range in {
+			y, e := fn(x)
+			if e != nil {
+				errc <- e
+				for _ = range in {
+				}
+				return
+			}
+			ch <- y
+		}
+	}()
+	return ch, errc
+}
+
+func iterChan(it *Float64Iterator) (<-chan []float64, <-chan error) {
+
+	ch := make(chan []float64, BUFFER_SIZE)
+	errc := make(chan error, 1)
+	go func() {
+		for it.Next() {
+			ch <- it.Value()
+		}
+		close(ch)
+		if it.Err() != nil {
+			errc <- it.Err()
+		}
+		close(errc)
+	}()
+	return ch, errc
+}
@@ -0,0 +1,62 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFloat64Iterator(t *testing.T) {
+
+	tmpDir := getTempDir()
+	createDataFiles(t, tmpDir)
+	ds := &DataSet{Path: tmpDir + "data", Files: []string{"file1.json", "file2.json"}}
+
+	it := ds.Float64Iterator("wifi", "acceleration")
+	var n int
+	for it.Next() {
+		if len(it.Value()) != 3 {
+			t.Fatalf("unexpected value %v.", it.Value())
+		}
+		n++
+	}
+	CheckError(t, it.Err())
+	if n != 12 {
+		t.Fatalf("expected 12 rows, got %d.", n)
+	}
+
+	// Errors are returned instead of terminating the program.
+	ds.Files = append(ds.Files, "missing.json")
+	ch, errc := ds.Float64SliceChan("acceleration")
+	n = 0
+	for _ = range ch {
+		n++
+	}
+	if e := <-errc; e == nil || n != 12 {
+		t.Fatalf("expected error after 12 rows, got %v after %d rows.", e, n)
+	}
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	df.Data[4][2] = "x"
+	it = df.Float64Iterator("acceleration")
+	for n = 0; it.Next(); n++ {
+	}
+	if it.Err() == nil || n != 4 {
+		t.Fatalf("expected error after 4 rows, got %v after %d rows.", it.Err(), n)
+	}
+	if it.Next() {
+		t.Fatalf("iterator must stop after an error.")
+	}
+
+	ch, errc = df.Float64SliceChan("room")
+	for _ = range ch {
+	}
+	if <-errc == nil {
+		t.Fatalf("expected error for string variable.")
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+)
+
+// JoinType selects the rows kept by Join.
+type JoinType int
+
+const (
+	// Rows with a match in both frames.
+	InnerJoin JoinType = iota
+	// Every row of the left frame.
+	LeftJoin
+	// Every row of the right frame.
+	RightJoin
+	// Every row of both frames.
+	OuterJoin
+)
+
+// Returns a new data frame with the rows of a and b that have the same
+// values of the key variables, see JoinWith. Duplicate variable names get
+// the default suffixes.
+func Join(a, b *DataFrame, on []string, how JoinType) (*DataFrame, error) {
+
+	return JoinWith(a, b, on, how, nil)
+}
+
+// Returns a new data frame with the rows of a and b that have the same
+// values of the key variables. The key variables must exist in both frames
+// and hold strings, float64, or bool values. Rows with a nil key never
+// match. The output has the variables of a followed by the variables of b
+// that are not keys; names are resolved by nr. Rows are in the order of a,
+// each followed by its matches in the order of b, then the unmatched rows of
+// b for right and outer joins. Variables without a matching row are nil;
+// keys of unmatched rows of b are taken from b. If b has an index on the
+// only key variable, see SetIndex, matches are found with the index. If a
+// has an index, the output keeps it and an error is returned if its keys
+// are no longer unique. Description, BatchID, and properties are those of
+// a. Cell values are not deep copied.
+func JoinWith(a, b *DataFrame, on []string, how JoinType, nr *NameResolver) (*DataFrame, error) {
+
+	if len(on) == 0 {
+		return nil, fmt.Errorf("Join requires at least one key variable.")
+	}
+	if how < InnerJoin || how > OuterJoin {
+		return nil, fmt.Errorf("Unknown join type %d.", how)
+	}
+	aKeys, err := a.indices(on...)
+	if err != nil {
+		return nil, err
+	}
+	bKeys, err := b.indices(on...)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]bool, len(on))
+	for _, name := range on {
+		keys[name] = true
+	}
+	left, right, err := nr.resolve(a.VarNames, b.VarNames, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	// Right columns that are not keys, and the position of each key in a.
+	var bCols []int
+	names := append([]string(nil), left...)
+	for j, name := range b.VarNames {
+		if !keys[name] {
+			bCols = append(bCols, j)
+			names = append(names, right[j])
+		}
+	}
+	bKeyPos := make(map[int]int, len(on))
+	for k, idx := range aKeys {
+		bKeyPos[idx] = bKeys[k]
+	}
+
+	// Use the index of b if it is on the key, otherwise build one.
+	indexed := len(on) == 1 && b.index != nil && b.index.name == on[0]
+	bRows := make(map[string][]int)
+	for i, row := range b.Data {
+		if indexed {
+			break
+		}
+		key, ok, err := joinKey(row, bKeys, on, i)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			bRows[key] = append(bRows[key], i)
+		}
+	}
+
+	out := &DataFrame{
+		Description: a.Description,
+		BatchID:     a.BatchID,
+		VarNames:    names,
+		Data:        make([][]interface{}, 0, a.N()),
+		Properties:  copyProperties(a.Properties),
+	}
+	matched := make([]bool, b.N())
+	for i, row := range a.Data {
+		key, ok, err := joinKey(row, aKeys, on, i)
+		if err != nil {
+			return nil, err
+		}
+		var matches []int
+		switch {
+		case ok && indexed:
+			k, _ := indexKey(row[aKeys[0]])
+			if r, found := b.index.rows[k]; found {
+				matches = []int{r}
+			}
+		case ok:
+			matches = bRows[key]
+		}
+		for _, r := range matches {
+			matched[r] = true
+			out.Data = append(out.Data, joinRow(row, len(left), b.Data[r], bCols))
+		}
+		if len(matches) == 0 && (how == LeftJoin || how == OuterJoin) {
+			out.Data = append(out.Data, joinRow(row, len(left), nil, bCols))
+		}
+	}
+	if how == RightJoin || how == OuterJoin {
+		for r, row := range b.Data {
+			if matched[r] {
+				continue
+			}
+			values := joinRow(nil, len(left), row, bCols)
+			for idx, j := range bKeyPos {
+				values[idx] = row[j]
+			}
+			out.Data = append(out.Data, values)
+		}
+	}
+
+	for i, name := range names {
+		var u string
+		var ok bool
+		if i < len(left) {
+			u, ok = a.VarUnits[a.VarNames[i]]
+		} else {
+			u, ok = b.VarUnits[b.VarNames[bCols[i-len(left)]]]
+		}
+		if ok {
+			if out.VarUnits == nil {
+				out.VarUnits = make(map[string]string)
+			}
+			out.VarUnits[name] = u
+		}
+	}
+	out.resetVarMap()
+	if a.index != nil {
+		if err := out.SetIndex(left[a.varMap[a.index.name]]); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// Returns the encoding of the key values of a row, or false if a key is nil.
+func joinKey(row []interface{}, indices []int, names []string, i int) (string, bool, error) {
+
+	var buf bytes.Buffer
+	for j, idx := range indices {
+		if row[idx] == nil {
+			return "", false, nil
+		}
+		if !writeKey(&buf, row[idx]) {
+			return "", false, fmt.Errorf("In frame %d, key variable [%s] is of type [%s]. Must be of type string, float64, or bool.",
+				i, names[j], reflect.TypeOf(row[idx]).String())
+		}
+	}
+	return buf.String(), true, nil
+}
+
+// Returns the n values of a left row followed by the right columns. Values
+// of a nil row are nil.
+func joinRow(left []interface{}, n int, right []interface{}, cols []int) []interface{} {
+
+	row := make([]interface{}, n+len(cols))
+	copy(row, left)
+	if right != nil {
+		for k, c := range cols {
+			row[n+k] = right[c]
+		}
+	}
+	return row
+}
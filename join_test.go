@@ -0,0 +1,119 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestJoin(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	df.Data[5][0] = nil
+	rooms := &DataFrame{
+		VarNames: []string{"room", "floor", "acceleration"},
+		Data: [][]interface{}{
+			{"BED5", 2.0, 0.1},
+			{"KITCHEN", 1.0, 0.2},
+			{"BED5", 3.0, 0.3},
+		},
+		VarUnits: map[string]string{"floor": "level"},
+	}
+	rooms.resetVarMap()
+
+	// Returns the room and floor of each row.
+	rows := func(out *DataFrame) string {
+		var s []string
+		for i := range out.Data {
+			s = append(s, fmt.Sprintf("%v:%v", out.Data[i][0], out.Data[i][out.varMap["floor"]]))
+		}
+		return strings.Join(s, " ")
+	}
+	for _, c := range []struct {
+		how  JoinType
+		want string
+	}{
+		{InnerJoin, "BED5:2 BED5:3 BED5:2 BED5:3 BED5:2 BED5:3"},
+		{LeftJoin, "BED5:2 BED5:3 BED5:2 BED5:3 BED5:2 BED5:3 DINING:<nil> DINING:<nil> <nil>:<nil>"},
+		{RightJoin, "BED5:2 BED5:3 BED5:2 BED5:3 BED5:2 BED5:3 KITCHEN:1"},
+		{OuterJoin, "BED5:2 BED5:3 BED5:2 BED5:3 BED5:2 BED5:3 DINING:<nil> DINING:<nil> <nil>:<nil> KITCHEN:1"},
+	} {
+		out, e := Join(df, rooms, []string{"room"}, c.how)
+		CheckError(t, e)
+		if got := rows(out); got != c.want {
+			t.Fatalf("join %d: expected %s, got %s.", c.how, c.want, got)
+		}
+	}
+
+	out, e := Join(df, rooms, []string{"room"}, OuterJoin)
+	CheckError(t, e)
+	want := "room,wifi,acceleration_x,floor,acceleration_y"
+	if strings.Join(out.VarNames, ",") != want || out.VarUnits["floor"] != "level" || out.BatchID != df.BatchID {
+		t.Fatalf("unexpected frame %v %v.", out.VarNames, out.VarUnits)
+	}
+	if last := out.Data[out.N()-1]; last[1] != nil || last[4] != 0.2 {
+		t.Fatalf("unexpected unmatched row %v.", last)
+	}
+
+	out, e = JoinWith(df, rooms, []string{"room"}, InnerJoin, &NameResolver{RightPrefix: "r_"})
+	CheckError(t, e)
+	if strings.Join(out.VarNames, ",") != "room,wifi,acceleration,r_floor,r_acceleration" {
+		t.Fatalf("unexpected names %v.", out.VarNames)
+	}
+
+	if _, e = Join(df, rooms, []string{"wifi"}, InnerJoin); e == nil {
+		t.Fatalf("expected error for missing key.")
+	}
+	if _, e = Join(df, df, []string{"wifi"}, InnerJoin); e == nil {
+		t.Fatalf("expected error for vector key.")
+	}
+	if _, e = Join(df, rooms, nil, InnerJoin); e == nil {
+		t.Fatalf("expected error for no keys.")
+	}
+}
+
+func TestJoinIndex(t *testing.T) {
+
+	ids := &DataFrame{
+		VarNames: []string{"id", "room"},
+		Data:     [][]interface{}{{"a", "BED5"}, {"b", "DINING"}, {"c", "KITCHEN"}},
+	}
+	ids.resetVarMap()
+	CheckError(t, ids.SetIndex("id"))
+	floors := &DataFrame{
+		VarNames: []string{"room", "floor"},
+		Data:     [][]interface{}{{"KITCHEN", 1.0}, {"BED5", 2.0}},
+	}
+	floors.resetVarMap()
+	CheckError(t, floors.SetIndex("room"))
+
+	out, e := Join(ids, floors, []string{"room"}, OuterJoin)
+	CheckError(t, e)
+	want := fmt.Sprint([][]interface{}{{"a", "BED5", 2.0}, {"b", "DINING", nil}, {"c", "KITCHEN", 1.0}})
+	if got := fmt.Sprint(out.Data); got != want {
+		t.Fatalf("expected %s, got %s.", want, got)
+	}
+	if r, e := out.RowByKey("c"); e != nil || r != 2 {
+		t.Fatalf("expected row 2 for key c, got %d %v.", r, e)
+	}
+
+	// Same result without the index of the right frame.
+	floors.ResetIndex()
+	out, e = Join(ids, floors, []string{"room"}, OuterJoin)
+	CheckError(t, e)
+	if got := fmt.Sprint(out.Data); got != want {
+		t.Fatalf("expected %s, got %s.", want, got)
+	}
+
+	// Keys of the left index must stay unique.
+	floors.Data = append(floors.Data, []interface{}{"BED5", 3.0})
+	if _, e = Join(ids, floors, []string{"room"}, InnerJoin); e == nil {
+		t.Fatalf("expected error for duplicate key.")
+	}
+}
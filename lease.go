@@ -0,0 +1,143 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// Extension of the lease file that guards a manifest, see DataSetWriter.
+const LeaseExt = ".lock"
+
+// How long to wait for a lease, and the age after which a lease left by a
+// crashed process is broken. Leases are held for the time it takes to
+// update a manifest.
+var (
+	LeaseTimeout = 30 * time.Second
+	LeaseStale   = 2 * time.Minute
+)
+
+// Acquires the lease file fn, which is created exclusively and holds the
+// process id and host name. Waits up to LeaseTimeout for other holders.
+// While the lease is held, its modification time is refreshed every
+// LeaseStale/3 so that a slow holder is not taken for a crashed one.
+// Leases are advisory: they only exclude processes that use them. Returns a
+// function that releases the lease.
+func acquireLease(fn string) (release func(), err error) {
+
+	host, _ := os.Hostname()
+	owner := fmt.Sprintf("%d@%s %d\n", os.Getpid(), host, time.Now().UnixNano())
+	deadline := time.Now().Add(LeaseTimeout)
+	wait := time.Millisecond
+	for {
+		f, err := os.OpenFile(fn, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, err = f.WriteString(owner)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				os.Remove(fn)
+				return nil, err
+			}
+			return holdLease(fn, owner), nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		fi, err := os.Stat(fn)
+		switch {
+		case os.IsNotExist(err):
+			continue
+		case err != nil:
+			return nil, err
+		case time.Since(fi.ModTime()) > LeaseStale:
+			if err = breakLease(fn, fi); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if time.Now().After(deadline) {
+			b, _ := ioutil.ReadFile(fn)
+			return nil, fmt.Errorf("file %s: held by %s", fn, b)
+		}
+		time.Sleep(wait)
+		if wait < 100*time.Millisecond {
+			wait *= 2
+		}
+	}
+}
+
+// Refreshes the modification time of the lease until the returned function
+// is called, which removes the lease if it is still owned.
+func holdLease(fn, owner string) (release func()) {
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	ticker := time.NewTicker(LeaseStale / 3)
+	go func() {
+		defer close(stopped)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if !ownsLease(fn, owner) {
+					return
+				}
+				now := time.Now()
+				os.Chtimes(fn, now, now)
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-stopped
+			if ownsLease(fn, owner) {
+				os.Remove(fn)
+			}
+		})
+	}
+}
+
+func ownsLease(fn, owner string) bool {
+
+	b, err := ioutil.ReadFile(fn)
+	return err == nil && string(b) == owner
+}
+
+// Breaks the stale lease fn. The lease is moved aside first and removed
+// only if it is the file found to be stale; a lease created or refreshed in
+// the meantime is put back.
+func breakLease(fn string, stale os.FileInfo) error {
+
+	aside := fmt.Sprintf("%s.%d.%d", fn, os.Getpid(), time.Now().UnixNano())
+	if err := os.Rename(fn, aside); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	fi, err := os.Stat(aside)
+	if err != nil {
+		return err
+	}
+	if os.SameFile(fi, stale) && time.Since(fi.ModTime()) > LeaseStale {
+		return os.Remove(aside)
+	}
+	// Put the live lease back unless another lease was created.
+	if err = os.Link(aside, fn); err != nil && !os.IsExist(err) {
+		return err
+	}
+	return os.Remove(aside)
+}
@@ -0,0 +1,76 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestLease(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-lease")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	defer func(timeout, stale time.Duration) {
+		LeaseTimeout, LeaseStale = timeout, stale
+	}(LeaseTimeout, LeaseStale)
+	LeaseTimeout = 50 * time.Millisecond
+	fn := filepath.Join(dir, ManifestFile+LeaseExt)
+
+	release, e := acquireLease(fn)
+	CheckError(t, e)
+	if _, e = acquireLease(fn); e == nil {
+		t.Fatalf("expected error for held lease.")
+	}
+	release()
+	release, e = acquireLease(fn)
+	CheckError(t, e)
+
+	// A lease older than LeaseStale is broken.
+	old := time.Now().Add(-time.Hour)
+	CheckError(t, os.Chtimes(fn, old, old))
+	LeaseStale = time.Minute
+	release2, e := acquireLease(fn)
+	CheckError(t, e)
+	release2()
+	release()
+	if fileExists(fn) {
+		t.Fatalf("lease file must be removed.")
+	}
+
+	// A held lease is refreshed so it never becomes stale.
+	n := runtime.NumGoroutine()
+	LeaseStale = 150 * time.Millisecond
+	release, e = acquireLease(fn)
+	CheckError(t, e)
+	time.Sleep(400 * time.Millisecond)
+	if _, e = acquireLease(fn); e == nil {
+		t.Fatalf("expected error for refreshed lease.")
+	}
+
+	// A lease refreshed after it was found stale is not broken.
+	fi, e := os.Stat(fn)
+	CheckError(t, e)
+	CheckError(t, breakLease(fn, fi))
+	if !fileExists(fn) {
+		t.Fatalf("live lease must be put back.")
+	}
+	matches, _ := filepath.Glob(fn + ".*")
+	if len(matches) != 0 {
+		t.Fatalf("unexpected files %v.", matches)
+	}
+	release()
+	release()
+	if fileExists(fn) {
+		t.Fatalf("lease file must be removed.")
+	}
+	checkGoroutines(t, n)
+}
@@ -0,0 +1,256 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Limits enforced while parsing a data frame. A zero value means no limit.
+type ReadLimits struct {
+	// Maximum number of bytes read from the input.
+	MaxBytes int64 `yaml:"max_bytes"`
+	// Maximum number of rows.
+	MaxRows int `yaml:"max_rows"`
+	// Maximum number of elements in a vector value.
+	MaxVecLen int `yaml:"max_vec_len"`
+	// Maximum length in bytes of a string value.
+	MaxStringLen int `yaml:"max_string_len"`
+}
+
+// Reads a data frame from file enforcing limits. See ReadDataFrameLimits.
+func ReadDataFrameFileLimits(fn string, limits ReadLimits) (df *DataFrame, e error) {
+
+	f, e := os.Open(fn)
+	if e != nil {
+		return
+	}
+	defer f.Close()
+	df, e = ReadDataFrameLimits(f, limits)
+	if e != nil {
+		return
+	}
+	df.setReportFile(fn)
+	return
+}
+
+// Reads a data frame from an io.Reader enforcing limits. Rows are decoded one
+// at a time so parsing stops as soon as a limit is exceeded.
+func ReadDataFrameLimits(r io.Reader, limits ReadLimits) (df *DataFrame, e error) {
+
+	lr := &limitedReader{r: stripBOM(r), n: limits.MaxBytes}
+	dec := json.NewDecoder(lr)
+	df = &DataFrame{}
+	e = decodeDataFrame(dec, df, func(row []interface{}) error {
+		if limits.MaxRows > 0 && len(df.Data) >= limits.MaxRows {
+			return fmt.Errorf("Number of rows exceeds limit of %d.", limits.MaxRows)
+		}
+		if e := limits.checkRow(row); e != nil {
+			return fmt.Errorf("In frame %d, %s", len(df.Data), e)
+		}
+		df.Data = append(df.Data, row)
+		return nil
+	})
+	if lr.exceeded {
+		return nil, fmt.Errorf("Input exceeds limit of %d bytes.", limits.MaxBytes)
+	}
+	if e != nil {
+		return nil, e
+	}
+	if e = limits.checkHeader(df); e != nil {
+		return nil, e
+	}
+	if e = checkRowWidths(df); e != nil {
+		return nil, e
+	}
+	df.resetVarMap()
+	df.coerceJSON()
+	return
+}
+
+// Decodes a data frame from a JSON stream. Rows in the data array are passed
+// to fn one at a time instead of being stored in df.
+func decodeDataFrame(dec *json.Decoder, df *DataFrame, fn func(row []interface{}) error) error {
+
+	if e := expectDelim(dec, '{'); e != nil {
+		return e
+	}
+	for dec.More() {
+		t, e := dec.Token()
+		if e != nil {
+			return e
+		}
+		if key, _ := t.(string); key == "data" {
+			e = decodeRows(dec, fn)
+		} else {
+			e = decodeField(dec, df, key)
+		}
+		if e != nil {
+			return e
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+// Decodes the value of a data frame field other than the data array.
+// Unknown fields are skipped.
+func decodeField(dec *json.Decoder, df *DataFrame, key string) error {
+
+	switch key {
+	case "description":
+		return dec.Decode(&df.Description)
+	case "batchid":
+		return dec.Decode(&df.BatchID)
+	case "var_names":
+		return dec.Decode(&df.VarNames)
+	case "properties":
+		return dec.Decode(&df.Properties)
+	case "var_units":
+		return dec.Decode(&df.VarUnits)
+	}
+	var skip json.RawMessage
+	return dec.Decode(&skip)
+}
+
+// Decodes the data array one row at a time.
+func decodeRows(dec *json.Decoder, fn func(row []interface{}) error) error {
+
+	if !dec.More() {
+		return fmt.Errorf("missing data array.")
+	}
+	t, e := dec.Token()
+	if e != nil {
+		return e
+	}
+	if t == nil {
+		return nil
+	}
+	if d, ok := t.(json.Delim); !ok || d != '[' {
+		return fmt.Errorf("data must be an array, found %v.", t)
+	}
+	for dec.More() {
+		var row []interface{}
+		if e = dec.Decode(&row); e != nil {
+			return e
+		}
+		restoreRow(row)
+		if e = fn(row); e != nil {
+			return e
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+
+	t, e := dec.Token()
+	if e != nil {
+		return e
+	}
+	if d, ok := t.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("expected %v, found %v.", delim, t)
+	}
+	return nil
+}
+
+// Returns an error if a row doesn't have one value per variable.
+func checkRowWidths(df *DataFrame) error {
+
+	for i, row := range df.Data {
+		if len(row) != len(df.VarNames) {
+			return fmt.Errorf("In frame %d, row has %d values, expected %d.", i, len(row), len(df.VarNames))
+		}
+	}
+	return nil
+}
+
+// Checks a data frame decoded by a reader that doesn't enforce the limits
+// while parsing.
+func (limits ReadLimits) checkDataFrame(df *DataFrame) error {
+
+	if limits.MaxRows > 0 && df.N() > limits.MaxRows {
+		return fmt.Errorf("Number of rows exceeds limit of %d.", limits.MaxRows)
+	}
+	for i, row := range df.Data {
+		if e := limits.checkRow(row); e != nil {
+			return fmt.Errorf("In frame %d, %s", i, e)
+		}
+	}
+	return limits.checkHeader(df)
+}
+
+func (limits ReadLimits) checkHeader(df *DataFrame) error {
+
+	for _, s := range append([]string{df.Description, df.BatchID}, df.VarNames...) {
+		if e := limits.checkString(s); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+func (limits ReadLimits) checkRow(row []interface{}) error {
+
+	for _, v := range row {
+		n := -1
+		switch x := v.(type) {
+		case string:
+			if e := limits.checkString(x); e != nil {
+				return e
+			}
+		case []interface{}:
+			n = len(x)
+		case []float64:
+			n = len(x)
+		}
+		if limits.MaxVecLen > 0 && n > limits.MaxVecLen {
+			return fmt.Errorf("vector length %d exceeds limit of %d.", n, limits.MaxVecLen)
+		}
+	}
+	return nil
+}
+
+func (limits ReadLimits) checkString(s string) error {
+
+	if limits.MaxStringLen > 0 && len(s) > limits.MaxStringLen {
+		return fmt.Errorf("string length %d exceeds limit of %d.", len(s), limits.MaxStringLen)
+	}
+	return nil
+}
+
+// Returns an error after n bytes have been read. A non-positive n means no limit.
+type limitedReader struct {
+	r        io.Reader
+	n        int64
+	read     int64
+	exceeded bool
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+
+	if l.n <= 0 {
+		return l.r.Read(p)
+	}
+	if l.read >= l.n {
+		// Probe for one more byte to distinguish EOF from an oversized input.
+		var b [1]byte
+		n, e := l.r.Read(b[:])
+		if n > 0 {
+			l.exceeded = true
+			return 0, fmt.Errorf("input exceeds limit of %d bytes.", l.n)
+		}
+		return 0, e
+	}
+	if int64(len(p)) > l.n-l.read {
+		p = p[:l.n-l.read]
+	}
+	n, e := l.r.Read(p)
+	l.read += int64(n)
+	return n, e
+}
@@ -0,0 +1,138 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadDataFrameLimits(t *testing.T) {
+
+	// No limits, must match the regular reader.
+	df, e := ReadDataFrameLimits(strings.NewReader(file1), ReadLimits{})
+	CheckError(t, e)
+	ref, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	if !reflect.DeepEqual(df.Data, ref.Data) || !reflect.DeepEqual(df.VarNames, ref.VarNames) ||
+		df.BatchID != ref.BatchID || df.Description != ref.Description {
+		t.Fatalf("data frames don't match:\n%+v\n%+v", df, ref)
+	}
+	if _, e = df.Float64Slice(1, "wifi", "acceleration"); e != nil {
+		t.Fatal(e)
+	}
+
+	// Limits that are not exceeded.
+	ok := ReadLimits{MaxBytes: int64(len(file1)), MaxRows: 6, MaxVecLen: 2, MaxStringLen: 31}
+	_, e = ReadDataFrameLimits(strings.NewReader(file1), ok)
+	CheckError(t, e)
+
+	for _, lim := range []ReadLimits{
+		{MaxBytes: 100},
+		{MaxRows: 5},
+		{MaxVecLen: 1},
+		{MaxStringLen: 5},
+	} {
+		if _, e = ReadDataFrameLimits(strings.NewReader(file1), lim); e == nil {
+			t.Fatalf("expected error for limits %+v.", lim)
+		}
+		t.Logf("limits %+v: %s", lim, e)
+	}
+}
+
+func TestReadDataFrameLimitsMalformed(t *testing.T) {
+
+	for _, s := range []string{
+		``,
+		`[]`,
+		`{"data": 3}`,
+		`{"data": [1, 2]}`,
+		`{"data": [[1, 2]`,
+		`{"var_names": ["a"], "data": [["x"]]`,
+	} {
+		if _, e := ReadDataFrameLimits(strings.NewReader(s), ReadLimits{MaxBytes: 1000}); e == nil {
+			t.Fatalf("expected error for input %q.", s)
+		}
+	}
+}
+
+func TestRaggedRows(t *testing.T) {
+
+	for _, s := range []string{
+		`{"var_names": ["a", "b"], "data": [[1, 2], [3]]}`,
+		`{"data": [[1, 2], [3, 4, 5]], "var_names": ["a", "b"]}`,
+	} {
+		if _, e := ReadDataFrame(strings.NewReader(s)); e == nil {
+			t.Fatalf("expected error for input %q.", s)
+		}
+		if _, e := ReadDataFrameLimits(strings.NewReader(s), ReadLimits{}); e == nil {
+			t.Fatalf("expected error for input %q.", s)
+		}
+	}
+	it, e := NewRowIterator(strings.NewReader(`{"var_names": ["a", "b"], "data": [[1, 2], [3]]}`))
+	CheckError(t, e)
+	if _, e = it.Next(); e != nil {
+		t.Fatal(e)
+	}
+	if _, e = it.Next(); e == nil || e == io.EOF {
+		t.Fatalf("expected error for ragged row, got %v.", e)
+	}
+}
+
+func TestDataSetLimitsAllFormats(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-limits")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	CheckError(t, ioutil.WriteFile(filepath.Join(dir, "a.csv"), []byte("x,y\n1,abcdef\n2,b\n3,c\n"), 0644))
+	CheckError(t, ioutil.WriteFile(filepath.Join(dir, "b.lp"),
+		[]byte("m,tag=abcdef v=1 1\nm,tag=b v=2 2\nm,tag=c v=3 3\n"), 0644))
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	CheckError(t, df.WriteAvroFile(filepath.Join(dir, "c.avro")))
+
+	for _, fn := range []string{"a.csv", "b.lp", "c.avro"} {
+		ds := &DataSet{Path: dir, Files: []string{fn}}
+		_, e = ds.Next()
+		CheckError(t, e)
+		for _, lim := range []ReadLimits{
+			{MaxBytes: 20},
+			{MaxRows: 2},
+			{MaxStringLen: 5},
+		} {
+			lim := lim
+			ds := &DataSet{Path: dir, Files: []string{fn}, Limits: &lim}
+			if _, e = ds.Next(); e == nil {
+				t.Fatalf("file %s: expected error for limits %+v.", fn, lim)
+			}
+			t.Logf("file %s, limits %+v: %s", fn, lim, e)
+		}
+	}
+}
+
+func FuzzReadDataFrameLimits(f *testing.F) {
+
+	f.Add([]byte(file1))
+	f.Add([]byte(`{"var_names": ["a"], "data": [[[1, 2, 3]]]}`))
+	limits := ReadLimits{MaxBytes: 1 << 16, MaxRows: 100, MaxVecLen: 100, MaxStringLen: 100}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		df, e := ReadDataFrameLimits(strings.NewReader(string(b)), limits)
+		if e != nil {
+			return
+		}
+		if df.N() > limits.MaxRows {
+			t.Fatalf("read %d rows, limit is %d.", df.N(), limits.MaxRows)
+		}
+		if e = checkRowWidths(df); e != nil {
+			t.Fatal(e)
+		}
+	})
+}
@@ -0,0 +1,145 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Accumulates the mean and covariance of float64 vectors in a single pass.
+type covAccumulator struct {
+	n    int
+	mean []float64
+	// co-moment matrix, row-major.
+	c []float64
+}
+
+func newCovAccumulator(dim int) *covAccumulator {
+
+	return &covAccumulator{
+		mean: make([]float64, dim),
+		c:    make([]float64, dim*dim),
+	}
+}
+
+func (acc *covAccumulator) add(x []float64) error {
+
+	dim := len(acc.mean)
+	if len(x) != dim {
+		return fmt.Errorf("Vector dimension is %d, expected %d.", len(x), dim)
+	}
+	acc.n++
+	n := float64(acc.n)
+	dx := make([]float64, dim)
+	for i, v := range x {
+		dx[i] = v - acc.mean[i]
+		acc.mean[i] += dx[i] / n
+	}
+	for i := 0; i < dim; i++ {
+		for j := 0; j < dim; j++ {
+			acc.c[i*dim+j] += dx[i] * (x[j] - acc.mean[j])
+		}
+	}
+	return nil
+}
+
+// Returns the sample covariance matrix, row-major.
+func (acc *covAccumulator) cov() []float64 {
+
+	cov := make([]float64, len(acc.c))
+	if acc.n < 2 {
+		return cov
+	}
+	for i, v := range acc.c {
+		cov[i] = v / float64(acc.n-1)
+	}
+	return cov
+}
+
+// Computes the eigenvalues and eigenvectors of a symmetric n×n matrix a
+// (row-major) using the cyclic Jacobi method. Eigenvalues are returned in
+// descending order. Eigenvector k is stored in column k of vecs (row-major).
+func symEigen(a []float64, n int) (vals, vecs []float64) {
+
+	m := append([]float64(nil), a...)
+	vecs = make([]float64, n*n)
+	for i := 0; i < n; i++ {
+		vecs[i*n+i] = 1
+	}
+
+	for sweep := 0; sweep < 100; sweep++ {
+		var off float64
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				off += m[i*n+j] * m[i*n+j]
+			}
+		}
+		if off < 1e-30 {
+			break
+		}
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				apq := m[p*n+q]
+				if apq == 0 {
+					continue
+				}
+				theta := (m[q*n+q] - m[p*n+p]) / (2 * apq)
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := 0; k < n; k++ {
+					mkp, mkq := m[k*n+p], m[k*n+q]
+					m[k*n+p] = c*mkp - s*mkq
+					m[k*n+q] = s*mkp + c*mkq
+				}
+				for k := 0; k < n; k++ {
+					mpk, mqk := m[p*n+k], m[q*n+k]
+					m[p*n+k] = c*mpk - s*mqk
+					m[q*n+k] = s*mpk + c*mqk
+				}
+				for k := 0; k < n; k++ {
+					vkp, vkq := vecs[k*n+p], vecs[k*n+q]
+					vecs[k*n+p] = c*vkp - s*vkq
+					vecs[k*n+q] = s*vkp + c*vkq
+				}
+			}
+		}
+	}
+
+	// Sort by descending eigenvalue.
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.Sort(byValueDesc{order, m, n})
+	vals = make([]float64, n)
+	sorted := make([]float64, n*n)
+	for k, i := range order {
+		vals[k] = m[i*n+i]
+		for r := 0; r < n; r++ {
+			sorted[r*n+k] = vecs[r*n+i]
+		}
+	}
+	return vals, sorted
+}
+
+// Sorts indices by descending diagonal value.
+type byValueDesc struct {
+	order []int
+	m     []float64
+	n     int
+}
+
+func (s byValueDesc) Len() int      { return len(s.order) }
+func (s byValueDesc) Swap(i, j int) { s.order[i], s.order[j] = s.order[j], s.order[i] }
+func (s byValueDesc) Less(i, j int) bool {
+	return s.m[s.order[i]*s.n+s.order[i]] > s.m[s.order[j]*s.n+s.order[j]]
+}
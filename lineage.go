@@ -0,0 +1,65 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+
+	"launchpad.net/goyaml"
+)
+
+// Version of the package, recorded in the files written by transforms.
+const Version = "0.1.0"
+
+// Names of the properties added to the data frames written by
+// TransformDataSet, TransformDataSetIncremental, and pipelines, so every
+// derived file records how it was made.
+const (
+	// Version of the package that wrote the file.
+	LineageVersion = "_dataframe_version"
+	// Identifies the transforms: the SHA-256 of the transform specs of a
+	// pipeline, or the version given to TransformDataSetIncremental. Not
+	// set by TransformDataSet.
+	LineageTransforms = "_transforms"
+	// Name of the input file relative to the input data set path.
+	LineageInput = "_input_file"
+	// Hex encoded SHA-256 of the content of the input file.
+	LineageInputSHA256 = "_input_sha256"
+)
+
+// Adds the lineage properties to a data frame derived from input file i.
+func (ds *DataSet) addLineage(df *DataFrame, i int, transforms string) error {
+
+	h, err := hashFile(ds.Path + string(os.PathSeparator) + ds.Files[i])
+	if err != nil {
+		return err
+	}
+	if df.Properties == nil {
+		df.Properties = make(map[string]string)
+	}
+	df.Properties[LineageVersion] = Version
+	df.Properties[LineageInput] = ds.Files[i]
+	df.Properties[LineageInputSHA256] = h.SHA256
+	if transforms != "" {
+		df.Properties[LineageTransforms] = transforms
+	} else {
+		delete(df.Properties, LineageTransforms)
+	}
+	return nil
+}
+
+// Returns the hex encoded SHA-256 of the transform specs in YAML format.
+func hashTransforms(specs []TransformSpec) (string, error) {
+
+	b, err := goyaml.Marshal(specs)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:]), nil
+}
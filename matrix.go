@@ -0,0 +1,65 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+)
+
+// Joins float64 and []float64 variables for all rows and returns them as a
+// dense row-major matrix with one row per frame. The backing slice is allocated
+// once and can be wrapped without copying, for example using
+// mat64.NewDense(rows, cols, data) from the gonum matrix package.
+//
+// The number of columns is determined by the first row. All rows must have
+// the same dimension. Values are converted as in Float64Slice: missing
+// values are replaced according to the NA policy and non-finite values
+// according to the non-finite policy. Rows are never dropped, so the NADrop
+// and NonFiniteDrop policies return ErrDroppedRow.
+func (df *DataFrame) Float64Matrix(names ...string) (data []float64, rows, cols int, err error) {
+
+	if len(names) == 0 {
+		err = fmt.Errorf("No variable names were specified, must provide at least one var name.")
+		return
+	}
+	var indices []int
+	indices, err = df.indices(names...)
+	if err != nil {
+		return
+	}
+	rows = df.N()
+	if rows == 0 {
+		return
+	}
+
+	// Get the number of columns from the first row.
+	var first []float64
+	first, err = df.Float64Slice(0, names...)
+	if err != nil {
+		return
+	}
+	cols = len(first)
+	data = make([]float64, cols, rows*cols)
+	copy(data, first)
+
+	for i := 1; i < rows; i++ {
+		n := len(data)
+		if data, err = df.appendFloat64s(data, i, indices); err != nil {
+			data = nil
+			return
+		}
+		if len(data)-n != cols {
+			err = fmt.Errorf("In frame %d, dimension is %d, expected %d.", i, len(data)-n, cols)
+			data = nil
+			return
+		}
+		if err = df.nonFinite.apply(i, data[n:]); err != nil {
+			data = nil
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,102 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/gonum/floats"
+)
+
+func TestFloat64Matrix(t *testing.T) {
+
+	tmpDir := getTempDir()
+	f1, _ := createDataFiles(t, tmpDir)
+
+	df, dfe := ReadDataFrameFile(f1)
+	CheckError(t, dfe)
+
+	data, rows, cols, e := df.Float64Matrix("wifi", "acceleration")
+	CheckError(t, e)
+
+	if rows != 6 || cols != 3 {
+		t.Fatalf("dims must be 6x3, not %dx%d.", rows, cols)
+	}
+	for i := 0; i < rows; i++ {
+		sl, sle := df.Float64Slice(i, "wifi", "acceleration")
+		CheckError(t, sle)
+		if !floats.Equal(sl, data[i*cols:(i+1)*cols]) {
+			t.Fatalf("Mismatch in row %d: matrix is %v, slice is %v.", i, data[i*cols:(i+1)*cols], sl)
+		}
+	}
+
+	// Strings and the non-finite policy are handled as in Float64Slice.
+	df.Data[2][1] = []interface{}{"NaN", -50.0}
+	df.Data[4][2] = "-Inf"
+	CheckError(t, df.SetNonFinite(NonFiniteOptions{Policy: NonFiniteReplace, Value: -100}))
+	data, rows, cols, e = df.Float64Matrix("wifi", "acceleration")
+	CheckError(t, e)
+	for i := 0; i < rows; i++ {
+		sl, sle := df.Float64Slice(i, "wifi", "acceleration")
+		CheckError(t, sle)
+		if !floats.Equal(sl, data[i*cols:(i+1)*cols]) {
+			t.Fatalf("Mismatch in row %d: matrix is %v, slice is %v.", i, data[i*cols:(i+1)*cols], sl)
+		}
+	}
+	if data[2*cols] != -100 || data[4*cols+2] != -100 {
+		t.Fatalf("non-finite values not replaced: %v.", data)
+	}
+	CheckError(t, df.SetNonFinite(NonFiniteOptions{Policy: NonFiniteError}))
+	if _, _, _, e = df.Float64Matrix("wifi", "acceleration"); e == nil {
+		t.Fatalf("expected non-finite error.")
+	}
+	CheckError(t, df.SetNonFinite(NonFiniteOptions{}))
+
+	// Variable length rows must fail.
+	df.Data[3][1] = []float64{1, 2, 3}
+	if _, _, _, e = df.Float64Matrix("wifi", "acceleration"); e == nil {
+		t.Fatalf("expected dimension error.")
+	}
+}
+
+var (
+	bigFrame     *DataFrame
+	bigFrameOnce sync.Once
+)
+
+// Returns a synthetic frame with 1M rows.
+func getBigFrame() *DataFrame {
+
+	bigFrameOnce.Do(func() {
+		bigFrame = synthFrame(1000000, 3, 0)
+	})
+	return bigFrame
+}
+
+func BenchmarkFloat64SliceRows(b *testing.B) {
+
+	df := getBigFrame()
+	b.ResetTimer()
+	for k := 0; k < b.N; k++ {
+		for i := 0; i < df.N(); i++ {
+			if _, e := df.Float64Slice(i, "wifi", "acceleration"); e != nil {
+				b.Fatal(e)
+			}
+		}
+	}
+}
+
+func BenchmarkFloat64Matrix(b *testing.B) {
+
+	df := getBigFrame()
+	b.ResetTimer()
+	for k := 0; k < b.N; k++ {
+		if _, _, _, e := df.Float64Matrix("wifi", "acceleration"); e != nil {
+			b.Fatal(e)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// Sets the description of the data frame.
+func (df *DataFrame) SetDescription(description string) {
+
+	df.Description = description
+}
+
+// Sets the batch id of the data frame.
+func (df *DataFrame) SetBatchID(id string) {
+
+	df.BatchID = id
+}
+
+// Sets a property of the data frame. An empty value deletes the property.
+func (df *DataFrame) SetProperty(key, value string) {
+
+	if value == "" {
+		delete(df.Properties, key)
+		return
+	}
+	if df.Properties == nil {
+		df.Properties = make(map[string]string)
+	}
+	df.Properties[key] = value
+}
+
+// Edits the metadata of the JSON data frame in file fn. The frame passed to
+// edit has the description, batch id, variable names, units, and properties
+// of the file but no rows; the data array is copied to the new file byte
+// for byte, so values are never converted, and the number of variables
+// can't change. The new content is written to a temporary file which
+// replaces fn only if every step succeeds, so a failed edit never leaves a
+// corrupt file.
+func EditDataFrameFile(fn string, edit func(df *DataFrame) error) error {
+
+	info, err := os.Stat(fn)
+	if err != nil {
+		return err
+	}
+	f, err := openFile(fn)
+	if err != nil {
+		return err
+	}
+	b, err := ioutil.ReadAll(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	b = bytes.TrimPrefix(b, utf8BOM)
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(b, &fields); err != nil {
+		return fmt.Errorf("file %s: %s", fn, err)
+	}
+	df := &DataFrame{}
+	for key, raw := range fields {
+		if key == "data" {
+			continue
+		}
+		if err = decodeField(json.NewDecoder(bytes.NewReader(raw)), df, key); err != nil {
+			return fmt.Errorf("file %s: %s", fn, err)
+		}
+	}
+	df.Data = make([][]interface{}, 0)
+	df.resetVarMap()
+	nvars := len(df.VarNames)
+	if err = edit(df); err != nil {
+		return err
+	}
+	if len(df.VarNames) != nvars {
+		return fmt.Errorf("file %s: the number of variables can't change.", fn)
+	}
+	data := fields["data"]
+	if data == nil {
+		data = json.RawMessage("[]")
+	}
+	return writeFileAtomic(fn, info.Mode(), func(w io.Writer) error {
+		bw := bufio.NewWriter(w)
+		if err := df.writeJSONHeader(bw); err != nil {
+			return err
+		}
+		bw.WriteString("\"data\": ")
+		bw.Write(data)
+		bw.WriteString("\n}\n")
+		return bw.Flush()
+	})
+}
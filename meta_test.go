@@ -0,0 +1,105 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetMetadata(t *testing.T) {
+
+	df := Empty("room")
+	df.SetDescription("rooms")
+	df.SetBatchID("24001-015")
+	df.SetProperty("sensor", "ak-100")
+	df.SetProperty("status", "experimental")
+	df.SetProperty("status", "")
+	if df.Description != "rooms" || df.BatchID != "24001-015" {
+		t.Fatalf("unexpected metadata %+v.", df)
+	}
+	if len(df.Properties) != 1 || df.Properties["sensor"] != "ak-100" {
+		t.Fatalf("unexpected properties %v.", df.Properties)
+	}
+}
+
+func TestEditDataFrameFile(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-meta")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	fn := filepath.Join(dir, "file1.json")
+	CheckError(t, ioutil.WriteFile(fn, []byte(file1), 0644))
+
+	CheckError(t, EditDataFrameFile(fn, func(df *DataFrame) error {
+		df.SetBatchID("24001-016")
+		df.SetProperty("sensor", "ak-100")
+		return nil
+	}))
+	df, e := ReadDataFrameFile(fn)
+	CheckError(t, e)
+	if df.BatchID != "24001-016" || df.Properties["sensor"] != "ak-100" {
+		t.Fatalf("metadata not written: %+v.", df)
+	}
+	if df.N() != 6 || df.Description != "An indoor positioning data set." {
+		t.Fatalf("data must not change: %+v.", df)
+	}
+	v, e := df.Float64Slice(5, "wifi", "acceleration")
+	CheckError(t, e)
+	if v[0] != -42.209 || v[2] != 1.8 {
+		t.Fatalf("unexpected values %v.", v)
+	}
+
+	// The data array is copied byte for byte.
+	raw := `{"var_names": ["id", "v"], "batchid": "a", "data": [["007", "c"], [8, 1e2], ["1.50", null]]}`
+	CheckError(t, ioutil.WriteFile(fn, []byte(raw), 0644))
+	CheckError(t, EditDataFrameFile(fn, func(df *DataFrame) error {
+		df.SetBatchID("b")
+		return nil
+	}))
+	dataBytes := func(b []byte) string {
+		var m map[string]json.RawMessage
+		CheckError(t, json.Unmarshal(b, &m))
+		return string(m["data"])
+	}
+	edited, _ := ioutil.ReadFile(fn)
+	if dataBytes(edited) != dataBytes([]byte(raw)) {
+		t.Fatalf("data changed:\n%s", edited)
+	}
+	df, e = ReadDataFrameFile(fn)
+	CheckError(t, e)
+	if df.BatchID != "b" || df.Data[0][0] != "007" {
+		t.Fatalf("unexpected frame %+v.", df)
+	}
+	if e = EditDataFrameFile(fn, func(df *DataFrame) error {
+		df.VarNames = append(df.VarNames, "extra")
+		return nil
+	}); e == nil {
+		t.Fatalf("expected error for new variable.")
+	}
+
+	// A failed edit leaves the file untouched.
+	before, _ := ioutil.ReadFile(fn)
+	e = EditDataFrameFile(fn, func(df *DataFrame) error {
+		df.SetBatchID("bad")
+		return fmt.Errorf("abort")
+	})
+	if e == nil {
+		t.Fatalf("expected error.")
+	}
+	after, _ := ioutil.ReadFile(fn)
+	if string(before) != string(after) {
+		t.Fatalf("file must not change on error.")
+	}
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 {
+		t.Fatalf("temporary files left behind: %d files.", len(files))
+	}
+}
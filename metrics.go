@@ -0,0 +1,125 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics counts data set reads. A Metrics value can be shared by several
+// data sets, see DataSet.SetMetrics, and exported in the Prometheus text
+// format by serving it over HTTP.
+type Metrics struct {
+	// Prefix of the metric names. Defaults to "dataframe".
+	Namespace string
+
+	files       uint64
+	errors      uint64
+	rows        uint64
+	cacheHits   uint64
+	cacheMisses uint64
+	// Read time in nanoseconds.
+	readNanos uint64
+	// Rows per second of the last file, as float64 bits.
+	lastRate uint64
+}
+
+// Sets the metrics updated by the data set. Nil disables metrics.
+func (ds *DataSet) SetMetrics(m *Metrics) {
+
+	ds.metrics = m
+}
+
+// Records a file read. Nil metrics are ignored.
+func (m *Metrics) observeRead(df *DataFrame, err error, d time.Duration) {
+
+	if m == nil {
+		return
+	}
+	if err != nil {
+		atomic.AddUint64(&m.errors, 1)
+		return
+	}
+	atomic.AddUint64(&m.files, 1)
+	atomic.AddUint64(&m.rows, uint64(df.N()))
+	atomic.AddUint64(&m.readNanos, uint64(d))
+	if d > 0 {
+		atomic.StoreUint64(&m.lastRate, math.Float64bits(float64(df.N())/d.Seconds()))
+	}
+}
+
+func (m *Metrics) observeCache(hit bool) {
+
+	if m == nil {
+		return
+	}
+	if hit {
+		atomic.AddUint64(&m.cacheHits, 1)
+	} else {
+		atomic.AddUint64(&m.cacheMisses, 1)
+	}
+}
+
+// Returns the number of files read successfully.
+func (m *Metrics) Files() uint64 { return atomic.LoadUint64(&m.files) }
+
+// Returns the number of files that failed to read or parse.
+func (m *Metrics) Errors() uint64 { return atomic.LoadUint64(&m.errors) }
+
+// Returns the number of rows read.
+func (m *Metrics) Rows() uint64 { return atomic.LoadUint64(&m.rows) }
+
+// Returns the number of files read from the cache.
+func (m *Metrics) CacheHits() uint64 { return atomic.LoadUint64(&m.cacheHits) }
+
+// Returns the number of files that were not in the cache.
+func (m *Metrics) CacheMisses() uint64 { return atomic.LoadUint64(&m.cacheMisses) }
+
+// Writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+
+	ns := m.Namespace
+	if ns == "" {
+		ns = "dataframe"
+	}
+	var total int64
+	write := func(name, typ, help string, v float64) error {
+		n, err := fmt.Fprintf(w, "# HELP %s_%s %s\n# TYPE %s_%s %s\n%s_%s %g\n",
+			ns, name, help, ns, name, typ, ns, name, v)
+		total += int64(n)
+		return err
+	}
+	metrics := []struct {
+		name, typ, help string
+		v               float64
+	}{
+		{"files_read_total", "counter", "Files read successfully.", float64(m.Files())},
+		{"read_errors_total", "counter", "Files that failed to read or parse.", float64(m.Errors())},
+		{"rows_read_total", "counter", "Rows read.", float64(m.Rows())},
+		{"cache_hits_total", "counter", "Files read from the cache.", float64(m.CacheHits())},
+		{"cache_misses_total", "counter", "Files not found in the cache.", float64(m.CacheMisses())},
+		{"read_seconds_total", "counter", "Time spent reading files.", float64(atomic.LoadUint64(&m.readNanos)) / 1e9},
+		{"rows_per_second", "gauge", "Read throughput of the last file.", math.Float64frombits(atomic.LoadUint64(&m.lastRate))},
+	}
+	for _, x := range metrics {
+		if err := write(x.name, x.typ, x.help, x.v); err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// Serves the metrics in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	m.WriteTo(w)
+}
@@ -0,0 +1,56 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-metrics")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	CheckError(t, ioutil.WriteFile(filepath.Join(dir, "file1.json"), []byte(file1), 0644))
+	CheckError(t, ioutil.WriteFile(filepath.Join(dir, "bad.json"), []byte("{"), 0644))
+
+	m := &Metrics{}
+	ds := &DataSet{Path: dir, Files: []string{"file1.json", "file1.json", "bad.json"}, Cache: true}
+	ds.SetMetrics(m)
+	// Reads until the parse error of bad.json.
+	for {
+		_, e := ds.Next()
+		if e != nil {
+			break
+		}
+	}
+	if m.Files() != 2 || m.Rows() != 12 || m.Errors() != 1 {
+		t.Fatalf("unexpected counts: files %d, rows %d, errors %d.", m.Files(), m.Rows(), m.Errors())
+	}
+	if m.CacheHits() != 1 || m.CacheMisses() != 2 {
+		t.Fatalf("unexpected cache counts: hits %d, misses %d.", m.CacheHits(), m.CacheMisses())
+	}
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	t.Log(body)
+	for _, s := range []string{
+		"# TYPE dataframe_rows_read_total counter",
+		"dataframe_rows_read_total 12\n",
+		"dataframe_read_errors_total 1\n",
+		"# TYPE dataframe_rows_per_second gauge",
+	} {
+		if !strings.Contains(body, s) {
+			t.Fatalf("output doesn't contain %q.", s)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Variables filled by MQTTSource from the message rather than the payload.
+const (
+	MQTTTopic = "topic"
+	MQTTTime  = "time"
+)
+
+// A message received from a broker.
+type Message struct {
+	Topic   string
+	Payload []byte
+	// Time the message was received.
+	Time time.Time
+}
+
+// A Subscriber delivers the messages published on a set of topics. Wrap an
+// MQTT client, such as Eclipse Paho, to implement it.
+type Subscriber interface {
+	Subscribe(topics []string, handler func(Message)) error
+	Unsubscribe(topics []string) error
+}
+
+// Options for MQTTSource.
+type MQTTOptions struct {
+	// Topics to subscribe to.
+	Topics []string
+	// Variables of the data frames.
+	VarNames []string
+	// Flushes a data frame when it has MaxRows rows. Zero means no limit.
+	MaxRows int
+	// Flushes a non-empty data frame every Interval. Zero disables it.
+	Interval time.Duration
+	// Converts a message to a row with a value for each variable. The
+	// default decodes a JSON object and takes the values by variable name.
+	// Variables MQTTTopic and MQTTTime, if not in the payload, are set to
+	// the topic and the receive time as an RFC 3339 string.
+	Decode func(m Message) ([]interface{}, error)
+}
+
+// An MQTTSource accumulates messages into data frames and writes them with a
+// DataSetWriter when they reach a number of rows or at regular intervals.
+type MQTTSource struct {
+	sync.Mutex
+	sub  Subscriber
+	w    *DataSetWriter
+	opts MQTTOptions
+	df   *DataFrame
+	err  error
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// Returns a source that writes to w. Call Start to subscribe.
+func NewMQTTSource(sub Subscriber, w *DataSetWriter, opts MQTTOptions) *MQTTSource {
+
+	if opts.Decode == nil {
+		opts.Decode = decodeJSONMessage(opts.VarNames)
+	}
+	return &MQTTSource{sub: sub, w: w, opts: opts, df: Empty(opts.VarNames...)}
+}
+
+// Subscribes to the topics and starts the flush timer.
+func (s *MQTTSource) Start() error {
+
+	if s.done != nil {
+		return fmt.Errorf("The source is already started.")
+	}
+	if err := s.sub.Subscribe(s.opts.Topics, s.handle); err != nil {
+		return err
+	}
+	s.done = make(chan struct{})
+	if s.opts.Interval > 0 {
+		s.wg.Add(1)
+		go s.tick()
+	}
+	return nil
+}
+
+func (s *MQTTSource) tick() {
+
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.Flush()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *MQTTSource) handle(m Message) {
+
+	row, err := s.opts.Decode(m)
+	s.Lock()
+	defer s.Unlock()
+	if err == nil && len(row) != len(s.opts.VarNames) {
+		err = fmt.Errorf("decoded %d values, expected %d.", len(row), len(s.opts.VarNames))
+	}
+	if err != nil {
+		s.setErr(fmt.Errorf("topic %s: %s", m.Topic, err))
+		return
+	}
+	s.df.Data = append(s.df.Data, row)
+	if s.opts.MaxRows > 0 && s.df.N() >= s.opts.MaxRows {
+		s.flush()
+	}
+}
+
+// Writes the accumulated rows, if any, as a new data frame. If the write
+// fails, the rows are kept and written by the next flush.
+func (s *MQTTSource) Flush() error {
+
+	s.Lock()
+	defer s.Unlock()
+	return s.flush()
+}
+
+func (s *MQTTSource) flush() error {
+
+	if s.df.N() == 0 {
+		return nil
+	}
+	if _, err := s.w.Write(s.df); err != nil {
+		s.setErr(err)
+		return err
+	}
+	s.df = Empty(s.opts.VarNames...)
+	return nil
+}
+
+// Keeps the first error.
+func (s *MQTTSource) setErr(err error) {
+
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// Returns the first error found while decoding or writing messages.
+func (s *MQTTSource) Err() error {
+
+	s.Lock()
+	defer s.Unlock()
+	return s.err
+}
+
+// Unsubscribes, stops the flush timer, and writes the remaining rows.
+// Returns the first error found by the source.
+func (s *MQTTSource) Close() error {
+
+	if s.done != nil {
+		err := s.sub.Unsubscribe(s.opts.Topics)
+		close(s.done)
+		s.wg.Wait()
+		s.done = nil
+		if err != nil {
+			return err
+		}
+	}
+	s.Flush()
+	return s.Err()
+}
+
+func decodeJSONMessage(names []string) func(Message) ([]interface{}, error) {
+
+	return func(m Message) ([]interface{}, error) {
+		values := make(map[string]interface{})
+		if err := json.Unmarshal(m.Payload, &values); err != nil {
+			return nil, err
+		}
+		row := make([]interface{}, len(names))
+		for i, name := range names {
+			v, ok := values[name]
+			switch {
+			case ok:
+				row[i] = v
+			case name == MQTTTopic:
+				row[i] = m.Topic
+			case name == MQTTTime:
+				row[i] = m.Time.UTC().Format(time.RFC3339Nano)
+			}
+		}
+		return row, nil
+	}
+}
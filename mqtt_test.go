@@ -0,0 +1,107 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type fakeBroker struct {
+	handler func(Message)
+	topics  []string
+}
+
+func (b *fakeBroker) Subscribe(topics []string, handler func(Message)) error {
+	b.topics, b.handler = topics, handler
+	return nil
+}
+
+func (b *fakeBroker) Unsubscribe(topics []string) error {
+	b.handler = nil
+	return nil
+}
+
+func (b *fakeBroker) publish(topic, payload string) {
+	if b.handler != nil {
+		b.handler(Message{Topic: topic, Payload: []byte(payload), Time: time.Unix(1388534400, 0)})
+	}
+}
+
+func TestMQTTSource(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-mqtt")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	w, e := NewDataSetWriter(dir, "imu")
+	CheckError(t, e)
+
+	broker := &fakeBroker{}
+	src := NewMQTTSource(broker, w, MQTTOptions{
+		Topics:   []string{"sensors/+/imu"},
+		VarNames: []string{MQTTTime, MQTTTopic, "acceleration", "wifi"},
+		MaxRows:  2,
+	})
+	CheckError(t, src.Start())
+	for i := 0; i < 5; i++ {
+		broker.publish("sensors/ak-100/imu", fmt.Sprintf(`{"acceleration": %d.5, "wifi": [-50, -60]}`, i))
+	}
+	if n := len(w.DataSet().Files); n != 2 {
+		t.Fatalf("expected 2 files before close, got %d.", n)
+	}
+	CheckError(t, src.Close())
+
+	ds := w.DataSet()
+	if len(ds.Files) != 3 {
+		t.Fatalf("expected 3 files, got %d.", len(ds.Files))
+	}
+	df, e := ds.Next()
+	CheckError(t, e)
+	v, e := df.Float64Slice(1, "acceleration", "wifi")
+	CheckError(t, e)
+	if v[0] != 1.5 || v[2] != -60 {
+		t.Fatalf("unexpected values %v.", v)
+	}
+	if s, _ := df.String(0, MQTTTopic); s != "sensors/ak-100/imu" {
+		t.Fatalf("unexpected topic %s.", s)
+	}
+	if s, _ := df.String(0, MQTTTime); s != "2014-01-01T00:00:00Z" {
+		t.Fatalf("unexpected time %s.", s)
+	}
+
+	// Rows are kept if a write fails.
+	src = NewMQTTSource(broker, w, MQTTOptions{VarNames: []string{"acceleration"}})
+	CheckError(t, src.Start())
+	broker.publish("sensors/ak-100/imu", `{"acceleration": 1}`)
+	CheckError(t, os.RemoveAll(dir))
+	if src.Flush() == nil {
+		t.Fatalf("expected write error.")
+	}
+	CheckError(t, os.Mkdir(dir, 0755))
+	broker.publish("sensors/ak-100/imu", `{"acceleration": 2}`)
+	CheckError(t, src.Flush())
+	ds = w.DataSet()
+	df, e = ReadDataFrameFile(filepath.Join(dir, ds.Files[len(ds.Files)-1]))
+	CheckError(t, e)
+	if df.N() != 2 {
+		t.Fatalf("expected 2 rows, got %d.", df.N())
+	}
+	if src.Close() == nil {
+		t.Fatalf("expected the write error.")
+	}
+
+	// Bad payloads are reported.
+	src = NewMQTTSource(broker, w, MQTTOptions{VarNames: []string{"acceleration"}, Interval: time.Millisecond})
+	CheckError(t, src.Start())
+	broker.publish("sensors/ak-100/imu", `not json`)
+	if src.Close() == nil {
+		t.Fatalf("expected decode error.")
+	}
+}
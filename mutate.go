@@ -0,0 +1,60 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+)
+
+// Adds a variable with one value per row. Values must be float64, string,
+// bool, []float64, or nil; other integer and float types are converted to
+// float64.
+func (df *DataFrame) AddVar(name string, values []interface{}) error {
+
+	if len(values) != df.N() {
+		return fmt.Errorf("Number of values (%d) does not match number of rows (%d).", len(values), df.N())
+	}
+	converted := make([]interface{}, len(values))
+	for i, v := range values {
+		var err error
+		if converted[i], err = rowValue(v); err != nil {
+			return fmt.Errorf("In frame %d, variable [%s]: %s", i, name, err)
+		}
+	}
+	return df.addVar(name, converted)
+}
+
+// Adds a variable derived from each row. For example, to add a normalized
+// acceleration:
+//
+//	st, _ := df.ColStats("acceleration")
+//	df.Apply("acc_norm", func(r dataframe.Row) interface{} {
+//		a, ok := r.Float64("acceleration")
+//		if !ok {
+//			return nil
+//		}
+//		return (a - st.Mean) / st.StdDev
+//	})
+//
+// Values are converted as in AddVar. The frame is not modified if an error
+// occurs.
+func (df *DataFrame) Apply(name string, fn func(r Row) interface{}) error {
+
+	values := make([]interface{}, df.N())
+	for i := range values {
+		values[i] = fn(df.Row(i))
+	}
+	return df.AddVar(name, values)
+}
+
+// Returns a transform that adds a derived variable to every data frame, see
+// DataFrame.Apply.
+func Apply(name string, fn func(r Row) interface{}) Transform {
+
+	return func(df *DataFrame) (*DataFrame, error) {
+		return df, df.Apply(name, fn)
+	}
+}
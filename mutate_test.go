@@ -0,0 +1,69 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestAddVar(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+
+	CheckError(t, df.AddVar("count", []interface{}{1, 2, 3, 4, nil, 6}))
+	if v, ok := df.Row(3).Float64("count"); !ok || v != 4 {
+		t.Fatalf("expected 4, got %f.", v)
+	}
+	if na, _ := df.IsNA(4, "count"); !na {
+		t.Fatalf("expected NA.")
+	}
+	if e = df.AddVar("count", make([]interface{}, 6)); e == nil {
+		t.Fatalf("expected error for existing variable.")
+	}
+	if e = df.AddVar("short", make([]interface{}, 2)); e == nil {
+		t.Fatalf("expected error for wrong length.")
+	}
+	if e = df.AddVar("bad", []interface{}{1, 2, 3, 4, 5, struct{}{}}); e == nil {
+		t.Fatalf("expected error for unsupported type.")
+	}
+	if _, e = df.indices("bad"); e == nil {
+		t.Fatalf("variable must not be added on error.")
+	}
+}
+
+func TestApply(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	st, e := df.ColStats("acceleration")
+	CheckError(t, e)
+
+	norm := func(r Row) interface{} {
+		a, ok := r.Float64("acceleration")
+		if !ok {
+			return nil
+		}
+		return (a - st.Mean) / st.StdDev
+	}
+	df, e = Apply("acc_norm", norm)(df)
+	CheckError(t, e)
+	st, e = df.ColStats("acc_norm")
+	CheckError(t, e)
+	if math.Abs(st.Mean) > 1e-12 || math.Abs(st.StdDev-1) > 1e-12 {
+		t.Fatalf("expected normalized values, got %+v.", st)
+	}
+
+	CheckError(t, df.Apply("bedroom", func(r Row) interface{} {
+		s, _ := r.String("room")
+		return s == "BED5"
+	}))
+	if b, ok := df.Row(0).Bool("bedroom"); !ok || !b {
+		t.Fatalf("expected true.")
+	}
+}
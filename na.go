@@ -0,0 +1,159 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+)
+
+// NAPolicy determines what float extraction does with missing values. A
+// missing value, or NA, is a nil cell, written as null in JSON.
+type NAPolicy string
+
+const (
+	// Return an error. This is the default.
+	NAError NAPolicy = "error"
+	// Skip rows with missing values.
+	NADrop NAPolicy = "drop"
+	// Replace missing values with the mean of the variable. The mean of a
+	// vector variable is computed element by element.
+	NAMean NAPolicy = "mean"
+	// Replace missing values with a constant.
+	NAFill NAPolicy = "fill"
+)
+
+// Options for missing values in Float64Slice, Float64Matrix, iterators, and
+// channels.
+type NAOptions struct {
+	Policy NAPolicy `yaml:"policy"`
+	// Replacement value for NAFill. Every element of a missing vector is
+	// set to this value.
+	Value float64 `yaml:"value"`
+}
+
+// Sets the missing value options of the data frame.
+func (df *DataFrame) SetNA(opts NAOptions) error {
+
+	switch opts.Policy {
+	case "", NAError, NADrop, NAMean, NAFill:
+	default:
+		return fmt.Errorf("Unknown NA policy [%s].", opts.Policy)
+	}
+	df.na = opts
+	return nil
+}
+
+// Returns true if the value of a variable is missing.
+func (df *DataFrame) IsNA(frame int, name string) (bool, error) {
+
+	indices, err := df.indices(name)
+	if err != nil {
+		return false, err
+	}
+	if err = df.checkFrame(frame); err != nil {
+		return false, err
+	}
+	return df.Data[frame][indices[0]] == nil, nil
+}
+
+// Returns the number of missing values of a variable.
+func (df *DataFrame) CountNA(name string) (int, error) {
+
+	indices, err := df.indices(name)
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	for _, row := range df.Data {
+		if row[indices[0]] == nil {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Returns the values that replace a missing value of variable j according to
+// the NA policy.
+func (df *DataFrame) naValues(frame, j int) ([]float64, error) {
+
+	name := df.VarNames[j]
+	switch df.na.Policy {
+	case NADrop:
+		return nil, ErrDroppedRow
+	case NAFill:
+		dim, err := df.naDim(j)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]float64, dim)
+		for i := range values {
+			values[i] = df.na.Value
+		}
+		return values, nil
+	case NAMean:
+		return df.naMean(j)
+	}
+	return nil, fmt.Errorf("In frame %d, variable [%s] is nil.", frame, name)
+}
+
+// Returns the dimension of variable j from its first value.
+func (df *DataFrame) naDim(j int) (int, error) {
+
+	for _, row := range df.Data {
+		switch v := row[j].(type) {
+		case nil:
+			continue
+		case []float64:
+			return len(v), nil
+		case []interface{}:
+			return len(v), nil
+		}
+		return 1, nil
+	}
+	return 0, fmt.Errorf("Variable [%s] has no values.", df.VarNames[j])
+}
+
+// Returns the mean of variable j. Means are cached like column statistics.
+func (df *DataFrame) naMean(j int) ([]float64, error) {
+
+	name := df.VarNames[j]
+	df.cache.Lock()
+	defer df.cache.Unlock()
+	if mean, ok := df.cache.means[name]; ok {
+		return mean, nil
+	}
+	var acc *Accumulator
+	scalar := make([]float64, 1)
+	for i, row := range df.Data {
+		var x []float64
+		var err error
+		switch v := row[j].(type) {
+		case nil:
+			continue
+		case float64:
+			scalar[0] = v
+			x = scalar
+		default:
+			if x, err = toFloat64Slice(v); err != nil {
+				return nil, fmt.Errorf("In frame %d, variable [%s]: %s", i, name, err)
+			}
+		}
+		if acc == nil {
+			acc = NewAccumulator(len(x))
+		}
+		if err = acc.Add(x); err != nil {
+			return nil, fmt.Errorf("In frame %d, variable [%s]: %s", i, name, err)
+		}
+	}
+	if acc == nil {
+		return nil, fmt.Errorf("Variable [%s] has no values.", name)
+	}
+	if df.cache.means == nil {
+		df.cache.means = make(map[string][]float64)
+	}
+	df.cache.means[name] = acc.Mean()
+	return df.cache.means[name], nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func naFrame(t *testing.T) *DataFrame {
+
+	df, e := ReadDataFrame(strings.NewReader(`{"var_names": ["a", "v"], "data": [
+  [1, [1, 2]],
+  [null, [3, 4]],
+  [5, null],
+  [6, [8, 9]]
+]}`))
+	CheckError(t, e)
+	return df
+}
+
+func TestNAPolicy(t *testing.T) {
+
+	df := naFrame(t)
+	na, e := df.IsNA(1, "a")
+	CheckError(t, e)
+	if !na {
+		t.Fatalf("expected NA.")
+	}
+	if n, e := df.CountNA("v"); e != nil || n != 1 {
+		t.Fatalf("expected 1 NA, got %d, %v.", n, e)
+	}
+
+	// Error by default.
+	if _, e = df.Float64Slice(1, "a", "v"); e == nil {
+		t.Fatalf("expected error for missing value.")
+	}
+
+	CheckError(t, df.SetNA(NAOptions{Policy: NAFill, Value: -1}))
+	v, e := df.Float64Slice(2, "a", "v")
+	CheckError(t, e)
+	if !reflect.DeepEqual(v, []float64{5, -1, -1}) {
+		t.Fatalf("unexpected values %v.", v)
+	}
+
+	CheckError(t, df.SetNA(NAOptions{Policy: NAMean}))
+	v, e = df.Float64Slice(1, "a", "v")
+	CheckError(t, e)
+	if !reflect.DeepEqual(v, []float64{4, 3, 4}) {
+		t.Fatalf("unexpected values %v.", v)
+	}
+	data, rows, cols, e := df.Float64Matrix("a", "v")
+	CheckError(t, e)
+	if rows != 4 || cols != 3 || !reflect.DeepEqual(data[6:9], []float64{5, 4, 5}) {
+		t.Fatalf("unexpected matrix %v.", data)
+	}
+
+	// Means are recomputed after appending a row.
+	CheckError(t, df.AppendRow(10.0, []float64{4, 5}))
+	v, e = df.Float64Slice(1, "a", "v")
+	CheckError(t, e)
+	if v[0] != 5.5 || v[1] != 3 {
+		t.Fatalf("unexpected values %v.", v)
+	}
+
+	CheckError(t, df.SetNA(NAOptions{Policy: NADrop}))
+	if _, e = df.Float64Slice(1, "a"); e != ErrDroppedRow {
+		t.Fatalf("expected ErrDroppedRow, got %v.", e)
+	}
+	var n int
+	for _ = range df.Float64SliceChannel("a", "v") {
+		n++
+	}
+	if n != 3 {
+		t.Fatalf("expected 3 rows, got %d.", n)
+	}
+
+	if e = df.SetNA(NAOptions{Policy: "zero"}); e == nil {
+		t.Fatalf("expected error for unknown policy.")
+	}
+}
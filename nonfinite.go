@@ -0,0 +1,90 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// NonFinitePolicy determines what float extraction does with NaN and
+// infinite values.
+type NonFinitePolicy string
+
+const (
+	// Return NaN and infinite values unchanged. This is the default.
+	NonFinitePass NonFinitePolicy = "pass"
+	// Replace NaN and infinite values with a constant.
+	NonFiniteReplace NonFinitePolicy = "replace"
+	// Skip rows with NaN or infinite values.
+	NonFiniteDrop NonFinitePolicy = "drop"
+	// Return an error.
+	NonFiniteError NonFinitePolicy = "error"
+)
+
+// Returned by Float64Slice for rows that must be skipped under the
+// NonFiniteDrop or NADrop policies. Iterators and channels skip these rows.
+var ErrDroppedRow = errors.New("row dropped: missing or non-finite value")
+
+// Options for NaN and infinite values in Float64Slice, iterators, and
+// channels. JSON has no NaN or infinity so sources often encode them as
+// strings; the strings "NaN", "Inf", "+Inf", "-Inf", "Infinity", and
+// "-Infinity", in any case, are read as float64 values.
+type NonFiniteOptions struct {
+	Policy NonFinitePolicy `yaml:"policy"`
+	// Replacement value for NonFiniteReplace.
+	Value float64 `yaml:"value"`
+}
+
+// Sets the NaN and infinite value options of the data frame.
+func (df *DataFrame) SetNonFinite(opts NonFiniteOptions) error {
+
+	switch opts.Policy {
+	case "", NonFinitePass, NonFiniteReplace, NonFiniteDrop, NonFiniteError:
+	default:
+		return fmt.Errorf("Unknown non-finite policy [%s].", opts.Policy)
+	}
+	df.nonFinite = opts
+	return nil
+}
+
+// Parses the string encodings of NaN and infinity.
+func parseNonFinite(s string) (float64, bool) {
+
+	switch strings.ToLower(s) {
+	case "nan":
+		return math.NaN(), true
+	case "inf", "+inf", "infinity", "+infinity":
+		return math.Inf(1), true
+	case "-inf", "-infinity":
+		return math.Inf(-1), true
+	}
+	return 0, false
+}
+
+// Applies the policy to values extracted from a frame.
+func (opts NonFiniteOptions) apply(frame int, values []float64) error {
+
+	if opts.Policy == "" || opts.Policy == NonFinitePass {
+		return nil
+	}
+	for i, x := range values {
+		if !math.IsNaN(x) && !math.IsInf(x, 0) {
+			continue
+		}
+		switch opts.Policy {
+		case NonFiniteReplace:
+			values[i] = opts.Value
+		case NonFiniteDrop:
+			return ErrDroppedRow
+		default:
+			return fmt.Errorf("In frame %d, value %d is %g.", frame, i, x)
+		}
+	}
+	return nil
+}
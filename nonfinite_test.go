@@ -0,0 +1,82 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func nonFiniteFrame(t *testing.T) *DataFrame {
+
+	df, e := ReadDataFrame(strings.NewReader(`{"var_names": ["a", "v"], "data": [
+  [1, [1, 2]],
+  ["NaN", [3, 4]],
+  [5, [6, "-Inf"]],
+  [7, [8, 9]]
+]}`))
+	CheckError(t, e)
+	return df
+}
+
+func TestNonFinitePolicy(t *testing.T) {
+
+	df := nonFiniteFrame(t)
+
+	// Pass through.
+	v, e := df.Float64Slice(1, "a", "v")
+	CheckError(t, e)
+	if !math.IsNaN(v[0]) || v[1] != 3 {
+		t.Fatalf("unexpected values %v.", v)
+	}
+	v, e = df.Float64Slice(2, "a", "v")
+	CheckError(t, e)
+	if !math.IsInf(v[2], -1) {
+		t.Fatalf("unexpected values %v.", v)
+	}
+
+	// Replace.
+	CheckError(t, df.SetNonFinite(NonFiniteOptions{Policy: NonFiniteReplace, Value: -1}))
+	v, e = df.Float64Slice(2, "a", "v")
+	CheckError(t, e)
+	if v[0] != 5 || v[2] != -1 {
+		t.Fatalf("unexpected values %v.", v)
+	}
+
+	// Error.
+	CheckError(t, df.SetNonFinite(NonFiniteOptions{Policy: NonFiniteError}))
+	if _, e = df.Float64Slice(1, "a"); e == nil || e == ErrDroppedRow {
+		t.Fatalf("expected error, got %v.", e)
+	}
+	t.Log(e)
+
+	// Drop.
+	CheckError(t, df.SetNonFinite(NonFiniteOptions{Policy: NonFiniteDrop}))
+	if _, e = df.Float64Slice(1, "a"); e != ErrDroppedRow {
+		t.Fatalf("expected ErrDroppedRow, got %v.", e)
+	}
+	var rows [][]float64
+	it := df.Float64Iterator("a", "v")
+	for it.Next() {
+		rows = append(rows, it.Value())
+	}
+	CheckError(t, it.Err())
+	if len(rows) != 2 || rows[0][0] != 1 || rows[1][0] != 7 {
+		t.Fatalf("unexpected rows %v.", rows)
+	}
+
+	if e = df.SetNonFinite(NonFiniteOptions{Policy: "bogus"}); e == nil {
+		t.Fatalf("expected error for unknown policy.")
+	}
+
+	// Strings that are not NaN or infinity are still an error.
+	df, e = ReadDataFrame(strings.NewReader(`{"var_names": ["a"], "data": [["x"]]}`))
+	CheckError(t, e)
+	if _, e = df.Float64Slice(0, "a"); e == nil {
+		t.Fatalf("expected error for string value.")
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Resource limits for reading and transforming a data set.
+type ExecOptions struct {
+	// Maximum number of goroutines reading and parsing files. The default
+	// is 1.
+	MaxWorkers int `yaml:"max_workers"`
+	// Maximum number of data frames in memory, counting frames being read
+	// and frames being consumed. The default is MaxWorkers.
+	MaxFrames int `yaml:"max_frames"`
+	// Maximum total size of the files in memory, in bytes of the input
+	// files. A file larger than the limit is read when no other file is in
+	// memory. Zero means no limit.
+	MaxBatchBytes int64 `yaml:"max_batch_bytes"`
+}
+
+func (opts ExecOptions) check() error {
+
+	if opts.MaxWorkers < 0 || opts.MaxFrames < 0 || opts.MaxBatchBytes < 0 {
+		return fmt.Errorf("Execution limits must not be negative, got %+v.", opts)
+	}
+	return nil
+}
+
+// Result of reading a data set file.
+type fileResult struct {
+	index int
+	df    *DataFrame
+	err   error
+}
+
+// Returns channels with the float64 variables of every row, see
+// DataFrame.Float64SliceChan, reading and parsing up to n files
+// concurrently. If ordered is true, rows are in data set order. Otherwise
+// the rows of each file are sent as soon as the file is read; rows of a file
+// stay together and in order. At most n files are held in memory. Unlike
+// Float64SliceChan, the position of Next is not changed. Hooks, see SetHooks,
+// may be called concurrently.
+func (ds *DataSet) Float64SliceChanParallel(n int, ordered bool, names ...string) (<-chan []float64, <-chan error) {
+
+	ch := make(chan []float64, BUFFER_SIZE)
+	errc := make(chan error, 1)
+	go func() {
+		err := ds.readParallel(context.Background(), ExecOptions{MaxWorkers: n}, ordered, func(i int, df *DataFrame) error {
+			it := df.Float64Iterator(names...)
+			for it.Next() {
+				ch <- it.Value()
+			}
+			return it.Err()
+		})
+		close(ch)
+		if err != nil {
+			errc <- err
+		}
+		close(errc)
+	}()
+	return ch, errc
+}
+
+// Reads the files within the limits and calls fn with the index of each
+// file and its data frame from the calling goroutine. Stops at the first
+// error or when ctx is cancelled, and returns after every worker exits.
+func (ds *DataSet) readParallel(ctx context.Context, opts ExecOptions, ordered bool, fn func(i int, df *DataFrame) error) error {
+
+	if err := opts.check(); err != nil {
+		return err
+	}
+	n := opts.MaxWorkers
+	if n < 1 {
+		n = 1
+	}
+	maxFrames := opts.MaxFrames
+	if maxFrames < 1 {
+		maxFrames = n
+	}
+	// Load shared state before starting the workers.
+	if err := ds.loadCalibration(); err != nil {
+		return err
+	}
+	sizes := make([]int64, len(ds.Files))
+	if opts.MaxBatchBytes > 0 {
+		for i, name := range ds.Files {
+			fi, err := os.Stat(ds.Path + string(os.PathSeparator) + name)
+			if err != nil {
+				return err
+			}
+			sizes[i] = fi.Size()
+		}
+	}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer close(done)
+
+	// Tokens limit the number of files read but not yet consumed. Released
+	// sizes return their bytes to the batch budget.
+	tokens := make(chan struct{}, maxFrames)
+	released := make(chan int64, len(ds.Files))
+	jobs := make(chan int)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+		var inFlight int64
+		for i := range ds.Files {
+			for inFlight > 0 && inFlight+sizes[i] > opts.MaxBatchBytes && opts.MaxBatchBytes > 0 {
+				select {
+				case size := <-released:
+					inFlight -= size
+				case <-done:
+					return
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case tokens <- struct{}{}:
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
+			inFlight += sizes[i]
+			select {
+			case jobs <- i:
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	results := make(chan fileResult, n)
+	var slots []chan fileResult
+	if ordered {
+		slots = make([]chan fileResult, len(ds.Files))
+		for i := range slots {
+			slots[i] = make(chan fileResult, 1)
+		}
+	}
+	wg.Add(n)
+	for w := 0; w < n; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				df, err := ds.loadFile(i)
+				r := fileResult{index: i, df: df, err: err}
+				if ordered {
+					slots[i] <- r
+					continue
+				}
+				select {
+				case results <- r:
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+
+	var row int
+	for k := range ds.Files {
+		var r fileResult
+		if ordered {
+			select {
+			case r = <-slots[k]:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		} else {
+			select {
+			case r = <-results:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if r.err != nil {
+			return r.err
+		}
+		if ds.Provenance {
+			if err := addProvenance(r.df, ds.Files[r.index], row); err != nil {
+				return err
+			}
+			row += r.df.N()
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(r.index, r.df); err != nil {
+			return err
+		}
+		released <- sizes[r.index]
+		<-tokens
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gonum/floats"
+)
+
+func TestFloat64SliceChanParallel(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-parallel")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	spec := synthSpec(50, 3, 0)
+	spec.Files = 8
+	ds, e := GenerateDataSet(spec, dir)
+	CheckError(t, e)
+	names := []string{"wifi", "acceleration", ProvenanceGlobalRow}
+	ds.Provenance = true
+
+	var want [][]float64
+	ch, errc := ds.Float64SliceChan(names...)
+	for v := range ch {
+		want = append(want, v)
+	}
+	CheckError(t, <-errc)
+
+	for _, n := range []int{1, 3, 16} {
+		var got [][]float64
+		ch, errc := ds.Float64SliceChanParallel(n, true, names...)
+		for v := range ch {
+			got = append(got, v)
+		}
+		CheckError(t, <-errc)
+		if len(got) != len(want) {
+			t.Fatalf("n=%d: expected %d rows, got %d.", n, len(want), len(got))
+		}
+		for i := range want {
+			if !floats.Equal(want[i], got[i]) {
+				t.Fatalf("n=%d, row %d: expected %v, got %v.", n, i, want[i], got[i])
+			}
+		}
+	}
+
+	// Unordered returns the same rows.
+	ch, errc = ds.Float64SliceChanParallel(4, false, names...)
+	var count int
+	for _ = range ch {
+		count++
+	}
+	CheckError(t, <-errc)
+	if count != len(want) {
+		t.Fatalf("expected %d rows, got %d.", len(want), count)
+	}
+
+	// Errors stop the iteration.
+	CheckError(t, ioutil.WriteFile(filepath.Join(dir, ds.Files[5]), []byte("{"), 0644))
+	ch, errc = ds.Float64SliceChanParallel(4, true, names...)
+	count = 0
+	for _ = range ch {
+		count++
+	}
+	if e = <-errc; e == nil {
+		t.Fatalf("expected error.")
+	}
+	if count != 5*50 {
+		t.Fatalf("expected %d rows before the error, got %d.", 5*50, count)
+	}
+}
+
+func TestExecOptions(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-parallel")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	spec := synthSpec(50, 3, 0)
+	spec.Files = 8
+	ds, e := GenerateDataSet(spec, dir)
+	CheckError(t, e)
+	// Size of the smallest file.
+	var size int64
+	for _, name := range ds.Files {
+		fi, e := os.Stat(filepath.Join(dir, name))
+		CheckError(t, e)
+		if size == 0 || fi.Size() < size {
+			size = fi.Size()
+		}
+	}
+
+	for _, opts := range []ExecOptions{
+		{MaxWorkers: 4, MaxFrames: 2},
+		{MaxWorkers: 4, MaxBatchBytes: 3 * size},
+		{MaxWorkers: 4, MaxBatchBytes: 1},
+	} {
+		// Count frames between the start of the read and the end of fn.
+		var mu sync.Mutex
+		var inMemory, max, files int
+		ds.SetHooks(&Hooks{OnFileStart: func(string) {
+			mu.Lock()
+			defer mu.Unlock()
+			inMemory++
+			if inMemory > max {
+				max = inMemory
+			}
+		}})
+		e = ds.readParallel(context.Background(), opts, true, func(i int, df *DataFrame) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if i != files {
+				t.Fatalf("expected file %d, got %d.", files, i)
+			}
+			inMemory--
+			files++
+			return nil
+		})
+		CheckError(t, e)
+		limit := opts.MaxFrames
+		if opts.MaxBatchBytes > 0 {
+			limit = int(opts.MaxBatchBytes / size)
+			if limit < 1 {
+				limit = 1
+			}
+		}
+		if files != 8 || max > limit {
+			t.Fatalf("%+v: read %d files with up to %d in memory.", opts, files, max)
+		}
+	}
+	ds.SetHooks(nil)
+	if e = ds.readParallel(context.Background(), ExecOptions{MaxWorkers: -1}, true, nil); e == nil {
+		t.Fatalf("expected error for negative limit.")
+	}
+}
+
+func TestReadParallelCancel(t *testing.T) {
+
+	ds, cleanup := synthDataSet(t, 8, 100, 2)
+	defer cleanup()
+	before := runtime.NumGoroutine()
+
+	for _, ordered := range []bool{true, false} {
+		ctx, cancel := context.WithCancel(context.Background())
+		var files int
+		e := ds.readParallel(ctx, ExecOptions{MaxWorkers: 4}, ordered, func(i int, df *DataFrame) error {
+			files++
+			if files == 2 {
+				cancel()
+			}
+			return nil
+		})
+		cancel()
+		if e != context.Canceled || files != 2 {
+			t.Fatalf("ordered=%v: expected cancel after 2 files, got %d files and error %v.", ordered, files, e)
+		}
+	}
+	checkGoroutines(t, before)
+}
+
+// Fails if the number of goroutines does not return to n.
+func checkGoroutines(t *testing.T, n int) {
+
+	for k := 0; k < 100 && runtime.NumGoroutine() > n; k++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if g := runtime.NumGoroutine(); g > n {
+		t.Fatalf("expected %d goroutines, got %d.", n, g)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import "fmt"
+
+// Principal components of a set of float variables.
+type PCA struct {
+	// Variables used to compute the components.
+	Names []string
+	// Mean vector.
+	Mean []float64
+	// Principal components in descending order of variance. Component k is
+	// stored in row k, row-major, with Dim() columns.
+	Components []float64
+	// Variance along each component.
+	Variance []float64
+	// Fraction of the total variance explained by each component.
+	ExplainedRatio []float64
+	k, dim         int
+}
+
+// Computes the top k principal components of the float variables.
+func (df *DataFrame) PCA(names []string, k int) (*PCA, error) {
+
+	data, rows, cols, err := df.Float64Matrix(names...)
+	if err != nil {
+		return nil, err
+	}
+	if rows < 2 {
+		return nil, fmt.Errorf("Need at least two frames to compute principal components.")
+	}
+	if k <= 0 || k > cols {
+		return nil, fmt.Errorf("Number of components must be between 1 and %d, got %d.", cols, k)
+	}
+	acc := newCovAccumulator(cols)
+	for i := 0; i < rows; i++ {
+		acc.add(data[i*cols : (i+1)*cols])
+	}
+	vals, vecs := symEigen(acc.cov(), cols)
+
+	var total float64
+	for _, v := range vals {
+		total += v
+	}
+	pca := &PCA{
+		Names:          names,
+		Mean:           acc.mean,
+		Components:     make([]float64, k*cols),
+		Variance:       vals[:k],
+		ExplainedRatio: make([]float64, k),
+		k:              k,
+		dim:            cols,
+	}
+	for c := 0; c < k; c++ {
+		for i := 0; i < cols; i++ {
+			pca.Components[c*cols+i] = vecs[i*cols+c]
+		}
+		if total > 0 {
+			pca.ExplainedRatio[c] = vals[c] / total
+		}
+	}
+	return pca, nil
+}
+
+// Returns the number of components.
+func (p *PCA) K() int {
+
+	return p.k
+}
+
+// Returns the dimension of the input vectors.
+func (p *PCA) Dim() int {
+
+	return p.dim
+}
+
+// Projects a vector onto the principal components.
+func (p *PCA) Project(x []float64) ([]float64, error) {
+
+	if len(x) != p.dim {
+		return nil, fmt.Errorf("Vector dimension is %d, expected %d.", len(x), p.dim)
+	}
+	y := make([]float64, p.k)
+	for c := 0; c < p.k; c++ {
+		comp := p.Components[c*p.dim : (c+1)*p.dim]
+		var sum float64
+		for i, v := range x {
+			sum += comp[i] * (v - p.Mean[i])
+		}
+		y[c] = sum
+	}
+	return y, nil
+}
+
+// Applies Project to every vector received from in. Returns a channel of
+// projections and a channel that receives the error that stops the
+// projection, see Whitener.TransformChannel.
+func (p *PCA) ProjectChannel(in <-chan []float64) (<-chan []float64, <-chan error) {
+
+	return mapChan(in, p.Project)
+}
+
+// Returns a transform that projects the variables used to compute the
+// components in every data frame and adds the projections as a []float64
+// variable named name. For example, to use a fitted PCA in pipeline specs:
+//
+//	RegisterTransform("pca", func(p Params) (Transform, error) {
+//		return pca.Transform("pc"), nil
+//	})
+func (p *PCA) Transform(name string) Transform {
+
+	return func(df *DataFrame) (*DataFrame, error) {
+		data, rows, cols, err := df.Float64Matrix(p.Names...)
+		if err != nil {
+			return nil, err
+		}
+		if rows > 0 && cols != p.dim {
+			return nil, fmt.Errorf("Vector dimension is %d, expected %d.", cols, p.dim)
+		}
+		values := make([]interface{}, rows)
+		for i := range values {
+			if values[i], err = p.Project(data[i*cols : (i+1)*cols]); err != nil {
+				return nil, err
+			}
+		}
+		return df, df.AddVar(name, values)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPCA(t *testing.T) {
+
+	// Points along the line y = 2x with small noise in z.
+	df := &DataFrame{VarNames: []string{"x", "y", "z"}}
+	for i := 0; i < 100; i++ {
+		x := float64(i) / 10
+		z := 0.01 * float64(i%3-1)
+		df.Data = append(df.Data, []interface{}{x, 2 * x, z})
+	}
+	df.resetVarMap()
+
+	pca, e := df.PCA([]string{"x", "y", "z"}, 2)
+	CheckError(t, e)
+	t.Logf("pca: %+v", pca)
+
+	if pca.K() != 2 || pca.Dim() != 3 {
+		t.Fatalf("K/Dim must be 2/3, not %d/%d.", pca.K(), pca.Dim())
+	}
+	if pca.ExplainedRatio[0] < 0.999 {
+		t.Fatalf("first component must explain almost all variance, got %f.", pca.ExplainedRatio[0])
+	}
+
+	// First component is proportional to (1, 2, 0).
+	c := pca.Components[:3]
+	norm := math.Sqrt(5)
+	if math.Abs(math.Abs(c[0])-1/norm) > 1e-6 || math.Abs(math.Abs(c[1])-2/norm) > 1e-6 {
+		t.Fatalf("unexpected first component %v.", c)
+	}
+
+	// Projecting the mean gives zero.
+	y, e := pca.Project(pca.Mean)
+	CheckError(t, e)
+	if math.Abs(y[0]) > 1e-9 || math.Abs(y[1]) > 1e-9 {
+		t.Fatalf("projection of mean must be zero, got %v.", y)
+	}
+
+	var n int
+	ch, errc := pca.ProjectChannel(df.Float64SliceChannel("x", "y", "z"))
+	for y := range ch {
+		if len(y) != 2 {
+			t.Fatalf("projection must have dimension 2, got %d.", len(y))
+		}
+		n++
+	}
+	CheckError(t, <-errc)
+	if n != 100 {
+		t.Fatalf("expected 100 projections, got %d.", n)
+	}
+
+	in := make(chan []float64, 2)
+	in <- []float64{1, 2}
+	in <- []float64{1, 2, 3}
+	close(in)
+	ch, errc = pca.ProjectChannel(in)
+	for _ = range ch {
+		t.Fatalf("expected no projections.")
+	}
+	if e = <-errc; e == nil {
+		t.Fatalf("expected dimension error.")
+	}
+
+	if _, e = df.PCA([]string{"x", "y", "z"}, 4); e == nil {
+		t.Fatalf("expected error for k > dim.")
+	}
+
+	// Projection transform.
+	out, e := pca.Transform("pc")(df)
+	CheckError(t, e)
+	y, e = pca.Project([]float64{0.3, 0.6, -0.01})
+	CheckError(t, e)
+	pc, ok := out.Data[3][3].([]float64)
+	if !ok || len(pc) != 2 || pc[0] != y[0] || pc[1] != y[1] {
+		t.Fatalf("expected projection %v, got %v.", y, out.Data[3][3])
+	}
+	if _, e = pca.Transform("pc")(df); e == nil {
+		t.Fatalf("expected error for existing variable.")
+	}
+	if _, e = pca.Transform("pc2")(Empty("x", "y", "z")); e != nil {
+		t.Fatal(e)
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// A Pipe chains operations on a data frame. After the first error, the
+// remaining operations are skipped and the error is returned by Result.
+//
+//	df2, err := df.Pipe().Select("room", "acceleration").Filter(fn).Sort("room").Result()
+type Pipe struct {
+	view *View
+	err  error
+}
+
+// Starts a chain of operations on the data frame. Operations work on a view
+// of the frame, so no data is copied until Result is called.
+func (df *DataFrame) Pipe() *Pipe {
+
+	return &Pipe{view: df.View()}
+}
+
+// Keeps the named variables in the given order.
+func (p *Pipe) Select(names ...string) *Pipe {
+
+	if p.err != nil {
+		return p
+	}
+	p.view, p.err = p.view.Select(names...)
+	return p
+}
+
+// Keeps the rows for which fn returns true. The function gets the row of
+// the original frame.
+func (p *Pipe) Filter(fn func(row Row) bool) *Pipe {
+
+	if p.err != nil {
+		return p
+	}
+	df := p.view.parent
+	p.view = p.view.Filter(func(frame int) bool { return fn(df.Row(frame)) })
+	return p
+}
+
+// Keeps rows start to end-1.
+func (p *Pipe) Rows(start, end int) *Pipe {
+
+	if p.err != nil {
+		return p
+	}
+	p.view, p.err = p.view.Rows(start, end)
+	return p
+}
+
+// Sorts rows in ascending order by the named variables, which must be in
+// the view. The sort is stable. Variables must hold float64 or string
+// values. Nil values sort first.
+func (p *Pipe) Sort(names ...string) *Pipe {
+
+	if p.err != nil {
+		return p
+	}
+	if p.err = p.view.check(names...); p.err != nil {
+		return p
+	}
+	df := p.view.parent
+	indices, err := df.indices(names...)
+	if err != nil {
+		p.err = err
+		return p
+	}
+	rows := make([]int, p.view.N())
+	for i := range rows {
+		rows[i] = p.view.Frame(i)
+	}
+	s := &rowSorter{df: df, rows: rows, cols: indices}
+	sort.Stable(s)
+	if s.err != nil {
+		p.err = s.err
+		return p
+	}
+	p.view = &View{parent: df, rows: rows, names: p.view.names}
+	return p
+}
+
+// Returns the first error in the chain, if any.
+func (p *Pipe) Err() error {
+
+	return p.err
+}
+
+// Returns the view produced by the chain without copying data.
+func (p *Pipe) View() (*View, error) {
+
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.view, nil
+}
+
+// Returns a new data frame with the result of the chain or the first error.
+func (p *Pipe) Result() (*DataFrame, error) {
+
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.view.Materialize(), nil
+}
+
+// Sorts row numbers by the values of the given columns.
+type rowSorter struct {
+	df   *DataFrame
+	rows []int
+	cols []int
+	// optional descending order of each column.
+	desc []bool
+	err  error
+}
+
+func (s *rowSorter) Len() int      { return len(s.rows) }
+func (s *rowSorter) Swap(i, j int) { s.rows[i], s.rows[j] = s.rows[j], s.rows[i] }
+func (s *rowSorter) Less(i, j int) bool {
+
+	a, b := s.df.Data[s.rows[i]], s.df.Data[s.rows[j]]
+	for k, c := range s.cols {
+		cmp, err := compareValues(a[c], b[c])
+		if err != nil {
+			if s.err == nil {
+				s.err = fmt.Errorf("Variable [%s]: %s", s.df.VarNames[c], err)
+			}
+			return false
+		}
+		if cmp != 0 {
+			if s.desc != nil && s.desc[k] {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+	}
+	return false
+}
+
+// Compares two scalar values. Returns -1, 0, or 1. Nil is less than any
+// other value. Values must have the same type.
+func compareValues(a, b interface{}) (int, error) {
+
+	switch {
+	case a == nil && b == nil:
+		return 0, nil
+	case a == nil:
+		return -1, nil
+	case b == nil:
+		return 1, nil
+	}
+	switch x := a.(type) {
+	case float64:
+		y, ok := b.(float64)
+		if !ok {
+			break
+		}
+		switch {
+		case x < y:
+			return -1, nil
+		case x > y:
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		y, ok := b.(string)
+		if !ok {
+			break
+		}
+		switch {
+		case x < y:
+			return -1, nil
+		case x > y:
+			return 1, nil
+		}
+		return 0, nil
+	}
+	return 0, fmt.Errorf("Cannot compare values of type [%s] and [%s].",
+		reflect.TypeOf(a), reflect.TypeOf(b))
+}
@@ -0,0 +1,64 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPipe(t *testing.T) {
+
+	tmpDir := getTempDir()
+	_, f2 := createDataFiles(t, tmpDir)
+
+	df, dfe := ReadDataFrameFile(f2)
+	CheckError(t, dfe)
+
+	res, e := df.Pipe().
+		Select("room", "acceleration").
+		Filter(func(row Row) bool {
+			a, _ := row.Float64("acceleration")
+			return a > 1.35
+		}).
+		Sort("room", "acceleration").
+		Rows(0, 4).
+		Result()
+	CheckError(t, e)
+
+	expected := [][]interface{}{
+		{"DINING", 1.6},
+		{"DINING", 1.7},
+		{"DINING", 1.8},
+		{"KITCHEN", 1.4},
+	}
+	if !reflect.DeepEqual(res.Data, expected) {
+		t.Fatalf("got %v, expected %v.", res.Data, expected)
+	}
+
+	// The first error is returned at the end.
+	called := false
+	_, e = df.Pipe().
+		Select("room", "nope").
+		Filter(func(Row) bool { called = true; return true }).
+		Result()
+	if e == nil {
+		t.Fatalf("expected error for unknown variable.")
+	}
+	if called {
+		t.Fatalf("operations after an error must be skipped.")
+	}
+
+	// Variables dropped by Select can't be used to sort.
+	if _, e = df.Pipe().Select("room").Sort("acceleration").Result(); e == nil {
+		t.Fatalf("expected error sorting by a dropped variable.")
+	}
+
+	// Sorting mixed types fails.
+	if _, e = df.Pipe().Sort("wifi").Result(); e == nil {
+		t.Fatalf("expected error sorting vectors.")
+	}
+}
@@ -0,0 +1,534 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"launchpad.net/goyaml"
+)
+
+// A PipelineSpec defines a preprocessing job: every data frame of the
+// sources goes through the transforms and is written to every sink. Specs
+// are written in YAML, or JSON, which is valid YAML:
+//
+//	sources:
+//	  - dataset: raw/imu.yaml
+//	transforms:
+//	  - name: downsample
+//	    params: {time_var: t, period: 100ms, mode: mean}
+//	  - name: rename
+//	    params: {names: {acceleration: acc}}
+//	sinks:
+//	  - dir: clean
+//	  - dir: clean-binary
+//	    format: binary
+//
+// Relative paths are relative to the directory of the spec file.
+type PipelineSpec struct {
+	Sources    []PipelineSource `yaml:"sources"`
+	Transforms []TransformSpec  `yaml:"transforms"`
+	Sinks      []PipelineSink   `yaml:"sinks"`
+	// Resource limits, for example:
+	//
+	//	execution: {max_workers: 4, max_frames: 8, max_batch_bytes: 1073741824}
+	Execution ExecOptions `yaml:"execution"`
+}
+
+// A data set read by a pipeline.
+type PipelineSource struct {
+	// Data set file, see ReadDataSetFile.
+	DataSet string `yaml:"dataset"`
+}
+
+// A registered transform and its parameters, see RegisterTransform.
+type TransformSpec struct {
+	Name   string `yaml:"name"`
+	Params Params `yaml:"params"`
+}
+
+// Output formats of a pipeline sink.
+const (
+	SinkJSON   = "json"
+	SinkBinary = "binary"
+	SinkAvro   = "avro"
+)
+
+// A directory where a pipeline writes data frames. Files keep the names of
+// the input files, with the extension of the format, and a data set
+// manifest, see ManifestFile, lists them.
+type PipelineSink struct {
+	Dir string `yaml:"dir"`
+	// SinkJSON, the default, SinkBinary, or SinkAvro.
+	Format string `yaml:"format"`
+	// What to do with existing output files: "replace", the default,
+	// "error", or "skip", which resumes an interrupted run. See
+	// OverwritePolicy.
+	Overwrite string `yaml:"overwrite"`
+}
+
+// Reads a pipeline spec from a YAML file.
+func ReadPipelineSpecFile(fn string) (spec *PipelineSpec, e error) {
+
+	f, e := os.Open(fn)
+	if e != nil {
+		return
+	}
+	defer f.Close()
+	return ReadPipelineSpec(f)
+}
+
+// Reads a pipeline spec from an io.Reader.
+func ReadPipelineSpec(r io.Reader) (spec *PipelineSpec, e error) {
+
+	b, e := ioutil.ReadAll(r)
+	if e != nil {
+		return
+	}
+	spec = &PipelineSpec{}
+	if e = goyaml.Unmarshal(b, spec); e != nil {
+		return nil, e
+	}
+	return
+}
+
+// Reads a pipeline spec file and runs it.
+func RunPipeline(specFile string) error {
+
+	return RunPipelineContext(context.Background(), specFile)
+}
+
+// Reads a pipeline spec file and runs it until ctx is cancelled, see
+// PipelineSpec.RunContext.
+func RunPipelineContext(ctx context.Context, specFile string) error {
+
+	spec, err := ReadPipelineSpecFile(specFile)
+	if err != nil {
+		return err
+	}
+	if err = spec.RunContext(ctx, filepath.Dir(specFile)); err != nil {
+		return fmt.Errorf("pipeline %s: %s", specFile, err)
+	}
+	return nil
+}
+
+// Runs the pipeline. Relative paths are resolved against dir. Output frames
+// record the package version, the transform specs, and their inputs, see
+// LineageTransforms.
+func (spec *PipelineSpec) Run(dir string) error {
+
+	return spec.RunContext(context.Background(), dir)
+}
+
+// Like Run but stops when ctx is cancelled and returns ctx.Err(). On error,
+// the files written by the run are removed and no manifest is written.
+func (spec *PipelineSpec) RunContext(ctx context.Context, dir string) (err error) {
+
+	transforms, err := spec.transforms()
+	if err != nil {
+		return err
+	}
+	if len(spec.Sources) == 0 || len(spec.Sinks) == 0 {
+		return fmt.Errorf("A pipeline needs at least one source and one sink.")
+	}
+	if err = spec.Execution.check(); err != nil {
+		return err
+	}
+	id, err := hashTransforms(spec.Transforms)
+	if err != nil {
+		return err
+	}
+	sinks := make([]string, len(spec.Sinks))
+	policies := make([]OverwritePolicy, len(spec.Sinks))
+	for k, sink := range spec.Sinks {
+		if sink.Format != "" && sink.Format != SinkJSON && sink.Format != SinkBinary && sink.Format != SinkAvro {
+			return fmt.Errorf("Unknown sink format [%s].", sink.Format)
+		}
+		if policies[k], err = ParseOverwritePolicy(sink.Overwrite); err != nil {
+			return err
+		}
+		sinks[k] = resolvePath(dir, sink.Dir)
+	}
+
+	// Files listed in the manifests, and files written by this run.
+	files := make([][]string, len(sinks))
+	written := make([][]string, len(sinks))
+	var done bool
+	defer func() {
+		if err == nil || done {
+			return
+		}
+		for k, sink := range sinks {
+			for _, name := range written[k] {
+				os.Remove(filepath.Join(sink, name))
+			}
+		}
+	}()
+	seen := make(map[string]string)
+	for _, src := range spec.Sources {
+		fn := resolvePath(dir, src.DataSet)
+		ds, err := ReadDataSetFile(fn)
+		if err != nil {
+			return err
+		}
+		err = transformEach(ctx, ds, spec.Execution, id, transforms, func(i int, df *DataFrame) error {
+			name := jsonName(ds.Files[i])
+			if prev, ok := seen[name]; ok {
+				return fmt.Errorf("Output file %s of %s was already written for %s.", name, fn, prev)
+			}
+			seen[name] = fn
+			for k, sink := range spec.Sinks {
+				out, ok, err := writeSinkFile(sinks[k], name, sink.Format, policies[k], df)
+				if err != nil {
+					return err
+				}
+				files[k] = append(files[k], out)
+				if ok {
+					written[k] = append(written[k], out)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	done = true
+	for k, sink := range sinks {
+		fn := filepath.Join(sink, ManifestFile)
+		release, err := acquireLease(fn + LeaseExt)
+		if err != nil {
+			return err
+		}
+		err = writeManifest(fn, sink, files[k])
+		release()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Builds the transforms of the spec.
+func (spec *PipelineSpec) transforms() ([]Transform, error) {
+
+	transforms := make([]Transform, len(spec.Transforms))
+	for k, ts := range spec.Transforms {
+		factory, err := LookupTransform(ts.Name)
+		if err != nil {
+			return nil, err
+		}
+		if transforms[k], err = factory(ts.Params); err != nil {
+			return nil, fmt.Errorf("transform %s: %s", ts.Name, err)
+		}
+	}
+	return transforms, nil
+}
+
+// Writes a data frame to a sink. Returns the file name and false if an
+// existing file was skipped.
+func writeSinkFile(dir, name, format string, policy OverwritePolicy, df *DataFrame) (string, bool, error) {
+
+	switch format {
+	case SinkBinary:
+		name = strings.TrimSuffix(strings.TrimSuffix(name, GzipExt), ".json") + BinaryExt
+	case SinkAvro:
+		name = strings.TrimSuffix(strings.TrimSuffix(name, GzipExt), ".json") + AvroExt
+	}
+	fn := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+		return "", false, err
+	}
+	write := df.WriteDataFrame
+	switch format {
+	case SinkBinary:
+		write = df.WriteBinary
+	case SinkAvro:
+		write = df.WriteAvro
+	}
+	ok, err := writeFilePolicy(fn, 0644, policy, write)
+	return name, ok, err
+}
+
+func resolvePath(dir, fn string) string {
+
+	if filepath.IsAbs(fn) {
+		return fn
+	}
+	return filepath.Join(dir, fn)
+}
+
+// Parameters of a transform in a pipeline spec.
+type Params map[string]interface{}
+
+// Returns true if the parameter is set.
+func (p Params) Has(name string) bool {
+
+	_, ok := p[name]
+	return ok
+}
+
+// Returns a string parameter.
+func (p Params) String(name string) (string, error) {
+
+	v, ok := p[name]
+	if !ok {
+		return "", fmt.Errorf("Missing parameter [%s].", name)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("Parameter [%s] must be a string, got %v.", name, v)
+	}
+	return s, nil
+}
+
+// Returns a numeric parameter.
+func (p Params) Float64(name string) (float64, error) {
+
+	v, ok := p[name]
+	if !ok {
+		return 0, fmt.Errorf("Missing parameter [%s].", name)
+	}
+	switch x := v.(type) {
+	case float64:
+		return x, nil
+	case int:
+		return float64(x), nil
+	case int64:
+		return float64(x), nil
+	}
+	return 0, fmt.Errorf("Parameter [%s] must be a number, got %v.", name, v)
+}
+
+// Returns an integer parameter.
+func (p Params) Int(name string) (int, error) {
+
+	f, err := p.Float64(name)
+	if err != nil {
+		return 0, err
+	}
+	if f != float64(int(f)) {
+		return 0, fmt.Errorf("Parameter [%s] must be an integer, got %g.", name, f)
+	}
+	return int(f), nil
+}
+
+// Returns a duration parameter written as a string such as "100ms".
+func (p Params) Duration(name string) (time.Duration, error) {
+
+	s, err := p.String(name)
+	if err != nil {
+		return 0, err
+	}
+	return time.ParseDuration(s)
+}
+
+// Returns a list of strings parameter.
+func (p Params) Strings(name string) ([]string, error) {
+
+	v, ok := p[name]
+	if !ok {
+		return nil, fmt.Errorf("Missing parameter [%s].", name)
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Parameter [%s] must be a list, got %v.", name, v)
+	}
+	strs := make([]string, len(list))
+	for i, x := range list {
+		if strs[i], ok = x.(string); !ok {
+			return nil, fmt.Errorf("Parameter [%s] must be a list of strings, got %v.", name, x)
+		}
+	}
+	return strs, nil
+}
+
+// Returns a parameter that maps strings to strings.
+func (p Params) StringMap(name string) (map[string]string, error) {
+
+	v, ok := p[name]
+	if !ok {
+		return nil, fmt.Errorf("Missing parameter [%s].", name)
+	}
+	m := make(map[string]string)
+	switch x := v.(type) {
+	case map[interface{}]interface{}:
+		for k, v := range x {
+			ks, ok1 := k.(string)
+			vs, ok2 := v.(string)
+			if !ok1 || !ok2 {
+				return nil, fmt.Errorf("Parameter [%s] must map strings to strings, got %v: %v.", name, k, v)
+			}
+			m[ks] = vs
+		}
+	case map[string]interface{}:
+		for k, v := range x {
+			vs, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("Parameter [%s] must map strings to strings, got %v: %v.", name, k, v)
+			}
+			m[k] = vs
+		}
+	default:
+		return nil, fmt.Errorf("Parameter [%s] must be a map, got %v.", name, v)
+	}
+	return m, nil
+}
+
+// A TransformFactory builds a transform from the parameters in a pipeline
+// spec.
+type TransformFactory func(p Params) (Transform, error)
+
+var transformFactories = struct {
+	sync.RWMutex
+	m map[string]TransformFactory
+}{m: make(map[string]TransformFactory)}
+
+func init() {
+
+	RegisterTransform("decimate", func(p Params) (Transform, error) {
+		factor, err := p.Int("factor")
+		if err != nil {
+			return nil, err
+		}
+		mode, err := p.String("mode")
+		if err != nil {
+			return nil, err
+		}
+		return Decimate(factor, DecimateMode(mode)), nil
+	})
+	RegisterTransform("downsample", func(p Params) (Transform, error) {
+		timeVar, err := p.String("time_var")
+		if err != nil {
+			return nil, err
+		}
+		period, err := p.Duration("period")
+		if err != nil {
+			return nil, err
+		}
+		mode, err := p.String("mode")
+		if err != nil {
+			return nil, err
+		}
+		return Downsample(timeVar, period, DecimateMode(mode)), nil
+	})
+	RegisterTransform("upsample", func(p Params) (Transform, error) {
+		timeVar, err := p.String("time_var")
+		if err != nil {
+			return nil, err
+		}
+		period, err := p.Duration("period")
+		if err != nil {
+			return nil, err
+		}
+		method, err := p.String("method")
+		if err != nil {
+			return nil, err
+		}
+		return Upsample(timeVar, period, Interpolation(method)), nil
+	})
+	RegisterTransform("trim", func(p Params) (Transform, error) {
+		name, lo, hi, err := percentileParams(p)
+		if err != nil {
+			return nil, err
+		}
+		return func(df *DataFrame) (*DataFrame, error) {
+			return df.Trim(name, lo, hi)
+		}, nil
+	})
+	RegisterTransform("winsorize", func(p Params) (Transform, error) {
+		name, lo, hi, err := percentileParams(p)
+		if err != nil {
+			return nil, err
+		}
+		return func(df *DataFrame) (*DataFrame, error) {
+			return df, df.Winsorize(name, lo, hi)
+		}, nil
+	})
+	RegisterTransform("select", func(p Params) (Transform, error) {
+		names, err := p.Strings("vars")
+		if err != nil {
+			return nil, err
+		}
+		return func(df *DataFrame) (*DataFrame, error) {
+			v, err := df.View().Select(names...)
+			if err != nil {
+				return nil, err
+			}
+			return v.Materialize(), nil
+		}, nil
+	})
+	RegisterTransform("rename", func(p Params) (Transform, error) {
+		names, err := p.StringMap("names")
+		if err != nil {
+			return nil, err
+		}
+		return func(df *DataFrame) (*DataFrame, error) {
+			return df, df.Rename(names)
+		}, nil
+	})
+}
+
+func percentileParams(p Params) (name string, lo, hi float64, err error) {
+
+	if name, err = p.String("var"); err != nil {
+		return
+	}
+	if lo, err = p.Float64("lo"); err != nil {
+		return
+	}
+	hi, err = p.Float64("hi")
+	return
+}
+
+// Registers a transform factory by name so the transform can be used in
+// pipeline specs. Built-in transforms are decimate, downsample, upsample,
+// trim, winsorize, select, and rename. Registering an existing name returns an
+// error.
+func RegisterTransform(name string, factory TransformFactory) error {
+
+	transformFactories.Lock()
+	defer transformFactories.Unlock()
+	if _, ok := transformFactories.m[name]; ok {
+		return fmt.Errorf("Transform [%s] is already registered.", name)
+	}
+	transformFactories.m[name] = factory
+	return nil
+}
+
+// Returns the transform factory registered with name.
+func LookupTransform(name string) (TransformFactory, error) {
+
+	transformFactories.RLock()
+	defer transformFactories.RUnlock()
+	factory, ok := transformFactories.m[name]
+	if !ok {
+		return nil, fmt.Errorf("There is no transform named [%s].", name)
+	}
+	return factory, nil
+}
+
+// Returns the names of the registered transforms in sorted order.
+func Transforms() []string {
+
+	transformFactories.RLock()
+	defer transformFactories.RUnlock()
+	names := make([]string, 0, len(transformFactories.m))
+	for name := range transformFactories.m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
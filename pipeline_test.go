@@ -0,0 +1,168 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunPipeline(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-pipeline")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	CheckError(t, os.MkdirAll(filepath.Join(dir, "raw"), 0755))
+	CheckError(t, ioutil.WriteFile(filepath.Join(dir, "raw", "file1.json"), []byte(file1), 0644))
+	CheckError(t, ioutil.WriteFile(filepath.Join(dir, "raw", "file2.json"), []byte(file2), 0644))
+	CheckError(t, ioutil.WriteFile(filepath.Join(dir, "raw.yaml"),
+		[]byte("path: "+filepath.Join(dir, "raw")+"\nfiles: [file1.json, file2.json]\n"), 0644))
+
+	spec := filepath.Join(dir, "pipeline.yaml")
+	CheckError(t, ioutil.WriteFile(spec, []byte(`
+sources:
+  - dataset: raw.yaml
+transforms:
+  - name: trim
+    params: {var: acceleration, lo: 0, hi: 50}
+  - name: rename
+    params:
+      names: {acceleration: acc}
+sinks:
+  - dir: clean
+  - dir: clean-binary
+    format: binary
+  - dir: clean-avro
+    format: avro
+`), 0644))
+
+	// A cancelled run writes nothing.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if e = RunPipelineContext(ctx, spec); e == nil || !strings.Contains(e.Error(), context.Canceled.Error()) {
+		t.Fatalf("expected cancel error, got %v.", e)
+	}
+	if names, _ := ioutil.ReadDir(filepath.Join(dir, "clean")); len(names) != 0 {
+		t.Fatalf("expected no output, got %d files.", len(names))
+	}
+	CheckError(t, RunPipeline(spec))
+
+	for _, sink := range []string{"clean", "clean-binary", "clean-avro"} {
+		ds, e := ReadDataSetFile(filepath.Join(dir, sink, ManifestFile))
+		CheckError(t, e)
+		if len(ds.Files) != 2 {
+			t.Fatalf("%s: unexpected files %v.", sink, ds.Files)
+		}
+		var rows int
+		for {
+			df, e := ds.Next()
+			if e == io.EOF {
+				break
+			}
+			CheckError(t, e)
+			if _, e = df.indices("acc"); e != nil {
+				t.Fatalf("%s: variable not renamed.", sink)
+			}
+			if len(df.Properties[LineageTransforms]) != 64 || df.Properties[LineageInputSHA256] == "" {
+				t.Fatalf("%s: missing lineage %v.", sink, df.Properties)
+			}
+			rows += df.N()
+		}
+		// Trim keeps the lower half of each file.
+		if rows != 6 {
+			t.Fatalf("%s: expected 6 rows, got %d.", sink, rows)
+		}
+	}
+	if !fileExists(filepath.Join(dir, "clean-binary", "file1"+BinaryExt)) {
+		t.Fatalf("expected binary output.")
+	}
+}
+
+func TestPipelineSpecErrors(t *testing.T) {
+
+	// JSON is valid YAML.
+	spec, e := ReadPipelineSpec(strings.NewReader(`{"sources": [{"dataset": "a.yaml"}],
+  "transforms": [{"name": "decimate", "params": {"factor": 2.5, "mode": "mean"}}],
+  "sinks": [{"dir": "out"}]}`))
+	CheckError(t, e)
+	if e = spec.Run(os.TempDir()); e == nil || !strings.Contains(e.Error(), "integer") {
+		t.Fatalf("expected parameter error, got %v.", e)
+	}
+	spec.Transforms[0].Name = "nope"
+	if e = spec.Run(os.TempDir()); e == nil || !strings.Contains(e.Error(), "nope") {
+		t.Fatalf("expected unknown transform error, got %v.", e)
+	}
+	spec.Transforms = nil
+	spec.Sinks[0].Format = "xml"
+	if e = spec.Run(os.TempDir()); e == nil {
+		t.Fatalf("expected unknown format error.")
+	}
+	spec.Sinks[0].Format = ""
+	spec.Sinks[0].Overwrite = "append"
+	if e = spec.Run(os.TempDir()); e == nil || !strings.Contains(e.Error(), "append") {
+		t.Fatalf("expected unknown overwrite policy error, got %v.", e)
+	}
+	if e = RegisterTransform("rename", nil); e == nil {
+		t.Fatalf("expected error for duplicate transform.")
+	}
+	if len(Transforms()) < 7 {
+		t.Fatalf("missing built-in transforms %v.", Transforms())
+	}
+}
+
+func TestExplainPipeline(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-pipeline")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	CheckError(t, os.MkdirAll(filepath.Join(dir, "raw"), 0755))
+	CheckError(t, ioutil.WriteFile(filepath.Join(dir, "raw", "file1.json"), []byte(file1), 0644))
+	CheckError(t, ioutil.WriteFile(filepath.Join(dir, "raw", "file2.json"), []byte(file2), 0644))
+	CheckError(t, ioutil.WriteFile(filepath.Join(dir, "raw.yaml"),
+		[]byte("path: "+filepath.Join(dir, "raw")+"\nfiles: [file1.json, file2.json]\n"), 0644))
+	spec := filepath.Join(dir, "pipeline.yaml")
+	CheckError(t, ioutil.WriteFile(spec, []byte(`
+sources:
+  - dataset: raw.yaml
+transforms:
+  - name: decimate
+    params: {factor: 2, mode: keep}
+  - name: select
+    params: {vars: [room, acceleration]}
+  - name: rename
+    params:
+      names: {acceleration: acc}
+sinks:
+  - dir: clean
+`), 0644))
+
+	plan, e := ExplainPipeline(spec)
+	CheckError(t, e)
+	t.Log("\n" + plan.String())
+	if plan.InputRows != 12 || plan.EstimatedRows != 6 || len(plan.Steps) != 3 {
+		t.Fatalf("unexpected plan %+v.", plan)
+	}
+	if strings.Join(plan.PrunedVars, ",") != "wifi,acceleration" || strings.Join(plan.AddedVars, ",") != "acc" {
+		t.Fatalf("unexpected variables %v %v.", plan.PrunedVars, plan.AddedVars)
+	}
+	if fileExists(filepath.Join(dir, "clean")) {
+		t.Fatalf("explain must not write output.")
+	}
+
+	// Estimates are extrapolated from the previews.
+	ExplainPreviewRows = 2
+	defer func() { ExplainPreviewRows = 1000 }()
+	plan, e = ExplainPipeline(spec)
+	CheckError(t, e)
+	if plan.EstimatedRows != 6 {
+		t.Fatalf("expected 6 rows, got %d.", plan.EstimatedRows)
+	}
+}
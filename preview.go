@@ -0,0 +1,150 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var errStopDecoding = errors.New("stop decoding")
+
+// Returns a data frame with the first nPerFile rows of each file in the data
+// set and a variable ProvenanceFile with the file name. JSON files are
+// decoded only up to the last row needed, so previews of large files are
+// fast. All files must have the same variables. Data set limits are
+// enforced. Data set transformations, such as calibration, are not applied.
+// The coercion report, see CoercionReport, lists the cells of the previewed
+// rows. For JSON files, the type of each variable is inferred from the
+// previewed rows only, so the report may differ from that of a full read.
+func (ds *DataSet) Preview(nPerFile int) (*DataFrame, error) {
+
+	if nPerFile < 0 {
+		return nil, fmt.Errorf("Number of rows must not be negative, got %d.", nPerFile)
+	}
+	var preview *DataFrame
+	sep := string(os.PathSeparator)
+	for _, name := range ds.Files {
+		fn := ds.Path + sep + name
+		df, err := ds.previewFile(fn, nPerFile)
+		if err != nil {
+			return nil, err
+		}
+		if preview == nil {
+			preview = Empty(append(append([]string(nil), df.VarNames...), ProvenanceFile)...)
+			preview.Description = df.Description
+			preview.BatchID = "preview"
+			preview.VarUnits = df.VarUnits
+		} else if strings.Join(df.VarNames, "\x00") != strings.Join(preview.VarNames[:len(preview.VarNames)-1], "\x00") {
+			return nil, fmt.Errorf("file %s: variables %v don't match %v.", fn, df.VarNames, preview.VarNames[:len(preview.VarNames)-1])
+		}
+		for _, c := range df.coercions {
+			if c.Row < df.N() {
+				c.Row += preview.N()
+				preview.coercions = append(preview.coercions, c)
+			}
+		}
+		for _, row := range df.Data {
+			preview.Data = append(preview.Data, append(row, name))
+		}
+	}
+	if preview == nil {
+		return Empty(ProvenanceFile), nil
+	}
+	return preview, nil
+}
+
+// Reads the first n rows of a file. Errors include the file name.
+func (ds *DataSet) previewFile(fn string, n int) (*DataFrame, error) {
+
+	if !strings.HasSuffix(strings.TrimSuffix(fn, GzipExt), ".json") || ds.verifier != nil || ds.decrypter != nil {
+		df, err := ds.readFile(fn)
+		if err != nil {
+			return nil, err
+		}
+		if df.N() > n {
+			df.Data = df.Data[:n]
+		}
+		return df, nil
+	}
+	f, err := openFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	df, err := ds.previewJSON(f, n)
+	if err != nil {
+		return nil, fmt.Errorf("file %s: %s", fn, err)
+	}
+	df.setReportFile(fn)
+	return df, nil
+}
+
+// Decodes the first n rows of a JSON or binary data frame enforcing the
+// data set limits. The limits apply to the input read, which includes the
+// rows after the first n when the variable names follow the data.
+func (ds *DataSet) previewJSON(r io.Reader, n int) (*DataFrame, error) {
+
+	var limits ReadLimits
+	if ds.Limits != nil {
+		limits = *ds.Limits
+	}
+	r, binary := isBinary(r)
+	if binary {
+		df, err := ReadDataFrameBinaryLimits(r, limits)
+		if err != nil {
+			return nil, err
+		}
+		if df.N() > n {
+			df.Data = df.Data[:n]
+		}
+		return df, nil
+	}
+	r, err := NewDecodingReader(r, ds.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	lr := &limitedReader{r: r, n: limits.MaxBytes}
+	df := &DataFrame{Data: make([][]interface{}, 0, n)}
+	var rows int
+	err = decodeDataFrame(json.NewDecoder(lr), df, func(row []interface{}) error {
+		if limits.MaxRows > 0 && rows >= limits.MaxRows {
+			return fmt.Errorf("Number of rows exceeds limit of %d.", limits.MaxRows)
+		}
+		if err := limits.checkRow(row); err != nil {
+			return fmt.Errorf("In frame %d, %s", rows, err)
+		}
+		rows++
+		if len(df.Data) < n {
+			df.Data = append(df.Data, row)
+			return nil
+		}
+		// Keep decoding if the variable names come after the data.
+		if df.VarNames != nil {
+			return errStopDecoding
+		}
+		return nil
+	})
+	if lr.exceeded {
+		return nil, fmt.Errorf("Input exceeds limit of %d bytes.", limits.MaxBytes)
+	}
+	if err != nil && err != errStopDecoding {
+		return nil, err
+	}
+	if err = limits.checkHeader(df); err != nil {
+		return nil, err
+	}
+	if err = checkRowWidths(df); err != nil {
+		return nil, err
+	}
+	df.resetVarMap()
+	df.coerceJSON()
+	return df, nil
+}
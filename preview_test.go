@@ -0,0 +1,71 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPreview(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-preview")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	CheckError(t, ioutil.WriteFile(filepath.Join(dir, "file1.json"), []byte(file1), 0644))
+	// Truncated file: the decoder must stop before the syntax error.
+	CheckError(t, ioutil.WriteFile(filepath.Join(dir, "file2.json"), []byte(file2[:strings.Index(file2, "[\"DINING\"")]), 0644))
+	CheckError(t, ioutil.WriteFile(filepath.Join(dir, "file3.csv"), []byte("room,wifi,acceleration\nBATH,-50,1.1\nBATH,-51,1.2\nBATH,-52,1.3\n"), 0644))
+
+	ds := &DataSet{Path: dir, Files: []string{"file1.json", "file2.json", "file3.csv"}}
+	df, e := ds.Preview(2)
+	CheckError(t, e)
+	if df.N() != 6 {
+		t.Fatalf("expected 6 rows, got %d.", df.N())
+	}
+	for i, want := range []string{"file1.json", "file1.json", "file2.json", "file2.json", "file3.csv", "file3.csv"} {
+		if s, _ := df.String(i, ProvenanceFile); s != want {
+			t.Fatalf("row %d: expected %s, got %s.", i, want, s)
+		}
+	}
+	if s, _ := df.String(2, "room"); s != "KITCHEN" {
+		t.Fatalf("unexpected room %s.", s)
+	}
+
+	if _, e = ds.Preview(4); e == nil {
+		t.Fatalf("expected error for truncated file.")
+	}
+	if n := strings.Count(e.Error(), "file2.json"); n != 1 {
+		t.Fatalf("file name must appear once in %q.", e)
+	}
+
+	// Limits.
+	ds.Limits = &ReadLimits{MaxRows: 1}
+	if _, e = ds.Preview(2); e == nil {
+		t.Fatalf("expected row limit error.")
+	}
+	t.Log(e)
+	ds.Limits = &ReadLimits{MaxVecLen: 1}
+	if _, e = ds.Preview(2); e == nil || strings.Count(e.Error(), "file1.json") != 1 {
+		t.Fatalf("expected vector limit error for file1.json, got %v.", e)
+	}
+	ds.Limits = nil
+
+	// Coercion report of the previewed rows.
+	bad := strings.Replace(file1, "1.4]", `"x"]`, 1)
+	bad = strings.Replace(bad, "1.8]", `"y"]`, 1)
+	CheckError(t, ioutil.WriteFile(filepath.Join(dir, "bad.json"), []byte(bad), 0644))
+	ds.Files = []string{"file1.json", "bad.json"}
+	df, e = ds.Preview(3)
+	CheckError(t, e)
+	r := df.CoercionReport()
+	if len(r) != 1 || r[0].Row != 4 || r[0].Raw != "x" || !strings.HasSuffix(r[0].File, "bad.json") {
+		t.Fatalf("unexpected coercion report %v.", r)
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"sync"
+)
+
+// Noise mechanisms for differential privacy.
+type NoiseMechanism string
+
+const (
+	// Laplace noise with scale Sensitivity/Epsilon. Gives epsilon-DP.
+	NoiseLaplace NoiseMechanism = "laplace"
+	// Gaussian noise with standard deviation
+	// Sensitivity*sqrt(2*ln(1.25/Delta))/Epsilon. Gives (epsilon, delta)-DP
+	// and requires Epsilon < 1.
+	NoiseGaussian NoiseMechanism = "gaussian"
+)
+
+// Describes the noise added to a numeric variable.
+type NoiseSpec struct {
+	Var       string         `yaml:"var"`
+	Mechanism NoiseMechanism `yaml:"mechanism"`
+	// Maximum change of a value due to a single individual.
+	Sensitivity float64 `yaml:"sensitivity"`
+	// Privacy loss spent each time the noise is applied to a frame.
+	Epsilon float64 `yaml:"epsilon"`
+	// Probability of failure for the Gaussian mechanism.
+	Delta float64 `yaml:"delta"`
+}
+
+// Keeps track of the total privacy loss using sequential composition: the
+// epsilon of every noise application is added to the spent budget.
+type PrivacyAccountant struct {
+	sync.Mutex
+	budget float64
+	spent  float64
+}
+
+// Returns an accountant with a total epsilon budget.
+func NewPrivacyAccountant(budget float64) *PrivacyAccountant {
+
+	return &PrivacyAccountant{budget: budget}
+}
+
+// Spends epsilon. Returns an error, without spending, if the budget would be
+// exceeded.
+func (a *PrivacyAccountant) Spend(epsilon float64) error {
+
+	a.Lock()
+	defer a.Unlock()
+	if a.spent+epsilon > a.budget {
+		return fmt.Errorf("Privacy budget exceeded: spent %g, requested %g, budget %g.", a.spent, epsilon, a.budget)
+	}
+	a.spent += epsilon
+	return nil
+}
+
+// Returns the epsilon spent so far.
+func (a *PrivacyAccountant) Spent() float64 {
+
+	a.Lock()
+	defer a.Unlock()
+	return a.spent
+}
+
+// Returns the epsilon left in the budget.
+func (a *PrivacyAccountant) Remaining() float64 {
+
+	a.Lock()
+	defer a.Unlock()
+	return a.budget - a.spent
+}
+
+// Adds noise to float64 and []float64 variables in place. Nil values are
+// left unchanged. The epsilon of each spec is charged to the accountant
+// before any value is modified; acct may be nil to skip accounting.
+func (df *DataFrame) AddNoise(r *rand.Rand, acct *PrivacyAccountant, specs ...NoiseSpec) error {
+
+	var total float64
+	for _, s := range specs {
+		if err := s.check(); err != nil {
+			return err
+		}
+		if _, err := df.indices(s.Var); err != nil {
+			return err
+		}
+		total += s.Epsilon
+	}
+	if acct != nil {
+		if err := acct.Spend(total); err != nil {
+			return err
+		}
+	}
+	for _, s := range specs {
+		idx := df.varMap[s.Var]
+		for i, row := range df.Data {
+			switch x := row[idx].(type) {
+			case nil:
+			case float64:
+				row[idx] = x + s.sample(r)
+			case []float64, []interface{}:
+				vec, err := toFloat64Slice(x)
+				if err != nil {
+					return fmt.Errorf("In frame %d, variable [%s]: %s", i, s.Var, err)
+				}
+				out := make([]float64, len(vec))
+				for j, f := range vec {
+					out[j] = f + s.sample(r)
+				}
+				row[idx] = out
+			default:
+				return fmt.Errorf("In frame %d, variable [%s] has unsupported type [%s].",
+					i, s.Var, reflect.TypeOf(x).String())
+			}
+		}
+		df.Invalidate(s.Var)
+	}
+	return nil
+}
+
+// Returns a transform that adds noise to every data frame, see
+// DataFrame.AddNoise. Each data frame spends the epsilon of the specs.
+func AddNoise(seed int64, acct *PrivacyAccountant, specs ...NoiseSpec) Transform {
+
+	r := rand.New(rand.NewSource(seed))
+	return func(df *DataFrame) (*DataFrame, error) {
+		return df, df.AddNoise(r, acct, specs...)
+	}
+}
+
+func (s NoiseSpec) check() error {
+
+	// Comparisons are negated so NaN is rejected.
+	if !(s.Epsilon > 0) || !(s.Sensitivity > 0) || math.IsInf(s.Epsilon, 1) || math.IsInf(s.Sensitivity, 1) {
+		return fmt.Errorf("Variable [%s]: epsilon and sensitivity must be positive and finite.", s.Var)
+	}
+	switch s.Mechanism {
+	case NoiseLaplace:
+	case NoiseGaussian:
+		if !(s.Delta > 0 && s.Delta < 1) {
+			return fmt.Errorf("Variable [%s]: delta must be in (0, 1).", s.Var)
+		}
+		if s.Epsilon >= 1 {
+			return fmt.Errorf("Variable [%s]: the Gaussian mechanism requires epsilon < 1, got %g.", s.Var, s.Epsilon)
+		}
+	default:
+		return fmt.Errorf("Variable [%s]: unknown noise mechanism [%s].", s.Var, s.Mechanism)
+	}
+	return nil
+}
+
+// Returns a noise sample.
+func (s NoiseSpec) sample(r *rand.Rand) float64 {
+
+	if s.Mechanism == NoiseGaussian {
+		sigma := s.Sensitivity * math.Sqrt(2*math.Log(1.25/s.Delta)) / s.Epsilon
+		return r.NormFloat64() * sigma
+	}
+	b := s.Sensitivity / s.Epsilon
+	u := r.Float64() - 0.5
+	if u < 0 {
+		return b * math.Log(1+2*u)
+	}
+	return -b * math.Log(1-2*u)
+}
@@ -0,0 +1,81 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestNoiseDistribution(t *testing.T) {
+
+	r := rand.New(rand.NewSource(1))
+	for _, s := range []NoiseSpec{
+		{Mechanism: NoiseLaplace, Sensitivity: 1, Epsilon: 0.5},
+		{Mechanism: NoiseGaussian, Sensitivity: 1, Epsilon: 0.5, Delta: 1e-5},
+	} {
+		acc := NewAccumulator(1)
+		for i := 0; i < 100000; i++ {
+			acc.Add([]float64{s.sample(r)})
+		}
+		// Laplace variance is 2b^2.
+		want := 2 * math.Pow(s.Sensitivity/s.Epsilon, 2)
+		if s.Mechanism == NoiseGaussian {
+			want = math.Pow(s.Sensitivity*math.Sqrt(2*math.Log(1.25/s.Delta))/s.Epsilon, 2)
+		}
+		mean, variance := acc.Mean()[0], acc.Variance()[0]
+		if math.Abs(mean) > 0.1 || math.Abs(variance-want)/want > 0.05 {
+			t.Fatalf("%s: mean %g, variance %g, expected variance %g.", s.Mechanism, mean, variance, want)
+		}
+	}
+}
+
+func TestAddNoise(t *testing.T) {
+
+	acct := NewPrivacyAccountant(1)
+	spec := NoiseSpec{Var: "acceleration", Mechanism: NoiseLaplace, Sensitivity: 0.1, Epsilon: 0.4}
+	transform := AddNoise(7, acct, spec)
+
+	df := synthFrame(10, 2, 0)
+	orig, _ := df.Float64Slice(0, "acceleration")
+	_, e := transform(df)
+	CheckError(t, e)
+	noisy, _ := df.Float64Slice(0, "acceleration")
+	if orig[0] == noisy[0] {
+		t.Fatalf("noise was not added.")
+	}
+	_, e = transform(synthFrame(10, 2, 0))
+	CheckError(t, e)
+	if math.Abs(acct.Spent()-0.8) > 1e-12 {
+		t.Fatalf("spent %g, expected 0.8.", acct.Spent())
+	}
+
+	// Budget exhausted: the frame is not modified.
+	df = synthFrame(10, 2, 0)
+	if _, e = transform(df); e == nil {
+		t.Fatalf("expected error for exhausted budget.")
+	}
+	v, _ := df.Float64Slice(0, "acceleration")
+	if v[0] != orig[0] {
+		t.Fatalf("frame must not change when the budget is exceeded.")
+	}
+
+	bad := NoiseSpec{Var: "acceleration", Mechanism: NoiseGaussian, Sensitivity: 1, Epsilon: 0.5}
+	if e = df.AddNoise(rand.New(rand.NewSource(0)), nil, bad); e == nil {
+		t.Fatalf("expected error for missing delta.")
+	}
+	for _, bad := range []NoiseSpec{
+		{Var: "acceleration", Mechanism: NoiseGaussian, Sensitivity: 1, Epsilon: 1, Delta: 1e-5},
+		{Var: "acceleration", Mechanism: NoiseLaplace, Sensitivity: math.NaN(), Epsilon: 0.5},
+		{Var: "acceleration", Mechanism: NoiseLaplace, Sensitivity: 1, Epsilon: math.NaN()},
+		{Var: "acceleration", Mechanism: NoiseGaussian, Sensitivity: 1, Epsilon: 0.5, Delta: math.NaN()},
+	} {
+		if e = df.AddNoise(rand.New(rand.NewSource(0)), nil, bad); e == nil {
+			t.Fatalf("expected error for %+v.", bad)
+		}
+	}
+}
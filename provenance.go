@@ -0,0 +1,49 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+// Names of the provenance variables added by a DataSet when Provenance is set.
+const (
+	// Source file name relative to the data set path.
+	ProvenanceFile = "_file"
+	// Batch id of the source data frame.
+	ProvenanceBatchID = "_batchid"
+	// Row number within the source file.
+	ProvenanceRow = "_row"
+	// Row number across all the files in the data set.
+	ProvenanceGlobalRow = "_global_row"
+)
+
+// Adds provenance variables to a data frame read from file fn. Global row
+// numbers start at first.
+func addProvenance(df *DataFrame, fn string, first int) error {
+
+	n := df.N()
+	files := make([]interface{}, n)
+	batches := make([]interface{}, n)
+	rows := make([]interface{}, n)
+	global := make([]interface{}, n)
+	for i := 0; i < n; i++ {
+		files[i] = fn
+		batches[i] = df.BatchID
+		rows[i] = float64(i)
+		global[i] = float64(first + i)
+	}
+	for _, v := range []struct {
+		name   string
+		values []interface{}
+	}{
+		{ProvenanceFile, files},
+		{ProvenanceBatchID, batches},
+		{ProvenanceRow, rows},
+		{ProvenanceGlobalRow, global},
+	} {
+		if e := df.addVar(v.name, v.values); e != nil {
+			return e
+		}
+	}
+	return nil
+}
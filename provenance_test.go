@@ -0,0 +1,50 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"io"
+	"testing"
+)
+
+func TestProvenance(t *testing.T) {
+
+	tmpDir := getTempDir()
+	createDataFiles(t, tmpDir)
+	ds := &DataSet{Path: tmpDir + "data", Files: []string{"file1.json", "file2.json"}, Provenance: true}
+
+	for pass := 0; pass < 2; pass++ {
+		var global int
+		for k := 0; ; k++ {
+			df, e := ds.Next()
+			if e == io.EOF {
+				break
+			}
+			CheckError(t, e)
+			for i := 0; i < df.N(); i++ {
+				fn, e := df.String(i, ProvenanceFile)
+				CheckError(t, e)
+				if fn != ds.Files[k] {
+					t.Fatalf("file must be %s, not %s.", ds.Files[k], fn)
+				}
+				b, e := df.String(i, ProvenanceBatchID)
+				CheckError(t, e)
+				if b != df.BatchID {
+					t.Fatalf("batch id must be %s, not %s.", df.BatchID, b)
+				}
+				rows, e := df.Float64Slice(i, ProvenanceRow, ProvenanceGlobalRow)
+				CheckError(t, e)
+				if rows[0] != float64(i) || rows[1] != float64(global) {
+					t.Fatalf("rows must be %d/%d, not %v.", i, global, rows)
+				}
+				global++
+			}
+		}
+		if global != 12 {
+			t.Fatalf("expected 12 rows, got %d.", global)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// Redaction policies.
+type RedactPolicy string
+
+const (
+	// Replaces values with a keyed hash. Equal values have equal hashes so
+	// the variable can still be used for grouping and joins.
+	RedactHash RedactPolicy = "hash"
+	// Keeps the first Length characters of strings, or Length decimal
+	// places of float64 values.
+	RedactTruncate RedactPolicy = "truncate"
+	// Removes the variable.
+	RedactDrop RedactPolicy = "drop"
+)
+
+// Default number of hex characters of a hashed value.
+const DefaultHashLength = 16
+
+// Describes how to redact a variable.
+type Redaction struct {
+	Var    string       `yaml:"var"`
+	Policy RedactPolicy `yaml:"policy"`
+	// Secret key for RedactHash. Without a key, hashes of known identifiers
+	// can be recovered by brute force.
+	Key string `yaml:"key"`
+	// Number of characters or decimal places kept by RedactTruncate, or
+	// number of hex characters of hashes (DefaultHashLength if zero).
+	Length int `yaml:"length"`
+}
+
+// Redacts variables in place. Nil values are left unchanged. Rules are
+// applied in order. All rules are checked and the redacted values are
+// computed before the frame is changed, so on error the frame is not
+// modified.
+func (df *DataFrame) Redact(redactions ...Redaction) error {
+
+	// Redacted values by variable name.
+	cols := make(map[string][]interface{})
+	dropped := make(map[string]bool)
+	for _, r := range redactions {
+		indices, err := df.indices(r.Var)
+		if err != nil || dropped[r.Var] {
+			return fmt.Errorf("There is no variable [%s] in the data frame.", r.Var)
+		}
+		switch r.Policy {
+		case RedactDrop:
+			dropped[r.Var] = true
+			continue
+		case RedactHash, RedactTruncate:
+		default:
+			return fmt.Errorf("Unknown redaction policy [%s] for variable [%s].", r.Policy, r.Var)
+		}
+		if r.Length < 0 {
+			return fmt.Errorf("Redaction length for variable [%s] is %d, must not be negative.", r.Var, r.Length)
+		}
+		col, ok := cols[r.Var]
+		if !ok {
+			col = make([]interface{}, df.N())
+			for i, row := range df.Data {
+				col[i] = row[indices[0]]
+			}
+		}
+		for i, x := range col {
+			if x == nil {
+				continue
+			}
+			v, err := r.redact(x)
+			if err != nil {
+				return fmt.Errorf("In frame %d, variable [%s]: %s", i, r.Var, err)
+			}
+			col[i] = v
+		}
+		cols[r.Var] = col
+	}
+
+	for name, col := range cols {
+		if dropped[name] {
+			continue
+		}
+		idx := df.varMap[name]
+		for i, row := range df.Data {
+			row[idx] = col[i]
+		}
+		if df.IndexName() == name {
+			df.ResetIndex()
+		}
+		df.Invalidate(name)
+	}
+	for _, r := range redactions {
+		if r.Policy == RedactDrop {
+			if err := df.removeVar(r.Var); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Returns a transform that redacts variables, see DataFrame.Redact.
+func Redact(redactions ...Redaction) Transform {
+
+	return func(df *DataFrame) (*DataFrame, error) {
+		return df, df.Redact(redactions...)
+	}
+}
+
+func (r Redaction) redact(v interface{}) (interface{}, error) {
+
+	if r.Policy == RedactHash {
+		var s string
+		switch x := v.(type) {
+		case string:
+			s = x
+		case float64:
+			s = strconv.FormatFloat(x, 'g', -1, 64)
+		case bool:
+			s = strconv.FormatBool(x)
+		default:
+			return nil, fmt.Errorf("can't hash type [%s].", reflect.TypeOf(v).String())
+		}
+		mac := hmac.New(sha256.New, []byte(r.Key))
+		mac.Write([]byte(s))
+		h := hex.EncodeToString(mac.Sum(nil))
+		n := r.Length
+		if n <= 0 {
+			n = DefaultHashLength
+		}
+		if n < len(h) {
+			h = h[:n]
+		}
+		return h, nil
+	}
+
+	switch x := v.(type) {
+	case string:
+		runes := []rune(x)
+		if len(runes) > r.Length {
+			return string(runes[:r.Length]), nil
+		}
+		return x, nil
+	case float64:
+		return truncate(x, r.Length), nil
+	case []float64, []interface{}:
+		vec, err := toFloat64Slice(x)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]float64, len(vec))
+		for i, f := range vec {
+			out[i] = truncate(f, r.Length)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("can't truncate type [%s].", reflect.TypeOf(v).String())
+}
+
+// Truncates x toward zero to n decimal places.
+func truncate(x float64, n int) float64 {
+
+	p := math.Pow(10, float64(n))
+	return math.Trunc(x*p) / p
+}
@@ -0,0 +1,80 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	CheckError(t, df.Redact(
+		Redaction{Var: "room", Policy: RedactHash, Key: "secret"},
+		Redaction{Var: "wifi", Policy: RedactTruncate, Length: 1},
+		Redaction{Var: "acceleration", Policy: RedactDrop},
+	))
+	if df.NumVariables() != 2 || df.VarNames[1] != "wifi" {
+		t.Fatalf("unexpected variables %v.", df.VarNames)
+	}
+	r0, _ := df.String(0, "room")
+	r1, _ := df.String(1, "room")
+	r3, _ := df.String(3, "room")
+	if len(r0) != DefaultHashLength || r0 == "BED5" || r0 != r1 || r0 == r3 {
+		t.Fatalf("unexpected hashes %s %s %s.", r0, r1, r3)
+	}
+	v, e := df.Float64Slice(5, "wifi")
+	CheckError(t, e)
+	if v[0] != -42.2 || v[1] != -39.6 {
+		t.Fatalf("unexpected truncated values %v.", v)
+	}
+	if _, e = df.Float64Slice(0, "acceleration"); e == nil {
+		t.Fatalf("acceleration must be dropped.")
+	}
+
+	// A different key gives different hashes.
+	df2, _ := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, df2.Redact(Redaction{Var: "room", Policy: RedactHash, Key: "other"}))
+	if s, _ := df2.String(0, "room"); s == r0 {
+		t.Fatalf("hash must depend on the key.")
+	}
+	if e = df2.Redact(Redaction{Var: "room", Policy: "blur"}); e == nil {
+		t.Fatalf("expected error for unknown policy.")
+	}
+
+	// Failed redactions leave the frame unchanged.
+	df3, _ := ReadDataFrame(strings.NewReader(file1))
+	df3.Data[4][1] = true
+	for _, rules := range [][]Redaction{
+		{{Var: "room", Policy: RedactTruncate, Length: -1}},
+		{{Var: "room", Policy: RedactHash}, {Var: "wifi", Policy: RedactTruncate}},
+		{{Var: "acceleration", Policy: RedactDrop}, {Var: "acceleration", Policy: RedactHash}},
+	} {
+		if e = df3.Redact(rules...); e == nil {
+			t.Fatalf("expected error for %v.", rules)
+		}
+		if s, _ := df3.String(0, "room"); s != "BED5" || df3.NumVariables() != 3 {
+			t.Fatalf("frame modified by %v.", rules)
+		}
+		if v, _ := df3.Float64Slice(0, "wifi"); v[0] != -40.8 {
+			t.Fatalf("frame modified by %v.", rules)
+		}
+	}
+
+	// Rules on the same variable are applied in order.
+	CheckError(t, df3.Redact(
+		Redaction{Var: "room", Policy: RedactTruncate, Length: 2},
+		Redaction{Var: "room", Policy: RedactHash},
+	))
+	df4, _ := ReadDataFrame(strings.NewReader(file1))
+	df4.Data[0][0] = "BE"
+	CheckError(t, df4.Redact(Redaction{Var: "room", Policy: RedactHash}))
+	if a, b := df3.Data[0][0], df4.Data[0][0]; a != b {
+		t.Fatalf("expected %v, got %v.", b, a)
+	}
+}
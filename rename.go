@@ -0,0 +1,162 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+)
+
+// Default suffixes for duplicate variable names, as in R and pandas merges.
+var DefaultSuffixes = [2]string{"_x", "_y"}
+
+// Renames variables. The map goes from old to new names. Returns an error,
+// without renaming, if an old name doesn't exist or the new names are not
+// unique.
+func (df *DataFrame) Rename(names map[string]string) error {
+
+	renamed := make([]string, len(df.VarNames))
+	copy(renamed, df.VarNames)
+	for old, name := range names {
+		indices, err := df.indices(old)
+		if err != nil {
+			return err
+		}
+		renamed[indices[0]] = name
+	}
+	if err := checkUnique(renamed); err != nil {
+		return err
+	}
+
+	// Build the new state from the old one so that swaps work.
+	if df.VarUnits != nil {
+		units := make(map[string]string, len(df.VarUnits))
+		for k, u := range df.VarUnits {
+			if _, ok := names[k]; !ok {
+				units[k] = u
+			}
+		}
+		for k, u := range df.VarUnits {
+			if name, ok := names[k]; ok {
+				units[name] = u
+			}
+		}
+		df.VarUnits = units
+	}
+	if name, ok := names[df.IndexName()]; ok {
+		df.index.name = name
+	}
+	for old, name := range names {
+		df.Invalidate(old)
+		df.Invalidate(name)
+	}
+	df.VarNames = renamed
+	df.resetVarMap()
+	return nil
+}
+
+// Adds a prefix to the named variables, or to all the variables if no
+// names are given.
+func (df *DataFrame) Prefix(prefix string, names ...string) error {
+
+	if len(names) == 0 {
+		names = df.VarNames
+	}
+	m := make(map[string]string, len(names))
+	for _, name := range names {
+		m[name] = prefix + name
+	}
+	return df.Rename(m)
+}
+
+func checkUnique(names []string) error {
+
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if seen[name] {
+			return fmt.Errorf("Duplicate variable name [%s].", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// Resolves variable names when the columns of two frames are combined, as
+// in joins and column binding.
+type NameResolver struct {
+	// Renames variables of the left and right frames, from old to new
+	// names, before looking for duplicates.
+	Left, Right map[string]string
+	// Prefixes added to every variable of the left and right frames, except
+	// the keys. Empty by default.
+	LeftPrefix, RightPrefix string
+	// Suffixes appended to the names that are still duplicated. Defaults to
+	// DefaultSuffixes.
+	Suffixes [2]string
+}
+
+// Returns the output names of the left and right variables. Keys are
+// variables that appear once in the output and are never renamed.
+// Returns an error if the names are still duplicated after resolution.
+// A nil resolver uses the default suffixes.
+func (nr *NameResolver) resolve(left, right []string, keys map[string]bool) (l, r []string, err error) {
+
+	if nr == nil {
+		nr = &NameResolver{}
+	}
+	suffixes := nr.Suffixes
+	if suffixes[0] == "" && suffixes[1] == "" {
+		suffixes = DefaultSuffixes
+	}
+	rename := func(names []string, m map[string]string, prefix string) []string {
+		out := make([]string, len(names))
+		for i, name := range names {
+			if keys[name] {
+				out[i] = name
+				continue
+			}
+			if n, ok := m[name]; ok {
+				name = n
+			}
+			out[i] = prefix + name
+		}
+		return out
+	}
+	l = rename(left, nr.Left, nr.LeftPrefix)
+	r = rename(right, nr.Right, nr.RightPrefix)
+
+	inLeft := make(map[string]bool, len(l))
+	for i, name := range l {
+		if !keys[left[i]] {
+			inLeft[name] = true
+		}
+	}
+	dup := make(map[string]bool)
+	for i, name := range r {
+		if !keys[right[i]] && inLeft[name] {
+			dup[name] = true
+		}
+	}
+	for i, name := range l {
+		if dup[name] {
+			l[i] = name + suffixes[0]
+		}
+	}
+	for i, name := range r {
+		if dup[name] {
+			r[i] = name + suffixes[1]
+		}
+	}
+	all := append([]string(nil), l...)
+	for i, name := range r {
+		if !keys[right[i]] {
+			all = append(all, name)
+		}
+	}
+	if err = checkUnique(all); err != nil {
+		return nil, nil, err
+	}
+	return l, r, nil
+}
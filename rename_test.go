@@ -0,0 +1,86 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRename(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	df.VarUnits = map[string]string{"acceleration": "m/s^2"}
+	CheckError(t, df.SetIndex("acceleration"))
+
+	CheckError(t, df.Rename(map[string]string{"acceleration": "acc", "room": "location"}))
+	if strings.Join(df.VarNames, ",") != "location,wifi,acc" {
+		t.Fatalf("unexpected names %v.", df.VarNames)
+	}
+	if df.Unit("acc") != "m/s^2" || df.IndexName() != "acc" {
+		t.Fatalf("units and index must follow the rename.")
+	}
+	if v, e := df.Float64Slice(0, "acc"); e != nil || v[0] != 1.3 {
+		t.Fatalf("unexpected value %v %v.", v, e)
+	}
+	if e = df.Rename(map[string]string{"acc": "wifi"}); e == nil {
+		t.Fatalf("expected error for duplicate name.")
+	}
+	if e = df.Rename(map[string]string{"foo": "bar"}); e == nil {
+		t.Fatalf("expected error for unknown name.")
+	}
+
+	CheckError(t, df.Prefix("imu_", "acc"))
+	CheckError(t, df.Prefix("s_"))
+	if strings.Join(df.VarNames, ",") != "s_location,s_wifi,s_imu_acc" {
+		t.Fatalf("unexpected names %v.", df.VarNames)
+	}
+
+	// Swap names.
+	df, e = ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	df.VarUnits = map[string]string{"acceleration": "m/s^2", "wifi": "dBm"}
+	CheckError(t, df.SetIndex("acceleration"))
+	CheckError(t, df.Rename(map[string]string{"acceleration": "wifi", "wifi": "acceleration"}))
+	if strings.Join(df.VarNames, ",") != "room,acceleration,wifi" {
+		t.Fatalf("unexpected names %v.", df.VarNames)
+	}
+	if df.Unit("wifi") != "m/s^2" || df.Unit("acceleration") != "dBm" || df.IndexName() != "wifi" {
+		t.Fatalf("units and index must follow the swap: %v %s.", df.VarUnits, df.IndexName())
+	}
+	if v, e := df.Float64Slice(0, "wifi"); e != nil || len(v) != 1 || v[0] != 1.3 {
+		t.Fatalf("unexpected value %v %v.", v, e)
+	}
+}
+
+func TestNameResolver(t *testing.T) {
+
+	left := []string{"room", "wifi", "acceleration"}
+	right := []string{"room", "acceleration", "x", "y"}
+	keys := map[string]bool{"room": true}
+
+	var nr *NameResolver
+	l, r, e := nr.resolve(left, right, keys)
+	CheckError(t, e)
+	if strings.Join(l, ",") != "room,wifi,acceleration_x" || strings.Join(r, ",") != "room,acceleration_y,x,y" {
+		t.Fatalf("unexpected names %v %v.", l, r)
+	}
+
+	nr = &NameResolver{Right: map[string]string{"acceleration": "acc"}, RightPrefix: "coord_"}
+	l, r, e = nr.resolve(left, right, keys)
+	CheckError(t, e)
+	if strings.Join(l, ",") != "room,wifi,acceleration" || strings.Join(r, ",") != "room,coord_acc,coord_x,coord_y" {
+		t.Fatalf("unexpected names %v %v.", l, r)
+	}
+
+	nr = &NameResolver{Right: map[string]string{"x": "wifi"}, Suffixes: [2]string{"", "_r"}}
+	l, r, e = nr.resolve(left, right, keys)
+	CheckError(t, e)
+	if l[1] != "wifi" || r[2] != "wifi_r" {
+		t.Fatalf("unexpected names %v %v.", l, r)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// Returns a new data frame with n random rows, drawn with or without
+// replacement. Without replacement, n is limited to the number of rows. The
+// rows keep their original order. The seed makes the sample reproducible.
+func (df *DataFrame) Sample(n int, replace bool, seed int64) *DataFrame {
+
+	rows := sampleRows(rand.New(rand.NewSource(seed)), df.N(), n, replace)
+	return (&View{parent: df, rows: rows, names: df.VarNames}).Materialize()
+}
+
+// Returns a data frame with n random rows of the whole data set, see
+// DataFrame.Sample. Only the sampled rows are held in memory, but every
+// file is read twice: once to count the rows and once to get the sample.
+// Rows are in data set order, and data set options such as provenance are
+// applied. All files must have the same variables.
+func (ds *DataSet) Sample(n int, replace bool, seed int64) (*DataFrame, error) {
+
+	counts := make([]int, len(ds.Files))
+	var total int
+	for i, fn := range ds.Files {
+		c, err := ds.countRows(fn)
+		if err != nil {
+			return nil, fmt.Errorf("file %s: %s", fn, err)
+		}
+		counts[i] = c
+		total += c
+	}
+	rows := sampleRows(rand.New(rand.NewSource(seed)), total, n, replace)
+
+	var sample *DataFrame
+	var first, k int
+	for i, c := range counts {
+		selected := make([]int, 0)
+		for ; k < len(rows) && rows[k] < first+c; k++ {
+			selected = append(selected, rows[k]-first)
+		}
+		if len(selected) > 0 {
+			df, err := ds.loadFile(i)
+			if err != nil {
+				return nil, err
+			}
+			if df.N() != c {
+				return nil, fmt.Errorf("file %s: has %d rows, expected %d.", ds.Files[i], df.N(), c)
+			}
+			if ds.Provenance {
+				if err = addProvenance(df, ds.Files[i], first); err != nil {
+					return nil, err
+				}
+			}
+			part := (&View{parent: df, rows: selected, names: df.VarNames}).Materialize()
+			if sample == nil {
+				sample = part
+				sample.BatchID = "sample"
+			} else if strings.Join(part.VarNames, "\x00") != strings.Join(sample.VarNames, "\x00") {
+				return nil, fmt.Errorf("file %s: variables %v don't match %v.", ds.Files[i], part.VarNames, sample.VarNames)
+			} else {
+				sample.Data = append(sample.Data, part.Data...)
+			}
+		}
+		first += c
+	}
+	if sample == nil {
+		return Empty(), nil
+	}
+	return sample, nil
+}
+
+// Returns n sorted row numbers between 0 and total-1.
+func sampleRows(r *rand.Rand, total, n int, replace bool) []int {
+
+	if n <= 0 || total == 0 {
+		return []int{}
+	}
+	rows := make([]int, 0, n)
+	if replace {
+		for i := 0; i < n; i++ {
+			rows = append(rows, r.Intn(total))
+		}
+	} else {
+		if n > total {
+			n = total
+		}
+		// Floyd's algorithm uses memory proportional to n, not total.
+		selected := make(map[int]bool, n)
+		for j := total - n; j < total; j++ {
+			t := r.Intn(j + 1)
+			if selected[t] {
+				t = j
+			}
+			selected[t] = true
+			rows = append(rows, t)
+		}
+	}
+	sort.Ints(rows)
+	return rows
+}
@@ -0,0 +1,64 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestSample(t *testing.T) {
+
+	df := synthFrame(100, 2, 0)
+	s := df.Sample(10, false, 1)
+	if s.N() != 10 || !reflect.DeepEqual(s.VarNames, df.VarNames) {
+		t.Fatalf("unexpected sample %v.", s.Data)
+	}
+	if !reflect.DeepEqual(s.Data, df.Sample(10, false, 1).Data) {
+		t.Fatalf("sample is not deterministic.")
+	}
+	if df.Sample(200, false, 1).N() != 100 || df.Sample(200, true, 1).N() != 200 {
+		t.Fatalf("unexpected sample size.")
+	}
+
+	rows := sampleRows(rand.New(rand.NewSource(3)), 50, 50, false)
+	for i, r := range rows {
+		if r != i {
+			t.Fatalf("sample without replacement must not repeat rows: %v.", rows)
+		}
+	}
+}
+
+func TestDataSetSample(t *testing.T) {
+
+	tmpDir := getTempDir()
+	createDataFiles(t, tmpDir)
+	ds := &DataSet{Path: tmpDir + "data", Files: []string{"file1.json", "file2.json"}, Provenance: true}
+
+	s, e := ds.Sample(12, false, 1)
+	CheckError(t, e)
+	if s.N() != 12 {
+		t.Fatalf("expected all 12 rows, got %d.", s.N())
+	}
+	// Rows are in data set order with provenance.
+	for i := 0; i < 12; i++ {
+		if v, ok := s.Row(i).Float64(ProvenanceGlobalRow); !ok || v != float64(i) {
+			t.Fatalf("row %d: unexpected provenance %v.", i, s.Data[i])
+		}
+	}
+
+	s, e = ds.Sample(5, true, 7)
+	CheckError(t, e)
+	if s.N() != 5 {
+		t.Fatalf("expected 5 rows, got %d.", s.N())
+	}
+	s, e = (&DataSet{}).Sample(5, true, 7)
+	CheckError(t, e)
+	if s.N() != 0 {
+		t.Fatalf("expected empty sample.")
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// Names of the types of variables in a schema.
+const (
+	TypeFloat64 = "float64"
+	TypeString  = "string"
+	TypeBool    = "bool"
+	TypeVector  = "[]float64"
+	// Any type, values are not checked.
+	TypeAny = "any"
+)
+
+// A variable in a schema.
+type SchemaVar struct {
+	Name string `yaml:"name"`
+	// One of the Type* constants.
+	Type string `yaml:"type"`
+	// Values must not be nil.
+	Required bool `yaml:"required"`
+	// Numeric values, and each element of vectors, must be in [Min, Max].
+	Min *float64 `yaml:"min"`
+	Max *float64 `yaml:"max"`
+	// String values must be one of the levels.
+	Levels []string `yaml:"levels"`
+}
+
+// A Schema declares the variable names, in order, the type of each
+// variable, and optional constraints on the values. Nil values are allowed
+// for every type unless the variable is required. For example, in YAML:
+//
+//	schema:
+//	  - {name: room, type: string, required: true, levels: [BED5, DINING]}
+//	  - {name: wifi, type: "[]float64", min: -120, max: 0}
+//	  - {name: acceleration, type: float64}
+type Schema []SchemaVar
+
+// Returns the schema of a data frame. The type of a variable is the type of
+// its first non-nil value, or TypeAny if all the values are nil.
+func InferSchema(df *DataFrame) Schema {
+
+	s := make(Schema, len(df.VarNames))
+	for j, name := range df.VarNames {
+		s[j] = SchemaVar{Name: name, Type: TypeAny}
+		for _, row := range df.Data {
+			if row[j] != nil {
+				s[j].Type = valueType(row[j])
+				break
+			}
+		}
+	}
+	return s
+}
+
+// Returns an error describing the first difference between the schema and
+// the variable names and cell types of the data frame, or a
+// *ValidationError listing the values that violate the constraints.
+func (s Schema) Check(df *DataFrame) error {
+
+	if len(s) != len(df.VarNames) {
+		return fmt.Errorf("Data frame has %d variables %v, schema has %d.", len(df.VarNames), df.VarNames, len(s))
+	}
+	var violations []Violation
+	for j, v := range s {
+		if v.Name != df.VarNames[j] {
+			return fmt.Errorf("Variable %d is [%s], schema has [%s].", j, df.VarNames[j], v.Name)
+		}
+		switch v.Type {
+		case TypeFloat64, TypeString, TypeBool, TypeVector, TypeAny:
+		default:
+			return fmt.Errorf("Unknown type [%s] for variable [%s] in schema.", v.Type, v.Name)
+		}
+		var levels map[string]bool
+		if v.Levels != nil {
+			levels = make(map[string]bool, len(v.Levels))
+			for _, l := range v.Levels {
+				levels[l] = true
+			}
+		}
+		report := func(row int, rule string, value interface{}) {
+			violations = append(violations, Violation{
+				BatchID: df.BatchID, Row: row, Var: v.Name, Rule: rule, Value: value,
+			})
+		}
+		for i, row := range df.Data {
+			if row[j] == nil {
+				if v.Required {
+					report(i, RuleRequired, nil)
+				}
+				continue
+			}
+			typ := valueType(row[j])
+			if v.Type != TypeAny && typ != v.Type {
+				return fmt.Errorf("In frame %d, variable [%s] is of type [%s]. Must be of type %s.",
+					i, v.Name, typ, v.Type)
+			}
+			switch typ {
+			case TypeFloat64:
+				v.checkRange(i, row[j].(float64), report)
+			case TypeVector:
+				vec, e := toFloat64Slice(row[j])
+				if e != nil {
+					return fmt.Errorf("In frame %d, variable [%s]: %s", i, v.Name, e)
+				}
+				for _, x := range vec {
+					v.checkRange(i, x, report)
+				}
+			case TypeString:
+				if levels != nil && !levels[row[j].(string)] {
+					report(i, RuleLevels, row[j])
+				}
+			}
+		}
+	}
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// NaN is outside every range.
+func (v SchemaVar) checkRange(row int, x float64, report func(int, string, interface{})) {
+
+	if v.Min != nil && !(x >= *v.Min) {
+		report(row, RuleMin, x)
+	}
+	if v.Max != nil && !(x <= *v.Max) {
+		report(row, RuleMax, x)
+	}
+}
+
+// Sets the schema that the data frame must satisfy to be written.
+// WriteDataFrame, WriteBinary, WriteAvro, WriteArrow, WriteVW,
+// WriteGoSource, and the file and data set writers built on them return the
+// error from Schema.Check, without writing, if the frame doesn't satisfy
+// the schema. A nil schema disables the check.
+func (df *DataFrame) SetSchema(s Schema) {
+
+	df.schema = s
+}
+
+// Checks the schema set with SetSchema. Called by every writer before any
+// output is written.
+func (df *DataFrame) checkWrite() error {
+
+	if df.schema == nil {
+		return nil
+	}
+	return df.schema.Check(df)
+}
+
+// Reads every file in the data set and checks that variable names and cell
+// types match the data set schema. If the data set has no schema, all files
+// must match the schema inferred from the files read so far: a variable
+// whose values are all nil in the first files takes the type of the first
+// non-nil value in a later file. The data set is reset before and after
+// validation; files are read in order even if shuffling is on.
+func (ds *DataSet) Validate() error {
+
+	defer ds.pauseShuffle()()
+	ds.Reset()
+	defer ds.Reset()
+	schema := ds.Schema
+	for i := 0; ; i++ {
+		df, e := ds.Next()
+		if e == io.EOF {
+			return nil
+		}
+		if e != nil {
+			return e
+		}
+		if schema, e = ds.checkSchema(schema, df); e != nil {
+			return fmt.Errorf("file %s: %s", ds.Files[i], e)
+		}
+	}
+}
+
+// Checks a data frame against the data set schema or, if the data set has
+// no schema, against the schema inferred so far. Returns the schema to
+// check the next frame against.
+func (ds *DataSet) checkSchema(schema Schema, df *DataFrame) (Schema, error) {
+
+	if schema == nil {
+		return InferSchema(df), nil
+	}
+	if err := schema.Check(df); err != nil {
+		return nil, err
+	}
+	if ds.Schema != nil {
+		return schema, nil
+	}
+	for j, t := range InferSchema(df) {
+		if schema[j].Type == TypeAny {
+			schema[j].Type = t.Type
+		}
+	}
+	return schema, nil
+}
+
+// Returns the schema type name of a value. Vectors decoded from JSON are
+// TypeVector.
+func valueType(v interface{}) string {
+
+	switch x := v.(type) {
+	case float64:
+		return TypeFloat64
+	case string:
+		return TypeString
+	case bool:
+		return TypeBool
+	case []float64:
+		return TypeVector
+	case []interface{}:
+		for _, e := range x {
+			if _, ok := e.(float64); !ok {
+				return reflect.TypeOf(v).String()
+			}
+		}
+		return TypeVector
+	}
+	return reflect.TypeOf(v).String()
+}
@@ -0,0 +1,136 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	s := InferSchema(df)
+	if len(s) != 3 || s[0].Type != TypeString || s[1].Type != TypeVector || s[2].Type != TypeFloat64 {
+		t.Fatalf("unexpected schema %v.", s)
+	}
+	CheckError(t, s.Check(df))
+
+	df.Data[1][2] = "1.4"
+	if e = s.Check(df); e == nil {
+		t.Fatalf("expected type error.")
+	}
+	t.Log(e)
+	df.Data[1][2] = nil
+	CheckError(t, s.Check(df))
+
+	// Constraints. NaN is outside every range.
+	max := 2.0
+	s[2].Max = &max
+	df.Data[3][2] = math.NaN()
+	ve, ok := s.Check(df).(*ValidationError)
+	if !ok || len(ve.Violations) != 1 || ve.Violations[0].Row != 3 || ve.Violations[0].Rule != RuleMax {
+		t.Fatalf("expected max violation in row 3, got %v.", ve)
+	}
+	s[2].Required = true
+	if ve, ok = s.Check(df).(*ValidationError); !ok || len(ve.Violations) != 2 || ve.Violations[0].Rule != RuleRequired {
+		t.Fatalf("expected required violation in row 1, got %v.", ve)
+	}
+
+	dir, e := ioutil.TempDir("", "dataframe-schema")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	CheckError(t, ioutil.WriteFile(filepath.Join(dir, "file1.json"), []byte(file1), 0644))
+	CheckError(t, ioutil.WriteFile(filepath.Join(dir, "file2.json"), []byte(file2), 0644))
+	bad := strings.Replace(file2, `"acceleration"]`, `"accel"]`, 1)
+	CheckError(t, ioutil.WriteFile(filepath.Join(dir, "bad.json"), []byte(bad), 0644))
+
+	ds, e := ReadDataSet(strings.NewReader(`
+path: ` + dir + `
+files: [file1.json, file2.json]
+schema:
+  - {name: room, type: string}
+  - {name: wifi, type: "[]float64"}
+  - {name: acceleration, type: float64}
+`))
+	CheckError(t, e)
+	if len(ds.Schema) != 3 || ds.Schema[1].Type != TypeVector {
+		t.Fatalf("unexpected schema %v.", ds.Schema)
+	}
+	CheckError(t, ds.Validate())
+
+	ds.Schema[2].Type = TypeString
+	if e = ds.Validate(); e == nil {
+		t.Fatalf("expected type error.")
+	}
+	t.Log(e)
+
+	// Constraints declared in YAML.
+	ds, e = ReadDataSet(strings.NewReader(`
+path: ` + dir + `
+files: [file1.json, file2.json]
+schema:
+  - {name: room, type: string, required: true, levels: [BED5, DINING]}
+  - {name: wifi, type: "[]float64", min: -120, max: 0}
+  - {name: acceleration, type: float64}
+`))
+	CheckError(t, e)
+	if e = ds.Validate(); e == nil || !strings.Contains(e.Error(), "file2.json") || !strings.Contains(e.Error(), "KITCHEN") {
+		t.Fatalf("expected levels violation in file2.json, got %v.", e)
+	}
+	t.Log(e)
+
+	// Types of variables that are nil in the first file come from later
+	// files. Vectors with NaN elements written by WriteDataFrame are
+	// vectors.
+	a, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	b, e := ReadDataFrame(strings.NewReader(file2))
+	CheckError(t, e)
+	for _, row := range a.Data {
+		row[2] = nil
+	}
+	b.Data[0][1] = []interface{}{math.NaN(), -1.0}
+	c, e := ReadDataFrame(strings.NewReader(file2))
+	CheckError(t, e)
+	c.Data[2][2] = "1.4"
+	for name, df := range map[string]*DataFrame{"nil.json": a, "nan.json": b, "string.json": c} {
+		CheckError(t, df.WriteDataFrameFile(filepath.Join(dir, name)))
+	}
+	ds = &DataSet{Path: dir, Files: []string{"nil.json", "nan.json"}}
+	CheckError(t, ds.Validate())
+	_, e = ds.Collect()
+	CheckError(t, e)
+	ds.Files = append(ds.Files, "string.json")
+	if e = ds.Validate(); e == nil || !strings.Contains(e.Error(), "string.json") {
+		t.Fatalf("expected type error in string.json, got %v.", e)
+	}
+	if _, e = ds.Collect(); e == nil || !strings.Contains(e.Error(), "string.json") {
+		t.Fatalf("expected type error in string.json, got %v.", e)
+	}
+	t.Log(e)
+
+	// Without a schema, files must match the files before them.
+	ds = &DataSet{Path: dir, Files: []string{"file1.json", "file2.json", "bad.json"}}
+	e = ds.Validate()
+	if e == nil || !strings.Contains(e.Error(), "bad.json") {
+		t.Fatalf("expected error for bad.json, got %v.", e)
+	}
+	t.Log(e)
+
+	// Shuffling doesn't change the file and frame in the error.
+	for seed := int64(0); seed < 5; seed++ {
+		ds.SetShuffle(seed, ShuffleAll)
+		if e2 := ds.Validate(); e2 == nil || e2.Error() != e.Error() {
+			t.Fatalf("seed %d: expected %v, got %v.", seed, e, e2)
+		}
+	}
+}
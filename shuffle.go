@@ -0,0 +1,74 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"math/rand"
+)
+
+// ShuffleMode selects what is shuffled when iterating a data set.
+type ShuffleMode int
+
+const (
+	// Files and rows are read in order. This is the default.
+	ShuffleNone ShuffleMode = 0
+	// Files are read in random order.
+	ShuffleFiles ShuffleMode = 1 << iota
+	// The rows of each file are returned in random order.
+	ShuffleRows
+	// Both files and rows are shuffled.
+	ShuffleAll = ShuffleFiles | ShuffleRows
+)
+
+// Shuffles the order of the files and/or rows returned by Next and by the
+// iterators and channels built on it, for example to feed stochastic
+// gradient descent without shuffling the data on disk. Rows are shuffled
+// within each file, so only one file is in memory at a time. Every pass
+// over the data set uses a new order; the sequence of orders is determined
+// by the seed. Provenance variables are added before the rows are shuffled,
+// so ProvenanceRow is the row in the file, but ProvenanceGlobalRow follows
+// the order in which the files are read. Resets the data set.
+func (ds *DataSet) SetShuffle(seed int64, mode ShuffleMode) {
+
+	ds.shuffle = mode
+	ds.rand = rand.New(rand.NewSource(seed))
+	ds.order = nil
+	ds.Reset()
+}
+
+// Returns the index of the file to read at position i of the current pass.
+func (ds *DataSet) fileAt(i int) int {
+
+	if ds.shuffle&ShuffleFiles == 0 {
+		return i
+	}
+	if i == 0 || len(ds.order) != len(ds.Files) {
+		ds.order = ds.rand.Perm(len(ds.Files))
+	}
+	return ds.order[i]
+}
+
+// Disables shuffling until the returned function is called, for passes
+// that report file names and row numbers.
+func (ds *DataSet) pauseShuffle() (resume func()) {
+
+	mode := ds.shuffle
+	ds.shuffle = ShuffleNone
+	return func() { ds.shuffle = mode }
+}
+
+// Shuffles the rows of a data frame read by Next.
+func (ds *DataSet) shuffleRows(df *DataFrame) {
+
+	if ds.shuffle&ShuffleRows == 0 {
+		return
+	}
+	data := make([][]interface{}, len(df.Data))
+	for i, p := range ds.rand.Perm(len(df.Data)) {
+		data[i] = df.Data[p]
+	}
+	df.Data = data
+}
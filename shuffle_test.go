@@ -0,0 +1,73 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// Returns the acceleration values of every row in iteration order.
+func shuffleValues(t *testing.T, ds *DataSet) []float64 {
+
+	values := make([]float64, 0)
+	for v := range ds.Float64SliceChannel("acceleration") {
+		values = append(values, v[0])
+	}
+	return values
+}
+
+func TestSetShuffle(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-shuffle")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	spec := synthSpec(20, 1, 0)
+	spec.Files = 5
+	ds, e := GenerateDataSet(spec, dir)
+	CheckError(t, e)
+	ordered := shuffleValues(t, ds)
+
+	ds.SetShuffle(1, ShuffleFiles)
+	files := shuffleValues(t, ds)
+	if reflect.DeepEqual(files, ordered) {
+		t.Fatalf("files were not shuffled.")
+	}
+	// Rows of each file stay together.
+	if !containsRun(ordered, files[:20]) {
+		t.Fatalf("rows of a file must stay in order.")
+	}
+
+	ds.SetShuffle(1, ShuffleAll)
+	epoch1 := shuffleValues(t, ds)
+	epoch2 := shuffleValues(t, ds)
+	if reflect.DeepEqual(epoch1, epoch2) {
+		t.Fatalf("each pass must use a new order.")
+	}
+	ds.SetShuffle(1, ShuffleAll)
+	if !reflect.DeepEqual(epoch1, shuffleValues(t, ds)) {
+		t.Fatalf("shuffle is not deterministic.")
+	}
+	sort.Float64s(epoch1)
+	sort.Float64s(ordered)
+	if !reflect.DeepEqual(epoch1, ordered) {
+		t.Fatalf("shuffled rows must be a permutation of the rows.")
+	}
+}
+
+// Returns true if run appears as consecutive values in values.
+func containsRun(values, run []float64) bool {
+
+	for i := 0; i+len(run) <= len(values); i++ {
+		if reflect.DeepEqual(values[i:i+len(run)], run) {
+			return true
+		}
+	}
+	return false
+}
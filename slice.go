@@ -0,0 +1,50 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+// Returns a copy of rows start to end-1 with the variables and metadata of
+// df. The range is clamped to the rows of the frame, so Slice(10, 20) of a
+// frame with 15 rows returns rows 10 to 14. Cell values are not deep copied.
+func (df *DataFrame) Slice(start, end int) *DataFrame {
+
+	if start < 0 {
+		start = 0
+	}
+	if end > df.N() {
+		end = df.N()
+	}
+	if start > end {
+		start = end
+	}
+	v, _ := df.View().Rows(start, end)
+	return v.Materialize()
+}
+
+// Returns a copy of the first n rows, see Slice.
+func (df *DataFrame) Head(n int) *DataFrame {
+
+	return df.Slice(0, n)
+}
+
+// Returns a copy of the last n rows, see Slice.
+func (df *DataFrame) Tail(n int) *DataFrame {
+
+	return df.Slice(df.N()-n, df.N())
+}
+
+// Splits the rows into consecutive frames of size rows. The last frame has
+// the remaining rows. Returns nil if size is not positive.
+func (df *DataFrame) Chunks(size int) []*DataFrame {
+
+	if size <= 0 {
+		return nil
+	}
+	chunks := make([]*DataFrame, 0, (df.N()+size-1)/size)
+	for start := 0; start < df.N(); start += size {
+		chunks = append(chunks, df.Slice(start, start+size))
+	}
+	return chunks
+}
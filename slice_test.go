@@ -0,0 +1,51 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSlice(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	df.Properties = map[string]string{"status": "experimental"}
+
+	s := df.Slice(2, 4)
+	if s.N() != 2 || !reflect.DeepEqual(s.Data, df.Data[2:4]) {
+		t.Fatalf("unexpected rows %v.", s.Data)
+	}
+	if !reflect.DeepEqual(s.VarNames, df.VarNames) || s.Properties["status"] != "experimental" {
+		t.Fatalf("metadata not preserved: %+v.", s)
+	}
+	if v, e := s.String(0, "room"); e != nil || v != "BED5" {
+		t.Fatalf("expected BED5, got %s, %v.", v, e)
+	}
+
+	// Copies don't share rows with the original.
+	s.Data[0][0] = "KITCHEN"
+	if df.Data[2][0] != "BED5" {
+		t.Fatalf("slice must be a copy.")
+	}
+
+	if h := df.Head(2); !reflect.DeepEqual(h.Data, df.Data[:2]) {
+		t.Fatalf("unexpected head %v.", h.Data)
+	}
+	if tl := df.Tail(2); !reflect.DeepEqual(tl.Data, df.Data[4:]) {
+		t.Fatalf("unexpected tail %v.", tl.Data)
+	}
+	if df.Head(10).N() != 6 || df.Tail(10).N() != 6 || df.Slice(5, 2).N() != 0 || df.Slice(-3, 1).N() != 1 {
+		t.Fatalf("ranges must be clamped.")
+	}
+
+	chunks := df.Chunks(4)
+	if len(chunks) != 2 || chunks[0].N() != 4 || chunks[1].N() != 2 {
+		t.Fatalf("unexpected chunks %v.", chunks)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"sort"
+)
+
+// SortOrder is the direction of a sort key.
+type SortOrder int
+
+const (
+	// Ascending order. Nil values sort first.
+	Asc SortOrder = iota
+	// Descending order. Nil values sort last.
+	Desc
+)
+
+// A Sorter sorts the rows of a data frame by one or more variables:
+//
+//	sorted, err := df.Sort("room", dataframe.Asc).ThenBy("acceleration", dataframe.Desc).Result()
+type Sorter struct {
+	df    *DataFrame
+	names []string
+	desc  []bool
+}
+
+// Returns a sorter by a variable. Variables must hold float64 or string
+// values. Call ThenBy to add keys and Result to sort.
+func (df *DataFrame) Sort(name string, order SortOrder) *Sorter {
+
+	return (&Sorter{df: df}).ThenBy(name, order)
+}
+
+// Adds a key used to order rows with the same values of the previous keys.
+func (s *Sorter) ThenBy(name string, order SortOrder) *Sorter {
+
+	s.names = append(s.names, name)
+	s.desc = append(s.desc, order == Desc)
+	return s
+}
+
+// Returns a new data frame with the rows sorted by the keys. The sort is
+// stable. The original data frame is not modified.
+func (s *Sorter) Result() (*DataFrame, error) {
+
+	indices, err := s.df.indices(s.names...)
+	if err != nil {
+		return nil, err
+	}
+	rs := &rowSorter{df: s.df, rows: rowRange(0, s.df.N()), cols: indices, desc: s.desc}
+	sort.Stable(rs)
+	if rs.err != nil {
+		return nil, rs.err
+	}
+	return (&View{parent: s.df, rows: rs.rows, names: s.df.VarNames}).Materialize(), nil
+}
+
+// Returns a new data frame with the rows sorted by a function that reports
+// whether row i must sort before row j, where i and j are row numbers in df.
+// The sort is stable.
+func (df *DataFrame) SortBy(less func(i, j int) bool) *DataFrame {
+
+	rows := rowRange(0, df.N())
+	sort.Stable(&lessSorter{rows: rows, less: less})
+	return (&View{parent: df, rows: rows, names: df.VarNames}).Materialize()
+}
+
+type lessSorter struct {
+	rows []int
+	less func(i, j int) bool
+}
+
+func (s *lessSorter) Len() int           { return len(s.rows) }
+func (s *lessSorter) Swap(i, j int)      { s.rows[i], s.rows[j] = s.rows[j], s.rows[i] }
+func (s *lessSorter) Less(i, j int) bool { return s.less(s.rows[i], s.rows[j]) }
@@ -0,0 +1,57 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSort(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	df.Data[1][2] = nil
+
+	sorted, e := df.Sort("room", Desc).ThenBy("acceleration", Asc).Result()
+	CheckError(t, e)
+	var got []string
+	for i := range sorted.Data {
+		room, _ := sorted.Row(i).String("room")
+		acc, ok := sorted.Row(i).Float64("acceleration")
+		if !ok {
+			got = append(got, room+":nil")
+			continue
+		}
+		got = append(got, fmt.Sprintf("%s:%v", room, acc))
+	}
+	want := "DINING:1.6 DINING:1.7 DINING:1.8 BED5:nil BED5:1.3 BED5:1.5"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("expected %s, got %s.", want, strings.Join(got, " "))
+	}
+	if df.Data[0][0] != "BED5" {
+		t.Fatalf("original frame must not be modified.")
+	}
+
+	if _, e = df.Sort("wifi", Asc).Result(); e == nil {
+		t.Fatalf("expected error for vector variable.")
+	}
+	if _, e = df.Sort("nope", Asc).Result(); e == nil {
+		t.Fatalf("expected error for unknown variable.")
+	}
+
+	// Descending acceleration with a custom function.
+	idx := df.varMap["acceleration"]
+	by := df.SortBy(func(i, j int) bool {
+		a, _ := df.Data[i][idx].(float64)
+		b, _ := df.Data[j][idx].(float64)
+		return a > b
+	})
+	if by.Data[0][2] != 1.8 || by.Data[5][2] != nil {
+		t.Fatalf("unexpected order %v.", by.Data)
+	}
+}
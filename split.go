@@ -0,0 +1,280 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Partitions the rows by the values of a string variable, for example one
+// data frame per room. The returned frames keep the metadata of df and the
+// original row order. Returns an error if a value is nil or not a string;
+// use GroupBy to partition by nil, float64, or bool values.
+func (df *DataFrame) SplitBy(name string) (map[string]*DataFrame, error) {
+
+	indices, err := df.indices(name)
+	if err != nil {
+		return nil, err
+	}
+	idx := indices[0]
+	groups := make(map[string][]int)
+	for i, row := range df.Data {
+		switch v := row[idx].(type) {
+		case nil:
+			return nil, fmt.Errorf("In frame %d, variable [%s] is nil. Use GroupBy to keep nil values in their own group.", i, name)
+		case string:
+			groups[v] = append(groups[v], i)
+		default:
+			return nil, fmt.Errorf("In frame %d, variable [%s] is of type [%s]. Must be of type string, use GroupBy for float64 and bool levels.",
+				i, name, reflect.TypeOf(v).String())
+		}
+	}
+	parts := make(map[string]*DataFrame, len(groups))
+	for level, rows := range groups {
+		v := &View{parent: df, rows: rows, names: df.VarNames}
+		parts[level] = v.Materialize()
+	}
+	return parts, nil
+}
+
+// Splits the data frame into sessions wherever the time variable jumps
+// forward by more than maxGap or goes back in time. Session i gets batch id
+// df.BatchID + "-i". Returns a single session if there are no gaps.
+func (df *DataFrame) SplitOnGaps(timeVar string, maxGap time.Duration) ([]*DataFrame, error) {
+
+	times, err := df.timeColumn(timeVar)
+	if err != nil {
+		return nil, err
+	}
+	gap := maxGap.Seconds()
+	sessions := make([][]int, 0)
+	for i, t := range times {
+		if i == 0 || t-times[i-1] > gap || t < times[i-1] {
+			sessions = append(sessions, []int{})
+		}
+		sessions[len(sessions)-1] = append(sessions[len(sessions)-1], i)
+	}
+	parts := make([]*DataFrame, len(sessions))
+	for i, rows := range sessions {
+		v := &View{parent: df, rows: rows, names: df.VarNames}
+		parts[i] = v.Materialize()
+		parts[i].BatchID = fmt.Sprintf("%s-%d", df.BatchID, i)
+	}
+	return parts, nil
+}
+
+// Splits the data set into train and test data sets by assigning whole
+// files, rather than rows, to each set so that rows from a session never
+// appear in both. The files are shuffled with the seed and round(n *
+// testFraction) of them go to the test set. The returned data sets keep the
+// configuration of ds and the original file order.
+func (ds *DataSet) SplitSessions(testFraction float64, seed int64) (train, test *DataSet, err error) {
+
+	if testFraction < 0 || testFraction > 1 {
+		return nil, nil, fmt.Errorf("Test fraction must be between 0 and 1, got %g.", testFraction)
+	}
+	n := len(ds.Files)
+	nTest := int(math.Floor(float64(n)*testFraction + 0.5))
+	isTest := make([]bool, n)
+	for _, i := range rand.New(rand.NewSource(seed)).Perm(n)[:nTest] {
+		isTest[i] = true
+	}
+	train, test = ds.withFiles(nil), ds.withFiles(nil)
+	for i, fn := range ds.Files {
+		if isTest[i] {
+			test.Files = append(test.Files, fn)
+		} else {
+			train.Files = append(train.Files, fn)
+		}
+	}
+	return train, test, nil
+}
+
+// Returns a copy of the data set with a different list of files.
+func (ds *DataSet) withFiles(files []string) *DataSet {
+
+	c := *ds
+	c.Files = files
+	c.Reset()
+	return &c
+}
+
+// Options for Split.
+type SplitOptions struct {
+	// Seed of the random assignment. The same seed gives the same split.
+	Seed int64
+	// If true, items are not shuffled: the first part gets the first items.
+	Ordered bool
+	// Optional string variable. If set, the items of each level are split
+	// separately so every part has about the same proportion of each level.
+	// Nil values form their own level.
+	Stratify string
+}
+
+// Splits the rows into parts with the given fractions, for example train,
+// test, and validation frames with Split(opts, 0.8, 0.1, 0.1). Fractions
+// must sum to one. Part sizes are rounded so every row is in exactly one
+// part. The parts keep the metadata of df and the original row order.
+func (df *DataFrame) Split(opts SplitOptions, fractions ...float64) ([]*DataFrame, error) {
+
+	groups := [][]int{rowRange(0, df.N())}
+	if opts.Stratify != "" {
+		labels, err := df.stratifyLabels(opts.Stratify)
+		if err != nil {
+			return nil, err
+		}
+		groups = groupLabels(labels)
+	}
+	rows, err := splitGroups(groups, opts, fractions)
+	if err != nil {
+		return nil, err
+	}
+	parts := make([]*DataFrame, len(rows))
+	for k, r := range rows {
+		parts[k] = (&View{parent: df, rows: r, names: df.VarNames}).Materialize()
+	}
+	return parts, nil
+}
+
+// Like DataFrame.Split but assigns whole files, as SplitSessions does. When
+// stratifying, the level of a file is its most frequent value of the
+// variable, which requires reading every file. The returned data sets keep
+// the configuration of ds and the original file order.
+func (ds *DataSet) Split(opts SplitOptions, fractions ...float64) ([]*DataSet, error) {
+
+	groups := [][]int{rowRange(0, len(ds.Files))}
+	if opts.Stratify != "" {
+		labels := make([]interface{}, len(ds.Files))
+		for i := range ds.Files {
+			df, err := ds.loadFile(i)
+			if err != nil {
+				return nil, err
+			}
+			levels, err := df.stratifyLabels(opts.Stratify)
+			if err != nil {
+				return nil, fmt.Errorf("file %s: %s", ds.Files[i], err)
+			}
+			labels[i] = majority(levels)
+		}
+		groups = groupLabels(labels)
+	}
+	files, err := splitGroups(groups, opts, fractions)
+	if err != nil {
+		return nil, err
+	}
+	parts := make([]*DataSet, len(files))
+	for k, f := range files {
+		names := make([]string, len(f))
+		for j, i := range f {
+			names[j] = ds.Files[i]
+		}
+		parts[k] = ds.withFiles(names)
+	}
+	return parts, nil
+}
+
+// Returns the values of a string variable.
+func (df *DataFrame) stratifyLabels(name string) ([]interface{}, error) {
+
+	indices, err := df.indices(name)
+	if err != nil {
+		return nil, err
+	}
+	labels := make([]interface{}, df.N())
+	for i, row := range df.Data {
+		switch v := row[indices[0]].(type) {
+		case nil, string:
+			labels[i] = v
+		default:
+			return nil, colTypeError(i, name, v, "string")
+		}
+	}
+	return labels, nil
+}
+
+// Returns the most frequent label. On ties, returns the label that reaches
+// the count first.
+func majority(labels []interface{}) interface{} {
+
+	counts := make(map[interface{}]int)
+	var best interface{}
+	var max int
+	for _, l := range labels {
+		counts[l]++
+		if counts[l] > max {
+			best, max = l, counts[l]
+		}
+	}
+	return best
+}
+
+// Returns the item indices for each label in order of first appearance.
+func groupLabels(labels []interface{}) [][]int {
+
+	index := make(map[interface{}]int)
+	groups := make([][]int, 0)
+	for i, l := range labels {
+		k, ok := index[l]
+		if !ok {
+			k = len(groups)
+			index[l] = k
+			groups = append(groups, nil)
+		}
+		groups[k] = append(groups[k], i)
+	}
+	return groups
+}
+
+// Splits each group of items by the fractions and returns the sorted items
+// of each part.
+func splitGroups(groups [][]int, opts SplitOptions, fractions []float64) ([][]int, error) {
+
+	if len(fractions) == 0 {
+		return nil, fmt.Errorf("Split requires at least one fraction.")
+	}
+	var sum float64
+	for _, f := range fractions {
+		if f < 0 {
+			return nil, fmt.Errorf("Fractions must not be negative, got %g.", f)
+		}
+		sum += f
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		return nil, fmt.Errorf("Fractions must sum to 1, got %g.", sum)
+	}
+	r := rand.New(rand.NewSource(opts.Seed))
+	parts := make([][]int, len(fractions))
+	for _, items := range groups {
+		if !opts.Ordered {
+			shuffled := make([]int, len(items))
+			for i, p := range r.Perm(len(items)) {
+				shuffled[i] = items[p]
+			}
+			items = shuffled
+		}
+		n := float64(len(items))
+		var start int
+		var cum float64
+		for k, f := range fractions {
+			cum += f
+			end := int(math.Floor(n*cum + 0.5))
+			if k == len(fractions)-1 || end > len(items) {
+				end = len(items)
+			}
+			parts[k] = append(parts[k], items[start:end]...)
+			start = end
+		}
+	}
+	for _, p := range parts {
+		sort.Ints(p)
+	}
+	return parts, nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitBy(t *testing.T) {
+
+	tmpDir := getTempDir()
+	f1, _ := createDataFiles(t, tmpDir)
+
+	df, dfe := ReadDataFrameFile(f1)
+	CheckError(t, dfe)
+
+	parts, e := df.SplitBy("room")
+	CheckError(t, e)
+
+	if len(parts) != 2 {
+		t.Fatalf("expected 2 parts, got %d.", len(parts))
+	}
+	if parts["BED5"].N() != 3 || parts["DINING"].N() != 3 {
+		t.Fatalf("unexpected part sizes %d and %d.", parts["BED5"].N(), parts["DINING"].N())
+	}
+	sl, e := parts["DINING"].Float64Slice(1, "acceleration")
+	CheckError(t, e)
+	if sl[0] != 1.7 {
+		t.Fatalf("expected 1.7, got %f.", sl[0])
+	}
+	if parts["BED5"].BatchID != df.BatchID {
+		t.Fatalf("metadata not preserved.")
+	}
+
+	if _, e = df.SplitBy("acceleration"); e == nil || !strings.Contains(e.Error(), "GroupBy") {
+		t.Fatalf("expected error for float variable, got %v.", e)
+	}
+	t.Log(e)
+	df.Data[5][0] = nil
+	if _, e = df.SplitBy("room"); e == nil || !strings.Contains(e.Error(), "nil") {
+		t.Fatalf("expected error for nil value, got %v.", e)
+	}
+	t.Log(e)
+}
+
+func TestSplitOnGaps(t *testing.T) {
+
+	df := Empty("t", "x")
+	df.BatchID = "24001-015"
+	for i, ts := range []float64{0, 1, 2, 10, 11, 5, 6} {
+		df.Data = append(df.Data, []interface{}{ts, float64(i)})
+	}
+	parts, e := df.SplitOnGaps("t", 2*time.Second)
+	CheckError(t, e)
+	if len(parts) != 3 {
+		t.Fatalf("expected 3 sessions, got %d.", len(parts))
+	}
+	for i, n := range []int{3, 2, 2} {
+		if parts[i].N() != n {
+			t.Fatalf("session %d: expected %d rows, got %d.", i, n, parts[i].N())
+		}
+	}
+	if parts[2].BatchID != "24001-015-2" {
+		t.Fatalf("unexpected batch id %s.", parts[2].BatchID)
+	}
+	if v, _ := parts[1].Float64Slice(0, "x"); v[0] != 3 {
+		t.Fatalf("expected row 3, got %v.", v)
+	}
+
+	parts, e = df.SplitOnGaps("t", time.Minute)
+	CheckError(t, e)
+	if len(parts) != 2 {
+		t.Fatalf("expected 2 sessions, got %d.", len(parts))
+	}
+	df.Data[3][0] = "bad"
+	if _, e = df.SplitOnGaps("t", time.Minute); e == nil {
+		t.Fatalf("expected error for invalid time.")
+	}
+}
+
+func TestSplitSessions(t *testing.T) {
+
+	ds := &DataSet{Path: "data", Files: []string{"s0.json", "s1.json", "s2.json", "s3.json", "s4.json"}}
+	ds.Provenance = true
+	train, test, e := ds.SplitSessions(0.4, 3)
+	CheckError(t, e)
+	if len(train.Files) != 3 || len(test.Files) != 2 {
+		t.Fatalf("unexpected split %v %v.", train.Files, test.Files)
+	}
+	seen := map[string]bool{}
+	for _, fn := range append(train.Files, test.Files...) {
+		if seen[fn] {
+			t.Fatalf("file %s is in both sets.", fn)
+		}
+		seen[fn] = true
+	}
+	if !train.Provenance || test.Path != "data" {
+		t.Fatalf("configuration not preserved.")
+	}
+	train2, _, e := ds.SplitSessions(0.4, 3)
+	CheckError(t, e)
+	for i := range train.Files {
+		if train.Files[i] != train2.Files[i] {
+			t.Fatalf("split is not deterministic.")
+		}
+	}
+	if _, _, e = ds.SplitSessions(1.5, 3); e == nil {
+		t.Fatalf("expected error for invalid fraction.")
+	}
+}
+
+func TestSplit(t *testing.T) {
+
+	df := synthFrame(1000, 2, 0)
+	parts, e := df.Split(SplitOptions{Seed: 1}, 0.8, 0.1, 0.1)
+	CheckError(t, e)
+	if len(parts) != 3 || parts[0].N() != 800 || parts[1].N() != 100 || parts[2].N() != 100 {
+		t.Fatalf("unexpected part sizes.")
+	}
+	again, e := df.Split(SplitOptions{Seed: 1}, 0.8, 0.1, 0.1)
+	CheckError(t, e)
+	if !reflect.DeepEqual(parts[1].Data, again[1].Data) {
+		t.Fatalf("split is not deterministic.")
+	}
+
+	ordered, e := df.Split(SplitOptions{Ordered: true}, 0.5, 0.5)
+	CheckError(t, e)
+	if !reflect.DeepEqual(ordered[1].Data, df.Data[500:]) {
+		t.Fatalf("ordered split must keep consecutive rows.")
+	}
+
+	// Each part has the class proportions of the frame.
+	parts, e = df.Split(SplitOptions{Seed: 2, Stratify: "room"}, 0.7, 0.3)
+	CheckError(t, e)
+	all, e := df.SplitBy("room")
+	CheckError(t, e)
+	test, e := parts[1].SplitBy("room")
+	CheckError(t, e)
+	for room, f := range all {
+		want := int(math.Floor(float64(f.N())*0.3 + 0.5))
+		if test[room].N() != want {
+			t.Fatalf("room %s: expected %d test rows, got %d.", room, want, test[room].N())
+		}
+	}
+	if parts[0].N()+parts[1].N() != df.N() {
+		t.Fatalf("rows lost in split.")
+	}
+
+	if _, e = df.Split(SplitOptions{}, 0.5, 0.4); e == nil {
+		t.Fatalf("expected error for fractions that don't sum to 1.")
+	}
+	if _, e = df.Split(SplitOptions{Stratify: "acceleration"}, 1); e == nil {
+		t.Fatalf("expected error for non-string variable.")
+	}
+}
+
+func TestDataSetSplit(t *testing.T) {
+
+	tmpDir := getTempDir()
+	createDataFiles(t, tmpDir)
+	ds := &DataSet{Path: tmpDir + "data", Files: []string{"file1.json", "file2.json", "file1.json", "file2.json"}}
+
+	parts, e := ds.Split(SplitOptions{Seed: 1, Stratify: "room"}, 0.5, 0.5)
+	CheckError(t, e)
+	// file1 is mostly BED5 and file2 mostly KITCHEN, ties go to the first.
+	for _, p := range parts {
+		if len(p.Files) != 2 || p.Files[0] != "file1.json" || p.Files[1] != "file2.json" {
+			t.Fatalf("expected one file of each level, got %v.", p.Files)
+		}
+	}
+	parts, e = ds.Split(SplitOptions{Ordered: true}, 0.75, 0.25)
+	CheckError(t, e)
+	if len(parts[0].Files) != 3 || parts[1].Files[0] != "file2.json" || parts[1].Path != ds.Path {
+		t.Fatalf("unexpected split %v %v.", parts[0].Files, parts[1].Files)
+	}
+}
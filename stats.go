@@ -0,0 +1,125 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Summary statistics for a float64 variable.
+type ColStats struct {
+	// Number of non-nil values.
+	N int
+	// Mean of the values.
+	Mean float64
+	// Sample standard deviation of the values.
+	StdDev float64
+	// Minimum value.
+	Min float64
+	// Maximum value.
+	Max float64
+}
+
+// Caches column statistics. Entries are updated when rows are appended and
+// invalidated when the frame is otherwise mutated.
+type statsCache struct {
+	sync.Mutex
+	stats map[string]*Accumulator
+	// means used to replace missing values, see NAMean.
+	means map[string][]float64
+}
+
+// Returns summary statistics for a float64 variable. Nil values are ignored.
+// Results are cached in the data frame until the frame is mutated using the
+// DataFrame methods. Callers that modify Data directly must call Invalidate.
+func (df *DataFrame) ColStats(name string) (st ColStats, err error) {
+
+	df.cache.Lock()
+	defer df.cache.Unlock()
+	if acc, ok := df.cache.stats[name]; ok {
+		return colStats(acc), nil
+	}
+
+	var indices []int
+	indices, err = df.indices(name)
+	if err != nil {
+		return
+	}
+	idx := indices[0]
+	acc := NewAccumulator(1)
+	x := make([]float64, 1)
+	for i, row := range df.Data {
+		switch v := row[idx].(type) {
+		case nil:
+			continue
+		case float64:
+			x[0] = v
+		default:
+			err = fmt.Errorf("In frame %d, variable [%s] is of type [%s]. Must be of type float64.",
+				i, name, reflect.TypeOf(v).String())
+			return
+		}
+		acc.Add(x)
+	}
+	if acc.Count() == 0 {
+		return st, fmt.Errorf("Variable [%s] has no values.", name)
+	}
+	if df.cache.stats == nil {
+		df.cache.stats = make(map[string]*Accumulator)
+	}
+	df.cache.stats[name] = acc
+	return colStats(acc), nil
+}
+
+func colStats(acc *Accumulator) ColStats {
+
+	return ColStats{
+		N:      acc.Count(),
+		Mean:   acc.Mean()[0],
+		StdDev: acc.StdDev()[0],
+		Min:    acc.Min()[0],
+		Max:    acc.Max()[0],
+	}
+}
+
+// Updates cached statistics with a row appended to the frame. Statistics of
+// variables with a value that is not float64 or nil are discarded.
+func (df *DataFrame) updateStats(row []interface{}) {
+
+	df.cache.Lock()
+	defer df.cache.Unlock()
+	df.cache.means = nil
+	x := make([]float64, 1)
+	for name, acc := range df.cache.stats {
+		switch v := row[df.varMap[name]].(type) {
+		case nil:
+		case float64:
+			x[0] = v
+			acc.Add(x)
+		default:
+			delete(df.cache.stats, name)
+		}
+	}
+}
+
+// Discards cached statistics for the named variables. If no names are
+// provided, all cached statistics are discarded.
+func (df *DataFrame) Invalidate(names ...string) {
+
+	df.cache.Lock()
+	defer df.cache.Unlock()
+	if len(names) == 0 {
+		df.cache.stats = nil
+		df.cache.means = nil
+		return
+	}
+	for _, name := range names {
+		delete(df.cache.stats, name)
+		delete(df.cache.means, name)
+	}
+}
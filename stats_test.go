@@ -0,0 +1,55 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"math"
+	"testing"
+)
+
+func TestColStats(t *testing.T) {
+
+	tmpDir := getTempDir()
+	f1, _ := createDataFiles(t, tmpDir)
+
+	df, dfe := ReadDataFrameFile(f1)
+	CheckError(t, dfe)
+
+	st, e := df.ColStats("acceleration")
+	CheckError(t, e)
+	t.Logf("stats: %+v", st)
+
+	if st.N != 6 {
+		t.Fatalf("N must be 6, not %d.", st.N)
+	}
+	if math.Abs(st.Mean-1.55) > 1e-9 {
+		t.Fatalf("Mean must be 1.55, not %f.", st.Mean)
+	}
+	if math.Abs(st.StdDev-math.Sqrt(0.035)) > 1e-9 {
+		t.Fatalf("StdDev must be %f, not %f.", math.Sqrt(0.035), st.StdDev)
+	}
+	if st.Min != 1.3 || st.Max != 1.8 {
+		t.Fatalf("Min/Max must be 1.3/1.8, not %f/%f.", st.Min, st.Max)
+	}
+
+	// Cached value survives direct mutation until invalidated.
+	df.Data[0][2] = 100.0
+	st, e = df.ColStats("acceleration")
+	CheckError(t, e)
+	if st.Max != 1.8 {
+		t.Fatalf("expected cached Max 1.8, got %f.", st.Max)
+	}
+	df.Invalidate("acceleration")
+	st, e = df.ColStats("acceleration")
+	CheckError(t, e)
+	if st.Max != 100 {
+		t.Fatalf("expected Max 100 after invalidation, got %f.", st.Max)
+	}
+
+	if _, e = df.ColStats("room"); e == nil {
+		t.Fatalf("expected error for string variable.")
+	}
+}
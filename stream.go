@@ -0,0 +1,144 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Reads the rows of a JSON data frame one at a time, so frames larger than
+// memory can be processed. Only the current row is held in memory.
+//
+// Rows are returned as decoded by encoding/json: numbers are float64 and
+// vectors are []interface{}. Unlike ReadDataFrame, string values are not
+// coerced to numbers because that requires seeing the whole column.
+type RowIterator struct {
+	dec    *json.Decoder
+	header *DataFrame
+	closer io.Closer
+	inData bool
+	done   bool
+	n      int
+	err    error
+}
+
+// Returns an iterator over the rows of a JSON data frame. Fields written
+// before the data array are decoded before returning.
+func NewRowIterator(r io.Reader) (*RowIterator, error) {
+
+	it := &RowIterator{dec: json.NewDecoder(stripBOM(r)), header: &DataFrame{}}
+	if err := expectDelim(it.dec, '{'); err != nil {
+		return nil, err
+	}
+	if err := it.advance(); err != nil {
+		return nil, err
+	}
+	it.header.resetVarMap()
+	return it, nil
+}
+
+// Returns an iterator over the rows of a JSON data frame file. Files ending
+// in GzipExt are decompressed. The caller must call Close.
+func NewRowIteratorFile(fn string) (*RowIterator, error) {
+
+	f, err := openFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	it, err := NewRowIterator(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("file %s: %s", fn, err)
+	}
+	it.closer = f
+	return it, nil
+}
+
+// Returns the next row, or io.EOF after the last row.
+func (it *RowIterator) Next() ([]interface{}, error) {
+
+	if it.err != nil {
+		return nil, it.err
+	}
+	for !it.done {
+		if it.inData {
+			if it.dec.More() {
+				var row []interface{}
+				if err := it.dec.Decode(&row); err != nil {
+					it.err = fmt.Errorf("In frame %d, %s", it.n, err)
+					return nil, it.err
+				}
+				if n := len(it.header.VarNames); n > 0 && len(row) != n {
+					it.err = fmt.Errorf("In frame %d, row has %d values, expected %d.", it.n, len(row), n)
+					return nil, it.err
+				}
+				restoreRow(row)
+				it.n++
+				return row, nil
+			}
+			if it.err = expectDelim(it.dec, ']'); it.err != nil {
+				return nil, it.err
+			}
+			it.inData = false
+		}
+		if it.err = it.advance(); it.err != nil {
+			return nil, it.err
+		}
+	}
+	it.header.resetVarMap()
+	it.err = io.EOF
+	return nil, it.err
+}
+
+// Returns the number of rows read so far.
+func (it *RowIterator) N() int { return it.n }
+
+// Returns a data frame with the fields decoded so far and no data. Fields
+// written after the data array, such as properties and units in files
+// written by this package, are available once Next returns io.EOF.
+func (it *RowIterator) Header() *DataFrame { return it.header }
+
+// Closes the file opened by NewRowIteratorFile.
+func (it *RowIterator) Close() error {
+
+	if it.closer == nil {
+		return nil
+	}
+	return it.closer.Close()
+}
+
+// Decodes fields up to the start of the data array or the end of the
+// object.
+func (it *RowIterator) advance() error {
+
+	for it.dec.More() {
+		t, err := it.dec.Token()
+		if err != nil {
+			return err
+		}
+		if key, _ := t.(string); key != "data" {
+			if err = decodeField(it.dec, it.header, key); err != nil {
+				return err
+			}
+			continue
+		}
+		if t, err = it.dec.Token(); err != nil {
+			return err
+		}
+		if t == nil {
+			continue
+		}
+		if d, ok := t.(json.Delim); !ok || d != '[' {
+			return fmt.Errorf("data must be an array, found %v.", t)
+		}
+		it.inData = true
+		return nil
+	}
+	it.done = true
+	return expectDelim(it.dec, '}')
+}
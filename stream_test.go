@@ -0,0 +1,93 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRowIterator(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	it, e := NewRowIterator(strings.NewReader(file1))
+	CheckError(t, e)
+	if !reflect.DeepEqual(it.Header().VarNames, df.VarNames) {
+		t.Fatalf("expected var names %v, got %v.", df.VarNames, it.Header().VarNames)
+	}
+	for i := 0; ; i++ {
+		row, e := it.Next()
+		if e == io.EOF {
+			break
+		}
+		CheckError(t, e)
+		if !reflect.DeepEqual(row, df.Data[i]) {
+			t.Fatalf("row %d: expected %v, got %v.", i, df.Data[i], row)
+		}
+	}
+	if it.N() != df.N() {
+		t.Fatalf("expected %d rows, got %d.", df.N(), it.N())
+	}
+	if _, e = it.Next(); e != io.EOF {
+		t.Fatalf("expected io.EOF after last row, got %v.", e)
+	}
+
+	// Fields after the data are available at the end.
+	it, e = NewRowIterator(strings.NewReader(`{"data": [[1, "a"], [2, "b"]], "var_names": ["x", "y"], "batchid": "b1"}`))
+	CheckError(t, e)
+	if it.Header().VarNames != nil {
+		t.Fatalf("var names must not be known before the data.")
+	}
+	for {
+		if _, e = it.Next(); e == io.EOF {
+			break
+		}
+		CheckError(t, e)
+	}
+	if h := it.Header(); h.BatchID != "b1" || len(h.VarNames) != 2 || h.N() != 0 {
+		t.Fatalf("unexpected header %+v.", h)
+	}
+
+	it, e = NewRowIterator(strings.NewReader(`{"var_names": ["x"], "data": [[1], [2}`))
+	CheckError(t, e)
+	_, e = it.Next()
+	CheckError(t, e)
+	if _, e = it.Next(); e == nil || e == io.EOF {
+		t.Fatalf("expected syntax error, got %v.", e)
+	}
+	if _, e = NewRowIterator(strings.NewReader(`{"data": 3}`)); e == nil {
+		t.Fatalf("expected error for non-array data.")
+	}
+}
+
+func TestRowIteratorFile(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-stream")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	df := synthFrame(100, 3, 0)
+	fn := filepath.Join(dir, "frame.json.gz")
+	CheckError(t, df.WriteDataFrameFile(fn))
+
+	it, e := NewRowIteratorFile(fn)
+	CheckError(t, e)
+	defer it.Close()
+	for {
+		if _, e = it.Next(); e == io.EOF {
+			break
+		}
+		CheckError(t, e)
+	}
+	if it.N() != 100 || it.Header().BatchID != df.BatchID {
+		t.Fatalf("unexpected %d rows, header %+v.", it.N(), it.Header())
+	}
+}
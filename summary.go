@@ -0,0 +1,223 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+)
+
+// Number of most frequent values reported for string variables.
+const SummaryTopValues = 5
+
+// Maximum number of distinct string values counted per variable. Variables
+// with more values are reported as high cardinality, without levels, so
+// summaries of ids and free text use bounded memory.
+const SummaryMaxLevels = 10000
+
+// Number of occurrences of a string value.
+type ValueCount struct {
+	Value string
+	Count int
+}
+
+// Summary of a variable. Numeric fields are set for float64 variables and
+// are NaN when there are no values. Cardinality and Top are set for string
+// variables with at most SummaryMaxLevels distinct values.
+type VarSummary struct {
+	// Variable name.
+	Name string
+	// Type of the values, for example "float64", "string", or "[]float64".
+	// Variables with values of more than one type are "mixed".
+	Type string
+	// Number of non-nil values.
+	Count int
+	// Number of nil values.
+	NA     int
+	Mean   float64
+	StdDev float64
+	Min    float64
+	Max    float64
+	// Number of distinct string values.
+	Cardinality int
+	// Most frequent string values in decreasing order of count.
+	Top []ValueCount
+	// The variable has more than SummaryMaxLevels distinct string values.
+	// Cardinality and Top are not set.
+	HighCardinality bool
+}
+
+// Per variable summaries in variable order.
+type Summary []VarSummary
+
+// Returns a summary of each variable in the data frame.
+func (df *DataFrame) Summary() Summary {
+
+	s := newSummarizer()
+	s.add(df)
+	return s.summary()
+}
+
+// Returns a summary of each variable across all the data frames in the data
+// set. The data set is reset before and after the summary.
+func (ds *DataSet) Summary() (Summary, error) {
+
+	ds.Reset()
+	defer ds.Reset()
+	s := newSummarizer()
+	for {
+		df, e := ds.Next()
+		if e == io.EOF {
+			break
+		}
+		if e != nil {
+			return nil, e
+		}
+		s.add(df)
+	}
+	return s.summary(), nil
+}
+
+// Returns the summary as a table.
+func (s Summary) String() string {
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "var\ttype\tcount\tNA\tmean\tstddev\tmin\tmax\tlevels\ttop")
+	for _, v := range s {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t", v.Name, v.Type, v.Count, v.NA)
+		if v.Type == "float64" {
+			fmt.Fprintf(w, "%.4g\t%.4g\t%.4g\t%.4g\t", v.Mean, v.StdDev, v.Min, v.Max)
+		} else {
+			fmt.Fprint(w, "\t\t\t\t")
+		}
+		if v.HighCardinality {
+			fmt.Fprintf(w, ">%d\t", SummaryMaxLevels)
+		} else if v.Type == "string" {
+			fmt.Fprintf(w, "%d\t", v.Cardinality)
+			for i, vc := range v.Top {
+				if i > 0 {
+					fmt.Fprint(w, " ")
+				}
+				fmt.Fprintf(w, "%s:%d", vc.Value, vc.Count)
+			}
+		} else {
+			fmt.Fprint(w, "\t")
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+type varSummarizer struct {
+	typ    string
+	count  int
+	na     int
+	acc    *Accumulator
+	levels map[string]int
+}
+
+// Accumulates summaries over data frames.
+type summarizer struct {
+	names []string
+	vars  map[string]*varSummarizer
+}
+
+func newSummarizer() *summarizer {
+
+	return &summarizer{vars: make(map[string]*varSummarizer)}
+}
+
+func (s *summarizer) add(df *DataFrame) {
+
+	x := make([]float64, 1)
+	for j, name := range df.VarNames {
+		vs, ok := s.vars[name]
+		if !ok {
+			vs = &varSummarizer{acc: NewAccumulator(1), levels: make(map[string]int)}
+			s.vars[name] = vs
+			s.names = append(s.names, name)
+		}
+		for _, row := range df.Data {
+			v := row[j]
+			if v == nil {
+				vs.na++
+				continue
+			}
+			vs.count++
+			typ := valueType(v)
+			if vs.typ == "" {
+				vs.typ = typ
+			} else if vs.typ != typ {
+				vs.typ = "mixed"
+			}
+			switch v := v.(type) {
+			case float64:
+				x[0] = v
+				vs.acc.Add(x)
+			case string:
+				if vs.levels == nil {
+					break
+				}
+				vs.levels[v]++
+				if len(vs.levels) > SummaryMaxLevels {
+					vs.levels = nil
+				}
+			}
+		}
+	}
+}
+
+func (s *summarizer) summary() Summary {
+
+	sum := make(Summary, len(s.names))
+	for i, name := range s.names {
+		vs := s.vars[name]
+		v := VarSummary{Name: name, Type: vs.typ, Count: vs.count, NA: vs.na}
+		if v.Type == "" {
+			v.Type = "nil"
+		}
+		v.Mean, v.StdDev, v.Min, v.Max = math.NaN(), math.NaN(), math.NaN(), math.NaN()
+		if vs.acc.Count() > 0 {
+			v.Mean, v.Min, v.Max = vs.acc.Mean()[0], vs.acc.Min()[0], vs.acc.Max()[0]
+			v.StdDev = vs.acc.StdDev()[0]
+		}
+		if vs.levels == nil {
+			v.HighCardinality = true
+		} else if len(vs.levels) > 0 {
+			v.Cardinality = len(vs.levels)
+			top := make([]ValueCount, 0, len(vs.levels))
+			for value, n := range vs.levels {
+				top = append(top, ValueCount{Value: value, Count: n})
+			}
+			sort.Sort(byCount(top))
+			if len(top) > SummaryTopValues {
+				top = top[:SummaryTopValues]
+			}
+			v.Top = top
+		}
+		sum[i] = v
+	}
+	return sum
+}
+
+// Sorts value counts in decreasing order of count, then by value.
+type byCount []ValueCount
+
+func (b byCount) Len() int      { return len(b) }
+func (b byCount) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b byCount) Less(i, j int) bool {
+
+	if b[i].Count != b[j].Count {
+		return b[i].Count > b[j].Count
+	}
+	return b[i].Value < b[j].Value
+}
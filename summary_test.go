@@ -0,0 +1,62 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSummary(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	df.Data[0][2] = nil
+
+	s := df.Summary()
+	t.Log("\n" + s.String())
+	if len(s) != 3 {
+		t.Fatalf("expected 3 variables, got %d.", len(s))
+	}
+	room, wifi, acc := s[0], s[1], s[2]
+	if room.Type != "string" || room.Cardinality != 2 || len(room.Top) != 2 || room.Top[0].Value != "BED5" {
+		t.Fatalf("unexpected room summary %+v.", room)
+	}
+	if wifi.Type != "[]float64" || wifi.Count != 6 || !math.IsNaN(wifi.Mean) {
+		t.Fatalf("unexpected wifi summary %+v.", wifi)
+	}
+	if acc.Type != "float64" || acc.Count != 5 || acc.NA != 1 || acc.Min != 1.4 || acc.Max != 1.8 ||
+		math.Abs(acc.Mean-1.6) > 1e-9 {
+		t.Fatalf("unexpected acceleration summary %+v.", acc)
+	}
+
+	tmpDir := getTempDir()
+	createDataFiles(t, tmpDir)
+	ds := &DataSet{Path: tmpDir + "data", Files: []string{"file1.json", "file2.json"}}
+	s, e = ds.Summary()
+	CheckError(t, e)
+	if s[0].Cardinality != 3 || s[0].Top[0].Value != "DINING" || s[0].Top[0].Count != 6 {
+		t.Fatalf("unexpected room summary %+v.", s[0])
+	}
+	if s[2].Count != 12 {
+		t.Fatalf("expected 12 values, got %d.", s[2].Count)
+	}
+
+	// High cardinality.
+	df = Empty("id")
+	for i := 0; i <= SummaryMaxLevels; i++ {
+		df.Data = append(df.Data, []interface{}{fmt.Sprintf("id-%d", i)})
+	}
+	s = df.Summary()
+	if v := s[0]; !v.HighCardinality || v.Cardinality != 0 || v.Top != nil || v.Count != SummaryMaxLevels+1 {
+		t.Fatalf("unexpected id summary %+v.", v)
+	}
+	if !strings.Contains(s.String(), fmt.Sprintf(">%d", SummaryMaxLevels)) {
+		t.Fatalf("high cardinality not reported:\n%s", s)
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Returns the value of a time variable in seconds. Time variables hold
+// float64 seconds, for example Unix time, or RFC 3339 strings.
+func timeSeconds(v interface{}) (float64, error) {
+
+	switch x := v.(type) {
+	case float64:
+		return x, nil
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, x)
+		if err != nil {
+			return 0, err
+		}
+		return float64(t.UnixNano()) / 1e9, nil
+	case nil:
+		return 0, fmt.Errorf("time value is nil.")
+	}
+	return 0, fmt.Errorf("time value has unsupported type [%s].", reflect.TypeOf(v).String())
+}
+
+// Returns the values of a time variable in seconds.
+func (df *DataFrame) timeColumn(name string) ([]float64, error) {
+
+	indices, err := df.indices(name)
+	if err != nil {
+		return nil, err
+	}
+	idx := indices[0]
+	times := make([]float64, df.N())
+	for i, row := range df.Data {
+		if times[i], err = timeSeconds(row[idx]); err != nil {
+			return nil, fmt.Errorf("In frame %d, variable [%s]: %s", i, name, err)
+		}
+	}
+	return times, nil
+}
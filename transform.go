@@ -0,0 +1,243 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"launchpad.net/goyaml"
+)
+
+// A Transform modifies a data frame in place or returns a new one. Returning
+// nil drops the data frame from the output.
+type Transform func(df *DataFrame) (*DataFrame, error)
+
+// Reads every file in the data set, applies the transforms in order, and
+// writes the results in JSON format to dir using the same file names. Files
+// in other formats are written with extension ".json"; compressed files stay
+// compressed. Output frames record their inputs, see LineageInput. Returns
+// a data set for the output files. The position of Next in the input data set is not changed.
+func TransformDataSet(ds *DataSet, dir string, transforms ...Transform) (*DataSet, error) {
+
+	return TransformDataSetContext(context.Background(), ds, dir, transforms...)
+}
+
+// Like TransformDataSet but stops when ctx is cancelled and returns
+// ctx.Err(). On error, the output files written by the call are removed.
+func TransformDataSetContext(ctx context.Context, ds *DataSet, dir string, transforms ...Transform) (*DataSet, error) {
+
+	outputs, err := transformFiles(ctx, ds, dir, "", transforms)
+	if err != nil {
+		return nil, err
+	}
+	out := &DataSet{Path: dir, Files: make([]string, 0, len(outputs))}
+	for _, name := range outputs {
+		if name != "" {
+			out.Files = append(out.Files, name)
+		}
+	}
+	return out, nil
+}
+
+// Transforms and writes every file in the data set. Returns the output file
+// name of each input file, or an empty string if the frame was dropped. On
+// error, the files already written are removed. The id identifies the
+// transforms, see LineageTransforms.
+func transformFiles(ctx context.Context, ds *DataSet, dir, id string, transforms []Transform) ([]string, error) {
+
+	outputs := make([]string, len(ds.Files))
+	err := transformEach(ctx, ds, ExecOptions{}, id, transforms, func(i int, df *DataFrame) error {
+		name := jsonName(ds.Files[i])
+		fn := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+			return err
+		}
+		if err := df.writeJSONFile(fn, 0644); err != nil {
+			return err
+		}
+		outputs[i] = name
+		return nil
+	})
+	if err != nil {
+		for _, name := range outputs {
+			if name != "" {
+				os.Remove(filepath.Join(dir, name))
+			}
+		}
+		return nil, err
+	}
+	return outputs, nil
+}
+
+// Reads every file in the data set within the limits, applies the
+// transforms in order, and calls fn with the index of the file and the
+// result unless it was dropped. Files are transformed in order. Stops
+// between transforms when ctx is cancelled. Results have the lineage
+// properties, with id for LineageTransforms.
+func transformEach(ctx context.Context, ds *DataSet, opts ExecOptions, id string, transforms []Transform, fn func(i int, df *DataFrame) error) error {
+
+	return ds.readParallel(ctx, opts, true, func(i int, df *DataFrame) error {
+		var err error
+		for k, t := range transforms {
+			if err = ctx.Err(); err != nil {
+				return err
+			}
+			start := time.Now()
+			df, err = t(df)
+			ds.hooks.transform(ds.Files[i], k, df, err, time.Since(start))
+			if err != nil {
+				return fmt.Errorf("file %s: %s", ds.Files[i], err)
+			}
+			if df == nil {
+				return nil
+			}
+		}
+		if err = ds.addLineage(df, i, id); err != nil {
+			return err
+		}
+		return fn(i, df)
+	})
+}
+
+// Name of the file in the output directory of TransformDataSetIncremental
+// that records the inputs of each output file.
+const TransformStateFile = "transform.lock"
+
+// Records the input of an output file.
+type transformInput struct {
+	FileHash `yaml:",inline"`
+	// Output file name, empty if the frame was dropped.
+	Output string `yaml:"output"`
+}
+
+type transformState struct {
+	Version string           `yaml:"version"`
+	Inputs  []transformInput `yaml:"inputs"`
+}
+
+// Like TransformDataSet but only reads and writes the files that changed
+// since the previous export to dir. Input content hashes are recorded in
+// TransformStateFile; inputs with the same hash are skipped and their
+// previous outputs are kept. Outputs of inputs that are no longer in the
+// data set are removed. The version identifies the transforms: when it
+// changes, every file is exported again, and it is recorded in the output
+// frames, see LineageTransforms.
+func TransformDataSetIncremental(ds *DataSet, dir, version string, transforms ...Transform) (*DataSet, error) {
+
+	return TransformDataSetIncrementalContext(context.Background(), ds, dir, version, transforms...)
+}
+
+// Like TransformDataSetIncremental but stops when ctx is cancelled and
+// returns ctx.Err(). The state file is not updated, so the files that were
+// not exported are exported by the next call.
+func TransformDataSetIncrementalContext(ctx context.Context, ds *DataSet, dir, version string, transforms ...Transform) (*DataSet, error) {
+
+	hashes, err := ds.FileHashes()
+	if err != nil {
+		return nil, err
+	}
+	stateFile := filepath.Join(dir, TransformStateFile)
+	var state transformState
+	b, err := ioutil.ReadFile(stateFile)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return nil, err
+	default:
+		if err = goyaml.Unmarshal(b, &state); err != nil {
+			return nil, fmt.Errorf("file %s: %s", stateFile, err)
+		}
+	}
+	prev := make(map[string]transformInput)
+	if state.Version == version {
+		for _, in := range state.Inputs {
+			prev[in.File] = in
+		}
+	}
+
+	inputs := make([]transformInput, len(hashes))
+	changed := make([]string, 0)
+	index := make([]int, 0)
+	for i, h := range hashes {
+		inputs[i] = transformInput{FileHash: h}
+		p, ok := prev[h.File]
+		if ok && p.SHA256 == h.SHA256 && (p.Output == "" || fileExists(filepath.Join(dir, p.Output))) {
+			inputs[i].Output = p.Output
+			continue
+		}
+		changed = append(changed, h.File)
+		index = append(index, i)
+	}
+	glog.V(1).Infof("transform: %d of %d files changed", len(changed), len(hashes))
+	if len(changed) > 0 {
+		outputs, err := transformFiles(ctx, ds.withFiles(changed), dir, version, transforms)
+		if err != nil {
+			return nil, err
+		}
+		for k, i := range index {
+			inputs[i].Output = outputs[k]
+		}
+	}
+
+	// Remove outputs of inputs that are no longer in the data set.
+	current := make(map[string]bool)
+	for _, in := range inputs {
+		current[in.Output] = true
+	}
+	for _, in := range state.Inputs {
+		if in.Output != "" && !current[in.Output] {
+			if err = os.Remove(filepath.Join(dir, in.Output)); err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+		}
+	}
+
+	if b, err = goyaml.Marshal(transformState{Version: version, Inputs: inputs}); err != nil {
+		return nil, err
+	}
+	if err = writeFileAtomic(stateFile, 0644, func(w io.Writer) error {
+		_, err := w.Write(b)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	out := &DataSet{Path: dir, Files: make([]string, 0, len(inputs))}
+	for _, in := range inputs {
+		if in.Output != "" {
+			out.Files = append(out.Files, in.Output)
+		}
+	}
+	return out, nil
+}
+
+func fileExists(fn string) bool {
+
+	_, err := os.Stat(fn)
+	return err == nil
+}
+
+// Returns the name of the JSON file for a data set file.
+func jsonName(name string) string {
+
+	gz := strings.HasSuffix(name, GzipExt)
+	name = strings.TrimSuffix(name, GzipExt)
+	switch filepath.Ext(name) {
+	case ".csv", ".lp", BinaryExt, AvroExt:
+		name = strings.TrimSuffix(name, filepath.Ext(name)) + ".json"
+	}
+	if gz {
+		name += GzipExt
+	}
+	return name
+}
@@ -0,0 +1,155 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestTransformDataSet(t *testing.T) {
+
+	tmpDir := getTempDir()
+	createDataFiles(t, tmpDir)
+	ds := &DataSet{Path: tmpDir + "data", Files: []string{"file1.json", "file2.json"}}
+	out, e := ioutil.TempDir("", "dataframe-export")
+	CheckError(t, e)
+	defer os.RemoveAll(out)
+
+	skip := func(df *DataFrame) (*DataFrame, error) {
+		if df.BatchID == "24001-016" {
+			return nil, nil
+		}
+		return df, nil
+	}
+	exported, e := TransformDataSet(ds, out, Redact(Redaction{Var: "room", Policy: RedactDrop}), skip)
+	CheckError(t, e)
+	if exported.Path != out || len(exported.Files) != 1 || exported.Files[0] != "file1.json" {
+		t.Fatalf("unexpected data set %+v.", exported)
+	}
+	if _, e = os.Stat(filepath.Join(out, "file2.json")); !os.IsNotExist(e) {
+		t.Fatalf("file2.json must be skipped.")
+	}
+
+	df, e := exported.Next()
+	CheckError(t, e)
+	if df.NumVariables() != 2 || df.N() != 6 || df.BatchID != "24001-015" {
+		t.Fatalf("unexpected frame %+v.", df)
+	}
+	h, e := hashFile(filepath.Join(ds.Path, "file1.json"))
+	CheckError(t, e)
+	if df.Properties[LineageVersion] != Version || df.Properties[LineageInput] != "file1.json" ||
+		df.Properties[LineageInputSHA256] != h.SHA256 {
+		t.Fatalf("unexpected lineage %v.", df.Properties)
+	}
+	if _, ok := df.Properties[LineageTransforms]; ok {
+		t.Fatalf("unexpected transforms id.")
+	}
+	if _, e = exported.Next(); e != io.EOF {
+		t.Fatalf("expected EOF.")
+	}
+}
+
+func TestTransformDataSetCancel(t *testing.T) {
+
+	ds, cleanup := synthDataSet(t, 8, 100, 2)
+	defer cleanup()
+	out, e := ioutil.TempDir("", "dataframe-export")
+	CheckError(t, e)
+	defer os.RemoveAll(out)
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var files int
+	stop := func(df *DataFrame) (*DataFrame, error) {
+		if files++; files == 3 {
+			cancel()
+		}
+		return df, nil
+	}
+	if _, e = TransformDataSetContext(ctx, ds, out, stop, Decimate(2, DecimateKeep)); e != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v.", e)
+	}
+	if files != 3 {
+		t.Fatalf("expected 3 files, got %d.", files)
+	}
+	// Outputs and temp files are removed.
+	if names, _ := ioutil.ReadDir(out); len(names) != 0 {
+		t.Fatalf("expected empty output dir, got %d files.", len(names))
+	}
+	checkGoroutines(t, before)
+}
+
+func TestTransformDataSetIncremental(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-incremental")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	in := filepath.Join(dir, "in")
+	out := filepath.Join(dir, "out")
+	CheckError(t, os.MkdirAll(in, 0755))
+	for i, name := range []string{"a.json", "b.json", "c.json"} {
+		CheckError(t, synthFrame(5, 2, int64(i)).WriteDataFrameFile(filepath.Join(in, name)))
+	}
+
+	var calls int
+	count := func(df *DataFrame) (*DataFrame, error) {
+		calls++
+		return df, nil
+	}
+	ds := &DataSet{Path: in, Files: []string{"a.json", "b.json", "c.json"}}
+	exported, e := TransformDataSetIncremental(ds, out, "v1", count)
+	CheckError(t, e)
+	if calls != 3 || len(exported.Files) != 3 {
+		t.Fatalf("expected 3 transformed files, got %d %v.", calls, exported.Files)
+	}
+	df, e := exported.Next()
+	CheckError(t, e)
+	if df.Properties[LineageTransforms] != "v1" || df.Properties[LineageInput] != "a.json" {
+		t.Fatalf("unexpected lineage %v.", df.Properties)
+	}
+
+	// Nothing changed.
+	calls = 0
+	_, e = TransformDataSetIncremental(ds, out, "v1", count)
+	CheckError(t, e)
+	if calls != 0 {
+		t.Fatalf("expected no transformed files, got %d.", calls)
+	}
+
+	// Modify b, remove c, add d.
+	CheckError(t, synthFrame(7, 2, 10).WriteDataFrameFile(filepath.Join(in, "b.json")))
+	CheckError(t, synthFrame(3, 2, 11).WriteDataFrameFile(filepath.Join(in, "d.json")))
+	ds.Files = []string{"a.json", "b.json", "d.json"}
+	exported, e = TransformDataSetIncremental(ds, out, "v1", count)
+	CheckError(t, e)
+	if calls != 2 || strings.Join(exported.Files, ",") != "a.json,b.json,d.json" {
+		t.Fatalf("expected 2 transformed files, got %d %v.", calls, exported.Files)
+	}
+	if _, e = os.Stat(filepath.Join(out, "c.json")); !os.IsNotExist(e) {
+		t.Fatalf("output of removed input must be deleted.")
+	}
+	df, e = ReadDataFrameFile(filepath.Join(out, "b.json"))
+	CheckError(t, e)
+	if df.N() != 7 {
+		t.Fatalf("expected updated output with 7 rows, got %d.", df.N())
+	}
+
+	// A new version exports everything.
+	calls = 0
+	_, e = TransformDataSetIncremental(ds, out, "v2", count)
+	CheckError(t, e)
+	if calls != 3 {
+		t.Fatalf("expected 3 transformed files, got %d.", calls)
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// Returns the p-th percentile (0 <= p <= 100) of a float64 variable using
+// linear interpolation between closest ranks. Nil values are ignored.
+func (df *DataFrame) Percentile(name string, p float64) (float64, error) {
+
+	if err := checkPercentile(p); err != nil {
+		return 0, err
+	}
+	values, err := df.sortedValues(name)
+	if err != nil {
+		return 0, err
+	}
+	return percentile(values, p)
+}
+
+// Returns a new data frame without the rows where the float64 variable is
+// below the lo-th percentile or above the hi-th percentile. Rows where the
+// variable is nil are kept.
+func (df *DataFrame) Trim(name string, lo, hi float64) (*DataFrame, error) {
+
+	min, max, err := df.percentileRange(name, lo, hi)
+	if err != nil {
+		return nil, err
+	}
+	idx := df.varMap[name]
+	v := df.View().Filter(func(frame int) bool {
+		x, ok := df.Data[frame][idx].(float64)
+		return !ok || (x >= min && x <= max)
+	})
+	return v.Materialize(), nil
+}
+
+// Clamps the values of a float64 variable to the range between the lo-th and
+// hi-th percentiles in place.
+func (df *DataFrame) Winsorize(name string, lo, hi float64) error {
+
+	min, max, err := df.percentileRange(name, lo, hi)
+	if err != nil {
+		return err
+	}
+	return df.Broadcast(name, func(x float64) float64 {
+		return math.Max(min, math.Min(max, x))
+	})
+}
+
+func (df *DataFrame) percentileRange(name string, lo, hi float64) (min, max float64, err error) {
+
+	if err = checkPercentile(lo); err != nil {
+		return
+	}
+	if err = checkPercentile(hi); err != nil {
+		return
+	}
+	if lo > hi {
+		err = fmt.Errorf("Lower percentile %g is greater than upper percentile %g.", lo, hi)
+		return
+	}
+	values, err := df.sortedValues(name)
+	if err != nil {
+		return
+	}
+	if min, err = percentile(values, lo); err != nil {
+		return
+	}
+	max, err = percentile(values, hi)
+	return
+}
+
+// Returns the non-nil values of a float64 variable in ascending order.
+func (df *DataFrame) sortedValues(name string) ([]float64, error) {
+
+	indices, err := df.indices(name)
+	if err != nil {
+		return nil, err
+	}
+	idx := indices[0]
+	values := make([]float64, 0, df.N())
+	for i, row := range df.Data {
+		switch v := row[idx].(type) {
+		case nil:
+		case float64:
+			values = append(values, v)
+		default:
+			return nil, fmt.Errorf("In frame %d, variable [%s] is of type [%s]. Must be of type float64.",
+				i, name, reflect.TypeOf(v).String())
+		}
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("Variable [%s] has no values.", name)
+	}
+	sort.Float64s(values)
+	return values, nil
+}
+
+// Returns an error if p is NaN or not between 0 and 100.
+func checkPercentile(p float64) error {
+
+	if !(p >= 0 && p <= 100) {
+		return fmt.Errorf("Percentile must be between 0 and 100, got %g.", p)
+	}
+	return nil
+}
+
+// Computes a percentile of sorted values.
+func percentile(sorted []float64, p float64) (float64, error) {
+
+	if err := checkPercentile(p); err != nil {
+		return 0, err
+	}
+	pos := p / 100 * float64(len(sorted)-1)
+	i := int(pos)
+	if i >= len(sorted)-1 {
+		return sorted[len(sorted)-1], nil
+	}
+	frac := pos - float64(i)
+	return sorted[i] + frac*(sorted[i+1]-sorted[i]), nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"math"
+	"testing"
+)
+
+func trimFrame() *DataFrame {
+
+	df := Empty("rssi")
+	for i := 1; i <= 11; i++ {
+		df.Data = append(df.Data, []interface{}{float64(i * 10)})
+	}
+	df.Data = append(df.Data, []interface{}{nil})
+	return df
+}
+
+func TestPercentile(t *testing.T) {
+
+	df := trimFrame()
+	for _, c := range []struct{ p, v float64 }{{0, 10}, {50, 60}, {100, 110}, {25, 35}, {95, 105}} {
+		v, e := df.Percentile("rssi", c.p)
+		CheckError(t, e)
+		if math.Abs(v-c.v) > 1e-9 {
+			t.Fatalf("percentile %g must be %g, not %g.", c.p, c.v, v)
+		}
+	}
+	for _, p := range []float64{101, -1, math.NaN(), math.Inf(1)} {
+		if _, e := df.Percentile("rssi", p); e == nil {
+			t.Fatalf("expected error for percentile %g.", p)
+		}
+	}
+	if _, e := df.Trim("rssi", math.NaN(), 90); e == nil {
+		t.Fatalf("expected error for invalid percentile.")
+	}
+}
+
+func TestTrimWinsorize(t *testing.T) {
+
+	df := trimFrame()
+	trimmed, e := df.Trim("rssi", 10, 90)
+	CheckError(t, e)
+	// Keeps 20..100 and the nil row.
+	if trimmed.N() != 10 {
+		t.Fatalf("expected 10 rows, got %d.", trimmed.N())
+	}
+	if df.N() != 12 {
+		t.Fatalf("Trim must not modify the frame.")
+	}
+
+	CheckError(t, df.Winsorize("rssi", 10, 90))
+	first, _ := df.Float64Slice(0, "rssi")
+	last, _ := df.Float64Slice(10, "rssi")
+	if first[0] != 20 || last[0] != 100 {
+		t.Fatalf("unexpected winsorized values %v %v.", first, last)
+	}
+	if df.Data[11][0] != nil {
+		t.Fatalf("nil values must be kept.")
+	}
+	if _, e = df.Trim("rssi", 90, 10); e == nil {
+		t.Fatalf("expected error for inverted range.")
+	}
+}
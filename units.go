@@ -0,0 +1,131 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"math"
+)
+
+// A unit is defined by its physical quantity and conversions to and from
+// the base unit of that quantity.
+type unit struct {
+	quantity string
+	toBase   func(float64) float64
+	fromBase func(float64) float64
+}
+
+func linearUnit(quantity string, factor float64) unit {
+
+	return unit{
+		quantity: quantity,
+		toBase:   func(x float64) float64 { return x * factor },
+		fromBase: func(x float64) float64 { return x / factor },
+	}
+}
+
+const standardGravity = 9.80665
+
+// Built-in units. Base units are m/s^2, s, and mW.
+var units = map[string]unit{
+	"m/s^2": linearUnit("acceleration", 1),
+	"m/s²":  linearUnit("acceleration", 1),
+	"g":     linearUnit("acceleration", standardGravity),
+	"mg":    linearUnit("acceleration", standardGravity/1000),
+
+	"s":  linearUnit("time", 1),
+	"ms": linearUnit("time", 1e-3),
+	"us": linearUnit("time", 1e-6),
+	"ns": linearUnit("time", 1e-9),
+
+	"mW": linearUnit("power", 1),
+	"W":  linearUnit("power", 1000),
+	"dBm": {
+		quantity: "power",
+		toBase:   func(x float64) float64 { return math.Pow(10, x/10) },
+		fromBase: func(x float64) float64 { return 10 * math.Log10(x) },
+	},
+	"dBW": {
+		quantity: "power",
+		toBase:   func(x float64) float64 { return math.Pow(10, (x+30)/10) },
+		fromBase: func(x float64) float64 { return 10*math.Log10(x) - 30 },
+	},
+}
+
+// Unit aliases.
+var unitAliases = map[string]string{
+	"seconds": "s",
+	"millis":  "ms",
+	"micros":  "us",
+	"nanos":   "ns",
+	"milli-g": "mg",
+}
+
+func lookupUnit(name string) (unit, string, error) {
+
+	if a, ok := unitAliases[name]; ok {
+		name = a
+	}
+	u, ok := units[name]
+	if !ok {
+		return unit{}, "", fmt.Errorf("Unknown unit [%s].", name)
+	}
+	return u, name, nil
+}
+
+// Returns the unit of a variable or an empty string if the unit is unknown.
+func (df *DataFrame) Unit(name string) string {
+
+	return df.VarUnits[name]
+}
+
+// Sets the unit of a variable. The unit must be in the built-in unit table.
+func (df *DataFrame) SetUnit(name, unitName string) error {
+
+	if _, err := df.indices(name); err != nil {
+		return err
+	}
+	_, canonical, err := lookupUnit(unitName)
+	if err != nil {
+		return err
+	}
+	if df.VarUnits == nil {
+		df.VarUnits = make(map[string]string)
+	}
+	df.VarUnits[name] = canonical
+	return nil
+}
+
+// Converts the values of a float64 or vector variable to the target unit in
+// place and updates the variable unit. Supported units are m/s^2, g, mg
+// (milli-g), s, ms, us, ns, mW, W, dBm, and dBW.
+func (df *DataFrame) ConvertUnit(name, target string) error {
+
+	from := df.Unit(name)
+	if from == "" {
+		return fmt.Errorf("Variable [%s] has no unit.", name)
+	}
+	src, _, err := lookupUnit(from)
+	if err != nil {
+		return err
+	}
+	dst, canonical, err := lookupUnit(target)
+	if err != nil {
+		return err
+	}
+	if src.quantity != dst.quantity {
+		return fmt.Errorf("Cannot convert %s (%s) to %s (%s).", from, src.quantity, target, dst.quantity)
+	}
+	if from == canonical {
+		return nil
+	}
+	err = df.Broadcast(name, func(x float64) float64 { return dst.fromBase(src.toBase(x)) })
+	if err != nil {
+		return err
+	}
+	df.VarUnits[name] = canonical
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestConvertUnit(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(`{
+"var_names": ["acc", "rssi", "ts"],
+"var_units": {"acc": "milli-g", "rssi": "dBm"},
+"data": [[1000, -30, 1500], [500, -60, 2500]]
+}`))
+	CheckError(t, e)
+
+	if df.Unit("acc") != "milli-g" {
+		t.Fatalf("unit must be milli-g, not %s.", df.Unit("acc"))
+	}
+	CheckError(t, df.ConvertUnit("acc", "m/s^2"))
+	if df.Unit("acc") != "m/s^2" {
+		t.Fatalf("unit must be m/s^2, not %s.", df.Unit("acc"))
+	}
+	v, _ := df.Float64Slice(0, "acc")
+	if math.Abs(v[0]-standardGravity) > 1e-9 {
+		t.Fatalf("expected %f, got %f.", standardGravity, v[0])
+	}
+
+	CheckError(t, df.ConvertUnit("rssi", "mW"))
+	v, _ = df.Float64Slice(0, "rssi")
+	if math.Abs(v[0]-0.001) > 1e-12 {
+		t.Fatalf("expected 0.001 mW, got %g.", v[0])
+	}
+	CheckError(t, df.ConvertUnit("rssi", "dBm"))
+	v, _ = df.Float64Slice(1, "rssi")
+	if math.Abs(v[0]+60) > 1e-9 {
+		t.Fatalf("expected -60 dBm, got %g.", v[0])
+	}
+
+	// No unit.
+	if e = df.ConvertUnit("ts", "s"); e == nil {
+		t.Fatalf("expected error for variable without unit.")
+	}
+	CheckError(t, df.SetUnit("ts", "millis"))
+	CheckError(t, df.ConvertUnit("ts", "seconds"))
+	v, _ = df.Float64Slice(1, "ts")
+	if v[0] != 2.5 || df.Unit("ts") != "s" {
+		t.Fatalf("expected 2.5 s, got %g %s.", v[0], df.Unit("ts"))
+	}
+
+	// Incompatible units.
+	if e = df.ConvertUnit("acc", "s"); e == nil {
+		t.Fatalf("expected error converting acceleration to time.")
+	}
+	if e = df.SetUnit("acc", "furlong"); e == nil {
+		t.Fatalf("expected error for unknown unit.")
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Interpolation methods for resampling.
+type Interpolation string
+
+const (
+	// Piecewise linear interpolation.
+	InterpLinear Interpolation = "linear"
+	// Natural cubic spline interpolation.
+	InterpSpline Interpolation = "spline"
+)
+
+// Returns a new data frame sampled at a fixed period of the time variable.
+// Sample times are the multiples of period between the first and last time
+// so frames resampled with the same period share the same times and can be
+// fused. Float64 and vector variables are interpolated, ignoring nil
+// values; other variables take the value of the last row at or before the
+// sample time. Times must be strictly increasing.
+func (df *DataFrame) Upsample(timeVar string, period time.Duration, method Interpolation) (*DataFrame, error) {
+
+	if period <= 0 {
+		return nil, fmt.Errorf("Period must be positive, got %s.", period)
+	}
+	if method != InterpLinear && method != InterpSpline {
+		return nil, fmt.Errorf("Unknown interpolation method [%s].", method)
+	}
+	times, err := df.timeColumn(timeVar)
+	if err != nil {
+		return nil, err
+	}
+	for i := 1; i < len(times); i++ {
+		if times[i] <= times[i-1] {
+			return nil, fmt.Errorf("In frame %d, variable [%s] is not strictly increasing.", i, timeVar)
+		}
+	}
+
+	out := df.View().Filter(func(int) bool { return false }).Materialize()
+	if len(times) == 0 {
+		return out, nil
+	}
+	p := period.Seconds()
+	k0 := math.Ceil(times[0]/p - 1e-9)
+	k1 := math.Floor(times[len(times)-1]/p + 1e-9)
+	grid := make([]float64, 0)
+	for k := k0; k <= k1; k++ {
+		grid = append(grid, k*p)
+	}
+	out.Data = make([][]interface{}, len(grid))
+	for i := range out.Data {
+		out.Data[i] = make([]interface{}, len(df.VarNames))
+	}
+
+	tidx := df.varMap[timeVar]
+	_, floatTime := df.Data[0][tidx].(float64)
+	for i, t := range grid {
+		if floatTime {
+			out.Data[i][tidx] = t
+		} else {
+			sec, frac := math.Modf(t)
+			out.Data[i][tidx] = time.Unix(int64(sec), int64(math.Floor(frac*1e9+0.5))).UTC().Format(time.RFC3339Nano)
+		}
+	}
+	for j, name := range df.VarNames {
+		if j == tidx {
+			continue
+		}
+		if err = df.interpolateVar(out, j, times, grid, method); err != nil {
+			return nil, fmt.Errorf("variable [%s]: %s", name, err)
+		}
+	}
+	return out, nil
+}
+
+// Returns a transform that upsamples every data frame, see
+// DataFrame.Upsample.
+func Upsample(timeVar string, period time.Duration, method Interpolation) Transform {
+
+	return func(df *DataFrame) (*DataFrame, error) {
+		return df.Upsample(timeVar, period, method)
+	}
+}
+
+// Fills column j of out at the grid times.
+func (df *DataFrame) interpolateVar(out *DataFrame, j int, times, grid []float64, method Interpolation) error {
+
+	// Collect non-nil points.
+	xs := make([]float64, 0, len(times))
+	var ys [][]float64
+	var numeric, scalar bool
+	for i, row := range df.Data {
+		switch x := row[j].(type) {
+		case nil:
+			continue
+		case float64:
+			numeric, scalar = true, true
+			ys = append(ys, []float64{x})
+		case []float64, []interface{}:
+			vec, err := toFloat64Slice(x)
+			if err != nil {
+				return err
+			}
+			if len(ys) > 0 && len(vec) != len(ys[0]) {
+				return fmt.Errorf("vector dimensions %d and %d don't match.", len(ys[0]), len(vec))
+			}
+			numeric = true
+			ys = append(ys, vec)
+		default:
+			// Step interpolation.
+			for k, t := range grid {
+				n := sort.SearchFloat64s(times, t+1e-9) - 1
+				if n >= 0 {
+					out.Data[k][j] = df.Data[n][j]
+				}
+			}
+			return nil
+		}
+		xs = append(xs, times[i])
+	}
+	if !numeric {
+		return nil
+	}
+
+	dim := len(ys[0])
+	fns := make([]func(float64) float64, dim)
+	for d := 0; d < dim; d++ {
+		col := make([]float64, len(ys))
+		for i := range ys {
+			col[i] = ys[i][d]
+		}
+		fns[d] = interpolator(xs, col, method)
+	}
+	for k, t := range grid {
+		if t < xs[0]-1e-9 || t > xs[len(xs)-1]+1e-9 {
+			continue
+		}
+		if scalar {
+			out.Data[k][j] = fns[0](t)
+			continue
+		}
+		vec := make([]float64, dim)
+		for d := range vec {
+			vec[d] = fns[d](t)
+		}
+		out.Data[k][j] = vec
+	}
+	return nil
+}
+
+// Returns a function that interpolates the points (xs, ys). xs must be
+// strictly increasing.
+func interpolator(xs, ys []float64, method Interpolation) func(float64) float64 {
+
+	n := len(xs)
+	if n == 1 {
+		return func(float64) float64 { return ys[0] }
+	}
+	// Second derivatives of the natural cubic spline, zero for linear.
+	m := make([]float64, n)
+	if method == InterpSpline && n > 2 {
+		// Solve the tridiagonal system with the Thomas algorithm.
+		c := make([]float64, n)
+		d := make([]float64, n)
+		for i := 1; i < n-1; i++ {
+			h0, h1 := xs[i]-xs[i-1], xs[i+1]-xs[i]
+			a, b := h0, 2*(h0+h1)
+			r := 6 * ((ys[i+1]-ys[i])/h1 - (ys[i]-ys[i-1])/h0)
+			den := b - a*c[i-1]
+			c[i] = h1 / den
+			d[i] = (r - a*d[i-1]) / den
+		}
+		for i := n - 2; i > 0; i-- {
+			m[i] = d[i] - c[i]*m[i+1]
+		}
+	}
+	return func(x float64) float64 {
+		i := sort.SearchFloat64s(xs, x)
+		if i == 0 {
+			i = 1
+		}
+		if i >= n {
+			i = n - 1
+		}
+		x0, x1 := xs[i-1], xs[i]
+		h := x1 - x0
+		a, b := (x1-x)/h, (x-x0)/h
+		return a*ys[i-1] + b*ys[i] + ((a*a*a-a)*m[i-1]+(b*b*b-b)*m[i])*h*h/6
+	}
+}
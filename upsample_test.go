@@ -0,0 +1,83 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestUpsampleLinear(t *testing.T) {
+
+	df := Empty("t", "x", "wifi", "room")
+	df.Data = append(df.Data,
+		[]interface{}{0.0, 0.0, []interface{}{-40.0, -60.0}, "BED5"},
+		[]interface{}{1.0, 10.0, []interface{}{-50.0, -60.0}, "DINING"},
+		[]interface{}{2.0, nil, []interface{}{-60.0, -70.0}, "DINING"},
+		[]interface{}{3.0, 40.0, []interface{}{-40.0, -70.0}, "KITCHEN"},
+	)
+	out, e := df.Upsample("t", 250*time.Millisecond, InterpLinear)
+	CheckError(t, e)
+	if out.N() != 13 {
+		t.Fatalf("expected 13 rows, got %d.", out.N())
+	}
+	v, e := out.Float64Slice(6, "t", "x", "wifi")
+	CheckError(t, e)
+	// x is interpolated between t=1 and t=3, ignoring the nil value.
+	want := []float64{1.5, 17.5, -55, -65}
+	for i := range want {
+		if math.Abs(v[i]-want[i]) > 1e-9 {
+			t.Fatalf("expected %v, got %v.", want, v)
+		}
+	}
+	for _, c := range []struct {
+		row  int
+		room string
+	}{{3, "BED5"}, {4, "DINING"}, {11, "DINING"}, {12, "KITCHEN"}} {
+		if r, _ := out.String(c.row, "room"); r != c.room {
+			t.Fatalf("row %d: expected %s, got %s.", c.row, c.room, r)
+		}
+	}
+}
+
+func TestUpsampleSpline(t *testing.T) {
+
+	// A natural cubic spline reproduces a straight line and is smooth
+	// through the knots of a curve.
+	df := Empty("t", "x")
+	for i := 0; i <= 4; i++ {
+		ts := float64(i) + 0.1
+		df.Data = append(df.Data, []interface{}{ts, math.Sin(ts)})
+	}
+	out, e := df.Upsample("t", 100*time.Millisecond, InterpSpline)
+	CheckError(t, e)
+	if out.N() != 41 {
+		t.Fatalf("expected 41 rows, got %d.", out.N())
+	}
+	for i := 0; i < out.N(); i++ {
+		v, _ := out.Float64Slice(i, "t", "x")
+		if math.Abs(v[1]-math.Sin(v[0])) > 0.05 {
+			t.Fatalf("at t=%g expected %g, got %g.", v[0], math.Sin(v[0]), v[1])
+		}
+	}
+
+	// RFC 3339 times are written as RFC 3339.
+	df = Empty("t", "x")
+	df.Data = append(df.Data,
+		[]interface{}{"2014-01-01T00:00:00Z", 0.0},
+		[]interface{}{"2014-01-01T00:00:01Z", 1.0},
+	)
+	out, e = df.Upsample("t", 500*time.Millisecond, InterpSpline)
+	CheckError(t, e)
+	if s, _ := out.String(1, "t"); s != "2014-01-01T00:00:00.5Z" {
+		t.Fatalf("unexpected time %s.", s)
+	}
+	df.Data[1][0] = df.Data[0][0]
+	if _, e = df.Upsample("t", time.Second, InterpLinear); e == nil {
+		t.Fatalf("expected error for repeated times.")
+	}
+}
@@ -0,0 +1,313 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"launchpad.net/goyaml"
+)
+
+// Names of the validation rules reported in violations.
+const (
+	RuleRequired  = "required"
+	RuleMin       = "min"
+	RuleMax       = "max"
+	RulePattern   = "pattern"
+	RuleLevels    = "levels"
+	RuleMonotonic = "monotonic"
+	RuleZScore    = "zscore"
+)
+
+// Validation rules for a variable. All the rules are optional.
+type Rule struct {
+	// Variable name.
+	Var string `yaml:"var"`
+	// Values must not be nil.
+	Required bool `yaml:"required"`
+	// Numeric values, and each element of vectors, must be in [Min, Max].
+	Min *float64 `yaml:"min"`
+	Max *float64 `yaml:"max"`
+	// String values must match the regular expression.
+	Pattern string `yaml:"pattern"`
+	// String values must be one of the levels.
+	Levels []string `yaml:"levels"`
+	// Values must be non-decreasing from row to row, for example timestamps.
+	Monotonic bool `yaml:"monotonic"`
+	// Outlier detection for float64 variables: the absolute z-score of values
+	// must not exceed MaxZScore. Zero disables the rule.
+	MaxZScore float64 `yaml:"max_zscore"`
+
+	re     *regexp.Regexp
+	levels map[string]bool
+}
+
+// A ValidationSpec is a list of rules. For example, in YAML:
+//
+//	rules:
+//	  - var: room
+//	    required: true
+//	    levels: [BED5, DINING, KITCHEN]
+//	  - var: wifi
+//	    min: -120
+//	    max: 0
+//	  - var: timestamp
+//	    monotonic: true
+type ValidationSpec struct {
+	Rules []*Rule `yaml:"rules"`
+}
+
+// A Violation describes a cell that doesn't satisfy a rule.
+type Violation struct {
+	// File name when validating a data set.
+	File string
+	// Batch id of the data frame.
+	BatchID string
+	// Row number.
+	Row int
+	// Variable name.
+	Var string
+	// Name of the rule, one of the Rule* constants.
+	Rule string
+	// Offending value.
+	Value interface{}
+}
+
+func (v Violation) String() string {
+
+	return fmt.Sprintf("%s batch %s row %d, variable [%s]: value %v violates rule %s",
+		v.File, v.BatchID, v.Row, v.Var, v.Value, v.Rule)
+}
+
+// Returned by Schema.Check, and by the writers, when a data frame violates
+// the constraints of a schema.
+type ValidationError struct {
+	Violations []Violation
+}
+
+// Number of violations included in the error message.
+const maxReportedViolations = 3
+
+func (e *ValidationError) Error() string {
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%d validation rule violations", len(e.Violations))
+	for i, v := range e.Violations {
+		if i == maxReportedViolations {
+			buf.WriteString("; ...")
+			break
+		}
+		buf.WriteString("; ")
+		buf.WriteString(strings.TrimSpace(v.String()))
+	}
+	return buf.String()
+}
+
+// Reads a validation spec from a YAML file.
+func ReadValidationSpecFile(fn string) (spec *ValidationSpec, e error) {
+
+	f, e := os.Open(fn)
+	if e != nil {
+		return
+	}
+	defer f.Close()
+	return ReadValidationSpec(f)
+}
+
+// Reads a validation spec from an io.Reader.
+func ReadValidationSpec(r io.Reader) (spec *ValidationSpec, e error) {
+
+	b, e := ioutil.ReadAll(r)
+	if e != nil {
+		return
+	}
+	spec = &ValidationSpec{}
+	if e = goyaml.Unmarshal(b, spec); e != nil {
+		return nil, e
+	}
+	if e = spec.compile(); e != nil {
+		return nil, e
+	}
+	return
+}
+
+func (spec *ValidationSpec) compile() error {
+
+	for _, r := range spec.Rules {
+		if r.Pattern != "" && r.re == nil {
+			re, e := regexp.Compile(r.Pattern)
+			if e != nil {
+				return fmt.Errorf("variable [%s]: %s", r.Var, e)
+			}
+			r.re = re
+		}
+		if len(r.Levels) > 0 && r.levels == nil {
+			r.levels = make(map[string]bool)
+			for _, l := range r.Levels {
+				r.levels[l] = true
+			}
+		}
+	}
+	return nil
+}
+
+// Checks every row of the data frame against the rules. Returns the
+// violations found, or an error if a rule refers to a variable that is not
+// in the frame.
+func (spec *ValidationSpec) ValidateFrame(df *DataFrame) ([]Violation, error) {
+
+	if e := spec.compile(); e != nil {
+		return nil, e
+	}
+	violations := make([]Violation, 0)
+	report := func(row int, name, rule string, value interface{}) {
+		violations = append(violations, Violation{
+			BatchID: df.BatchID, Row: row, Var: name, Rule: rule, Value: value,
+		})
+	}
+	for _, r := range spec.Rules {
+		indices, e := df.indices(r.Var)
+		if e != nil {
+			return nil, e
+		}
+		idx := indices[0]
+		var stats ColStats
+		if r.MaxZScore > 0 {
+			if stats, e = df.ColStats(r.Var); e != nil {
+				return nil, e
+			}
+		}
+		var prev interface{}
+		for i, row := range df.Data {
+			v := row[idx]
+			if v == nil {
+				if r.Required {
+					report(i, r.Var, RuleRequired, v)
+				}
+				continue
+			}
+			switch x := v.(type) {
+			case float64:
+				r.checkRange(i, x, report)
+				if r.MaxZScore > 0 && stats.StdDev > 0 && math.Abs(x-stats.Mean)/stats.StdDev > r.MaxZScore {
+					report(i, r.Var, RuleZScore, x)
+				}
+			case []float64, []interface{}:
+				vec, e := toFloat64Slice(x)
+				if e != nil {
+					return nil, fmt.Errorf("In frame %d, variable [%s]: %s", i, r.Var, e)
+				}
+				for _, f := range vec {
+					r.checkRange(i, f, report)
+				}
+			case string:
+				if r.re != nil && !r.re.MatchString(x) {
+					report(i, r.Var, RulePattern, x)
+				}
+				if r.levels != nil && !r.levels[x] {
+					report(i, r.Var, RuleLevels, x)
+				}
+			default:
+				return nil, fmt.Errorf("In frame %d, variable [%s] has unsupported type [%s].",
+					i, r.Var, reflect.TypeOf(v).String())
+			}
+			if r.Monotonic {
+				if prev != nil {
+					cmp, e := compareValues(prev, v)
+					if e != nil {
+						return nil, fmt.Errorf("In frame %d, variable [%s]: %s", i, r.Var, e)
+					}
+					if cmp > 0 {
+						report(i, r.Var, RuleMonotonic, v)
+					}
+				}
+				prev = v
+			}
+		}
+	}
+	return violations, nil
+}
+
+func (r *Rule) checkRange(row int, x float64, report func(int, string, string, interface{})) {
+
+	if r.Min != nil && x < *r.Min {
+		report(row, r.Var, RuleMin, x)
+	}
+	if r.Max != nil && x > *r.Max {
+		report(row, r.Var, RuleMax, x)
+	}
+}
+
+// Validates every file in the data set. Files that can't be read return an
+// error. The data set is reset before and after validation. Files and rows
+// are read in order even if shuffling is on, see SetShuffle.
+func (spec *ValidationSpec) ValidateDataSet(ds *DataSet) ([]Violation, error) {
+
+	defer ds.pauseShuffle()()
+	ds.Reset()
+	defer ds.Reset()
+	violations := make([]Violation, 0)
+	for i := 0; ; i++ {
+		df, e := ds.Next()
+		if e == io.EOF {
+			break
+		}
+		if e != nil {
+			return nil, e
+		}
+		v, e := spec.ValidateFrame(df)
+		if e != nil {
+			return nil, fmt.Errorf("file %s: %s", ds.Files[i], e)
+		}
+		for k := range v {
+			v[k].File = ds.Files[i]
+		}
+		violations = append(violations, v...)
+	}
+	return violations, nil
+}
+
+// Validates the data frame and, for each variable with rules, appends a
+// bool variable named name + "_anomaly" that is true in the rows where the
+// variable violates a rule. Rows are not removed so they can be reviewed
+// before filtering.
+func (df *DataFrame) FlagAnomalies(spec *ValidationSpec) error {
+
+	violations, err := spec.ValidateFrame(df)
+	if err != nil {
+		return err
+	}
+	flags := make(map[string][]interface{})
+	names := make([]string, 0)
+	for _, r := range spec.Rules {
+		if _, ok := flags[r.Var]; ok {
+			continue
+		}
+		values := make([]interface{}, df.N())
+		for i := range values {
+			values[i] = false
+		}
+		flags[r.Var] = values
+		names = append(names, r.Var)
+	}
+	for _, v := range violations {
+		flags[v.Var][v.Row] = true
+	}
+	for _, name := range names {
+		if err = df.addVar(name+"_anomaly", flags[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
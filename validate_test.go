@@ -0,0 +1,126 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const validationSpec = `
+rules:
+  - var: room
+    required: true
+    levels: [BED5, DINING]
+  - var: wifi
+    min: -42
+    max: 0
+  - var: acceleration
+    monotonic: true
+  - var: room
+    pattern: "^[A-Z]+$"
+`
+
+func TestValidateFrame(t *testing.T) {
+
+	spec, e := ReadValidationSpec(strings.NewReader(validationSpec))
+	CheckError(t, e)
+
+	tmpDir := getTempDir()
+	f1, _ := createDataFiles(t, tmpDir)
+	df, dfe := ReadDataFrameFile(f1)
+	CheckError(t, dfe)
+	df.Data[0][0] = nil
+	df.Data[4][2] = 1.0
+
+	violations, e := spec.ValidateFrame(df)
+	CheckError(t, e)
+	for _, v := range violations {
+		t.Log(v)
+	}
+
+	counts := make(map[string]int)
+	for _, v := range violations {
+		counts[v.Rule]++
+	}
+	// Row 0 nil room; wifi below -42 in rows 2..5; acceleration drop in row 4;
+	// BED5 fails the pattern in rows 1 and 2.
+	expected := map[string]int{RuleRequired: 1, RuleMin: 4, RuleMonotonic: 1, RulePattern: 2}
+	for k, n := range expected {
+		if counts[k] != n {
+			t.Fatalf("expected %d %s violations, got %d.", n, k, counts[k])
+		}
+	}
+	if len(violations) != 8 {
+		t.Fatalf("expected 8 violations, got %d.", len(violations))
+	}
+
+	// Data set validation reports file names.
+	ds := &DataSet{Path: tmpDir + "data", Files: []string{"file1.json", "file2.json"}}
+	violations, e = spec.ValidateDataSet(ds)
+	CheckError(t, e)
+	var kitchen int
+	for _, v := range violations {
+		if v.Rule == RuleLevels {
+			kitchen++
+			if v.File != "file2.json" || v.Value != "KITCHEN" {
+				t.Fatalf("unexpected violation %v.", v)
+			}
+		}
+	}
+	if kitchen != 3 {
+		t.Fatalf("expected 3 level violations, got %d.", kitchen)
+	}
+
+	// Shuffling doesn't change file names and row numbers.
+	for seed := int64(0); seed < 5; seed++ {
+		ds.SetShuffle(seed, ShuffleAll)
+		shuffled, e := spec.ValidateDataSet(ds)
+		CheckError(t, e)
+		if !reflect.DeepEqual(shuffled, violations) {
+			t.Fatalf("seed %d: violations differ with shuffling:\n%v\n%v", seed, shuffled, violations)
+		}
+		if ds.shuffle != ShuffleAll {
+			t.Fatalf("shuffle mode was not restored.")
+		}
+	}
+
+	if _, e = ReadValidationSpec(strings.NewReader("rules:\n  - var: x\n    pattern: \"[\"\n")); e == nil {
+		t.Fatalf("expected error for invalid pattern.")
+	}
+}
+
+func TestFlagAnomalies(t *testing.T) {
+
+	df := Empty("room", "acceleration")
+	for i := 0; i < 20; i++ {
+		df.Data = append(df.Data, []interface{}{"BED5", 1.0 + 0.01*float64(i%3)})
+	}
+	df.Data[7][1] = 25.0
+	df.Data[9][0] = "ATTIC"
+
+	spec, e := ReadValidationSpec(strings.NewReader(`
+rules:
+  - var: acceleration
+    max_zscore: 3
+  - var: room
+    levels: [BED5]
+`))
+	CheckError(t, e)
+	CheckError(t, df.FlagAnomalies(spec))
+
+	if df.N() != 20 || df.NumVariables() != 4 {
+		t.Fatalf("dims must be 20x4, not %dx%d.", df.N(), df.NumVariables())
+	}
+	for i := 0; i < df.N(); i++ {
+		acc := df.Data[i][df.varMap["acceleration_anomaly"]].(bool)
+		room := df.Data[i][df.varMap["room_anomaly"]].(bool)
+		if acc != (i == 7) || room != (i == 9) {
+			t.Fatalf("unexpected flags in row %d: %v %v.", i, acc, room)
+		}
+	}
+}
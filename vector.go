@@ -0,0 +1,186 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// A VecReducer reduces a vector value to a scalar.
+type VecReducer func(v []float64) float64
+
+// Computes the mean of each vector in variable name and stores the result
+// as a new float64 variable named name + "_mean".
+func (df *DataFrame) VecMean(name string) error {
+
+	return df.VecReduce(name, name+"_mean", func(v []float64) float64 {
+		if len(v) == 0 {
+			return math.NaN()
+		}
+		var sum float64
+		for _, x := range v {
+			sum += x
+		}
+		return sum / float64(len(v))
+	})
+}
+
+// Computes the max of each vector in variable name and stores the result
+// as a new float64 variable named name + "_max".
+func (df *DataFrame) VecMax(name string) error {
+
+	return df.VecReduce(name, name+"_max", func(v []float64) float64 {
+		if len(v) == 0 {
+			return math.NaN()
+		}
+		max := v[0]
+		for _, x := range v[1:] {
+			if x > max {
+				max = x
+			}
+		}
+		return max
+	})
+}
+
+// Computes the min of each vector in variable name and stores the result
+// as a new float64 variable named name + "_min".
+func (df *DataFrame) VecMin(name string) error {
+
+	return df.VecReduce(name, name+"_min", func(v []float64) float64 {
+		if len(v) == 0 {
+			return math.NaN()
+		}
+		min := v[0]
+		for _, x := range v[1:] {
+			if x < min {
+				min = x
+			}
+		}
+		return min
+	})
+}
+
+// Computes the Euclidean norm of each vector in variable name and stores the result
+// as a new float64 variable named name + "_norm".
+func (df *DataFrame) VecNorm(name string) error {
+
+	return df.VecReduce(name, name+"_norm", func(v []float64) float64 {
+		var sum float64
+		for _, x := range v {
+			sum += x * x
+		}
+		return math.Sqrt(sum)
+	})
+}
+
+// Applies reducer to each vector in variable name and stores the result as a new
+// float64 variable named newName.
+func (df *DataFrame) VecReduce(name, newName string, reducer VecReducer) error {
+
+	indices, err := df.indices(name)
+	if err != nil {
+		return err
+	}
+	idx := indices[0]
+	values := make([]interface{}, df.N())
+	for i := range df.Data {
+		v, err := toFloat64Slice(df.Data[i][idx])
+		if err != nil {
+			return fmt.Errorf("In frame %d, variable [%s]: %s", i, name, err)
+		}
+		values[i] = reducer(v)
+	}
+	return df.addVar(newName, values)
+}
+
+// Converts a vector value to a []float64.
+func toFloat64Slice(value interface{}) ([]float64, error) {
+
+	switch v := value.(type) {
+	case nil:
+		return nil, fmt.Errorf("value is nil.")
+	case []float64:
+		return v, nil
+	case []interface{}:
+		floats := make([]float64, len(v))
+		for i, x := range v {
+			f, ok := x.(float64)
+			if !ok {
+				return nil, fmt.Errorf("element %d of type %T is not a float64.", i, x)
+			}
+			floats[i] = f
+		}
+		return floats, nil
+	default:
+		return nil, fmt.Errorf("Vector of type %s in not supported.", reflect.TypeOf(v).String())
+	}
+}
+
+// DimPolicy determines what happens when a vector variable doesn't have the
+// expected dimension.
+type DimPolicy string
+
+const (
+	// Return an error when the dimension doesn't match.
+	DimError DimPolicy = "error"
+	// Pad short vectors with the pad value, error on long vectors.
+	DimPad DimPolicy = "pad"
+	// Truncate long vectors, error on short vectors.
+	DimTruncate DimPolicy = "truncate"
+	// Pad short vectors and truncate long vectors.
+	DimPadTruncate DimPolicy = "pad_truncate"
+)
+
+// Declares the expected dimension of a vector variable.
+type VecDim struct {
+	// Expected number of elements.
+	Dim int `yaml:"dim"`
+	// What to do when the dimension doesn't match. Defaults to DimError.
+	Policy DimPolicy `yaml:"policy"`
+	// Value used to pad short vectors.
+	Pad float64 `yaml:"pad"`
+}
+
+// Verifies that all the vectors in variable name have the expected dimension.
+// Vectors are padded or truncated in place according to the policy.
+func (df *DataFrame) EnforceDim(name string, dim VecDim) error {
+
+	indices, err := df.indices(name)
+	if err != nil {
+		return err
+	}
+	idx := indices[0]
+	defer df.Invalidate(name)
+	pad := dim.Policy == DimPad || dim.Policy == DimPadTruncate
+	truncate := dim.Policy == DimTruncate || dim.Policy == DimPadTruncate
+	for i := range df.Data {
+		v, err := toFloat64Slice(df.Data[i][idx])
+		if err != nil {
+			return fmt.Errorf("In frame %d, variable [%s]: %s", i, name, err)
+		}
+		switch {
+		case len(v) == dim.Dim:
+			continue
+		case len(v) < dim.Dim && pad:
+			nv := make([]float64, dim.Dim)
+			copy(nv, v)
+			for j := len(v); j < dim.Dim; j++ {
+				nv[j] = dim.Pad
+			}
+			v = nv
+		case len(v) > dim.Dim && truncate:
+			v = v[:dim.Dim]
+		default:
+			return fmt.Errorf("In frame %d, variable [%s] has dimension %d, expected %d.",
+				i, name, len(v), dim.Dim)
+		}
+		df.Data[i][idx] = v
+	}
+	return nil
+}
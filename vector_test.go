@@ -0,0 +1,79 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonum/floats"
+)
+
+func TestVecReductions(t *testing.T) {
+
+	tmpDir := getTempDir()
+	f1, _ := createDataFiles(t, tmpDir)
+
+	df, dfe := ReadDataFrameFile(f1)
+	CheckError(t, dfe)
+
+	CheckError(t, df.VecMean("wifi"))
+	CheckError(t, df.VecMax("wifi"))
+	CheckError(t, df.VecMin("wifi"))
+	CheckError(t, df.VecNorm("wifi"))
+
+	if df.NumVariables() != 7 {
+		t.Fatalf("NumVariables must be 7, not %d.", df.NumVariables())
+	}
+
+	sl, sle := df.Float64Slice(1, "wifi_mean", "wifi_max", "wifi_min", "wifi_norm")
+	CheckError(t, sle)
+	t.Logf("reductions for frame 1: %+v", sl)
+
+	norm := math.Sqrt(41.8*41.8 + 41.1*41.1)
+	if !floats.EqualApprox(sl, []float64{-41.45, -41.1, -41.8, norm}, 1e-9) {
+		t.Fatalf("vector %v doesn't match.", sl)
+	}
+
+	// Adding the same variable twice must fail.
+	if e := df.VecMean("wifi"); e == nil {
+		t.Fatalf("expected error for duplicate variable.")
+	}
+}
+
+func TestEnforceDim(t *testing.T) {
+
+	tmpDir := getTempDir()
+	f1, _ := createDataFiles(t, tmpDir)
+
+	df, dfe := ReadDataFrameFile(f1)
+	CheckError(t, dfe)
+
+	// Dimension matches.
+	CheckError(t, df.EnforceDim("wifi", VecDim{Dim: 2}))
+
+	// Pad.
+	if e := df.EnforceDim("wifi", VecDim{Dim: 3}); e == nil {
+		t.Fatalf("expected dimension error.")
+	}
+	CheckError(t, df.EnforceDim("wifi", VecDim{Dim: 3, Policy: DimPad, Pad: -100}))
+	sl, sle := df.Float64Slice(1, "wifi", "acceleration")
+	CheckError(t, sle)
+	if !floats.Equal(sl, []float64{-41.8, -41.1, -100, 1.4}) {
+		t.Fatalf("vector %v doesn't match.", sl)
+	}
+
+	// Truncate.
+	if e := df.EnforceDim("wifi", VecDim{Dim: 1, Policy: DimPad}); e == nil {
+		t.Fatalf("expected dimension error.")
+	}
+	CheckError(t, df.EnforceDim("wifi", VecDim{Dim: 1, Policy: DimPadTruncate}))
+	sl, sle = df.Float64Slice(1, "wifi", "acceleration")
+	CheckError(t, sle)
+	if !floats.Equal(sl, []float64{-41.8, 1.4}) {
+		t.Fatalf("vector %v doesn't match.", sl)
+	}
+}
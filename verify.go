@@ -0,0 +1,123 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// Extension of detached signature files. The signature of file f is read
+// from f + SignatureExt.
+const SignatureExt = ".sig"
+
+// A Verifier checks the detached signature of a data file before it is
+// parsed. The file name is provided for error messages and key selection.
+type Verifier interface {
+	Verify(fn string, data, sig []byte) error
+}
+
+// The VerifierFunc type is an adapter to allow the use of ordinary functions
+// as verifiers.
+type VerifierFunc func(fn string, data, sig []byte) error
+
+func (f VerifierFunc) Verify(fn string, data, sig []byte) error {
+	return f(fn, data, sig)
+}
+
+// A Decrypter returns the plaintext of an encrypted data file. When a data
+// set has both a verifier and a decrypter, the signature is verified on the
+// encrypted content.
+type Decrypter interface {
+	Decrypt(fn string, data []byte) ([]byte, error)
+}
+
+// The DecrypterFunc type is an adapter to allow the use of ordinary
+// functions as decrypters.
+type DecrypterFunc func(fn string, data []byte) ([]byte, error)
+
+func (f DecrypterFunc) Decrypt(fn string, data []byte) ([]byte, error) {
+	return f(fn, data)
+}
+
+// Sets a verifier used to check the signature of every file read by the
+// data set. Reading fails if a signature file is missing or invalid.
+func (ds *DataSet) SetVerifier(v Verifier) {
+
+	ds.verifier = v
+}
+
+// Sets a decrypter applied to every file read by the data set.
+func (ds *DataSet) SetDecrypter(d Decrypter) {
+
+	ds.decrypter = d
+}
+
+// Reads the file content and applies the verifier and decrypter.
+func (ds *DataSet) applyHooks(fn string, r io.Reader) ([]byte, error) {
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if ds.verifier != nil {
+		sig, err := ioutil.ReadFile(fn + SignatureExt)
+		if err != nil {
+			return nil, fmt.Errorf("missing signature for %s: %s", fn, err)
+		}
+		if err = ds.verifier.Verify(fn, data, sig); err != nil {
+			return nil, fmt.Errorf("signature verification failed for %s: %s", fn, err)
+		}
+	}
+	if ds.decrypter != nil {
+		if data, err = ds.decrypter.Decrypt(fn, data); err != nil {
+			return nil, fmt.Errorf("decryption failed for %s: %s", fn, err)
+		}
+	}
+	return data, nil
+}
+
+// An HMACVerifier verifies hex encoded HMAC-SHA256 signatures with a shared key.
+type HMACVerifier struct {
+	Key []byte
+}
+
+// Returns the hex encoded HMAC-SHA256 signature of data.
+func (h HMACVerifier) Sign(data []byte) []byte {
+
+	mac := hmac.New(sha256.New, h.Key)
+	mac.Write(data)
+	return []byte(hex.EncodeToString(mac.Sum(nil)))
+}
+
+// Verifies a hex encoded HMAC-SHA256 signature. Surrounding white space in
+// the signature is ignored.
+func (h HMACVerifier) Verify(fn string, data, sig []byte) error {
+
+	expected := h.Sign(data)
+	if !hmac.Equal(expected, []byte(strings.TrimSpace(string(sig)))) {
+		return fmt.Errorf("invalid signature.")
+	}
+	return nil
+}
+
+// Signs a file with the HMAC key and writes the signature to fn + SignatureExt.
+func (h HMACVerifier) SignFile(fn string) error {
+
+	data, err := ioutil.ReadFile(fn)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(fn+SignatureExt, 0644, func(w io.Writer) error {
+		_, err := w.Write(h.Sign(data))
+		return err
+	})
+}
@@ -0,0 +1,99 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerifier(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-verify")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+
+	spec := synthSpec(5, 2, 0)
+	spec.Files = 2
+	ds, e := GenerateDataSet(spec, dir)
+	CheckError(t, e)
+
+	v := HMACVerifier{Key: []byte("secret")}
+	for _, fn := range ds.Files {
+		CheckError(t, v.SignFile(filepath.Join(dir, fn)))
+	}
+	ds.SetVerifier(v)
+	for {
+		df, e := ds.Next()
+		if e == io.EOF {
+			break
+		}
+		CheckError(t, e)
+		if df.N() != 5 {
+			t.Fatalf("expected 5 rows, got %d.", df.N())
+		}
+	}
+
+	// Wrong key.
+	ds.SetVerifier(HMACVerifier{Key: []byte("other")})
+	if _, e = ds.Next(); e == nil {
+		t.Fatalf("expected error for invalid signature.")
+	}
+	t.Log(e)
+	ds.Reset()
+
+	// Missing signature.
+	ds.SetVerifier(v)
+	CheckError(t, os.Remove(filepath.Join(dir, ds.Files[0]+SignatureExt)))
+	if _, e = ds.Next(); e == nil {
+		t.Fatalf("expected error for missing signature.")
+	}
+	t.Log(e)
+}
+
+func TestDecrypter(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-decrypt")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+
+	spec := synthSpec(5, 2, 0)
+	spec.Files = 1
+	ds, e := GenerateDataSet(spec, dir)
+	CheckError(t, e)
+
+	// Toy cipher: xor every byte.
+	xor := func(fn string, data []byte) ([]byte, error) {
+		out := make([]byte, len(data))
+		for i, b := range data {
+			out[i] = b ^ 0x5a
+		}
+		return out, nil
+	}
+	fn := filepath.Join(dir, ds.Files[0])
+	plain, e := ioutil.ReadFile(fn)
+	CheckError(t, e)
+	cipher, _ := xor(fn, plain)
+	CheckError(t, ioutil.WriteFile(fn, cipher, 0644))
+	if bytes.Equal(plain, cipher) {
+		t.Fatalf("file must be encrypted.")
+	}
+
+	// Signature is computed on the encrypted content.
+	v := HMACVerifier{Key: []byte("secret")}
+	CheckError(t, v.SignFile(fn))
+	ds.SetVerifier(v)
+	ds.SetDecrypter(DecrypterFunc(xor))
+	df, e := ds.Next()
+	CheckError(t, e)
+	if df.N() != 5 {
+		t.Fatalf("expected 5 rows, got %d.", df.N())
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import "fmt"
+
+// A View is a subset of the rows and variables of a parent data frame. Views
+// share the data of the parent, so chains of Select and Filter calls don't
+// copy cells. Changes in the parent are visible through the view. Row
+// numbers passed to View methods are relative to the view.
+type View struct {
+	parent *DataFrame
+	// parent row numbers, nil means all rows.
+	rows []int
+	// selected variable names in order.
+	names []string
+}
+
+// Returns a view of all the rows and variables in the data frame.
+func (df *DataFrame) View() *View {
+
+	return &View{parent: df, names: df.VarNames}
+}
+
+// Returns a view with a subset of the variables in the given order.
+func (v *View) Select(names ...string) (*View, error) {
+
+	if err := v.check(names...); err != nil {
+		return nil, err
+	}
+	return &View{parent: v.parent, rows: v.rows, names: names}, nil
+}
+
+// Returns a view with the rows for which fn returns true. The function gets
+// the row number in the parent frame.
+func (v *View) Filter(fn func(frame int) bool) *View {
+
+	rows := make([]int, 0)
+	for i := 0; i < v.N(); i++ {
+		if frame := v.Frame(i); fn(frame) {
+			rows = append(rows, frame)
+		}
+	}
+	return &View{parent: v.parent, rows: rows, names: v.names}
+}
+
+// Returns a view with rows start to end-1 of this view.
+func (v *View) Rows(start, end int) (*View, error) {
+
+	if start < 0 || end > v.N() || start > end {
+		return nil, fmt.Errorf("Invalid row range [%d, %d) for view with %d rows.", start, end, v.N())
+	}
+	rows := make([]int, end-start)
+	for i := range rows {
+		rows[i] = v.Frame(start + i)
+	}
+	return &View{parent: v.parent, rows: rows, names: v.names}, nil
+}
+
+// Returns the parent data frame.
+func (v *View) Parent() *DataFrame {
+
+	return v.parent
+}
+
+// Returns the row number in the parent frame for row i in the view.
+func (v *View) Frame(i int) int {
+
+	if v.rows == nil {
+		return i
+	}
+	return v.rows[i]
+}
+
+// Returns number of rows in the view.
+func (v *View) N() int {
+
+	if v.rows == nil {
+		return v.parent.N()
+	}
+	return len(v.rows)
+}
+
+// Returns the names of the variables in the view.
+func (v *View) VarNames() []string {
+
+	return v.names
+}
+
+// Returns number of variables in the view.
+func (v *View) NumVariables() int {
+
+	return len(v.names)
+}
+
+// Joins float64 and []float64 variables in row i and returns them as a []float64.
+func (v *View) Float64Slice(i int, names ...string) ([]float64, error) {
+
+	if err := v.check(names...); err != nil {
+		return nil, err
+	}
+	return v.parent.Float64Slice(v.Frame(i), names...)
+}
+
+// Returns value of a string variable in row i.
+func (v *View) String(i int, name string) (string, error) {
+
+	if err := v.check(name); err != nil {
+		return "", err
+	}
+	return v.parent.String(v.Frame(i), name)
+}
+
+// Returns the raw value of a variable in row i.
+func (v *View) Value(i int, name string) (interface{}, error) {
+
+	if err := v.check(name); err != nil {
+		return nil, err
+	}
+	return v.parent.Data[v.Frame(i)][v.parent.varMap[name]], nil
+}
+
+// Copies the rows and variables in the view into a new data frame.
+// Cell values are not deep copied.
+func (v *View) Materialize() *DataFrame {
+
+	p := v.parent
+	df := &DataFrame{
+		Description: p.Description,
+		BatchID:     p.BatchID,
+		VarNames:    append([]string(nil), v.names...),
+		Data:        make([][]interface{}, v.N()),
+		Properties:  copyProperties(p.Properties),
+	}
+	cols := make([]int, len(v.names))
+	for j, name := range v.names {
+		cols[j] = p.varMap[name]
+		if u, ok := p.VarUnits[name]; ok {
+			if df.VarUnits == nil {
+				df.VarUnits = make(map[string]string)
+			}
+			df.VarUnits[name] = u
+		}
+	}
+	for i := range df.Data {
+		src := p.Data[v.Frame(i)]
+		row := make([]interface{}, len(cols))
+		for j, c := range cols {
+			row[j] = src[c]
+		}
+		df.Data[i] = row
+	}
+	df.resetVarMap()
+	return df
+}
+
+func (v *View) has(name string) bool {
+
+	for _, n := range v.names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *View) check(names ...string) error {
+
+	for _, name := range names {
+		if !v.has(name) {
+			return fmt.Errorf("There is no variable [%s] in the view.", name)
+		}
+	}
+	return nil
+}
+
+func copyProperties(p map[string]string) map[string]string {
+
+	if p == nil {
+		return nil
+	}
+	c := make(map[string]string, len(p))
+	for k, v := range p {
+		c[k] = v
+	}
+	return c
+}
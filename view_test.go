@@ -0,0 +1,66 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gonum/floats"
+)
+
+func TestView(t *testing.T) {
+
+	tmpDir := getTempDir()
+	f1, _ := createDataFiles(t, tmpDir)
+
+	df, dfe := ReadDataFrameFile(f1)
+	CheckError(t, dfe)
+
+	v, e := df.View().Select("room", "acceleration")
+	CheckError(t, e)
+	v = v.Filter(func(frame int) bool {
+		room, _ := df.String(frame, "room")
+		return room == "DINING"
+	})
+	if v.N() != 3 || v.NumVariables() != 2 {
+		t.Fatalf("dims must be 3x2, not %dx%d.", v.N(), v.NumVariables())
+	}
+	if v.Frame(0) != 3 {
+		t.Fatalf("first row must be frame 3, not %d.", v.Frame(0))
+	}
+	sl, e := v.Float64Slice(2, "acceleration")
+	CheckError(t, e)
+	if !floats.Equal(sl, []float64{1.8}) {
+		t.Fatalf("vector %v doesn't match.", sl)
+	}
+	if _, e = v.Float64Slice(0, "wifi"); e == nil {
+		t.Fatalf("expected error for variable outside the view.")
+	}
+
+	// Views share data with the parent.
+	df.Data[4][2] = 9.0
+	sl, _ = v.Float64Slice(1, "acceleration")
+	if sl[0] != 9 {
+		t.Fatalf("view doesn't see parent changes.")
+	}
+
+	sub, e := v.Rows(1, 3)
+	CheckError(t, e)
+	m := sub.Materialize()
+	expected := [][]interface{}{{"DINING", 9.0}, {"DINING", 1.8}}
+	if !reflect.DeepEqual(m.Data, expected) {
+		t.Fatalf("got %v, expected %v.", m.Data, expected)
+	}
+	room, e := m.String(0, "room")
+	CheckError(t, e)
+	if room != "DINING" || m.BatchID != df.BatchID {
+		t.Fatalf("materialized frame doesn't match view.")
+	}
+	if _, e = v.Rows(2, 5); e == nil {
+		t.Fatalf("expected error for invalid range.")
+	}
+}
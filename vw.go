@@ -0,0 +1,182 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// A VW namespace groups variables under a single namespace name.
+type VWNamespace struct {
+	Name string   `yaml:"name"`
+	Vars []string `yaml:"vars"`
+}
+
+// Describes how rows are exported in Vowpal Wabbit text format.
+type VWSpec struct {
+	// Variable with the label. Optional.
+	Label string `yaml:"label"`
+	// Variable used as the example tag. Optional.
+	Tag string `yaml:"tag"`
+	// Feature namespaces in output order.
+	Namespaces []VWNamespace `yaml:"namespaces"`
+}
+
+// Writes the data frame in Vowpal Wabbit text format, one example per row.
+//
+// Float variables become features "name:value", vector variables become
+// features "name_i:value", and string variables become binary features
+// "name=value". Zero and nil values are omitted.
+func (df *DataFrame) WriteVW(w io.Writer, spec VWSpec) error {
+
+	if err := df.checkWrite(); err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	if err := df.writeVW(bw, spec); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Writes all the files in the data set in Vowpal Wabbit text format.
+// See DataFrame.WriteVW.
+func (ds *DataSet) WriteVW(w io.Writer, spec VWSpec) error {
+
+	bw := bufio.NewWriter(w)
+	ds.Reset()
+	for {
+		df, e := ds.Next()
+		if e == io.EOF {
+			break
+		}
+		if e != nil {
+			ds.Reset()
+			return e
+		}
+		if e = df.writeVW(bw, spec); e != nil {
+			ds.Reset()
+			return fmt.Errorf("batch %s: %s", df.BatchID, e)
+		}
+	}
+	return bw.Flush()
+}
+
+func (df *DataFrame) writeVW(w *bufio.Writer, spec VWSpec) error {
+
+	label, tag := -1, -1
+	if spec.Label != "" {
+		idx, err := df.indices(spec.Label)
+		if err != nil {
+			return err
+		}
+		label = idx[0]
+	}
+	if spec.Tag != "" {
+		idx, err := df.indices(spec.Tag)
+		if err != nil {
+			return err
+		}
+		tag = idx[0]
+	}
+	ns := make([][]int, len(spec.Namespaces))
+	for i, n := range spec.Namespaces {
+		idx, err := df.indices(n.Vars...)
+		if err != nil {
+			return err
+		}
+		ns[i] = idx
+	}
+
+	for i, row := range df.Data {
+		if label >= 0 {
+			s, err := vwLabel(row[label])
+			if err != nil {
+				return fmt.Errorf("In frame %d, label: %s", i, err)
+			}
+			w.WriteString(s)
+		}
+		if tag >= 0 && row[tag] != nil {
+			w.WriteString(" '")
+			w.WriteString(vwEscape(fmt.Sprint(row[tag])))
+		}
+		for j, n := range spec.Namespaces {
+			w.WriteString(" |")
+			w.WriteString(vwEscape(n.Name))
+			for _, idx := range ns[j] {
+				if err := writeVWFeature(w, df.VarNames[idx], row[idx]); err != nil {
+					return fmt.Errorf("In frame %d, variable [%s]: %s", i, df.VarNames[idx], err)
+				}
+			}
+		}
+		w.WriteByte('\n')
+	}
+	return nil
+}
+
+func vwLabel(v interface{}) (string, error) {
+
+	switch x := v.(type) {
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64), nil
+	case string:
+		return vwEscape(x), nil
+	case bool:
+		if x {
+			return "1", nil
+		}
+		return "-1", nil
+	case nil:
+		return "", fmt.Errorf("value is nil.")
+	default:
+		return "", fmt.Errorf("type %s is not supported.", reflect.TypeOf(v).String())
+	}
+}
+
+func writeVWFeature(w *bufio.Writer, name string, v interface{}) error {
+
+	name = vwEscape(name)
+	switch x := v.(type) {
+	case nil:
+	case float64:
+		if x != 0 {
+			fmt.Fprintf(w, " %s:%s", name, strconv.FormatFloat(x, 'g', -1, 64))
+		}
+	case string:
+		fmt.Fprintf(w, " %s=%s", name, vwEscape(x))
+	case bool:
+		if x {
+			fmt.Fprintf(w, " %s", name)
+		}
+	case []float64, []interface{}:
+		vec, err := toFloat64Slice(x)
+		if err != nil {
+			return err
+		}
+		for k, f := range vec {
+			if f != 0 {
+				fmt.Fprintf(w, " %s_%d:%s", name, k, strconv.FormatFloat(f, 'g', -1, 64))
+			}
+		}
+	default:
+		return fmt.Errorf("type %s is not supported.", reflect.TypeOf(v).String())
+	}
+	return nil
+}
+
+// Replaces characters that have special meaning in VW format.
+var vwReplacer = strings.NewReplacer(" ", "_", "\t", "_", "|", "_", ":", "_", "\n", "_")
+
+func vwEscape(s string) string {
+
+	return vwReplacer.Replace(s)
+}
@@ -0,0 +1,58 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteVW(t *testing.T) {
+
+	df := &DataFrame{
+		VarNames: []string{"room", "wifi", "acceleration", "id"},
+		Data: [][]interface{}{
+			{"BED 5", []interface{}{-40.8, 0.0}, 1.3, "a1"},
+			{"DINING", []interface{}{-42.9, -40.11}, 0.0, "a2"},
+		},
+	}
+	df.resetVarMap()
+
+	spec := VWSpec{
+		Label: "room",
+		Tag:   "id",
+		Namespaces: []VWNamespace{
+			{Name: "w", Vars: []string{"wifi"}},
+			{Name: "a", Vars: []string{"acceleration"}},
+		},
+	}
+	var buf bytes.Buffer
+	CheckError(t, df.WriteVW(&buf, spec))
+	t.Logf("vw:\n%s", buf.String())
+
+	expected := `BED_5 'a1 |w wifi_0:-40.8 |a acceleration:1.3
+DINING 'a2 |w wifi_0:-42.9 wifi_1:-40.11 |a
+`
+	if buf.String() != expected {
+		t.Fatalf("got:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+
+	// Data set export.
+	tmpDir := getTempDir()
+	createDataFiles(t, tmpDir)
+	ds := &DataSet{Path: tmpDir + "data", Files: []string{"file1.json", "file2.json"}}
+	buf.Reset()
+	spec = VWSpec{Label: "acceleration", Namespaces: []VWNamespace{{Name: "r", Vars: []string{"room", "wifi"}}}}
+	CheckError(t, ds.WriteVW(&buf, spec))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 12 {
+		t.Fatalf("expected 12 lines, got %d.", len(lines))
+	}
+	if lines[0] != "1.3 |r room=BED5 wifi_0:-40.8 wifi_1:-41.2" {
+		t.Fatalf("unexpected line: %s", lines[0])
+	}
+}
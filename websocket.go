@@ -0,0 +1,282 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// A RowStream is an http.Handler that pushes rows to WebSocket clients as
+// JSON objects that map variable names to values, one message per row.
+// Clients can select a subset of the variables with the query parameter
+// "vars", a comma separated list of names. Non-finite floats are sent as
+// {"float64": "NaN"}, "Inf", or "-Inf", as in WriteDataFrame.
+type RowStream struct {
+	// Returns a function that yields the data frames for a new connection,
+	// one per call, until it returns io.EOF.
+	Open func() func() (*DataFrame, error)
+	// Variables sent to clients.
+	VarNames []string
+	// Delay between rows. Zero sends rows as fast as the client reads them.
+	Interval time.Duration
+	// Origins of the web pages allowed to connect, for example
+	// "https://example.com", or "*" for any origin. If empty, only pages
+	// served from the same host are allowed. Requests without an Origin
+	// header, which browsers always send, are allowed.
+	AllowedOrigins []string
+}
+
+// Returns a stream that plays back the data set to each client from the
+// beginning, one row every interval.
+func PlaybackDataSet(ds *DataSet, interval time.Duration, names ...string) *RowStream {
+
+	return &RowStream{
+		Open: func() func() (*DataFrame, error) {
+			return ds.withFiles(ds.Files).Next
+		},
+		VarNames: names,
+		Interval: interval,
+	}
+}
+
+func (s *RowStream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	if !s.allowOrigin(r) {
+		http.Error(w, fmt.Sprintf("origin %s is not allowed", r.Header.Get("Origin")), http.StatusForbidden)
+		return
+	}
+	names := s.VarNames
+	if q := r.URL.Query().Get("vars"); q != "" {
+		allowed := make(map[string]bool)
+		for _, n := range s.VarNames {
+			allowed[n] = true
+		}
+		names = strings.Split(q, ",")
+		for _, n := range names {
+			if !allowed[n] {
+				http.Error(w, fmt.Sprintf("unknown variable [%s]", n), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	conn, rw, err := upgradeWebSocket(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer conn.Close()
+
+	// Read client frames until the client closes the connection.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			op, _, err := readWSFrame(rw.Reader)
+			if err != nil || op == wsClose {
+				return
+			}
+		}
+	}()
+
+	status := wsStatusNormal
+	err = s.stream(rw.Writer, names, closed)
+	if err != nil {
+		status = wsStatusError
+	}
+	payload := make([]byte, 2)
+	binary.BigEndian.PutUint16(payload, status)
+	if err != nil {
+		payload = append(payload, []byte(err.Error())...)
+		if len(payload) > 125 {
+			payload = payload[:125]
+		}
+	}
+	writeWSFrame(rw.Writer, wsClose, payload)
+}
+
+func (s *RowStream) stream(w *bufio.Writer, names []string, closed chan struct{}) error {
+
+	next := s.Open()
+	var ticker *time.Ticker
+	if s.Interval > 0 {
+		ticker = time.NewTicker(s.Interval)
+		defer ticker.Stop()
+	}
+	for {
+		df, err := next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		indices, err := df.indices(names...)
+		if err != nil {
+			return err
+		}
+		for _, row := range df.Data {
+			if ticker != nil {
+				select {
+				case <-ticker.C:
+				case <-closed:
+					return nil
+				}
+			}
+			obj := make(map[string]interface{}, len(names))
+			for j, idx := range indices {
+				obj[names[j]], _ = finiteValue(row[idx])
+			}
+			b, err := json.Marshal(obj)
+			if err != nil {
+				return err
+			}
+			if err = writeWSFrame(w, wsText, b); err != nil {
+				return nil
+			}
+			select {
+			case <-closed:
+				return nil
+			default:
+			}
+		}
+	}
+}
+
+// Returns true if the Origin header of the request is allowed, see
+// AllowedOrigins.
+func (s *RowStream) allowOrigin(r *http.Request) bool {
+
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if len(s.AllowedOrigins) == 0 {
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+	for _, o := range s.AllowedOrigins {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// WebSocket opcodes and close status codes, see RFC 6455.
+const (
+	wsText  = 0x1
+	wsClose = 0x8
+
+	wsStatusNormal uint16 = 1000
+	wsStatusError  uint16 = 1011
+)
+
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Completes the WebSocket opening handshake and takes over the connection.
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request) (net.Conn, *bufio.ReadWriter, error) {
+
+	if r.Method != "GET" ||
+		!strings.EqualFold(r.Header.Get("Upgrade"), "websocket") ||
+		!strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade") {
+		return nil, nil, fmt.Errorf("not a websocket handshake")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" || r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return nil, nil, fmt.Errorf("unsupported websocket version")
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("connection can't be hijacked")
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	h := sha1.Sum([]byte(key + wsGUID))
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n",
+		base64.StdEncoding.EncodeToString(h[:]))
+	if err = rw.Flush(); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return conn, rw, nil
+}
+
+// Writes an unmasked frame, as sent by servers.
+func writeWSFrame(w *bufio.Writer, op byte, payload []byte) error {
+
+	w.WriteByte(0x80 | op)
+	n := len(payload)
+	switch {
+	case n < 126:
+		w.WriteByte(byte(n))
+	case n <= 0xFFFF:
+		w.WriteByte(126)
+		binary.Write(w, binary.BigEndian, uint16(n))
+	default:
+		w.WriteByte(127)
+		binary.Write(w, binary.BigEndian, uint64(n))
+	}
+	w.Write(payload)
+	return w.Flush()
+}
+
+// Reads a frame and unmasks the payload. Control frames are limited to 125
+// bytes and data frames to 1 MB.
+func readWSFrame(r *bufio.Reader) (op byte, payload []byte, err error) {
+
+	var h [2]byte
+	if _, err = io.ReadFull(r, h[:]); err != nil {
+		return
+	}
+	op = h[0] & 0x0F
+	masked := h[1]&0x80 != 0
+	n := uint64(h[1] & 0x7F)
+	switch n {
+	case 126:
+		var l uint16
+		err = binary.Read(r, binary.BigEndian, &l)
+		n = uint64(l)
+	case 127:
+		err = binary.Read(r, binary.BigEndian, &n)
+	}
+	if err != nil {
+		return
+	}
+	if n > 1<<20 {
+		err = fmt.Errorf("websocket frame too large: %d bytes", n)
+		return
+	}
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(r, mask[:]); err != nil {
+			return
+		}
+	}
+	payload = make([]byte, n)
+	if _, err = io.ReadFull(r, payload); err != nil {
+		return
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return
+}
@@ -0,0 +1,153 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Opens a websocket connection and returns the reader after the handshake.
+func dialWS(t *testing.T, url, path string) (net.Conn, *bufio.Reader) {
+
+	conn, e := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	CheckError(t, e)
+	fmt.Fprintf(conn, "GET %s HTTP/1.1\r\nHost: localhost\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n", path)
+	r := bufio.NewReader(conn)
+	resp, e := http.ReadResponse(r, nil)
+	CheckError(t, e)
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("unexpected status %s.", resp.Status)
+	}
+	if a := resp.Header.Get("Sec-WebSocket-Accept"); a != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("unexpected accept key %s.", a)
+	}
+	return conn, r
+}
+
+func TestRowStream(t *testing.T) {
+
+	tmpDir := getTempDir()
+	createDataFiles(t, tmpDir)
+	ds := &DataSet{Path: tmpDir + "data", Files: []string{"file1.json", "file2.json"}}
+	srv := httptest.NewServer(PlaybackDataSet(ds, 0, "room", "wifi", "acceleration"))
+	defer srv.Close()
+
+	conn, r := dialWS(t, srv.URL, "/?vars=room,acceleration")
+	defer conn.Close()
+	var rows []map[string]interface{}
+	for {
+		op, payload, e := readWSFrame(r)
+		CheckError(t, e)
+		if op == wsClose {
+			if len(payload) < 2 || payload[0] != 0x03 || payload[1] != 0xE8 {
+				t.Fatalf("unexpected close frame %v.", payload)
+			}
+			break
+		}
+		var row map[string]interface{}
+		CheckError(t, json.Unmarshal(payload, &row))
+		rows = append(rows, row)
+	}
+	if len(rows) != 12 {
+		t.Fatalf("expected 12 rows, got %d.", len(rows))
+	}
+	if rows[6]["room"] != "KITCHEN" || rows[6]["acceleration"] != 1.3 || len(rows[6]) != 2 {
+		t.Fatalf("unexpected row %v.", rows[6])
+	}
+
+	resp, e := http.Get(srv.URL + "/?vars=foo")
+	CheckError(t, e)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected bad request, got %s.", resp.Status)
+	}
+}
+
+func TestRowStreamOrigin(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	df.Data[0][2] = math.NaN()
+	df.Data[1][1] = []float64{math.Inf(1), -1}
+	s := &RowStream{
+		Open: func() func() (*DataFrame, error) {
+			done := false
+			return func() (*DataFrame, error) {
+				if done {
+					return nil, io.EOF
+				}
+				done = true
+				return df, nil
+			}
+		},
+		VarNames: df.VarNames,
+	}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	// Returns the status of a request with the origin.
+	status := func(origin string) int {
+		req, e := http.NewRequest("GET", srv.URL+"/?vars=foo", nil)
+		CheckError(t, e)
+		req.Header.Set("Origin", origin)
+		resp, e := http.DefaultClient.Do(req)
+		CheckError(t, e)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if c := status("http://evil.example.com"); c != http.StatusForbidden {
+		t.Fatalf("expected forbidden, got %d.", c)
+	}
+	if c := status(srv.URL); c != http.StatusBadRequest {
+		t.Fatalf("same origin must be allowed, got %d.", c)
+	}
+	s.AllowedOrigins = []string{"http://evil.example.com"}
+	if c := status("http://evil.example.com"); c != http.StatusBadRequest {
+		t.Fatalf("allowed origin must be accepted, got %d.", c)
+	}
+	if c := status(srv.URL); c != http.StatusForbidden {
+		t.Fatalf("expected forbidden, got %d.", c)
+	}
+	s.AllowedOrigins = nil
+
+	// Non-finite values don't end the stream.
+	conn, r := dialWS(t, srv.URL, "/")
+	defer conn.Close()
+	var rows []map[string]interface{}
+	for {
+		op, payload, e := readWSFrame(r)
+		CheckError(t, e)
+		if op == wsClose {
+			if payload[1] != 0xE8 {
+				t.Fatalf("unexpected close frame %q.", payload)
+			}
+			break
+		}
+		var row map[string]interface{}
+		CheckError(t, json.Unmarshal(payload, &row))
+		rows = append(rows, row)
+	}
+	if len(rows) != df.N() {
+		t.Fatalf("expected %d rows, got %d.", df.N(), len(rows))
+	}
+	values := []interface{}{rows[0]["acceleration"], rows[1]["wifi"]}
+	restoreRow(values)
+	if x, ok := values[0].(float64); !ok || !math.IsNaN(x) {
+		t.Fatalf("expected NaN, got %v.", rows[0]["acceleration"])
+	}
+	if v, ok := values[1].([]interface{}); !ok || !math.IsInf(v[0].(float64), 1) {
+		t.Fatalf("expected +Inf, got %v.", rows[1]["wifi"])
+	}
+}
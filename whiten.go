@@ -0,0 +1,131 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// A Whitener decorrelates float64 vectors and scales them to unit variance
+// using the mean and covariance estimated over a data set.
+type Whitener struct {
+	// Variables used to fit the transform.
+	Names []string
+	// Mean vector.
+	Mean []float64
+	// Covariance matrix, row-major.
+	Cov []float64
+	// Eigenvalues of the covariance matrix in descending order.
+	Eigenvalues []float64
+	// Eigenvectors of the covariance matrix stored as columns, row-major.
+	Eigenvectors []float64
+	dim          int
+}
+
+// Estimates the mean and covariance of the float variables in a single pass
+// over all the files in the data set. The data set is reset before and after
+// the pass.
+func (ds *DataSet) FitWhitener(names ...string) (*Whitener, error) {
+
+	ds.Reset()
+	var acc *covAccumulator
+	for {
+		df, e := ds.Next()
+		if e == io.EOF {
+			break
+		}
+		if e != nil {
+			ds.Reset()
+			return nil, e
+		}
+		for i := 0; i < df.N(); i++ {
+			x, e := df.Float64Slice(i, names...)
+			if e != nil {
+				ds.Reset()
+				return nil, e
+			}
+			if acc == nil {
+				acc = newCovAccumulator(len(x))
+			}
+			if e = acc.add(x); e != nil {
+				ds.Reset()
+				return nil, fmt.Errorf("In batch %s, frame %d: %s", df.BatchID, i, e)
+			}
+		}
+	}
+	if acc == nil || acc.n < 2 {
+		return nil, fmt.Errorf("Need at least two frames to estimate the covariance.")
+	}
+	return newWhitener(names, acc.mean, acc.cov()), nil
+}
+
+func newWhitener(names []string, mean, cov []float64) *Whitener {
+
+	dim := len(mean)
+	vals, vecs := symEigen(cov, dim)
+	return &Whitener{
+		Names:        names,
+		Mean:         mean,
+		Cov:          cov,
+		Eigenvalues:  vals,
+		Eigenvectors: vecs,
+		dim:          dim,
+	}
+}
+
+// Returns the dimension of the transform.
+func (w *Whitener) Dim() int {
+
+	return w.dim
+}
+
+// Rotates the centered vector into the eigenvector basis. The components of
+// the result are uncorrelated.
+func (w *Whitener) Decorrelate(x []float64) ([]float64, error) {
+
+	if len(x) != w.dim {
+		return nil, fmt.Errorf("Vector dimension is %d, expected %d.", len(x), w.dim)
+	}
+	y := make([]float64, w.dim)
+	for k := 0; k < w.dim; k++ {
+		var sum float64
+		for i := 0; i < w.dim; i++ {
+			sum += w.Eigenvectors[i*w.dim+k] * (x[i] - w.Mean[i])
+		}
+		y[k] = sum
+	}
+	return y, nil
+}
+
+// Decorrelates the vector and scales each component to unit variance.
+// Components with zero variance are set to zero.
+func (w *Whitener) Transform(x []float64) ([]float64, error) {
+
+	y, e := w.Decorrelate(x)
+	if e != nil {
+		return nil, e
+	}
+	for k := range y {
+		if w.Eigenvalues[k] <= 1e-12*math.Abs(w.Eigenvalues[0]) {
+			y[k] = 0
+			continue
+		}
+		y[k] /= math.Sqrt(w.Eigenvalues[k])
+	}
+	return y, nil
+}
+
+// Applies Transform to every vector received from in. Returns a channel of
+// transformed vectors and a channel that receives the error that stops the
+// transformation, such as a vector with the wrong dimension. Both channels
+// are closed at the end. Read the vectors channel to the end before reading
+// the error channel.
+func (w *Whitener) TransformChannel(in <-chan []float64) (<-chan []float64, <-chan error) {
+
+	return mapChan(in, w.Transform)
+}
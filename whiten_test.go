@@ -0,0 +1,77 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"testing"
+)
+
+func TestFitWhitener(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-whiten")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+
+	spec := synthSpec(500, 3, 0)
+	spec.Files = 3
+	ds, e := GenerateDataSet(spec, dir)
+	CheckError(t, e)
+
+	w, e := ds.FitWhitener("wifi", "acceleration")
+	CheckError(t, e)
+	if w.Dim() != 4 {
+		t.Fatalf("Dim must be 4, not %d.", w.Dim())
+	}
+	t.Logf("eigenvalues: %v", w.Eigenvalues)
+
+	// Whitened data must have zero mean and identity covariance.
+	acc := newCovAccumulator(4)
+	ch, errc := w.TransformChannel(ds.Float64SliceChannel("wifi", "acceleration"))
+	for x := range ch {
+		CheckError(t, acc.add(x))
+	}
+	CheckError(t, <-errc)
+	if acc.n != 1500 {
+		t.Fatalf("expected 1500 vectors, got %d.", acc.n)
+	}
+	cov := acc.cov()
+	for i := 0; i < 4; i++ {
+		if math.Abs(acc.mean[i]) > 1e-9 {
+			t.Fatalf("mean[%d] = %f, expected 0.", i, acc.mean[i])
+		}
+		for j := 0; j < 4; j++ {
+			expected := 0.0
+			if i == j {
+				expected = 1
+			}
+			if math.Abs(cov[i*4+j]-expected) > 1e-9 {
+				t.Fatalf("cov[%d,%d] = %f, expected %f.", i, j, cov[i*4+j], expected)
+			}
+		}
+	}
+
+	if _, e = w.Transform([]float64{1}); e == nil {
+		t.Fatalf("expected dimension error.")
+	}
+
+	// Errors are returned on the error channel.
+	in := make(chan []float64, 3)
+	in <- []float64{1, 2, 3, 4}
+	in <- []float64{1}
+	in <- []float64{1, 2, 3, 4}
+	close(in)
+	ch, errc = w.TransformChannel(in)
+	var n int
+	for _ = range ch {
+		n++
+	}
+	if e = <-errc; e == nil || n != 1 {
+		t.Fatalf("expected dimension error after 1 vector, got %d vectors and error %v.", n, e)
+	}
+}
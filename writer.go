@@ -0,0 +1,484 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"launchpad.net/goyaml"
+)
+
+// OverwritePolicy selects what file writers do when the output file
+// already exists.
+type OverwritePolicy int
+
+const (
+	// Replace the file atomically. The default.
+	OverwriteReplace OverwritePolicy = iota
+	// Keep the file and return an error.
+	OverwriteError
+	// Keep the file and don't write, for example to resume a job.
+	OverwriteSkip
+)
+
+var overwriteNames = []string{"replace", "error", "skip"}
+
+// Returns the policy with name "replace", "error", or "skip". An empty name
+// is OverwriteReplace.
+func ParseOverwritePolicy(name string) (OverwritePolicy, error) {
+
+	if name == "" {
+		return OverwriteReplace, nil
+	}
+	for k, n := range overwriteNames {
+		if n == name {
+			return OverwritePolicy(k), nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown overwrite policy [%s].", name)
+}
+
+// Returns the name of the policy.
+func (p OverwritePolicy) String() string {
+
+	if p < 0 || int(p) >= len(overwriteNames) {
+		return fmt.Sprintf("OverwritePolicy(%d)", int(p))
+	}
+	return overwriteNames[p]
+}
+
+// Writes the data frame to file fn in JSON format, see WriteDataFrame. The
+// file is replaced atomically so readers never see a partial file. Files
+// with extension GzipExt are compressed.
+func (df *DataFrame) WriteDataFrameFile(fn string) error {
+
+	return df.writeJSONFile(fn, 0644)
+}
+
+// Like WriteDataFrameFile, with a policy for an existing file.
+func (df *DataFrame) WriteDataFrameFileWith(fn string, policy OverwritePolicy) error {
+
+	_, err := writeFilePolicy(fn, 0644, policy, df.WriteDataFrame)
+	return err
+}
+
+// Writes the data frame to a temporary file in the directory of fn and
+// renames it to fn on success.
+func (df *DataFrame) writeJSONFile(fn string, perm os.FileMode) error {
+
+	return writeFileAtomic(fn, perm, df.WriteDataFrame)
+}
+
+// Calls write with a temporary file in the directory of fn and renames it to
+// fn on success. Files with extension GzipExt are compressed.
+func writeFileAtomic(fn string, perm os.FileMode, write func(io.Writer) error) error {
+
+	_, err := writeFilePolicy(fn, perm, OverwriteReplace, write)
+	return err
+}
+
+// Like writeFileAtomic, with a policy for an existing file. The temporary
+// file is synced before it is moved to fn, and removed on error. Files are
+// only created, never replaced, with OverwriteError and OverwriteSkip.
+// Returns false if the file was skipped.
+func writeFilePolicy(fn string, perm os.FileMode, policy OverwritePolicy, write func(io.Writer) error) (bool, error) {
+
+	if policy == OverwriteSkip && fileExists(fn) {
+		return false, nil
+	}
+	f, err := ioutil.TempFile(filepath.Dir(fn), "."+filepath.Base(fn))
+	if err != nil {
+		return false, err
+	}
+	tmp := f.Name()
+	if err = f.Chmod(perm); err == nil {
+		if strings.HasSuffix(fn, GzipExt) {
+			zw := gzip.NewWriter(f)
+			if err = write(zw); err == nil {
+				err = zw.Close()
+			}
+		} else {
+			err = write(f)
+		}
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if err == nil {
+		err = f.Close()
+	} else {
+		f.Close()
+	}
+	written := true
+	if err == nil {
+		if policy == OverwriteReplace {
+			err = os.Rename(tmp, fn)
+		} else {
+			// A link fails if fn exists, even if it was created after the
+			// check above.
+			if err = os.Link(tmp, fn); os.IsExist(err) {
+				if policy == OverwriteSkip {
+					written, err = false, nil
+				} else {
+					err = fmt.Errorf("already exists")
+				}
+			}
+			os.Remove(tmp)
+		}
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return false, fmt.Errorf("file %s: %s", fn, err)
+	}
+	return written, nil
+}
+
+// Writes the data frame in the JSON schema accepted by ReadDataFrame, with
+// one row per line. The output is deterministic: map keys are sorted and
+// floats use the shortest representation that reads back to the same value.
+// NaN and infinite values are written as {"float64": "NaN"}, "Inf", or
+// "-Inf", so the readers restore them as floats and a string cell "NaN"
+// stays a string.
+func (df *DataFrame) WriteDataFrame(w io.Writer) error {
+
+	if err := df.checkWrite(); err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	if err := df.writeJSONHeader(bw); err != nil {
+		return err
+	}
+	bw.WriteString("\"data\": [")
+	for i, row := range df.Data {
+		b, err := json.Marshal(finiteRow(row))
+		if err != nil {
+			return fmt.Errorf("In frame %d: %s", i, err)
+		}
+		if i > 0 {
+			bw.WriteString(",")
+		}
+		bw.WriteString("\n")
+		bw.Write(b)
+	}
+	bw.WriteString("\n]\n}\n")
+	return bw.Flush()
+}
+
+// Writes the opening brace and the fields that precede the data array.
+func (df *DataFrame) writeJSONHeader(bw *bufio.Writer) error {
+
+	field := func(name string, v interface{}) error {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("%s: %s", name, err)
+		}
+		fmt.Fprintf(bw, "%q: %s,\n", name, b)
+		return nil
+	}
+	bw.WriteString("{\n")
+	if err := field("description", df.Description); err != nil {
+		return err
+	}
+	if err := field("batchid", df.BatchID); err != nil {
+		return err
+	}
+	if err := field("var_names", df.VarNames); err != nil {
+		return err
+	}
+	if len(df.VarUnits) > 0 {
+		if err := field("var_units", df.VarUnits); err != nil {
+			return err
+		}
+	}
+	if len(df.Properties) > 0 {
+		if err := field("properties", df.Properties); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Key of the JSON object that encodes a non-finite float.
+const nonFiniteKey = "float64"
+
+// JSON has no NaN or infinity. Returns the row with non-finite floats,
+// including those in vectors, replaced by objects with the nonFiniteKey,
+// which restoreRow turns back into floats. The row is copied only if needed.
+func finiteRow(row []interface{}) []interface{} {
+
+	var out []interface{}
+	for j, x := range row {
+		y, ok := finiteValue(x)
+		if !ok {
+			continue
+		}
+		if out == nil {
+			out = append([]interface{}(nil), row...)
+		}
+		out[j] = y
+	}
+	if out == nil {
+		return row
+	}
+	return out
+}
+
+// Returns the value with non-finite floats replaced, and false if there
+// are none.
+func finiteValue(x interface{}) (interface{}, bool) {
+
+	switch v := x.(type) {
+	case float64:
+		if s, ok := nonFiniteString(v); ok {
+			return s, true
+		}
+	case []float64:
+		var out []interface{}
+		for e, f := range v {
+			s, ok := nonFiniteString(f)
+			if !ok {
+				continue
+			}
+			if out == nil {
+				out = make([]interface{}, len(v))
+				for k, f := range v {
+					out[k] = f
+				}
+			}
+			out[e] = s
+		}
+		if out != nil {
+			return out, true
+		}
+	case []interface{}:
+		var out []interface{}
+		for e, y := range v {
+			f, ok := y.(float64)
+			if !ok {
+				continue
+			}
+			s, ok := nonFiniteString(f)
+			if !ok {
+				continue
+			}
+			if out == nil {
+				out = append([]interface{}(nil), v...)
+			}
+			out[e] = s
+		}
+		if out != nil {
+			return out, true
+		}
+	}
+	return x, false
+}
+
+func nonFiniteString(f float64) (interface{}, bool) {
+
+	var s string
+	switch {
+	case math.IsNaN(f):
+		s = "NaN"
+	case math.IsInf(f, 1):
+		s = "Inf"
+	case math.IsInf(f, -1):
+		s = "-Inf"
+	default:
+		return nil, false
+	}
+	return map[string]string{nonFiniteKey: s}, true
+}
+
+// Restores, in place, the non-finite floats encoded by finiteRow, including
+// those in vectors.
+func restoreRow(row []interface{}) {
+
+	for j, x := range row {
+		switch v := x.(type) {
+		case map[string]interface{}:
+			if f, ok := restoreValue(v); ok {
+				row[j] = f
+			}
+		case []interface{}:
+			for e, y := range v {
+				if m, ok := y.(map[string]interface{}); ok {
+					if f, ok := restoreValue(m); ok {
+						v[e] = f
+					}
+				}
+			}
+		}
+	}
+}
+
+// Returns the float encoded by nonFiniteString, and false if m is not one.
+func restoreValue(m map[string]interface{}) (float64, bool) {
+
+	if len(m) != 1 {
+		return 0, false
+	}
+	s, ok := m[nonFiniteKey].(string)
+	if !ok {
+		return 0, false
+	}
+	return parseNonFinite(s)
+}
+
+// Name of the manifest written by DataSetWriter.
+const ManifestFile = "dataset.yaml"
+
+// A DataSetWriter writes data frames as files in a directory and keeps a
+// data set manifest, see ManifestFile, up to date after every file. It is
+// safe for concurrent use. Writers in different processes can share a
+// directory: files are never overwritten, and the manifest is updated while
+// holding a lease file, see LeaseExt, so no file is lost from it.
+type DataSetWriter struct {
+	sync.Mutex
+	dir    string
+	prefix string
+	files  []string
+	seq    int
+	sum    *summarizer
+}
+
+type manifest struct {
+	Path  string   `yaml:"path"`
+	Files []string `yaml:"files"`
+}
+
+// Returns a writer for directory dir, which is created if needed. Files are
+// named prefix-NNNNNN.json. If the directory already has a manifest, new
+// files are appended to it.
+func NewDataSetWriter(dir, prefix string) (*DataSetWriter, error) {
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	w := &DataSetWriter{dir: dir, prefix: prefix, sum: newSummarizer()}
+	files, err := readManifest(w.ManifestFile())
+	if err != nil {
+		return nil, err
+	}
+	w.files = files
+	w.seq = len(w.files)
+	return w, nil
+}
+
+// Returns the files listed in a manifest, or none if it doesn't exist.
+func readManifest(fn string) ([]string, error) {
+
+	b, err := ioutil.ReadFile(fn)
+	switch {
+	case os.IsNotExist(err):
+		return make([]string, 0), nil
+	case err != nil:
+		return nil, err
+	}
+	var m manifest
+	if err = goyaml.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("file %s: %s", fn, err)
+	}
+	return append(make([]string, 0, len(m.Files)), m.Files...), nil
+}
+
+// Returns the path of the manifest file.
+func (w *DataSetWriter) ManifestFile() string {
+
+	return filepath.Join(w.dir, ManifestFile)
+}
+
+// Writes a data frame to the next file and updates the manifest. Returns
+// the file name relative to the directory. Frames that don't satisfy the
+// schema set with SetSchema are not written and the error from
+// Schema.Check is returned.
+func (w *DataSetWriter) Write(df *DataFrame) (string, error) {
+
+	w.Lock()
+	defer w.Unlock()
+	if err := df.checkWrite(); err != nil {
+		return "", err
+	}
+	var name string
+	for {
+		name = fmt.Sprintf("%s-%06d.json", w.prefix, w.seq)
+		w.seq++
+		ok, err := writeFilePolicy(filepath.Join(w.dir, name), 0644, OverwriteSkip, df.WriteDataFrame)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			break
+		}
+	}
+	if err := w.addToManifest(name); err != nil {
+		return "", err
+	}
+	w.sum.add(df)
+	return name, nil
+}
+
+// Returns a summary of the data frames written by this writer, see
+// DataFrame.Summary. The summary is updated as frames are written; files
+// listed in the manifest before the writer was created are not included.
+func (w *DataSetWriter) Summary() Summary {
+
+	w.Lock()
+	defer w.Unlock()
+	return w.sum.summary()
+}
+
+// Adds a file to the manifest, including the files added by other writers
+// since it was last read.
+func (w *DataSetWriter) addToManifest(name string) error {
+
+	release, err := acquireLease(w.ManifestFile() + LeaseExt)
+	if err != nil {
+		return err
+	}
+	defer release()
+	files, err := readManifest(w.ManifestFile())
+	if err != nil {
+		return err
+	}
+	files = append(files, name)
+	if err = writeManifest(w.ManifestFile(), w.dir, files); err != nil {
+		return err
+	}
+	w.files = files
+	return nil
+}
+
+// Writes a manifest file atomically.
+func writeManifest(fn, dir string, files []string) error {
+
+	b, err := goyaml.Marshal(manifest{Path: dir, Files: files})
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(fn, 0644, func(out io.Writer) error {
+		_, err := out.Write(b)
+		return err
+	})
+}
+
+// Returns a data set with the files in the manifest after the last write.
+func (w *DataSetWriter) DataSet() *DataSet {
+
+	w.Lock()
+	defer w.Unlock()
+	return &DataSet{Path: w.dir, Files: append([]string(nil), w.files...)}
+}
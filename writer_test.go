@@ -0,0 +1,331 @@
+// Copyright 2013 AKUALAB INC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dataframe
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWriteDataFrame(t *testing.T) {
+
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	df.Properties = map[string]string{"url": "http://akualab.com", "status": "experimental"}
+	df.VarUnits = map[string]string{"acceleration": "m/s^2"}
+	df.Data[2][1] = nil
+	df.Data[3][2] = 0.1 + 0.2
+
+	var buf bytes.Buffer
+	CheckError(t, df.WriteDataFrame(&buf))
+	t.Log(buf.String())
+	df2, e := ReadDataFrame(bytes.NewReader(buf.Bytes()))
+	CheckError(t, e)
+	if !reflect.DeepEqual(df.VarNames, df2.VarNames) || !reflect.DeepEqual(df.Data, df2.Data) ||
+		!reflect.DeepEqual(df.Properties, df2.Properties) || !reflect.DeepEqual(df.VarUnits, df2.VarUnits) ||
+		df.Description != df2.Description || df.BatchID != df2.BatchID {
+		t.Fatalf("round trip is not lossless:\n%+v\n%+v", df, df2)
+	}
+
+	dir, e := ioutil.TempDir("", "dataframe-write")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	fn := filepath.Join(dir, "out.json")
+	CheckError(t, df.WriteDataFrameFile(fn))
+	b, e := ioutil.ReadFile(fn)
+	CheckError(t, e)
+	if !bytes.Equal(b, buf.Bytes()) {
+		t.Fatalf("file content differs from WriteDataFrame output.")
+	}
+
+	// Non-finite values round trip as floats, and strings stay strings.
+	df.Data[0][1] = []float64{math.NaN(), -1}
+	df.Data[0][2] = math.NaN()
+	df.Data[1][1] = []interface{}{math.Inf(1), math.Inf(-1)}
+	df.Data[1][2] = math.Inf(1)
+	df.Data[2][2] = math.Inf(-1)
+	df.Data[3][0] = "NaN"
+	df.Data[4][0] = "007"
+	buf.Reset()
+	CheckError(t, df.WriteDataFrame(&buf))
+	if !math.IsNaN(df.Data[0][1].([]float64)[0]) || !math.IsNaN(df.Data[0][2].(float64)) {
+		t.Fatalf("WriteDataFrame modified the data frame.")
+	}
+	df2, e = ReadDataFrame(bytes.NewReader(buf.Bytes()))
+	CheckError(t, e)
+	for i, want := range [][]float64{
+		{math.NaN(), -1, math.NaN()},
+		{math.Inf(1), math.Inf(-1), math.Inf(1)},
+	} {
+		got := append(append([]float64{}, cellFloats(t, df2.Data[i][1])...), cellFloats(t, df2.Data[i][2])...)
+		for j := range want {
+			if !(got[j] == want[j] || math.IsNaN(got[j]) && math.IsNaN(want[j])) {
+				t.Fatalf("frame %d: expected %v, got %v.", i, want, got)
+			}
+		}
+	}
+	if x, ok := df2.Data[2][2].(float64); !ok || !math.IsInf(x, -1) {
+		t.Fatalf("expected -Inf, got %v.", df2.Data[2][2])
+	}
+	if df2.Data[3][0] != "NaN" || df2.Data[4][0] != "007" {
+		t.Fatalf("expected strings \"NaN\" and \"007\", got %#v and %#v.", df2.Data[3][0], df2.Data[4][0])
+	}
+
+	// The streaming reader restores non-finite values too.
+	it, e := NewRowIterator(bytes.NewReader(buf.Bytes()))
+	CheckError(t, e)
+	row, e := it.Next()
+	CheckError(t, e)
+	if x, ok := row[2].(float64); !ok || !math.IsNaN(x) {
+		t.Fatalf("expected NaN, got %v.", row[2])
+	}
+}
+
+// Returns the floats of a float64 or []interface{} cell.
+func cellFloats(t *testing.T, x interface{}) []float64 {
+
+	switch v := x.(type) {
+	case float64:
+		return []float64{v}
+	case []interface{}:
+		var out []float64
+		for _, y := range v {
+			f, ok := y.(float64)
+			if !ok {
+				t.Fatalf("expected float64, got %#v.", y)
+			}
+			out = append(out, f)
+		}
+		return out
+	}
+	t.Fatalf("expected float64 or vector, got %#v.", x)
+	return nil
+}
+
+func TestOverwritePolicy(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-overwrite")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	df1, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+	df2, e := ReadDataFrame(strings.NewReader(file2))
+	CheckError(t, e)
+	fn := filepath.Join(dir, "out.json")
+
+	// Returns the batch id of the file.
+	batch := func() string {
+		df, e := ReadDataFrameFile(fn)
+		CheckError(t, e)
+		return df.BatchID
+	}
+	CheckError(t, df1.WriteDataFrameFileWith(fn, OverwriteError))
+	if e = df2.WriteDataFrameFileWith(fn, OverwriteError); e == nil || batch() != df1.BatchID {
+		t.Fatalf("expected error and original file.")
+	}
+	CheckError(t, df2.WriteDataFrameFileWith(fn, OverwriteSkip))
+	if batch() != df1.BatchID {
+		t.Fatalf("expected skipped file.")
+	}
+	CheckError(t, df2.WriteDataFrameFileWith(fn, OverwriteReplace))
+	if batch() != df2.BatchID {
+		t.Fatalf("expected replaced file.")
+	}
+	bin := filepath.Join(dir, "out"+BinaryExt)
+	CheckError(t, df1.WriteBinaryFileWith(bin, OverwriteError))
+	if e = df1.WriteBinaryFileWith(bin, OverwriteError); e == nil {
+		t.Fatalf("expected error for existing binary file.")
+	}
+
+	// No temporary files are left behind.
+	files, e := ioutil.ReadDir(dir)
+	CheckError(t, e)
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files, got %d.", len(files))
+	}
+
+	for _, name := range []string{"", "replace", "error", "skip"} {
+		p, e := ParseOverwritePolicy(name)
+		CheckError(t, e)
+		if name != "" && p.String() != name {
+			t.Fatalf("expected policy %s, got %s.", name, p)
+		}
+	}
+	if _, e = ParseOverwritePolicy("append"); e == nil {
+		t.Fatalf("expected error for unknown policy.")
+	}
+}
+
+func TestDataSetWriter(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-writer")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "out")
+
+	w, e := NewDataSetWriter(out, "imu")
+	CheckError(t, e)
+	for i := 0; i < 2; i++ {
+		name, e := w.Write(synthFrame(5, 2, int64(i)))
+		CheckError(t, e)
+		t.Log(name)
+	}
+
+	// Reopen and append.
+	w, e = NewDataSetWriter(out, "imu")
+	CheckError(t, e)
+	name, e := w.Write(synthFrame(3, 2, 2))
+	CheckError(t, e)
+	if name != "imu-000002.json" {
+		t.Fatalf("unexpected file name %s.", name)
+	}
+
+	ds, e := ReadDataSetFile(w.ManifestFile())
+	CheckError(t, e)
+	if len(ds.Files) != 3 || len(w.DataSet().Files) != 3 {
+		t.Fatalf("unexpected files %v.", ds.Files)
+	}
+	var rows int
+	for {
+		df, e := ds.Next()
+		if e == io.EOF {
+			break
+		}
+		CheckError(t, e)
+		rows += df.N()
+	}
+	if rows != 13 {
+		t.Fatalf("expected 13 rows, got %d.", rows)
+	}
+
+	// Summary of the frames written by the second writer.
+	if sum := w.Summary(); len(sum) == 0 || sum[0].Count+sum[0].NA != 3 {
+		t.Fatalf("unexpected summary %v.", sum)
+	}
+}
+
+func TestDataSetWriterShared(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-writer")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+
+	// Writers that share a directory, as in different processes.
+	var wg sync.WaitGroup
+	errs := make(chan error, 40)
+	for k := 0; k < 4; k++ {
+		w, e := NewDataSetWriter(dir, "imu")
+		CheckError(t, e)
+		wg.Add(1)
+		go func(w *DataSetWriter) {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				if _, e := w.Write(synthFrame(2, 2, int64(i))); e != nil {
+					errs <- e
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for e := range errs {
+		CheckError(t, e)
+	}
+	ds, e := ReadDataSetFile(filepath.Join(dir, ManifestFile))
+	CheckError(t, e)
+	seen := make(map[string]bool)
+	for _, name := range ds.Files {
+		seen[name] = true
+	}
+	if len(ds.Files) != 40 || len(seen) != 40 {
+		t.Fatalf("expected 40 distinct files, got %d.", len(seen))
+	}
+	if fileExists(filepath.Join(dir, ManifestFile+LeaseExt)) {
+		t.Fatalf("lease file must be removed.")
+	}
+}
+
+func TestWriteSchema(t *testing.T) {
+
+	dir, e := ioutil.TempDir("", "dataframe-writer")
+	CheckError(t, e)
+	defer os.RemoveAll(dir)
+
+	min := -42.0
+	schema := Schema{
+		{Name: "room", Type: TypeString, Required: true, Levels: []string{"BED5", "DINING"}},
+		{Name: "wifi", Type: TypeVector, Min: &min},
+		{Name: "acceleration", Type: TypeFloat64},
+	}
+	df, e := ReadDataFrame(strings.NewReader(file1))
+	CheckError(t, e)
+
+	// file1 has wifi values below -42.
+	df.SetSchema(schema)
+	w, e := NewDataSetWriter(dir, "room")
+	CheckError(t, e)
+	_, e = w.Write(df)
+	ve, ok := e.(*ValidationError)
+	if !ok || len(ve.Violations) == 0 || ve.Violations[0].Rule != RuleMin {
+		t.Fatalf("expected *ValidationError, got %v.", e)
+	}
+	t.Log(e)
+	if len(w.DataSet().Files) != 0 {
+		t.Fatalf("invalid frame must not be added to the manifest.")
+	}
+
+	// Every writer enforces the schema set on the frame.
+	var buf bytes.Buffer
+	for name, write := range map[string]func() error{
+		"json":   func() error { return df.WriteDataFrame(&buf) },
+		"binary": func() error { return df.WriteBinary(&buf) },
+		"avro":   func() error { return df.WriteAvro(&buf) },
+		"arrow":  func() error { return df.WriteArrow(&buf) },
+		"vw":     func() error { return df.WriteVW(&buf, VWSpec{}) },
+		"go":     func() error { return df.WriteGoSource(&buf, "rooms", "Rooms") },
+	} {
+		if _, ok := write().(*ValidationError); !ok {
+			t.Fatalf("%s writer: expected *ValidationError.", name)
+		}
+	}
+	fn := filepath.Join(dir, "file1.bin")
+	if e = df.WriteBinaryFile(fn); e == nil {
+		t.Fatalf("expected validation error.")
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("invalid frame must not be written.")
+	}
+	if _, err := os.Stat(fn); !os.IsNotExist(err) {
+		t.Fatalf("invalid frame must not be written.")
+	}
+	df.SetSchema(nil)
+	CheckError(t, df.WriteBinary(&buf))
+
+	// Valid frame.
+	df.SetSchema(schema)
+	for _, row := range df.Data {
+		row[1] = []interface{}{-10.0, -20.0}
+	}
+	_, e = w.Write(df)
+	CheckError(t, e)
+
+	// Nil and unknown levels.
+	df.Data[0][0] = nil
+	df.Data[1][0] = "KITCHEN"
+	e = df.WriteDataFrame(&buf)
+	if ve, ok = e.(*ValidationError); !ok || len(ve.Violations) != 2 ||
+		ve.Violations[0].Rule != RuleRequired || ve.Violations[1].Rule != RuleLevels {
+		t.Fatalf("expected required and levels violations, got %v.", e)
+	}
+}